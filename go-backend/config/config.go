@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +17,12 @@ type Config struct {
 	SupabaseKey            string
 	SupabaseServiceRoleKey string
 	CohereAPIKey           string
+	MetricsToken           string // Optional bearer token required to scrape /metrics
+	TracingExporter        string // otlp, stdout or none
+	TracingServiceName     string
+	TracingSampleRatio     float64
+	LogLevel               string // debug, info, warn, error
+	LogFormat              string // json or text
 }
 
 func LoadConfig() *Config {
@@ -42,6 +49,12 @@ func LoadConfig() *Config {
 		SupabaseKey:            os.Getenv("SUPABASE_ANON_KEY"),
 		SupabaseServiceRoleKey: os.Getenv("SUPABASE_SERVICE_ROLE_KEY"),
 		CohereAPIKey:           os.Getenv("COHERE_API_KEY"),
+		MetricsToken:           os.Getenv("METRICS_TOKEN"),
+		TracingExporter:        getEnv("TRACING_EXPORTER", "none"),
+		TracingServiceName:     getEnv("OTEL_SERVICE_NAME", "matic-platform-go"),
+		TracingSampleRatio:     getEnvFloat("TRACING_SAMPLE_RATIO", 1.0),
+		LogLevel:               getEnv("LOG_LEVEL", "info"),
+		LogFormat:              getEnv("LOG_FORMAT", "json"),
 	}
 }
 
@@ -51,3 +64,12 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
@@ -3,8 +3,12 @@ package database
 import (
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
+	"github.com/Jsanchez767/matic-platform/metrics"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -12,6 +16,27 @@ import (
 
 var DB *gorm.DB
 
+// Outcome of the last AutoMigrate run, reported by /readyz
+var (
+	migrationMu    sync.RWMutex
+	migrationRanAt time.Time
+	migrationErr   error
+)
+
+// MigrationStatus returns when AutoMigrate last ran (zero if it hasn't) and its error
+func MigrationStatus() (time.Time, error) {
+	migrationMu.RLock()
+	defer migrationMu.RUnlock()
+	return migrationRanAt, migrationErr
+}
+
+func recordMigration(err error) {
+	migrationMu.Lock()
+	defer migrationMu.Unlock()
+	migrationRanAt = time.Now()
+	migrationErr = err
+}
+
 func InitDB(databaseURL string) error {
 	var err error
 
@@ -35,6 +60,14 @@ func InitDB(databaseURL string) error {
 	sqlDB.SetMaxIdleConns(3)
 	sqlDB.SetMaxOpenConns(10)
 
+	// Expose pool stats (open/in-use/idle/wait) on /metrics
+	metrics.RegisterDBStats(sqlDB)
+
+	// Trace queries issued with DB.WithContext(ctx) under an active span
+	if err := tracing.RegisterGORMCallbacks(DB); err != nil {
+		log.Printf("⚠️  Failed to register GORM tracing callbacks: %v", err)
+	}
+
 	log.Println("✅ Database connected successfully")
 	return nil
 }
@@ -89,7 +122,14 @@ func AutoMigrate() error {
 		&models.EmailDraft{},
 		&models.EmailQueueItem{},
 		&models.ResendIntegration{},
+		&models.SMTPIntegration{},
+		&models.EmailRoutingPolicy{},
 		&models.EmailServiceHealth{},
+		&models.EmailSuppression{},
+		&models.EmailPreference{},
+		&models.EmailLink{},
+		&models.EmailCampaignVariant{},
+		&models.InboundEmail{},
 
 		// Ending Pages
 		&models.EndingPage{},
@@ -104,6 +144,7 @@ func AutoMigrate() error {
 		&models.AutomationIntegration{},
 	)
 
+	recordMigration(err)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -87,6 +87,14 @@ func DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": result})
 }
 
+// AuthUser is a legacy Supabase auth user as listed by ListAuthUsers
+type AuthUser struct {
+	ID         string  `json:"id"`
+	Email      string  `json:"email"`
+	CreatedAt  string  `json:"created_at"`
+	LastSignIn *string `json:"last_sign_in_at"`
+}
+
 // ListAuthUsers lists all users in auth.users (admin only)
 func ListAuthUsers(c *gin.Context) {
 	// Get the requesting user
@@ -108,8 +116,8 @@ func ListAuthUsers(c *gin.Context) {
 	useBetterAuth := queryType == "better_auth" || queryType == "ba" || queryType == "betterauth"
 
 	// Debug logging
-	fmt.Printf("🔍 ListAuthUsers: queryType=%s, useBetterAuth=%v, requestingUserID=%s, URL=%s\n",
-		queryType, useBetterAuth, requestingUserID, c.Request.URL.String())
+	slog.DebugContext(c.Request.Context(), "listing auth users",
+		"query_type", queryType, "use_better_auth", useBetterAuth, "requesting_user_id", requestingUserID, "url", c.Request.URL.String())
 
 	if useBetterAuth {
 		// For Better Auth users (CRM), we want to show only portal users (applicants)
@@ -122,7 +130,8 @@ func ListAuthUsers(c *gin.Context) {
 		var userExists bool
 		userCheckErr := database.DB.Raw("SELECT EXISTS(SELECT 1 FROM ba_users WHERE id = ?)", requestingUserID).Scan(&userExists).Error
 
-		fmt.Printf("🔍 Better Auth user check: userExists=%v, userCheckErr=%v, requestingUserID=%s\n", userExists, userCheckErr, requestingUserID)
+		slog.DebugContext(c.Request.Context(), "better auth user check",
+			"user_exists", userExists, "error", userCheckErr, "requesting_user_id", requestingUserID)
 
 		if userCheckErr != nil || !userExists {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid user"})
@@ -148,11 +157,11 @@ func ListAuthUsers(c *gin.Context) {
 		var count int64
 		countErr := database.DB.Raw("SELECT COUNT(*) FROM portal_applicants").Scan(&count).Error
 		if countErr != nil {
-			fmt.Printf("❌ Error counting portal applicants: %v\n", countErr)
+			slog.ErrorContext(c.Request.Context(), "failed to count portal applicants", "error", countErr)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count portal applicants: " + countErr.Error()})
 			return
 		}
-		fmt.Printf("📊 Total portal applicants in table: %d\n", count)
+		slog.DebugContext(c.Request.Context(), "portal applicants in table", "count", count)
 
 		var users []BetterAuthUser
 		queryErr := database.DB.Raw(`
@@ -171,14 +180,14 @@ func ListAuthUsers(c *gin.Context) {
 		`).Scan(&users).Error
 
 		if queryErr != nil {
-			fmt.Printf("❌ Error fetching portal applicants: %v\n", queryErr)
+			slog.ErrorContext(c.Request.Context(), "failed to fetch portal applicants", "error", queryErr)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch portal applicants: " + queryErr.Error()})
 			return
 		}
 
-		fmt.Printf("✅ Fetched %d portal applicants from portal_applicants table\n", len(users))
+		slog.DebugContext(c.Request.Context(), "fetched portal applicants", "count", len(users))
 		if len(users) > 0 {
-			fmt.Printf("📝 First user: %+v\n", users[0])
+			slog.DebugContext(c.Request.Context(), "first portal applicant", "user_id", users[0].ID)
 		}
 
 		// Ensure we always return an array, not null
@@ -204,14 +213,6 @@ func ListAuthUsers(c *gin.Context) {
 		return
 	}
 
-	// Get all users from auth.users (legacy Supabase)
-	type AuthUser struct {
-		ID         string  `json:"id"`
-		Email      string  `json:"email"`
-		CreatedAt  string  `json:"created_at"`
-		LastSignIn *string `json:"last_sign_in_at"`
-	}
-
 	var users []AuthUser
 	err = database.DB.Raw(`
 		SELECT id, email, created_at, last_sign_in_at
@@ -3,14 +3,22 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 )
 
+// AutosaveSubmissionInput is a partial save of a portal submission
+type AutosaveSubmissionInput struct {
+	Changes     map[string]interface{} `json:"changes" binding:"required"`
+	BaseVersion int                    `json:"base_version"`
+}
+
 // AutosavePortalSubmission - POST /api/v1/submissions/:id/autosave
 // Autosaves changed fields for a portal submission with optimistic locking
 func AutosavePortalSubmission(c *gin.Context) {
@@ -23,10 +31,7 @@ func AutosavePortalSubmission(c *gin.Context) {
 	}
 
 	// Parse request body
-	var input struct {
-		Changes     map[string]interface{} `json:"changes" binding:"required"`
-		BaseVersion int                    `json:"base_version"`
-	}
+	var input AutosaveSubmissionInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
@@ -42,13 +47,16 @@ func AutosavePortalSubmission(c *gin.Context) {
 	// For now, we don't do version conflict detection
 	// In the future, we could add a version field to FormSubmission and check it here
 
-	// Fetch form fields
-	var fields []models.FormField
-	if err := database.DB.Where("form_id = ?", submission.FormID).Find(&fields).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch form fields"})
+	// Closed or not-yet-open forms take no more answers
+	form, err := services.EnforceFormLifecycle(database.DB, submission.FormID, userID, &submission, false)
+	if err != nil {
+		respondFormUnavailable(c, err)
 		return
 	}
 
+	// Fetch the fields of the version the submission is pinned to
+	fields := services.PinnedFormFields(database.DB, form, submission.FormVersion)
+
 	// Create field map for lookup
 	fieldMap := make(map[string]models.FormField)
 	for _, field := range fields {
@@ -67,9 +75,12 @@ func AutosavePortalSubmission(c *gin.Context) {
 	for fieldKey, value := range input.Changes {
 		field, exists := fieldMap[fieldKey]
 		if !exists {
-			fmt.Printf("Warning: Field %s not found in form schema, skipping\n", fieldKey)
+			slog.WarnContext(c.Request.Context(), "field not found in form schema, skipping", "field_key", fieldKey)
 			continue
 		}
+		if services.IsCalculatedField(field) {
+			continue // The server computes these
+		}
 
 		// Determine value type
 		var valueType string
@@ -116,6 +127,7 @@ func AutosavePortalSubmission(c *gin.Context) {
 			ValueNumber:  valueNumber,
 			ValueBoolean: valueBoolean,
 			ValueJSON:    valueJSON,
+			ValueSource:  services.ValueSourceUser,
 			IsValid:      true,
 		}
 
@@ -128,11 +140,18 @@ func AutosavePortalSubmission(c *gin.Context) {
 		}
 	}
 
+	storeComputedResponses(c.Request.Context(), tx, fields, submission.ID, userID)
+
 	// Update submission metadata
+	var responses []models.FormResponse
+	tx.Where("submission_id = ?", submission.ID).Find(&responses)
+	path := services.ResolveSubmissionPath(tx, form, submission.FormVersion, services.SubmissionValues(fields, responses))
+
 	now := time.Now()
 	updates := map[string]interface{}{
-		"last_saved_at": now,
-		"updated_at":    now,
+		"last_saved_at":         now,
+		"updated_at":            now,
+		"completion_percentage": path.CompletionPercentage,
 	}
 
 	if err := tx.Model(&submission).Updates(updates).Error; err != nil {
@@ -141,6 +160,9 @@ func AutosavePortalSubmission(c *gin.Context) {
 		return
 	}
 
+	// Flag answers that would block submitting; autosave itself always succeeds
+	warnings := recordValidationWarnings(tx, fields, path, submission.ID)
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
@@ -153,5 +175,9 @@ func AutosavePortalSubmission(c *gin.Context) {
 		"version":  1,
 		"saved_at": now.Format(time.RFC3339),
 		"conflict": false,
+		"warnings": warnings,
+
+		"completion_percentage": path.CompletionPercentage,
+		"next_section_id":       path.NextSectionID,
 	})
 }
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// blindRedactorFor returns the redactor to apply before showing a
+// submission to the user, or nil when they may see it as submitted: the
+// form isn't blind, they are a workspace owner or editor, or it's their own
+func blindRedactorFor(form models.Form, fields []models.FormField, userID, applicantID string) *services.BlindRedactor {
+	redactor := services.NewBlindRedactor(form, fields)
+	if redactor == nil || (userID != "" && userID == applicantID) {
+		return nil
+	}
+	if checkWorkspaceRole(form.WorkspaceID, userID, reviewManagerRoles...) {
+		return nil
+	}
+	return redactor
+}
+
+// redactReviewQueue hides applicants on blind-review forms from a reviewer
+// who doesn't manage the form
+func redactReviewQueue(queue []models.ReviewQueueItem, reviewerID string) {
+	blind := map[uuid.UUID]bool{}
+	checked := map[uuid.UUID]bool{}
+	for i := range queue {
+		formID := queue[i].FormID
+		if !checked[formID] {
+			checked[formID] = true
+			var form models.Form
+			if err := database.DB.Select("id", "workspace_id", "settings").First(&form, "id = ?", formID).Error; err == nil {
+				blind[formID] = services.BlindReviewConfig(form.Settings).Enabled &&
+					!checkWorkspaceRole(form.WorkspaceID, reviewerID, reviewManagerRoles...)
+			}
+		}
+		if blind[formID] {
+			queue[i].ApplicantID = ""
+			queue[i].ApplicantName = ""
+		}
+	}
+}
+
+// GetRedactedSubmissionDocumentV2 serves the blind-review copy of an upload,
+// with the applicant's PII blacked out. The copy is made on first request
+// and cached.
+// GET /api/v2/submissions/:id/documents/:field_key/:index
+func GetRedactedSubmissionDocumentV2(c *gin.Context) {
+	userID := requestUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document index"})
+		return
+	}
+
+	var submission models.FormSubmission
+	if err := database.DB.Preload("Form").First(&submission, "id = ?", c.Param("id")).Error; err != nil || submission.Form == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+	if submission.UserID != userID &&
+		!checkWorkspaceRole(submission.Form.WorkspaceID, userID, reviewManagerRoles...) &&
+		!isAssignedReviewer(submission.ID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	fields := services.PinnedFormFields(database.DB, *submission.Form, submission.FormVersion)
+	redactor := services.NewBlindRedactor(*submission.Form, fields)
+	if redactor == nil || !redactor.Settings.RedactDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "This form does not redact documents"})
+		return
+	}
+
+	key := c.Param("field_key")
+	var field *models.FormField
+	for i := range fields {
+		if fields[i].FieldKey == key || fields[i].ID.String() == key ||
+			(fields[i].LegacyFieldID != nil && fields[i].LegacyFieldID.String() == key) {
+			field = &fields[i]
+			break
+		}
+	}
+	if field == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	answers := services.SubmissionAnswers(database.DB, submission)
+	sourceURL, ok := services.FileURLAt(answers[field.FieldKey], index)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	document, err := services.RedactDocumentForReview(c.Request.Context(), database.DB, submission.ID, sourceURL, redactor.KnownPII(answers))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to redact document", "component", "blind_review",
+			"submission_id", submission.ID, "field_key", field.FieldKey, "index", index, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to redact document"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, document.MimeType, document.Data)
+}
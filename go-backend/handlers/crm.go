@@ -175,6 +175,32 @@ func GetApplicantsCRM(c *gin.Context) {
 	c.JSON(http.StatusOK, applicants)
 }
 
+// ApplicantDetail is an applicant's profile as shown in the CRM
+type ApplicantDetail struct {
+	ID            string  `json:"id"`
+	Email         string  `json:"email"`
+	Name          *string `json:"name"`
+	UserType      string  `json:"user_type"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     *string `json:"updated_at,omitempty"`
+	EmailVerified bool    `json:"email_verified"`
+	LastLoginAt   *string `json:"last_login_at,omitempty"`
+}
+
+// ApplicationDetail is one of an applicant's applications as shown in the CRM
+type ApplicationDetail struct {
+	FormID        string                 `json:"form_id"`
+	FormName      string                 `json:"form_name"`
+	FormSlug      *string                `json:"form_slug,omitempty"`
+	SubmissionID  *string                `json:"submission_id,omitempty"`
+	Status        string                 `json:"status"`
+	CompletionPct int                    `json:"completion_percentage"`
+	SubmittedAt   *string                `json:"submitted_at,omitempty"`
+	LastSavedAt   *string                `json:"last_saved_at,omitempty"`
+	CreatedAt     string                 `json:"created_at"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+}
+
 // GetApplicantDetail returns detailed information about a single applicant
 // GET /api/v1/crm/applicants/:id?workspace_id=xxx
 func GetApplicantDetail(c *gin.Context) {
@@ -204,18 +230,6 @@ func GetApplicantDetail(c *gin.Context) {
 		return
 	}
 
-	// Get applicant details
-	type ApplicantDetail struct {
-		ID            string  `json:"id"`
-		Email         string  `json:"email"`
-		Name          *string `json:"name"`
-		UserType      string  `json:"user_type"`
-		CreatedAt     string  `json:"created_at"`
-		UpdatedAt     *string `json:"updated_at,omitempty"`
-		EmailVerified bool    `json:"email_verified"`
-		LastLoginAt   *string `json:"last_login_at,omitempty"`
-	}
-
 	var applicant ApplicantDetail
 	err = database.DB.Raw(`
 		SELECT 
@@ -240,20 +254,6 @@ func GetApplicantDetail(c *gin.Context) {
 		return
 	}
 
-	// Get their applications in this workspace
-	type ApplicationDetail struct {
-		FormID        string                 `json:"form_id"`
-		FormName      string                 `json:"form_name"`
-		FormSlug      *string                `json:"form_slug,omitempty"`
-		SubmissionID  *string                `json:"submission_id,omitempty"`
-		Status        string                 `json:"status"`
-		CompletionPct int                    `json:"completion_percentage"`
-		SubmittedAt   *string                `json:"submitted_at,omitempty"`
-		LastSavedAt   *string                `json:"last_saved_at,omitempty"`
-		CreatedAt     string                 `json:"created_at"`
-		Data          map[string]interface{} `json:"data,omitempty"`
-	}
-
 	var applications []ApplicationDetail
 	err = database.DB.Raw(`
 		SELECT 
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 
@@ -668,7 +669,7 @@ func CreateTableColumn(c *gin.Context) {
 	fieldType, exists := fieldService.GetFieldType(input.Type)
 	if !exists {
 		// Log warning but don't fail - allows for unknown types during transition
-		fmt.Printf("Warning: Unknown field type '%s', proceeding without registry defaults\n", input.Type)
+		slog.WarnContext(c.Request.Context(), "unknown field type, proceeding without registry defaults", "field_type", input.Type)
 	}
 
 	// Build field with field_type_id properly set
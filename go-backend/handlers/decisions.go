@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ============================================
+// DECISION HANDLERS
+// Accept / waitlist / reject, award pools and decision release
+// ============================================
+
+// ==================== AWARD POOLS ====================
+
+// ListAwardPoolsV2 lists a form's award pools with what is allocated from each
+// GET /api/v2/forms/:id/award-pools
+func ListAwardPoolsV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var pools []models.AwardPool
+	if err := database.DB.Where("form_id = ?", form.ID).Order("created_at ASC").Find(&pools).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.FillPoolBalances(database.DB, pools); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pools)
+}
+
+// CreateAwardPoolV2 creates an award budget for a form
+// POST /api/v2/forms/:id/award-pools
+func CreateAwardPoolV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name     string   `json:"name" binding:"required"`
+		Budget   *float64 `json:"budget" binding:"required"`
+		Currency string   `json:"currency"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if *input.Budget < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "budget can't be negative"})
+		return
+	}
+
+	pool := models.AwardPool{
+		FormID:   form.ID,
+		Name:     input.Name,
+		Budget:   *input.Budget,
+		Currency: strings.ToUpper(input.Currency),
+	}
+	if pool.Currency == "" {
+		pool.Currency = "USD"
+	}
+	if err := database.DB.Create(&pool).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	pool.Remaining = pool.Budget
+
+	c.JSON(http.StatusCreated, pool)
+}
+
+// UpdateAwardPoolV2 renames a pool or changes its budget. The budget can't
+// drop below what is already awarded from it.
+// PATCH /api/v2/forms/:id/award-pools/:pool_id
+func UpdateAwardPoolV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name     *string  `json:"name"`
+		Budget   *float64 `json:"budget"`
+		Currency *string  `json:"currency"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var pool models.AwardPool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&pool, "id = ? AND form_id = ?", c.Param("pool_id"), form.ID).Error; err != nil {
+			return err
+		}
+		if input.Budget != nil {
+			if err := services.SetAwardPoolBudget(tx, &pool, *input.Budget); err != nil {
+				return err
+			}
+		}
+		if input.Name != nil {
+			pool.Name = *input.Name
+		}
+		if input.Currency != nil {
+			pool.Currency = strings.ToUpper(*input.Currency)
+		}
+		if err := tx.Save(&pool).Error; err != nil {
+			return err
+		}
+		pools := []models.AwardPool{pool}
+		err := services.FillPoolBalances(tx, pools)
+		pool = pools[0]
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Award pool not found"})
+		return
+	}
+	if err != nil {
+		respondDecisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pool)
+}
+
+// DeleteAwardPoolV2 deletes a pool no decision draws on
+// DELETE /api/v2/forms/:id/award-pools/:pool_id
+func DeleteAwardPoolV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var pool models.AwardPool
+		if err := tx.First(&pool, "id = ? AND form_id = ?", c.Param("pool_id"), form.ID).Error; err != nil {
+			return err
+		}
+		return services.DeleteAwardPool(tx, pool)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Award pool not found"})
+		return
+	}
+	if err != nil {
+		respondDecisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Award pool deleted"})
+}
+
+// ==================== DECISIONS ====================
+
+// ListDecisionsV2 lists a form's decisions, the waitlist in rank order
+// GET /api/v2/forms/:id/decisions?decision=waitlisted&released=false
+func ListDecisionsV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Preload("AwardPool").Where("form_id = ?", form.ID)
+	if decision := c.Query("decision"); decision != "" {
+		query = query.Where("decision = ?", decision)
+	}
+	switch c.Query("released") {
+	case "true":
+		query = query.Where("released_at IS NOT NULL")
+	case "false":
+		query = query.Where("released_at IS NULL")
+	}
+
+	var decisions []models.SubmissionDecision
+	if err := query.Order("decision ASC, waitlist_rank ASC NULLS LAST, decided_at ASC").Find(&decisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
+
+// GetSubmissionDecisionV2 returns a submission's decision: in full to
+// workspace owners and editors, and to the applicant only once released
+// GET /api/v2/submissions/:id/decision
+func GetSubmissionDecisionV2(c *gin.Context) {
+	userID := requestUserID(c)
+
+	var submission models.FormSubmission
+	if err := database.DB.Preload("Form").First(&submission, "id = ?", c.Param("id")).Error; err != nil || submission.Form == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+
+	if checkWorkspaceRole(submission.Form.WorkspaceID, userID, reviewManagerRoles...) {
+		var decision models.SubmissionDecision
+		if err := database.DB.Preload("AwardPool").First(&decision, "submission_id = ?", submission.ID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No decision yet"})
+			return
+		}
+		c.JSON(http.StatusOK, decision)
+		return
+	}
+
+	if userID == "" || submission.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+	decision := services.PortalDecisionFor(database.DB, submission.ID)
+	if decision == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No decision yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// SaveSubmissionDecisionV2 records or changes a submission's decision. The
+// applicant isn't told until it is released.
+// PUT /api/v2/submissions/:id/decision
+func SaveSubmissionDecisionV2(c *gin.Context) {
+	submission, ok := loadReviewManagerSubmissionV2(c)
+	if !ok {
+		return
+	}
+
+	var input services.DecisionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID := requestUserID(c)
+	var decision models.SubmissionDecision
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		decision, err = services.SaveDecision(tx, submission, input, optionalString(actorID))
+		return err
+	})
+	if err != nil {
+		respondDecisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// DeleteSubmissionDecisionV2 clears a decision that hasn't been released
+// DELETE /api/v2/submissions/:id/decision
+func DeleteSubmissionDecisionV2(c *gin.Context) {
+	submission, ok := loadReviewManagerSubmissionV2(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("submission_id = ? AND released_at IS NULL", submission.ID).Delete(&models.SubmissionDecision{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "No unreleased decision to clear"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Decision cleared"})
+}
+
+// ReleaseDecisionsInput picks which decisions to release and when
+type ReleaseDecisionsInput struct {
+	SubmissionIDs []uuid.UUID `json:"submission_ids"`
+	ReleaseAt     *time.Time  `json:"release_at"`
+}
+
+// ReleaseDecisionsV2 schedules a form's unreleased decisions, or the listed
+// submissions', to be shown to applicants at release_at. Without a time, or
+// with one already past, they are released now and decision emails queued.
+// POST /api/v2/forms/:id/decisions/release
+func ReleaseDecisionsV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var input ReleaseDecisionsInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	releaseAt := time.Now()
+	if input.ReleaseAt != nil {
+		releaseAt = *input.ReleaseAt
+	}
+
+	scheduled, err := services.ScheduleDecisionRelease(database.DB, form.ID, input.SubmissionIDs, releaseAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	released := 0
+	if !releaseAt.After(time.Now()) {
+		released, err = services.ReleaseDueDecisions(c.Request.Context(), &form.ID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to release decisions", "component", "decisions",
+				"form_id", form.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "released": released})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"scheduled": scheduled, "released": released, "release_at": releaseAt})
+}
+
+// PromoteWaitlistInput controls how many waitlisted submissions are promoted
+type PromoteWaitlistInput struct {
+	Count     int        `json:"count"`
+	Release   bool       `json:"release"`
+	ReleaseAt *time.Time `json:"release_at"`
+}
+
+// PromoteWaitlistV2 accepts the next count waitlisted submissions, each with
+// the award it was waitlisted for, stopping when a pool runs out. Promoted
+// decisions are released at release_at, now when release is set, or held.
+// POST /api/v2/forms/:id/decisions/promote-waitlist
+func PromoteWaitlistV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var input PromoteWaitlistInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	releaseAt := input.ReleaseAt
+	if releaseAt == nil && input.Release {
+		now := time.Now()
+		releaseAt = &now
+	}
+
+	actorID := requestUserID(c)
+	var promoted []models.SubmissionDecision
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		promoted, err = services.PromoteFromWaitlist(tx, form.ID, input.Count, releaseAt, optionalString(actorID))
+		return err
+	})
+	if err != nil {
+		respondDecisionError(c, err)
+		return
+	}
+
+	released := 0
+	if releaseAt != nil && !releaseAt.After(time.Now()) {
+		if released, err = services.ReleaseDueDecisions(c.Request.Context(), &form.ID); err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to release promoted decisions", "component", "decisions",
+				"form_id", form.ID, "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promoted": promoted, "released": released})
+}
+
+// respondDecisionError answers a refused decision change with 409 (budget
+// exhausted, pool in use, empty waitlist) or 400 (bad input)
+func respondDecisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrBudgetExceeded), errors.Is(err, services.ErrPoolInUse),
+		errors.Is(err, services.ErrWaitlistEmpty), errors.Is(err, services.ErrDecisionReleased):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidDecision), errors.Is(err, services.ErrInvalidAward),
+		errors.Is(err, services.ErrPoolNotOnForm):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		slog.ErrorContext(c.Request.Context(), "decision request failed", "component", "decisions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -144,12 +144,15 @@ func GetRedactedDocumentBase64(c *gin.Context) {
 	})
 }
 
+// BatchAnalyzePIIRequest lists documents to scan for PII in one call
+type BatchAnalyzePIIRequest struct {
+	Documents []DocumentPIIRequest `json:"documents" binding:"required"`
+}
+
 // BatchAnalyzeDocumentsPII handles requests to analyze multiple documents
 // POST /api/v1/documents/analyze-pii/batch
 func BatchAnalyzeDocumentsPII(c *gin.Context) {
-	var req struct {
-		Documents []DocumentPIIRequest `json:"documents" binding:"required"`
-	}
+	var req BatchAnalyzePIIRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"regexp"
@@ -14,9 +16,11 @@ import (
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/logging"
 	"github.com/Jsanchez767/matic-platform/middleware"
 	"github.com/Jsanchez767/matic-platform/models"
 	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/Jsanchez767/matic-platform/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -191,7 +195,7 @@ func HandleGmailCallback(c *gin.Context) {
 	}
 
 	// Get user's email address
-	client := config.Client(context.Background(), token)
+	client := config.Client(tracing.OAuthContext(context.Background(), "gmail"), token)
 	gmailService, err := gmail.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
 		c.Redirect(http.StatusTemporaryRedirect, frontendURL+"/gmail-connected?error=gmail_service_failed")
@@ -236,7 +240,7 @@ func HandleGmailCallback(c *gin.Context) {
 		}
 
 		if err := database.DB.Create(&connection).Error; err != nil {
-			fmt.Printf("❌ Failed to save Gmail connection: %v\n", err)
+			slog.ErrorContext(c.Request.Context(), "failed to save gmail connection", "error", err)
 			c.Redirect(http.StatusTemporaryRedirect, frontendURL+"/gmail-connected?error=save_failed&details="+err.Error())
 			return
 		}
@@ -336,8 +340,10 @@ type SendEmailRequest struct {
 	IsHTML          bool              `json:"is_html"` // If true, Body is HTML content
 	MergeTags       bool              `json:"merge_tags"`
 	TrackOpens      bool              `json:"track_opens"`
+	TrackClicks     bool              `json:"track_clicks"` // Rewrite links through the signed click redirect
 	SaveTemplate    bool              `json:"save_template"`
 	TemplateName    string            `json:"template_name"`
+	Category        string            `json:"category"`          // reminders, announcements (default for campaigns); empty for a direct email
 	Attachments     []EmailAttachment `json:"attachments"`       // Optional file attachments
 	FromEmail       string            `json:"from_email"`        // Optional: specific email address to send from
 	SenderAccountID string            `json:"sender_account_id"` // Optional: Gmail account ID to send from
@@ -345,10 +351,34 @@ type SendEmailRequest struct {
 	ThreadID   string `json:"thread_id"`   // Gmail thread ID to reply to
 	InReplyTo  string `json:"in_reply_to"` // Message-ID of the email being replied to
 	References string `json:"references"`  // References header for threading
+
+	// Queued delivery: any of these queues the campaign instead of sending now
+	ScheduledFor        *time.Time `json:"scheduled_for"`         // Release the campaign at this time
+	StaggerDelaySeconds int        `json:"stagger_delay_seconds"` // Seconds between recipients
+	QuietHoursStart     string     `json:"quiet_hours_start"`     // e.g. "21:00" in the recipient's timezone
+	QuietHoursEnd       string     `json:"quiet_hours_end"`       // e.g. "08:00"
+	Timezone            string     `json:"timezone"`              // IANA timezone for recipients without their own
+
+	AllowUnknownMergeTags bool `json:"allow_unknown_merge_tags"` // Send even if merge tags don't match the form's fields
+
+	// A/B testing: each variant goes to its split of the audience, and once
+	// the test window ends the winner goes to everyone else
+	Variants          []EmailVariantInput `json:"variants"`
+	WinnerMetric      string              `json:"winner_metric"`       // open_rate (default), click_rate
+	TestWindowMinutes int                 `json:"test_window_minutes"` // How long the test runs before the winner is picked
+}
+
+// EmailVariantInput is one subject/body version of an A/B tested campaign
+type EmailVariantInput struct {
+	Label        string `json:"label"` // Defaults to A, B, ...
+	Subject      string `json:"subject"`
+	Body         string `json:"body"` // Defaults to the campaign body
+	BodyHTML     string `json:"body_html"`
+	SplitPercent int    `json:"split_percent"` // Share of the audience in this variant's test group
 }
 
 // processAttachments downloads files from URLs and prepares them for MIME encoding
-func processAttachments(attachments []EmailAttachment) []EmailAttachment {
+func processAttachments(ctx context.Context, attachments []EmailAttachment) []EmailAttachment {
 	if len(attachments) == 0 {
 		return nil
 	}
@@ -365,7 +395,7 @@ func processAttachments(attachments []EmailAttachment) []EmailAttachment {
 		if att.URL != "" {
 			data, err := downloadAndEncode(att.URL)
 			if err != nil {
-				fmt.Printf("[Email] Failed to download attachment %s: %v\n", att.Filename, err)
+				slog.ErrorContext(ctx, "failed to download attachment", "component", "email", "filename", att.Filename, "error", err)
 				continue
 			}
 			att.Data = data
@@ -409,7 +439,8 @@ func downloadAndEncode(url string) (string, error) {
 	return base64.StdEncoding.EncodeToString(data), nil
 }
 
-// SendEmail sends emails via Gmail API
+// SendEmail sends emails through the workspace's email router, or queues
+// them when scheduled, throttled or A/B tested
 func SendEmail(c *gin.Context) {
 	workspaceID := c.Query("workspace_id")
 	if workspaceID == "" {
@@ -423,11 +454,26 @@ func SendEmail(c *gin.Context) {
 		return
 	}
 
+	queued := req.ScheduledFor != nil || req.StaggerDelaySeconds > 0 || req.QuietHoursStart != "" || req.QuietHoursEnd != "" || len(req.Variants) > 0
+	if queued {
+		if msg := validateQueuedSend(&req); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+	}
+	if len(req.Variants) > 0 {
+		if msg := validateABTest(&req); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+	}
+
 	// Debug logging
-	fmt.Printf("[Email] SendEmail called - FormID: %s, SubmissionIDs: %d, EmailField: %s, MergeTags: %v\n",
-		req.FormID, len(req.SubmissionIDs), req.EmailField, req.MergeTags)
+	slog.InfoContext(c.Request.Context(), "send email requested", "component", "email",
+		"form_id", req.FormID, "submission_count", len(req.SubmissionIDs), "email_field", req.EmailField, "merge_tags", req.MergeTags)
 
-	// Get Gmail connection - use specific email if provided
+	// Sender: the requested Gmail account, or the workspace's first one. A
+	// workspace without Gmail sends as its Resend or SMTP integration.
 	var connection models.GmailConnection
 	query := database.DB.Where("workspace_id = ?", workspaceID)
 
@@ -439,53 +485,36 @@ func SendEmail(c *gin.Context) {
 		query = query.Where("id = ?", req.SenderAccountID)
 	}
 
-	if err := query.First(&connection).Error; err != nil {
+	if err := query.First(&connection).Error; err != nil && (req.FromEmail != "" || req.SenderAccountID != "") {
 		// If specific email/account not found, fall back to default connection
-		if req.FromEmail != "" || req.SenderAccountID != "" {
-			if err := database.DB.Where("workspace_id = ?", workspaceID).First(&connection).Error; err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Gmail not connected. Please connect your Gmail account first."})
-				return
-			}
-			fmt.Printf("[Email] Specific account not found, using default connection: %s\n", connection.Email)
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Gmail not connected. Please connect your Gmail account first."})
-			return
+		if err := database.DB.Where("workspace_id = ?", workspaceID).First(&connection).Error; err == nil {
+			slog.InfoContext(c.Request.Context(), "requested gmail account not found, using default connection", "component", "email",
+				"connection", connection.Email)
 		}
 	}
-
-	fmt.Printf("[Email] Using Gmail connection: %s (ID: %s)\n", connection.Email, connection.ID.String())
-
-	// Create OAuth token
-	token := &oauth2.Token{
-		AccessToken:  connection.AccessToken,
-		RefreshToken: connection.RefreshToken,
-		Expiry:       connection.TokenExpiry,
-	}
-
-	config := getGmailConfig()
-	client := config.Client(context.Background(), token)
-	gmailService, err := gmail.NewService(context.Background(), option.WithHTTPClient(client))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Gmail service"})
-		return
+	if connection.Email != "" {
+		slog.DebugContext(c.Request.Context(), "using gmail connection", "component", "email",
+			"connection", connection.Email, "connection_id", connection.ID)
 	}
 
 	// Get recipients - handle different combinations of inputs
 	var recipients []Recipient
 	if len(req.RecipientEmails) > 0 && len(req.SubmissionIDs) > 0 {
 		// Both provided: use recipient emails but enrich with submission data for merge tags
-		recipients = getRecipientsWithSubmissionData(req.FormID, req.RecipientEmails, req.SubmissionIDs)
-		fmt.Printf("[Email] Got %d recipients from emails+submissions\n", len(recipients))
+		recipients = getRecipientsWithSubmissionData(c.Request.Context(), req.FormID, req.RecipientEmails, req.SubmissionIDs)
+		slog.DebugContext(c.Request.Context(), "resolved recipients from emails and submissions", "component", "email",
+			"count", len(recipients))
 	} else if len(req.SubmissionIDs) > 0 {
 		// Look up submission data server-side (secure - doesn't expose data to frontend)
-		recipients = getRecipientsFromSubmissions(req.FormID, req.SubmissionIDs, req.EmailField)
-		fmt.Printf("[Email] Got %d recipients from submission IDs\n", len(recipients))
+		recipients = getRecipientsFromSubmissions(c.Request.Context(), req.FormID, req.SubmissionIDs, req.EmailField)
+		slog.DebugContext(c.Request.Context(), "resolved recipients from submissions", "component", "email", "count", len(recipients))
 	} else if len(req.RecipientEmails) > 0 {
 		// Direct email list provided - look up submission data for merge tags
-		recipients = getRecipientsWithData(req.FormID, req.RecipientEmails)
+		recipients = getRecipientsWithData(c.Request.Context(), req.FormID, req.RecipientEmails)
 	} else {
 		// Use filter-based recipients
-		recipients, err = getRecipients(req.FormID, req.Recipients)
+		var err error
+		recipients, err = getRecipients(c.Request.Context(), req.FormID, req.Recipients)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -497,6 +526,65 @@ func SendEmail(c *gin.Context) {
 		return
 	}
 
+	// Campaign emails are always categorized so recipients can opt out; a
+	// direct email to one recipient is transactional unless given a category
+	category := req.Category
+	if category == "" && (len(recipients) > 1 || queued) {
+		category = services.EmailCategoryAnnouncements
+	}
+	if category != "" && !services.IsValidEmailCategory(category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email category: " + category})
+		return
+	}
+
+	// Drop bounced, complained, unsubscribed and opted-out addresses
+	wsUUID, _ := uuid.Parse(workspaceID)
+	recipients, suppressed := excludeSuppressedRecipients(c.Request.Context(), wsUUID, recipients, category)
+	if len(recipients) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "All recipients are suppressed or opted out", "suppressed": suppressed})
+		return
+	}
+
+	// Check merge tags against the form's fields before anything is sent
+	if req.MergeTags {
+		var known *services.MergeFieldSet
+		if formUUID, err := uuid.Parse(req.FormID); err == nil {
+			known = services.FormMergeFields(formUUID)
+			for _, recipient := range recipients {
+				known.Add(getKeys(recipient.SubmissionData)...)
+			}
+		}
+		issues := validateEmailMergeTags(req.Subject, req.Body, req.BodyHTML, known)
+		for _, variant := range req.Variants {
+			for _, issue := range validateEmailMergeTags(variant.Subject, variant.Body, variant.BodyHTML, known) {
+				issue.Part = "variants." + variant.Label + "." + issue.Part
+				issues = append(issues, issue)
+			}
+		}
+		if blocksSend(issues, req.AllowUnknownMergeTags) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Email contains invalid or unknown merge tags", "issues": issues})
+			return
+		}
+	}
+
+	// Immediate sends can't be deferred, so refuse batches over the daily cap
+	// of the provider they would go through
+	router := services.NewEmailRouter()
+	if !queued {
+		serviceType, err := router.DetermineServiceType(c.Request.Context(), wsUUID, services.ServiceTypeGmail, "communication")
+		remaining, limited := 0, errors.Is(err, services.ErrDailyCapReached)
+		if err == nil {
+			remaining, limited = services.DailyCapRemaining(wsUUID, serviceType)
+		}
+		if limited && len(recipients) > remaining {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":     fmt.Sprintf("Daily sending limit reached: %d of %d recipients can be sent today. Schedule the campaign to send the rest automatically.", remaining, len(recipients)),
+				"remaining": remaining,
+			})
+			return
+		}
+	}
+
 	// Get workspace settings for branding
 	var workspace models.Workspace
 	database.DB.Where("id = ?", workspaceID).First(&workspace)
@@ -512,38 +600,74 @@ func SendEmail(c *gin.Context) {
 		CompanyLogo:   workspace.LogoURL,
 		FooterText:    "", // Will use default
 	}
+	preferencesEnabled := services.PreferencesEnabled()
+	if preferencesEnabled {
+		emailBuilder.PreferencesURL = services.PreferencesURLPlaceholder
+	}
 
 	// Generate production-ready HTML and plain text versions
-	initialBodyHTML := emailBuilder.BuildHTML()
-	initialBodyPlain := emailBuilder.BuildPlainText()
-
-	// If custom HTML was provided, use it (but still wrap it properly)
-	if req.BodyHTML != "" && req.IsHTML {
-		emailBuilder.Body = req.BodyHTML
-		emailBuilder.IsHTML = true
-		initialBodyHTML = emailBuilder.BuildHTML()
-	}
+	initialBodyPlain, initialBodyHTML := buildEmailBodies(emailBuilder, req.Subject, req.Body, req.BodyHTML, req.IsHTML)
 
 	// Create campaign if sending to multiple recipients
 	var campaign *models.EmailCampaign
-	wsUUID, _ := uuid.Parse(workspaceID)
 
-	if len(recipients) > 1 {
+	if len(recipients) > 1 || queued {
 		campaign = &models.EmailCampaign{
-			WorkspaceID: wsUUID,
-			Subject:     req.Subject,
-			Body:        initialBodyPlain,
-			BodyHTML:    initialBodyHTML,
-			SenderEmail: connection.Email,
-			Status:      "sending",
+			WorkspaceID:         wsUUID,
+			Subject:             req.Subject,
+			Body:                initialBodyPlain,
+			BodyHTML:            initialBodyHTML,
+			SenderEmail:         connection.Email,
+			Status:              "sending",
+			StaggerDelaySeconds: req.StaggerDelaySeconds,
+			QuietHoursStart:     req.QuietHoursStart,
+			QuietHoursEnd:       req.QuietHoursEnd,
+			Timezone:            req.Timezone,
+		}
+		if req.ScheduledFor != nil && req.ScheduledFor.After(time.Now()) {
+			campaign.Status = "scheduled"
+			campaign.ScheduledFor = req.ScheduledFor
 		}
 		if req.FormID != "" {
 			formUUID, _ := uuid.Parse(req.FormID)
 			campaign.FormID = &formUUID
 		}
+		if len(req.Variants) > 0 {
+			testStart := time.Now()
+			if campaign.ScheduledFor != nil {
+				testStart = *campaign.ScheduledFor
+			}
+			testEndsAt := testStart.Add(time.Duration(req.TestWindowMinutes) * time.Minute)
+			campaign.WinnerMetric = req.WinnerMetric
+			campaign.TestEndsAt = &testEndsAt
+		}
 		database.DB.Create(campaign)
 	}
 
+	// Variants keep their built bodies, like the campaign itself
+	var variants []models.EmailCampaignVariant
+	for _, input := range req.Variants {
+		body, bodyHTML := input.Body, input.BodyHTML
+		if body == "" {
+			body, bodyHTML = req.Body, req.BodyHTML
+		}
+		plain, html := buildEmailBodies(emailBuilder, input.Subject, body, bodyHTML, req.IsHTML)
+		variants = append(variants, models.EmailCampaignVariant{
+			CampaignID:   campaign.ID,
+			Label:        input.Label,
+			Subject:      input.Subject,
+			Body:         plain,
+			BodyHTML:     html,
+			SplitPercent: input.SplitPercent,
+		})
+	}
+	if len(variants) > 0 {
+		if err := database.DB.Create(&variants).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save campaign variants: " + err.Error()})
+			return
+		}
+	}
+
 	// Save template if requested
 	if req.SaveTemplate && req.TemplateName != "" {
 		template := models.EmailTemplate{
@@ -561,34 +685,60 @@ func SendEmail(c *gin.Context) {
 		database.DB.Create(&template)
 	}
 
+	if queued {
+		queuedCount, err := enqueueCampaignEmails(c.Request.Context(), &req, campaign, variants, recipients, connection.Email, initialBodyPlain, initialBodyHTML, preferencesEnabled, category)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue campaign: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"success":     true,
+			"queued":      queuedCount,
+			"total":       len(recipients),
+			"campaign_id": campaign.ID,
+			"status":      campaign.Status,
+			"suppressed":  suppressed,
+		})
+		return
+	}
+
 	// Send emails
 	var sentEmails []models.SentEmail
-	var errors []string
+	var sendErrors []string
 	backendURL := os.Getenv("GO_BACKEND_URL")
 	if backendURL == "" {
 		backendURL = "http://localhost:8080"
 	}
+	trackClicks := req.TrackClicks && services.ClickTrackingEnabled()
+	if req.TrackClicks && !trackClicks {
+		slog.WarnContext(c.Request.Context(), "click tracking needs EMAIL_PREFERENCES_SECRET; links left unchanged",
+			"component", "email")
+	}
+
+	// Process attachments - download from URL if needed and convert to base64
+	var attachments []services.EmailAttachment
+	for _, attachment := range processAttachments(c.Request.Context(), req.Attachments) {
+		attachments = append(attachments, services.EmailAttachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Data:        attachment.Data,
+		})
+	}
+	if req.ThreadID != "" {
+		slog.DebugContext(c.Request.Context(), "sending as reply to thread", "component", "email", "thread_id", req.ThreadID)
+	}
 
 	for _, recipient := range recipients {
 		// Generate tracking ID
 		trackingID := uuid.New().String()
 
-		// Process merge tags if enabled
-		subject := req.Subject
-		body := initialBodyPlain
-		bodyHTML := initialBodyHTML
-
-		fmt.Printf("[Email] Processing recipient %s, MergeTags=%v, SubmissionData=%v\n",
-			recipient.Email, req.MergeTags, recipient.SubmissionData != nil)
+		// Preference link and merge tags
+		subject, body, bodyHTML := personalizeEmail(c.Request.Context(), &req, recipient, wsUUID, initialBodyPlain, initialBodyHTML, preferencesEnabled)
 
-		if req.MergeTags && recipient.SubmissionData != nil {
-			fmt.Printf("[Email] Applying merge tags for %s with data: %v\n", recipient.Email, getKeys(recipient.SubmissionData))
-			subject = processMergeTags(subject, recipient.SubmissionData)
-			body = processMergeTags(body, recipient.SubmissionData)
-			bodyHTML = processMergeTags(bodyHTML, recipient.SubmissionData)
-			fmt.Printf("[Email] After merge - Subject: %s\n", subject)
-		} else if req.MergeTags {
-			fmt.Printf("[Email] MergeTags enabled but no SubmissionData for %s\n", recipient.Email)
+		// Rewrite links for click tracking after merge tags so merged URLs are tracked too
+		var trackedLinks []services.TrackedLink
+		if trackClicks && bodyHTML != "" {
+			bodyHTML, trackedLinks = services.RewriteLinks(bodyHTML, trackingID, backendURL)
 		}
 
 		// Add tracking pixel if enabled
@@ -601,33 +751,6 @@ func SendEmail(c *gin.Context) {
 			}
 		}
 
-		// Process attachments - download from URL if needed and convert to base64
-		processedAttachments := processAttachments(req.Attachments)
-
-		// Create MIME message with threading support and attachments
-		var message gmail.Message
-		mimeOpts := MIMEMessageOptions{
-			InReplyTo:   req.InReplyTo,
-			References:  req.References,
-			Attachments: processedAttachments,
-		}
-		// Build the From address with display name if available
-		fromAddress := connection.Email
-		if connection.DisplayName != "" {
-			fromAddress = fmt.Sprintf("%s <%s>", connection.DisplayName, connection.Email)
-		}
-		rawMessage := createMIMEMessageWithOptions(fromAddress, recipient.Email, recipient.Name, subject, body, bodyHTML, mimeOpts)
-		message.Raw = base64.URLEncoding.EncodeToString([]byte(rawMessage))
-
-		// Set thread ID if replying to an existing thread
-		if req.ThreadID != "" {
-			message.ThreadId = req.ThreadID
-			fmt.Printf("[Email] Sending as reply to thread: %s\n", req.ThreadID)
-		}
-
-		// Send via Gmail
-		sentMessage, err := gmailService.Users.Messages.Send("me", &message).Do()
-
 		sentEmail := models.SentEmail{
 			WorkspaceID:    wsUUID,
 			RecipientEmail: recipient.Email,
@@ -653,32 +776,51 @@ func SendEmail(c *gin.Context) {
 			sentEmail.FormID = &formUUID
 		}
 
+		// The router applies suppression, caps and provider fallback, and adds
+		// the List-Unsubscribe headers for categorized email
+		result, err := router.SendEmail(c.Request.Context(), services.EmailSendRequest{
+			WorkspaceID:  wsUUID,
+			To:           recipient.Email,
+			ToName:       recipient.Name,
+			From:         connection.Email,
+			FromName:     connection.DisplayName,
+			Subject:      subject,
+			Body:         body,
+			BodyHTML:     bodyHTML,
+			SubmissionID: sentEmail.SubmissionID,
+			FormID:       sentEmail.FormID,
+			ServiceType:  services.ServiceTypeGmail,
+			EmailType:    "communication",
+			Category:     category,
+			TrackOpens:   req.TrackOpens,
+			TrackingID:   trackingID,
+			ThreadID:     req.ThreadID,
+			InReplyTo:    req.InReplyTo,
+			References:   req.References,
+			Attachments:  attachments,
+		})
+		if result != nil {
+			result.ApplyTo(&sentEmail)
+		}
+		if err == nil && !result.Success {
+			err = errors.New(result.ErrorMessage)
+		}
 		if err != nil {
 			sentEmail.Status = "failed"
-			errorMsg := fmt.Sprintf("Failed to send to %s: %v", recipient.Email, err)
-			errors = append(errors, errorMsg)
-
-			// Check if this is an OAuth token error - mark connection as needing reconnection
-			errStr := err.Error()
-			if strings.Contains(errStr, "invalid_grant") ||
-				strings.Contains(errStr, "Token has been expired or revoked") ||
-				strings.Contains(errStr, "token expired") ||
-				strings.Contains(errStr, "invalid_token") ||
-				strings.Contains(errStr, "unauthorized") {
-				connection.NeedsReconnect = true
-				connection.ReconnectReason = "Your Gmail authorization has expired or been revoked. Please reconnect your account."
-				database.DB.Save(&connection)
-				fmt.Printf("[Email] OAuth error detected, marked connection as needing reconnection: %s\n", errStr)
-			}
-		} else {
-			sentEmail.GmailMessageID = sentMessage.Id
-			sentEmail.GmailThreadID = sentMessage.ThreadId
+			sendErrors = append(sendErrors, fmt.Sprintf("Failed to send to %s: %v", recipient.Email, err))
 		}
 
 		if err := database.DB.Create(&sentEmail).Error; err != nil {
-			fmt.Printf("[Email] Failed to save sent email record: %v\n", err)
+			slog.ErrorContext(c.Request.Context(), "failed to save sent email record", "component", "email", "error", err)
 		} else {
-			fmt.Printf("[Email] Saved sent email record: id=%s, recipient=%s, submission_id=%v\n", sentEmail.ID, sentEmail.RecipientEmail, sentEmail.SubmissionID)
+			slog.DebugContext(c.Request.Context(), "saved sent email record", "component", "email",
+				"sent_email_id", sentEmail.ID, "recipient", sentEmail.RecipientEmail, "submission_id", sentEmail.SubmissionID)
+			if sentEmail.Status != "failed" {
+				if err := services.SaveTrackedLinks(sentEmail, trackedLinks); err != nil {
+					slog.ErrorContext(c.Request.Context(), "failed to save tracked links", "component", "email",
+						"sent_email_id", sentEmail.ID, "error", err)
+				}
+			}
 		}
 		sentEmails = append(sentEmails, sentEmail)
 	}
@@ -692,14 +834,262 @@ func SendEmail(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success":     len(errors) == 0,
-		"sent_count":  len(sentEmails) - len(errors),
+		"success":     len(sendErrors) == 0,
+		"sent_count":  len(sentEmails) - len(sendErrors),
 		"total":       len(recipients),
-		"errors":      errors,
+		"errors":      sendErrors,
 		"campaign_id": campaign,
+		"suppressed":  suppressed,
 	})
 }
 
+// validateQueuedSend checks the scheduling fields of a queued send and
+// returns an error message, or "" when the request is valid
+func validateQueuedSend(req *SendEmailRequest) string {
+	if req.StaggerDelaySeconds < 0 {
+		return "stagger_delay_seconds must not be negative"
+	}
+	if err := services.ValidateQuietHours(req.QuietHoursStart, req.QuietHoursEnd); err != nil {
+		return err.Error()
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return "Invalid timezone: " + req.Timezone
+		}
+	}
+	// The queue stores rendered bodies only
+	if len(req.Attachments) > 0 {
+		return "Attachments can't be sent with scheduled or throttled campaigns"
+	}
+	if req.ThreadID != "" {
+		return "Replies can't be scheduled"
+	}
+	return ""
+}
+
+// validateABTest checks and fills in defaults for an A/B tested send and
+// returns an error message, or "" when the request is valid
+func validateABTest(req *SendEmailRequest) string {
+	if len(req.Variants) < 2 {
+		return "An A/B test needs at least two variants"
+	}
+	total := 0
+	labels := map[string]bool{}
+	for i := range req.Variants {
+		variant := &req.Variants[i]
+		if variant.Label == "" {
+			variant.Label = string(rune('A' + i))
+		}
+		if labels[variant.Label] {
+			return "Variant labels must be unique: " + variant.Label
+		}
+		labels[variant.Label] = true
+		if strings.TrimSpace(variant.Subject) == "" {
+			return "Variant " + variant.Label + " needs a subject"
+		}
+		if variant.SplitPercent < 1 || variant.SplitPercent > 100 {
+			return "Variant " + variant.Label + " split_percent must be between 1 and 100"
+		}
+		total += variant.SplitPercent
+	}
+	if total > 100 {
+		return "Variant split percentages add up to more than 100"
+	}
+	if req.TestWindowMinutes <= 0 {
+		return "test_window_minutes is required for A/B tests"
+	}
+
+	if req.WinnerMetric == "" {
+		req.WinnerMetric = services.WinnerMetricOpenRate
+	}
+	if !services.IsValidWinnerMetric(req.WinnerMetric) {
+		return "winner_metric must be open_rate or click_rate"
+	}
+	// The winner can only be judged on what's tracked
+	if req.WinnerMetric == services.WinnerMetricOpenRate && !req.TrackOpens {
+		return "Picking the winner by open_rate needs track_opens"
+	}
+	if req.WinnerMetric == services.WinnerMetricClickRate && !(req.TrackClicks && services.ClickTrackingEnabled()) {
+		return "Picking the winner by click_rate needs track_clicks and EMAIL_PREFERENCES_SECRET"
+	}
+	return ""
+}
+
+// buildEmailBodies wraps a subject and body in the workspace's email layout,
+// returning the plain text and HTML versions. Custom HTML replaces the body
+// in the HTML version.
+func buildEmailBodies(builder services.EmailTemplateBuilder, subject, body, bodyHTML string, isHTML bool) (string, string) {
+	builder.Subject = subject
+	builder.Body = body
+	builder.IsHTML = isHTML
+	plain := builder.BuildPlainText()
+	html := builder.BuildHTML()
+
+	// If custom HTML was provided, use it (but still wrap it properly)
+	if bodyHTML != "" && isHTML {
+		builder.Body = bodyHTML
+		html = builder.BuildHTML()
+	}
+	return plain, html
+}
+
+// enqueueCampaignEmails queues one personalized email per recipient for the
+// queue worker and, unless the campaign is scheduled for later, staggers them
+// from now. With A/B variants, the test group gets its assigned variant and
+// the holdout one held email per variant until the winner is picked. Returns
+// the number of recipients queued.
+func enqueueCampaignEmails(ctx context.Context, req *SendEmailRequest, campaign *models.EmailCampaign, variants []models.EmailCampaignVariant, recipients []Recipient, senderEmail, bodyPlain, bodyHTML string, preferencesEnabled bool, category string) (int, error) {
+	status := "pending"
+	scheduledFor := time.Now()
+	if campaign.Status == "scheduled" {
+		status = "scheduled"
+		scheduledFor = *campaign.ScheduledFor
+	}
+
+	newItem := func(recipient Recipient, variant *models.EmailCampaignVariant, status string) models.EmailQueueItem {
+		itemReq, itemPlain, itemHTML := req, bodyPlain, bodyHTML
+		if variant != nil {
+			variantReq := *req
+			variantReq.Subject = variant.Subject
+			itemReq, itemPlain, itemHTML = &variantReq, variant.Body, variant.BodyHTML
+		}
+		subject, body, html := personalizeEmail(ctx, itemReq, recipient, campaign.WorkspaceID, itemPlain, itemHTML, preferencesEnabled)
+		item := models.EmailQueueItem{
+			WorkspaceID:    campaign.WorkspaceID,
+			CampaignID:     &campaign.ID,
+			RecipientEmail: recipient.Email,
+			RecipientName:  recipient.Name,
+			Subject:        subject,
+			Body:           body,
+			BodyHTML:       html,
+			SenderEmail:    senderEmail,
+			ServiceType:    string(services.ServiceTypeGmail),
+			Category:       category,
+			Status:         status,
+			ScheduledFor:   scheduledFor,
+			RecipientTZ:    recipientTimezone(recipient, req.Timezone),
+			TrackingID:     uuid.New().String(),
+			TrackOpens:     req.TrackOpens,
+			TrackClicks:    req.TrackClicks,
+			TraceParent:    tracing.TraceParent(ctx),
+			RequestID:      logging.RequestID(ctx),
+		}
+		if recipient.SubmissionID != "" {
+			if subUUID, err := uuid.Parse(recipient.SubmissionID); err == nil {
+				item.SubmissionID = &subUUID
+			}
+		}
+		if variant != nil {
+			item.VariantID = &variant.ID
+		}
+		item.FormID = campaign.FormID
+		return item
+	}
+
+	items := make([]models.EmailQueueItem, 0, len(recipients))
+	for _, recipient := range recipients {
+		if len(variants) == 0 {
+			items = append(items, newItem(recipient, nil, status))
+			continue
+		}
+		if variant := services.AssignCampaignVariant(campaign.ID, recipient.Email, variants); variant != nil {
+			items = append(items, newItem(recipient, variant, status))
+			continue
+		}
+		// Holdout: one email per variant, held until the winner is picked
+		for i := range variants {
+			items = append(items, newItem(recipient, &variants[i], "held"))
+		}
+	}
+
+	if err := database.DB.Create(&items).Error; err != nil {
+		return 0, err
+	}
+	slog.InfoContext(ctx, "queued campaign emails", "component", "email",
+		"campaign_id", campaign.ID, "status", campaign.Status, "count", len(items))
+
+	if campaign.Status == "sending" {
+		if err := services.ProcessCampaignQueue(ctx, services.NewEmailRouter(), campaign.ID, campaign.StaggerDelaySeconds); err != nil {
+			return len(recipients), err
+		}
+	}
+	return len(recipients), nil
+}
+
+// recipientTimezone returns the recipient's timezone from their submission
+// when it's a valid IANA name, otherwise the campaign's fallback
+func recipientTimezone(recipient Recipient, fallback string) string {
+	for _, key := range []string{"timezone", "time_zone"} {
+		if tz, ok := recipient.SubmissionData[key].(string); ok && tz != "" {
+			if _, err := time.LoadLocation(tz); err == nil {
+				return tz
+			}
+		}
+	}
+	return fallback
+}
+
+// personalizeEmail adds the recipient's preference link and applies merge
+// tags, returning the subject, plain text and HTML bodies
+func personalizeEmail(ctx context.Context, req *SendEmailRequest, recipient Recipient, workspaceID uuid.UUID, body, bodyHTML string, preferencesEnabled bool) (string, string, string) {
+	subject := req.Subject
+
+	// Per-recipient preference link
+	if preferencesEnabled {
+		token, _ := services.PreferenceToken(workspaceID, recipient.Email)
+		preferencesURL := services.PreferencesURL(token)
+		bodyHTML = strings.ReplaceAll(bodyHTML, services.PreferencesURLPlaceholder, preferencesURL)
+		body += "\n\n---\nManage email preferences or unsubscribe: " + preferencesURL
+	}
+
+	slog.DebugContext(ctx, "personalizing email", "component", "email",
+		"recipient", recipient.Email, "merge_tags", req.MergeTags, "has_submission_data", recipient.SubmissionData != nil)
+
+	if req.MergeTags && recipient.SubmissionData != nil {
+		slog.DebugContext(ctx, "applying merge tags", "component", "email",
+			"recipient", recipient.Email, "data_keys", getKeys(recipient.SubmissionData))
+		subject = processMergeTags(ctx, subject, recipient.SubmissionData, false)
+		body = processMergeTags(ctx, body, recipient.SubmissionData, false)
+		bodyHTML = processMergeTags(ctx, bodyHTML, recipient.SubmissionData, true)
+		slog.DebugContext(ctx, "merged subject", "component", "email", "subject", subject)
+	} else if req.MergeTags {
+		slog.DebugContext(ctx, "merge tags enabled but recipient has no submission data", "component", "email",
+			"recipient", recipient.Email)
+	}
+
+	return subject, body, bodyHTML
+}
+
+// excludeSuppressedRecipients removes recipients on the workspace suppression
+// list or opted out of category, returning the remaining recipients and the
+// skipped addresses
+func excludeSuppressedRecipients(ctx context.Context, workspaceID uuid.UUID, recipients []Recipient, category string) ([]Recipient, []string) {
+	emails := make([]string, 0, len(recipients))
+	for _, r := range recipients {
+		emails = append(emails, r.Email)
+	}
+	suppressedSet := services.SuppressedEmails(workspaceID, emails)
+	for email := range services.OptedOutEmails(workspaceID, emails, category) {
+		suppressedSet[email] = true
+	}
+	if len(suppressedSet) == 0 {
+		return recipients, []string{}
+	}
+
+	kept := make([]Recipient, 0, len(recipients))
+	suppressed := []string{}
+	for _, r := range recipients {
+		if suppressedSet[services.NormalizeEmail(r.Email)] {
+			suppressed = append(suppressed, r.Email)
+			continue
+		}
+		kept = append(kept, r)
+	}
+	slog.InfoContext(ctx, "skipping suppressed or opted-out recipients", "component", "email",
+		"workspace_id", workspaceID, "count", len(suppressed))
+	return kept, suppressed
+}
+
 // Recipient represents an email recipient with optional submission data
 type Recipient struct {
 	Email          string
@@ -708,7 +1098,7 @@ type Recipient struct {
 	SubmissionData map[string]interface{}
 }
 
-func getRecipients(formID string, recipientFilters []string) ([]Recipient, error) {
+func getRecipients(ctx context.Context, formID string, recipientFilters []string) ([]Recipient, error) {
 	var recipients []Recipient
 
 	// Check if we have explicit email addresses
@@ -761,7 +1151,7 @@ func getRecipients(formID string, recipientFilters []string) ([]Recipient, error
 		}
 
 		// Try to find email field
-		email := findEmailInData(rowData)
+		email := findEmailInData(ctx, rowData)
 		if email == "" {
 			continue
 		}
@@ -780,10 +1170,11 @@ func getRecipients(formID string, recipientFilters []string) ([]Recipient, error
 }
 
 // getRecipientsFromSubmissions looks up submission data by submission IDs (secure server-side lookup)
-func getRecipientsFromSubmissions(formID string, submissionIDs []string, emailField string) []Recipient {
+func getRecipientsFromSubmissions(ctx context.Context, formID string, submissionIDs []string, emailField string) []Recipient {
 	var recipients []Recipient
 
-	fmt.Printf("[Email] getRecipientsFromSubmissions called with formID=%s, %d submissionIDs, emailField=%s\n", formID, len(submissionIDs), emailField)
+	slog.DebugContext(ctx, "resolving recipients from submissions", "component", "email",
+		"form_id", formID, "submission_count", len(submissionIDs), "email_field", emailField)
 
 	if len(submissionIDs) == 0 {
 		return recipients
@@ -792,16 +1183,16 @@ func getRecipientsFromSubmissions(formID string, submissionIDs []string, emailFi
 	// Query submissions by their IDs
 	var rows []models.Row
 	if err := database.DB.Where("id IN ?", submissionIDs).Find(&rows).Error; err != nil {
-		fmt.Printf("[Email] Error querying rows by IDs: %v\n", err)
+		slog.ErrorContext(ctx, "failed to query rows by ID", "component", "email", "error", err)
 		return recipients
 	}
 
-	fmt.Printf("[Email] Found %d rows\n", len(rows))
+	slog.DebugContext(ctx, "found rows", "component", "email", "count", len(rows))
 
 	for _, row := range rows {
 		var rowData map[string]interface{}
 		if err := json.Unmarshal(row.Data, &rowData); err != nil {
-			fmt.Printf("[Email] Error unmarshaling row data for %s: %v\n", row.ID, err)
+			slog.WarnContext(ctx, "failed to unmarshal row data", "component", "email", "row_id", row.ID, "error", err)
 			continue
 		}
 
@@ -812,15 +1203,15 @@ func getRecipientsFromSubmissions(formID string, submissionIDs []string, emailFi
 			if val, ok := rowData[emailField]; ok {
 				email = fmt.Sprintf("%v", val)
 			}
-			fmt.Printf("[Email] Using specified field '%s': %s\n", emailField, email)
+			slog.DebugContext(ctx, "using specified email field", "component", "email", "email_field", emailField, "email", email)
 		} else {
 			// Auto-detect email field
-			email = findEmailInData(rowData)
-			fmt.Printf("[Email] Auto-detected email: %s\n", email)
+			email = findEmailInData(ctx, rowData)
+			slog.DebugContext(ctx, "auto-detected email", "component", "email", "email", email)
 		}
 
 		if email == "" || !strings.Contains(email, "@") {
-			fmt.Printf("[Email] No valid email found for submission %s\n", row.ID)
+			slog.WarnContext(ctx, "no valid email found for submission", "component", "email", "row_id", row.ID)
 			continue
 		}
 
@@ -834,17 +1225,18 @@ func getRecipientsFromSubmissions(formID string, submissionIDs []string, emailFi
 			SubmissionData: rowData,
 		})
 
-		fmt.Printf("[Email] Added recipient: %s <%s> with %d data fields\n", name, email, len(rowData))
+		slog.DebugContext(ctx, "added recipient", "component", "email", "name", name, "email", email, "field_count", len(rowData))
 	}
 
 	return recipients
 }
 
 // getRecipientsWithSubmissionData combines explicit emails with submission data for merge tags
-func getRecipientsWithSubmissionData(formID string, emails []string, submissionIDs []string) []Recipient {
+func getRecipientsWithSubmissionData(ctx context.Context, formID string, emails []string, submissionIDs []string) []Recipient {
 	var recipients []Recipient
 
-	fmt.Printf("[Email] getRecipientsWithSubmissionData called with formID=%s, emails=%v, submissionIDs=%v\n", formID, emails, submissionIDs)
+	slog.DebugContext(ctx, "resolving recipients with submission data", "component", "email",
+		"form_id", formID, "emails", emails, "submission_ids", submissionIDs)
 
 	// If we have both emails and submission IDs, pair them together
 	// Typically used when sending to one recipient with their submission data
@@ -855,14 +1247,14 @@ func getRecipientsWithSubmissionData(formID string, emails []string, submissionI
 		// Look up the submission data
 		var row models.Row
 		if err := database.DB.Where("id = ?", submissionID).First(&row).Error; err != nil {
-			fmt.Printf("[Email] Error finding submission %s: %v\n", submissionID, err)
+			slog.WarnContext(ctx, "failed to find submission", "component", "email", "submission_id", submissionID, "error", err)
 			// Still return the recipient even without data
 			return []Recipient{{Email: email, SubmissionID: submissionID}}
 		}
 
 		var rowData map[string]interface{}
 		if err := json.Unmarshal(row.Data, &rowData); err != nil {
-			fmt.Printf("[Email] Error unmarshaling row data: %v\n", err)
+			slog.WarnContext(ctx, "failed to unmarshal row data", "component", "email", "error", err)
 			return []Recipient{{Email: email, SubmissionID: submissionID}}
 		}
 
@@ -873,7 +1265,8 @@ func getRecipientsWithSubmissionData(formID string, emails []string, submissionI
 			SubmissionID:   submissionID,
 			SubmissionData: rowData,
 		})
-		fmt.Printf("[Email] Added recipient with submission data: %s <%s> with %d fields\n", name, email, len(rowData))
+		slog.DebugContext(ctx, "added recipient with submission data", "component", "email",
+			"name", name, "email", email, "field_count", len(rowData))
 		return recipients
 	}
 
@@ -899,14 +1292,14 @@ func getRecipientsWithSubmissionData(formID string, emails []string, submissionI
 }
 
 // getRecipientsWithData looks up submission data for a list of email addresses
-func getRecipientsWithData(formID string, emails []string) []Recipient {
+func getRecipientsWithData(ctx context.Context, formID string, emails []string) []Recipient {
 	var recipients []Recipient
 
-	fmt.Printf("[Email] getRecipientsWithData called with formID=%s, emails=%v\n", formID, emails)
+	slog.DebugContext(ctx, "resolving recipients with data", "component", "email", "form_id", formID, "emails", emails)
 
 	// If no formID, we can't look up submission data
 	if formID == "" {
-		fmt.Println("[Email] No formID provided, returning emails without data")
+		slog.DebugContext(ctx, "no form ID provided, returning emails without data", "component", "email")
 		for _, email := range emails {
 			if strings.Contains(email, "@") {
 				recipients = append(recipients, Recipient{Email: email})
@@ -918,7 +1311,7 @@ func getRecipientsWithData(formID string, emails []string) []Recipient {
 	// Query all submissions for this form
 	var rows []models.Row
 	if err := database.DB.Where("table_id = ?", formID).Find(&rows).Error; err != nil {
-		fmt.Printf("[Email] Error querying rows: %v\n", err)
+		slog.ErrorContext(ctx, "failed to query rows", "component", "email", "error", err)
 		// If query fails, just return emails without data
 		for _, email := range emails {
 			if strings.Contains(email, "@") {
@@ -928,7 +1321,7 @@ func getRecipientsWithData(formID string, emails []string) []Recipient {
 		return recipients
 	}
 
-	fmt.Printf("[Email] Found %d rows for form %s\n", len(rows), formID)
+	slog.DebugContext(ctx, "found rows for form", "component", "email", "count", len(rows), "form_id", formID)
 
 	// Create a map of email -> submission data
 	emailToData := make(map[string]struct {
@@ -940,20 +1333,20 @@ func getRecipientsWithData(formID string, emails []string) []Recipient {
 	for _, row := range rows {
 		var rowData map[string]interface{}
 		if err := json.Unmarshal(row.Data, &rowData); err != nil {
-			fmt.Printf("[Email] Error unmarshaling row data: %v\n", err)
+			slog.WarnContext(ctx, "failed to unmarshal row data", "component", "email", "error", err)
 			continue
 		}
 
-		fmt.Printf("[Email] Row %s data keys: %v\n", row.ID, getKeys(rowData))
+		slog.DebugContext(ctx, "row data keys", "component", "email", "row_id", row.ID, "keys", getKeys(rowData))
 
 		// Find email in this row
-		rowEmail := findEmailInData(rowData)
+		rowEmail := findEmailInData(ctx, rowData)
 		if rowEmail == "" {
-			fmt.Printf("[Email] No email found in row %s\n", row.ID)
+			slog.DebugContext(ctx, "no email found in row", "component", "email", "row_id", row.ID)
 			continue
 		}
 
-		fmt.Printf("[Email] Found email %s in row %s\n", rowEmail, row.ID)
+		slog.DebugContext(ctx, "found email in row", "component", "email", "email", rowEmail, "row_id", row.ID)
 
 		// Store the mapping (lowercase for case-insensitive match)
 		emailToData[strings.ToLower(rowEmail)] = struct {
@@ -967,7 +1360,7 @@ func getRecipientsWithData(formID string, emails []string) []Recipient {
 		}
 	}
 
-	fmt.Printf("[Email] Email to data map has %d entries\n", len(emailToData))
+	slog.DebugContext(ctx, "built email to data map", "component", "email", "count", len(emailToData))
 
 	// Build recipients with their data
 	for _, email := range emails {
@@ -979,12 +1372,12 @@ func getRecipientsWithData(formID string, emails []string) []Recipient {
 
 		// Look up submission data by email (case-insensitive)
 		if subData, found := emailToData[strings.ToLower(email)]; found {
-			fmt.Printf("[Email] Found data for %s: %v\n", email, getKeys(subData.data))
+			slog.DebugContext(ctx, "found data for email", "component", "email", "email", email, "keys", getKeys(subData.data))
 			recipient.Name = subData.name
 			recipient.SubmissionID = subData.rowID
 			recipient.SubmissionData = subData.data
 		} else {
-			fmt.Printf("[Email] No data found for email %s\n", email)
+			slog.DebugContext(ctx, "no data found for email", "component", "email", "email", email)
 		}
 
 		recipients = append(recipients, recipient)
@@ -1056,13 +1449,13 @@ func findAllEmailsInData(data map[string]interface{}) []string {
 	return result
 }
 
-func findEmailInData(data map[string]interface{}) string {
+func findEmailInData(ctx context.Context, data map[string]interface{}) string {
 	// Common email field names - check _applicant_email first (stored by form submission)
 	emailFields := []string{"_applicant_email", "email", "Email", "EMAIL", "email_address", "emailAddress", "contact_email", "applicant_email"}
 	for _, field := range emailFields {
 		if val, ok := data[field]; ok {
 			if email, ok := val.(string); ok && strings.Contains(email, "@") {
-				fmt.Printf("[Email] Found email in field %s: %s\n", field, email)
+				slog.DebugContext(ctx, "found email in field", "component", "email", "field", field, "email", email)
 				return email
 			}
 		}
@@ -1071,7 +1464,7 @@ func findEmailInData(data map[string]interface{}) string {
 	// Check nested personal.personalEmail
 	if personal, ok := data["personal"].(map[string]interface{}); ok {
 		if email, ok := personal["personalEmail"].(string); ok && strings.Contains(email, "@") {
-			fmt.Printf("[Email] Found email in personal.personalEmail: %s\n", email)
+			slog.DebugContext(ctx, "found email in personal.personalEmail", "component", "email", "email", email)
 			return email
 		}
 	}
@@ -1079,7 +1472,7 @@ func findEmailInData(data map[string]interface{}) string {
 	// Search all fields for email-like values
 	for key, val := range data {
 		if str, ok := val.(string); ok && strings.Contains(str, "@") && strings.Contains(str, ".") {
-			fmt.Printf("[Email] Found email-like value in field %s: %s\n", key, str)
+			slog.DebugContext(ctx, "found email-like value in field", "component", "email", "field", key, "email", str)
 			return str
 		}
 	}
@@ -1100,204 +1493,20 @@ func findNameInData(data map[string]interface{}) string {
 	return ""
 }
 
-func processMergeTags(content string, data map[string]interface{}) string {
-	fmt.Printf("[Email] processMergeTags called with content length=%d, data keys=%v\n", len(content), getKeys(data))
-
-	// First, try exact key matches
-	for key, val := range data {
-		tag := fmt.Sprintf("{{%s}}", key)
-		if str, ok := val.(string); ok {
-			if strings.Contains(content, tag) {
-				fmt.Printf("[Email] Replacing %s with %s\n", tag, str)
-			}
-			content = strings.ReplaceAll(content, tag, str)
-		} else if val != nil {
-			valStr := fmt.Sprintf("%v", val)
-			content = strings.ReplaceAll(content, tag, valStr)
-		}
-	}
-
-	// Also try matching with normalized keys (replace spaces/underscores)
-	// This handles cases like {{First Name}} matching first_name or firstName
-	tagRegex := regexp.MustCompile(`\{\{([^}]+)\}\}`)
-	matches := tagRegex.FindAllStringSubmatch(content, -1)
-
-	for _, match := range matches {
-		if len(match) < 2 {
-			continue
-		}
-		tagName := match[1] // e.g., "First Name"
-		fullTag := match[0] // e.g., "{{First Name}}"
-
-		// Try to find a matching key in data
-		normalizedTagName := normalizeFieldName(tagName)
-
-		for key, val := range data {
-			normalizedKey := normalizeFieldName(key)
-
-			if normalizedTagName == normalizedKey {
-				var valStr string
-				if str, ok := val.(string); ok {
-					valStr = str
-				} else if val != nil {
-					valStr = fmt.Sprintf("%v", val)
-				}
-				fmt.Printf("[Email] Fuzzy match: %s -> %s = %s\n", fullTag, key, valStr)
-				content = strings.ReplaceAll(content, fullTag, valStr)
-				break
-			}
-		}
-	}
-
-	return content
-}
-
-// normalizeFieldName converts field names to a standard format for comparison
-func normalizeFieldName(name string) string {
-	// Convert to lowercase
-	name = strings.ToLower(name)
-	// Remove spaces, underscores, dashes
-	name = strings.ReplaceAll(name, " ", "")
-	name = strings.ReplaceAll(name, "_", "")
-	name = strings.ReplaceAll(name, "-", "")
-	return name
-}
-
-// MIMEMessageOptions contains optional headers for threading and attachments
-type MIMEMessageOptions struct {
-	InReplyTo   string
-	References  string
-	Attachments []EmailAttachment
-}
-
-func createMIMEMessage(from, to, toName, subject, textBody, htmlBody string) string {
-	return createMIMEMessageWithOptions(from, to, toName, subject, textBody, htmlBody, MIMEMessageOptions{})
-}
-
-func createMIMEMessageWithOptions(from, to, toName, subject, textBody, htmlBody string, opts MIMEMessageOptions) string {
-	boundary := "boundary_" + uuid.New().String()
-	hasAttachments := len(opts.Attachments) > 0
-
-	var sb strings.Builder
-
-	// Essential Headers for Deliverability
-	if toName != "" {
-		sb.WriteString(fmt.Sprintf("To: %s <%s>\r\n", toName, to))
-	} else {
-		sb.WriteString(fmt.Sprintf("To: %s\r\n", to))
-	}
-	sb.WriteString(fmt.Sprintf("From: %s\r\n", from))
-	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
-
-	// Add Message-ID for better threading and deliverability
-	messageID := fmt.Sprintf("<%s@%s>", uuid.New().String(), extractDomain(from))
-	sb.WriteString(fmt.Sprintf("Message-ID: %s\r\n", messageID))
-
-	// Add Date header (required by RFC 5322)
-	sb.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
-
-	// Threading headers for replies
-	if opts.InReplyTo != "" {
-		sb.WriteString(fmt.Sprintf("In-Reply-To: %s\r\n", opts.InReplyTo))
-	}
-	if opts.References != "" {
-		sb.WriteString(fmt.Sprintf("References: %s\r\n", opts.References))
-	}
-
-	// MIME Version (required)
-	sb.WriteString("MIME-Version: 1.0\r\n")
-
-	// List-Unsubscribe header (best practice for bulk emails)
-	// This helps prevent spam complaints
-	backendURL := os.Getenv("GO_BACKEND_URL")
-	if backendURL != "" {
-		sb.WriteString(fmt.Sprintf("List-Unsubscribe: <%s/api/v1/email/unsubscribe?email=%s>\r\n", backendURL, to))
-	}
-
-	if hasAttachments {
-		// multipart/mixed for attachments
-		sb.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=\"%s\"\r\n", boundary))
-		sb.WriteString("\r\n")
-
-		// Body part (as multipart/alternative)
-		altBoundary := "altboundary_" + uuid.New().String()
-		sb.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-		sb.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n", altBoundary))
-		sb.WriteString("\r\n")
-
-		// Plain text (always include for maximum compatibility)
-		sb.WriteString(fmt.Sprintf("--%s\r\n", altBoundary))
-		sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
-		sb.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
-		sb.WriteString("\r\n")
-		sb.WriteString(textBody)
-		sb.WriteString("\r\n\r\n")
-
-		// HTML part
-		if htmlBody != "" {
-			sb.WriteString(fmt.Sprintf("--%s\r\n", altBoundary))
-			sb.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-			sb.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
-			sb.WriteString("\r\n")
-			sb.WriteString(htmlBody)
-			sb.WriteString("\r\n\r\n")
-		}
-
-		sb.WriteString(fmt.Sprintf("--%s--\r\n\r\n", altBoundary))
-
-		// Attachments
-		for _, att := range opts.Attachments {
-			sb.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-			contentType := att.ContentType
-			if contentType == "" {
-				contentType = "application/octet-stream"
-			}
-			sb.WriteString(fmt.Sprintf("Content-Type: %s; name=\"%s\"\r\n", contentType, att.Filename))
-			sb.WriteString("Content-Transfer-Encoding: base64\r\n")
-			sb.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=\"%s\"\r\n", att.Filename))
-			sb.WriteString("\r\n")
-			// Attachment data should already be base64 encoded
-			sb.WriteString(att.Data)
-			sb.WriteString("\r\n\r\n")
-		}
-
-		sb.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
-	} else {
-		// No attachments - use simple multipart/alternative
-		sb.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n", boundary))
-		sb.WriteString("\r\n")
-
-		// Plain text part (ALWAYS include - many email clients require it)
-		sb.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-		sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
-		sb.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
-		sb.WriteString("\r\n")
-		sb.WriteString(textBody)
-		sb.WriteString("\r\n\r\n")
-
-		// HTML part
-		if htmlBody != "" {
-			sb.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-			sb.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
-			sb.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
-			sb.WriteString("\r\n")
-			sb.WriteString(htmlBody)
-			sb.WriteString("\r\n\r\n")
-		}
-
-		sb.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
-	}
-
-	return sb.String()
-}
-
-// extractDomain extracts domain from email address
-func extractDomain(email string) string {
-	parts := strings.Split(email, "@")
-	if len(parts) == 2 {
-		return parts[1]
+// processMergeTags renders merge tags with a recipient's submission data.
+// With html set, values are escaped for an HTML body. Tags that don't match
+// the data are left as written; a template that doesn't parse is returned
+// unchanged.
+func processMergeTags(ctx context.Context, content string, data map[string]interface{}, html bool) string {
+	rendered, err := services.RenderMergeTags(content, services.MapMergeResolver(data), services.MergeRenderOptions{
+		HTML:           html,
+		KeepUnresolved: true,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "merge tags not applied", "component", "email", "error", err)
+		return content
 	}
-	return "mail.local"
+	return rendered
 }
 
 // TrackEmailOpen handles the tracking pixel request
@@ -1311,16 +1520,7 @@ func TrackEmailOpen(c *gin.Context) {
 
 	// Check for known bot/scanner user agents
 	userAgent := strings.ToLower(c.GetHeader("User-Agent"))
-	isLikelyBot := strings.Contains(userAgent, "bot") ||
-		strings.Contains(userAgent, "crawler") ||
-		strings.Contains(userAgent, "spider") ||
-		strings.Contains(userAgent, "scan") ||
-		strings.Contains(userAgent, "check") ||
-		strings.Contains(userAgent, "preview") ||
-		strings.Contains(userAgent, "proxy") ||
-		strings.Contains(userAgent, "google") ||
-		strings.Contains(userAgent, "microsoft") ||
-		strings.Contains(userAgent, "yahoo")
+	isLikelyBot := isLikelyBotUserAgent(userAgent)
 
 	// Update the email record
 	var email models.SentEmail
@@ -1339,7 +1539,8 @@ func TrackEmailOpen(c *gin.Context) {
 			email.OpenCount++
 			database.DB.Save(&email)
 		} else {
-			fmt.Printf("[Email Tracking] Ignoring likely bot open - UA: %s, seconds since sent: %.1f\n", userAgent, secondssSinceSent)
+			slog.DebugContext(c.Request.Context(), "ignoring likely bot open", "component", "email_tracking",
+				"user_agent", userAgent, "seconds_since_sent", secondssSinceSent)
 		}
 	}
 
@@ -1347,6 +1548,53 @@ func TrackEmailOpen(c *gin.Context) {
 	c.Data(http.StatusOK, "image/gif", transparentGIF)
 }
 
+// TrackEmailClick records a click on a rewritten link and redirects to the
+// link's stored destination. Unknown or unsigned links get a 404 rather than
+// a redirect, so the endpoint can't be used as an open redirector.
+func TrackEmailClick(c *gin.Context) {
+	trackingID := c.Param("tracking_id")
+	linkID := c.Param("link_id")
+	if !services.VerifyClickSignature(trackingID, linkID, c.Query("sig")) {
+		c.String(http.StatusNotFound, "Link not found")
+		return
+	}
+
+	// Link scanners follow every URL on delivery; still redirect them but
+	// don't count the click
+	userAgent := strings.ToLower(c.GetHeader("User-Agent"))
+	count := !isLikelyBotUserAgent(userAgent)
+
+	destination, err := services.RecordClick(trackingID, linkID, count)
+	if errors.Is(err, services.ErrUnknownEmailLink) {
+		c.String(http.StatusNotFound, "Link not found")
+		return
+	}
+	if err != nil {
+		// The destination is known; don't break the link over a stats failure
+		slog.ErrorContext(c.Request.Context(), "failed to record click", "component", "email_tracking",
+			"tracking_id", trackingID, "link_id", linkID, "error", err)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, destination)
+}
+
+// isLikelyBotUserAgent flags mail scanners and proxies that fetch tracking
+// URLs on behalf of the recipient
+func isLikelyBotUserAgent(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	return strings.Contains(userAgent, "bot") ||
+		strings.Contains(userAgent, "crawler") ||
+		strings.Contains(userAgent, "spider") ||
+		strings.Contains(userAgent, "scan") ||
+		strings.Contains(userAgent, "check") ||
+		strings.Contains(userAgent, "preview") ||
+		strings.Contains(userAgent, "proxy") ||
+		strings.Contains(userAgent, "google") ||
+		strings.Contains(userAgent, "microsoft") ||
+		strings.Contains(userAgent, "yahoo")
+}
+
 // 1x1 transparent GIF
 var transparentGIF = []byte{
 	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00,
@@ -1438,6 +1686,14 @@ func CreateEmailTemplate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if issues := validateEmailMergeTags(template.Subject, template.Body, template.BodyHTML, nil); len(issues) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Template has merge tag syntax errors", "issues": issues})
+		return
+	}
+	if template.Type == "automated" && !services.IsValidEmailTrigger(template.TriggerOn) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Automated templates need trigger_on: submission, approval, rejection or waitlist"})
+		return
+	}
 
 	if err := database.DB.Create(&template).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
@@ -1461,11 +1717,83 @@ func UpdateEmailTemplate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if issues := validateEmailMergeTags(template.Subject, template.Body, template.BodyHTML, nil); len(issues) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Template has merge tag syntax errors", "issues": issues})
+		return
+	}
+	if template.Type == "automated" && !services.IsValidEmailTrigger(template.TriggerOn) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Automated templates need trigger_on: submission, approval, rejection or waitlist"})
+		return
+	}
 
 	database.DB.Save(&template)
 	c.JSON(http.StatusOK, template)
 }
 
+// ValidateEmailTemplateRequest is an email to check before saving or sending
+type ValidateEmailTemplateRequest struct {
+	FormID   string `json:"form_id"` // Check merge tags against this form's fields
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+	BodyHTML string `json:"body_html"`
+}
+
+// EmailMergeTagIssue is a merge tag problem in one part of an email
+type EmailMergeTagIssue struct {
+	Part string `json:"part"` // subject, body, body_html
+	services.MergeTagIssue
+}
+
+// ValidateEmailTemplate reports merge tag syntax errors and tags that don't
+// match the form's fields
+func ValidateEmailTemplate(c *gin.Context) {
+	var req ValidateEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var known *services.MergeFieldSet
+	if req.FormID != "" {
+		formUUID, err := uuid.Parse(req.FormID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form_id"})
+			return
+		}
+		known = services.FormMergeFields(formUUID)
+	}
+
+	issues := validateEmailMergeTags(req.Subject, req.Body, req.BodyHTML, known)
+	c.JSON(http.StatusOK, gin.H{"valid": len(issues) == 0, "issues": issues})
+}
+
+// validateEmailMergeTags checks each part of an email for merge tag syntax
+// errors and, when known is set, unknown tags
+func validateEmailMergeTags(subject, body, bodyHTML string, known *services.MergeFieldSet) []EmailMergeTagIssue {
+	issues := []EmailMergeTagIssue{}
+	for _, part := range []struct{ name, content string }{
+		{"subject", subject},
+		{"body", body},
+		{"body_html", bodyHTML},
+	} {
+		for _, issue := range services.ValidateMergeTemplate(part.content, known) {
+			issues = append(issues, EmailMergeTagIssue{Part: part.name, MergeTagIssue: issue})
+		}
+	}
+	return issues
+}
+
+// blocksSend reports whether merge tag issues should stop an email: syntax
+// errors always do, unknown tags unless the sender allowed them
+func blocksSend(issues []EmailMergeTagIssue, allowUnknown bool) bool {
+	for _, issue := range issues {
+		if issue.Kind == services.MergeIssueSyntax || !allowUnknown {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteEmailTemplate deletes an email template
 func DeleteEmailTemplate(c *gin.Context) {
 	id := c.Param("id")
@@ -1707,22 +2035,23 @@ type GmailEmail struct {
 }
 
 // searchGmailForRecipient searches Gmail for emails sent to a specific recipient
-func searchGmailForRecipient(workspaceID string, recipientEmail string) ([]GmailEmail, error) {
+func searchGmailForRecipient(ctx context.Context, workspaceID string, recipientEmail string) ([]GmailEmail, error) {
 	if recipientEmail == "" {
-		fmt.Printf("[Gmail Search] No recipient email provided\n")
+		slog.DebugContext(ctx, "no recipient email provided", "component", "gmail_search")
 		return nil, nil
 	}
 
-	fmt.Printf("[Gmail Search] Starting search for workspace: %s, recipient: %s\n", workspaceID, recipientEmail)
+	slog.DebugContext(ctx, "starting gmail search", "component", "gmail_search", "workspace_id", workspaceID, "recipient", recipientEmail)
 
 	// Get Gmail connection for this workspace
 	var connection models.GmailConnection
 	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&connection).Error; err != nil {
-		fmt.Printf("[Gmail Search] No Gmail connection for workspace %s: %v\n", workspaceID, err)
+		slog.InfoContext(ctx, "no gmail connection for workspace", "component", "gmail_search", "workspace_id", workspaceID, "error", err)
 		return nil, nil // No connection, just return empty
 	}
 
-	fmt.Printf("[Gmail Search] Found Gmail connection for: %s, token expires: %v\n", connection.Email, connection.TokenExpiry)
+	slog.DebugContext(ctx, "found gmail connection", "component", "gmail_search",
+		"connection", connection.Email, "token_expiry", connection.TokenExpiry)
 
 	// Create OAuth token
 	token := &oauth2.Token{
@@ -1735,17 +2064,17 @@ func searchGmailForRecipient(workspaceID string, recipientEmail string) ([]Gmail
 
 	// Check if token is expired and needs refresh
 	if token.Expiry.Before(time.Now()) {
-		fmt.Printf("[Gmail Search] Token expired at %v, refreshing...\n", token.Expiry)
+		slog.InfoContext(ctx, "gmail token expired, refreshing", "component", "gmail_search", "expired_at", token.Expiry)
 		// Use the TokenSource to refresh
 		tokenSource := config.TokenSource(context.Background(), token)
 		newToken, err := tokenSource.Token()
 		if err != nil {
-			fmt.Printf("[Gmail Search] Token refresh failed: %v\n", err)
+			slog.ErrorContext(ctx, "gmail token refresh failed", "component", "gmail_search", "error", err)
 			return nil, fmt.Errorf("token refresh failed: %v", err)
 		}
 		// Update stored token if it was refreshed
 		if newToken.AccessToken != token.AccessToken {
-			fmt.Printf("[Gmail Search] Token refreshed successfully, updating in database\n")
+			slog.InfoContext(ctx, "gmail token refreshed, updating in database", "component", "gmail_search")
 			connection.AccessToken = newToken.AccessToken
 			connection.RefreshToken = newToken.RefreshToken
 			connection.TokenExpiry = newToken.Expiry
@@ -1754,31 +2083,31 @@ func searchGmailForRecipient(workspaceID string, recipientEmail string) ([]Gmail
 		token = newToken
 	}
 
-	client := config.Client(context.Background(), token)
+	client := config.Client(tracing.OAuthContext(context.Background(), "gmail"), token)
 	gmailService, err := gmail.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
-		fmt.Printf("[Gmail Search] Failed to create Gmail service: %v\n", err)
+		slog.ErrorContext(ctx, "failed to create gmail service", "component", "gmail_search", "error", err)
 		return nil, err
 	}
 
 	// Search for ALL emails involving this recipient (sent to them OR received from them)
 	query := fmt.Sprintf("(to:%s OR from:%s)", recipientEmail, recipientEmail)
-	fmt.Printf("[Gmail Search] Searching with query: %s\n", query)
+	slog.DebugContext(ctx, "searching gmail", "component", "gmail_search", "query", query)
 
 	result, err := gmailService.Users.Messages.List("me").Q(query).MaxResults(50).Do()
 	if err != nil {
-		fmt.Printf("[Gmail Search] Search failed: %v\n", err)
+		slog.ErrorContext(ctx, "gmail search failed", "component", "gmail_search", "error", err)
 		return nil, err
 	}
 
-	fmt.Printf("[Gmail Search] Found %d messages\n", len(result.Messages))
+	slog.DebugContext(ctx, "found gmail messages", "component", "gmail_search", "count", len(result.Messages))
 
 	var emails []GmailEmail
 	for _, msg := range result.Messages {
 		// Get full message details
 		fullMsg, err := gmailService.Users.Messages.Get("me", msg.Id).Format("full").Do()
 		if err != nil {
-			fmt.Printf("[Gmail Search] Failed to get message %s: %v\n", msg.Id, err)
+			slog.WarnContext(ctx, "failed to get gmail message", "component", "gmail_search", "message_id", msg.Id, "error", err)
 			continue
 		}
 
@@ -1840,7 +2169,7 @@ func searchGmailForRecipient(workspaceID string, recipientEmail string) ([]Gmail
 }
 
 // searchGmailByThreads searches for all messages in specific threads
-func searchGmailByThreads(workspaceID string, threadIDs map[string]bool) ([]GmailEmail, error) {
+func searchGmailByThreads(ctx context.Context, workspaceID string, threadIDs map[string]bool) ([]GmailEmail, error) {
 	if len(threadIDs) == 0 {
 		return nil, nil
 	}
@@ -1875,7 +2204,7 @@ func searchGmailByThreads(workspaceID string, threadIDs map[string]bool) ([]Gmai
 		token = newToken
 	}
 
-	client := config.Client(context.Background(), token)
+	client := config.Client(tracing.OAuthContext(context.Background(), "gmail"), token)
 	gmailService, err := gmail.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
 		return nil, err
@@ -1883,14 +2212,15 @@ func searchGmailByThreads(workspaceID string, threadIDs map[string]bool) ([]Gmai
 
 	var allEmails []GmailEmail
 	for threadID := range threadIDs {
-		fmt.Printf("[Gmail Thread Search] Getting thread: %s\n", threadID)
+		slog.DebugContext(ctx, "getting gmail thread", "component", "gmail_search", "thread_id", threadID)
 		thread, err := gmailService.Users.Threads.Get("me", threadID).Format("full").Do()
 		if err != nil {
-			fmt.Printf("[Gmail Thread Search] Failed to get thread %s: %v\n", threadID, err)
+			slog.WarnContext(ctx, "failed to get gmail thread", "component", "gmail_search", "thread_id", threadID, "error", err)
 			continue
 		}
 
-		fmt.Printf("[Gmail Thread Search] Thread %s has %d messages\n", threadID, len(thread.Messages))
+		slog.DebugContext(ctx, "found gmail thread messages", "component", "gmail_search",
+			"thread_id", threadID, "count", len(thread.Messages))
 		for _, msg := range thread.Messages {
 			email := GmailEmail{
 				ID:             msg.Id,
@@ -2006,7 +2336,8 @@ func GetSubmissionEmailHistory(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("[Email History] Looking up emails for submission: %s, workspace: %s\n", submissionID, workspaceID)
+	slog.DebugContext(c.Request.Context(), "looking up email history", "component", "email_history",
+		"submission_id", submissionID, "workspace_id", workspaceID)
 
 	// First get the submission to find the recipient email
 	// Note: table_rows doesn't have workspace_id column, so we get it from data_tables via table_id
@@ -2016,13 +2347,14 @@ func GetSubmissionEmailHistory(c *gin.Context) {
 		TableID *string        `gorm:"column:table_id"`
 	}
 	if err := database.DB.Table("table_rows").Select("id, data, table_id").Where("id = ?", submissionID).First(&submission).Error; err != nil {
-		fmt.Printf("[Email History] Submission not found: %s, error: %v\n", submissionID, err)
+		slog.WarnContext(c.Request.Context(), "submission not found", "component", "email_history",
+			"submission_id", submissionID, "error", err)
 		c.JSON(http.StatusOK, []interface{}{})
 		return
 	}
 
 	// Use workspace_id from query parameter, or look it up from the table
-	fmt.Printf("[Email History] Initial workspaceID from query: '%s'\n", workspaceID)
+	slog.DebugContext(c.Request.Context(), "workspace ID from query", "component", "email_history", "workspace_id", workspaceID)
 	// If no workspace_id from query, try to get it from the table
 	if workspaceID == "" && submission.TableID != nil {
 		var table struct {
@@ -2030,21 +2362,22 @@ func GetSubmissionEmailHistory(c *gin.Context) {
 		}
 		database.DB.Table("data_tables").Select("workspace_id").Where("id = ?", *submission.TableID).First(&table)
 		workspaceID = table.WorkspaceID
-		fmt.Printf("[Email History] Got workspaceID from table: '%s'\n", workspaceID)
+		slog.DebugContext(c.Request.Context(), "workspace ID from table", "component", "email_history", "workspace_id", workspaceID)
 	}
-	fmt.Printf("[Email History] Final workspaceID: '%s'\n", workspaceID)
+	slog.DebugContext(c.Request.Context(), "resolved workspace ID", "component", "email_history", "workspace_id", workspaceID)
 
 	// Parse the data to find email
 	var data map[string]interface{}
 	if err := json.Unmarshal(submission.Data, &data); err != nil {
-		fmt.Printf("[Email History] Failed to parse submission data: %v\n", err)
+		slog.WarnContext(c.Request.Context(), "failed to parse submission data", "component", "email_history", "error", err)
 		c.JSON(http.StatusOK, []interface{}{})
 		return
 	}
 
 	// Find ALL emails in the submission data (supports multiple email fields)
 	recipientEmails := findAllEmailsInData(data)
-	fmt.Printf("[Email History] Found %d recipient emails: %v\n", len(recipientEmails), recipientEmails)
+	slog.DebugContext(c.Request.Context(), "found recipient emails", "component", "email_history",
+		"count", len(recipientEmails), "emails", recipientEmails)
 
 	// Query database for emails - search by submission_id OR any of the recipient emails
 	var dbEmails []models.SentEmail
@@ -2059,9 +2392,10 @@ func GetSubmissionEmailHistory(c *gin.Context) {
 			Find(&dbEmails)
 	}
 
-	fmt.Printf("[Email History] Found %d emails in database\n", len(dbEmails))
+	slog.DebugContext(c.Request.Context(), "found emails in database", "component", "email_history", "count", len(dbEmails))
 	for i, e := range dbEmails {
-		fmt.Printf("[Email History] Email %d: id=%s, to=%s, subject=%s, thread=%s\n", i, e.ID, e.RecipientEmail, e.Subject, e.GmailThreadID)
+		slog.DebugContext(c.Request.Context(), "email history entry", "component", "email_history",
+			"index", i, "sent_email_id", e.ID, "to", e.RecipientEmail, "subject", e.Subject, "thread_id", e.GmailThreadID)
 	}
 
 	// Collect thread IDs from database emails to search for replies
@@ -2074,32 +2408,37 @@ func GetSubmissionEmailHistory(c *gin.Context) {
 
 	// Also search Gmail for each recipient email
 	var gmailEmails []GmailEmail
-	fmt.Printf("[Email History] Gmail search check - workspaceID: '%s', recipientEmails count: %d\n", workspaceID, len(recipientEmails))
+	slog.DebugContext(c.Request.Context(), "gmail search check", "component", "email_history",
+		"workspace_id", workspaceID, "recipient_count", len(recipientEmails))
 	if workspaceID != "" && len(recipientEmails) > 0 {
-		fmt.Printf("[Email History] Starting Gmail search for %d emails\n", len(recipientEmails))
+		slog.DebugContext(c.Request.Context(), "starting gmail search", "component", "email_history",
+			"recipient_count", len(recipientEmails))
 		for _, email := range recipientEmails {
-			fmt.Printf("[Email History] Searching Gmail for: %s\n", email)
-			emails, err := searchGmailForRecipient(workspaceID, email)
+			slog.DebugContext(c.Request.Context(), "searching gmail for recipient", "component", "email_history", "email", email)
+			emails, err := searchGmailForRecipient(c.Request.Context(), workspaceID, email)
 			if err != nil {
-				fmt.Printf("[Email History] Gmail search error: %v\n", err)
+				slog.WarnContext(c.Request.Context(), "gmail search failed", "component", "email_history", "error", err)
 			}
 			gmailEmails = append(gmailEmails, emails...)
 		}
-		fmt.Printf("[Email History] Found %d emails in Gmail\n", len(gmailEmails))
+		slog.DebugContext(c.Request.Context(), "found emails in gmail", "component", "email_history", "count", len(gmailEmails))
 
 		// Also search for any threads we know about (catches replies)
 		if len(threadIDs) > 0 {
-			fmt.Printf("[Email History] Also searching %d known threads for replies\n", len(threadIDs))
-			threadEmails, err := searchGmailByThreads(workspaceID, threadIDs)
+			slog.DebugContext(c.Request.Context(), "searching known threads for replies", "component", "email_history",
+				"thread_count", len(threadIDs))
+			threadEmails, err := searchGmailByThreads(c.Request.Context(), workspaceID, threadIDs)
 			if err != nil {
-				fmt.Printf("[Email History] Thread search error: %v\n", err)
+				slog.WarnContext(c.Request.Context(), "gmail thread search failed", "component", "email_history", "error", err)
 			} else {
-				fmt.Printf("[Email History] Found %d emails from thread search\n", len(threadEmails))
+				slog.DebugContext(c.Request.Context(), "found emails from thread search", "component", "email_history",
+					"count", len(threadEmails))
 				gmailEmails = append(gmailEmails, threadEmails...)
 			}
 		}
 	} else {
-		fmt.Printf("[Email History] Skipping Gmail search - workspaceID empty: %v, no emails: %v\n", workspaceID == "", len(recipientEmails) == 0)
+		slog.DebugContext(c.Request.Context(), "skipping gmail search", "component", "email_history",
+			"workspace_id_empty", workspaceID == "", "no_emails", len(recipientEmails) == 0)
 	}
 
 	// Combine results, deduplicating by gmail_message_id
@@ -2121,11 +2460,20 @@ func GetSubmissionEmailHistory(c *gin.Context) {
 		}
 	}
 
-	fmt.Printf("[Email History] Returning %d total emails\n", len(combinedEmails))
+	slog.DebugContext(c.Request.Context(), "returning email history", "component", "email_history", "count", len(combinedEmails))
 
 	c.JSON(http.StatusOK, combinedEmails)
 }
 
+// SubmissionActivityItem is one entry in a submission's email activity feed
+type SubmissionActivityItem struct {
+	Type        string      `json:"type"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Timestamp   time.Time   `json:"timestamp"`
+	Data        interface{} `json:"data,omitempty"`
+}
+
 // GetSubmissionActivity returns activity log for a specific submission
 func GetSubmissionActivity(c *gin.Context) {
 	submissionID := c.Param("id")
@@ -2135,7 +2483,7 @@ func GetSubmissionActivity(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("[Email Activity] Looking up activity for submission: %s\n", submissionID)
+	slog.DebugContext(c.Request.Context(), "looking up email activity", "component", "email_activity", "submission_id", submissionID)
 
 	// First get the submission to find the recipient email
 	var submission struct {
@@ -2143,7 +2491,7 @@ func GetSubmissionActivity(c *gin.Context) {
 		Data datatypes.JSON `gorm:"column:data"`
 	}
 	if err := database.DB.Table("table_rows").Select("id, data").Where("id = ?", submissionID).First(&submission).Error; err != nil {
-		fmt.Printf("[Email Activity] Submission not found: %s\n", submissionID)
+		slog.WarnContext(c.Request.Context(), "submission not found", "component", "email_activity", "submission_id", submissionID)
 		c.JSON(http.StatusOK, []interface{}{}) // Return empty array instead of error
 		return
 	}
@@ -2151,14 +2499,15 @@ func GetSubmissionActivity(c *gin.Context) {
 	// Parse the data to find email
 	var data map[string]interface{}
 	if err := json.Unmarshal(submission.Data, &data); err != nil {
-		fmt.Printf("[Email Activity] Failed to parse submission data: %v\n", err)
+		slog.WarnContext(c.Request.Context(), "failed to parse submission data", "component", "email_activity", "error", err)
 		c.JSON(http.StatusOK, []interface{}{})
 		return
 	}
 
 	// Find ALL emails in the submission data
 	recipientEmails := findAllEmailsInData(data)
-	fmt.Printf("[Email Activity] Found %d recipient emails: %v\n", len(recipientEmails), recipientEmails)
+	slog.DebugContext(c.Request.Context(), "found recipient emails", "component", "email_activity",
+		"count", len(recipientEmails), "emails", recipientEmails)
 
 	// Get emails sent to this submission (by submission_id OR any email address)
 	var emails []models.SentEmail
@@ -2174,21 +2523,12 @@ func GetSubmissionActivity(c *gin.Context) {
 			Find(&emails)
 	}
 
-	fmt.Printf("[Email Activity] Found %d emails\n", len(emails))
-
-	// Build activity items from emails
-	type ActivityItem struct {
-		Type        string      `json:"type"`
-		Title       string      `json:"title"`
-		Description string      `json:"description"`
-		Timestamp   time.Time   `json:"timestamp"`
-		Data        interface{} `json:"data,omitempty"`
-	}
+	slog.DebugContext(c.Request.Context(), "found emails", "component", "email_activity", "count", len(emails))
 
-	var activities []ActivityItem
+	var activities []SubmissionActivityItem
 
 	for _, email := range emails {
-		activity := ActivityItem{
+		activity := SubmissionActivityItem{
 			Type:        "email_sent",
 			Title:       "Email Sent",
 			Description: email.Subject,
@@ -2204,7 +2544,7 @@ func GetSubmissionActivity(c *gin.Context) {
 
 		// Add opened event if email was opened
 		if email.OpenedAt != nil {
-			activities = append(activities, ActivityItem{
+			activities = append(activities, SubmissionActivityItem{
 				Type:        "email_opened",
 				Title:       "Email Opened",
 				Description: email.Subject,
@@ -6,12 +6,27 @@ import (
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 // Email Analytics Endpoints
 
+// EmailAnalyticsStats summarizes delivery and engagement for a workspace's sent email
+type EmailAnalyticsStats struct {
+	TotalSent      int64   `json:"total_sent"`
+	TotalDelivered int64   `json:"total_delivered"`
+	TotalOpened    int64   `json:"total_opened"`
+	TotalClicked   int64   `json:"total_clicked"`
+	TotalBounced   int64   `json:"total_bounced"`
+	TotalFailed    int64   `json:"total_failed"`
+	DeliveryRate   float64 `json:"delivery_rate"`
+	OpenRate       float64 `json:"open_rate"`
+	ClickRate      float64 `json:"click_rate"`
+	BounceRate     float64 `json:"bounce_rate"`
+}
+
 // GetEmailAnalytics returns email performance metrics
 func GetEmailAnalytics(c *gin.Context) {
 	workspaceID := c.Query("workspace_id")
@@ -61,18 +76,7 @@ func GetEmailAnalytics(c *gin.Context) {
 		query = query.Where("form_id = ?", formUUID)
 	}
 
-	var stats struct {
-		TotalSent      int64   `json:"total_sent"`
-		TotalDelivered int64   `json:"total_delivered"`
-		TotalOpened    int64   `json:"total_opened"`
-		TotalClicked   int64   `json:"total_clicked"`
-		TotalBounced   int64   `json:"total_bounced"`
-		TotalFailed    int64   `json:"total_failed"`
-		DeliveryRate   float64 `json:"delivery_rate"`
-		OpenRate       float64 `json:"open_rate"`
-		ClickRate      float64 `json:"click_rate"`
-		BounceRate     float64 `json:"bounce_rate"`
-	}
+	var stats EmailAnalyticsStats
 
 	query.Count(&stats.TotalSent)
 	query.Where("status IN ('delivered', 'opened', 'clicked')").Count(&stats.TotalDelivered)
@@ -106,16 +110,41 @@ func GetEmailServiceHealth(c *gin.Context) {
 		return
 	}
 
-	var gmailHealth, resendHealth models.EmailServiceHealth
+	// refresh=true runs each provider's health check instead of relying on the last send
+	if c.Query("refresh") == "true" {
+		services.NewEmailRouter().RefreshServiceHealth(c.Request.Context(), wsUUID)
+	}
+
+	var gmailHealth, resendHealth, smtpHealth models.EmailServiceHealth
 	database.DB.Where("workspace_id = ? AND service_type = ?", wsUUID, "gmail").First(&gmailHealth)
 	database.DB.Where("workspace_id = ? AND service_type = ?", wsUUID, "resend").First(&resendHealth)
+	database.DB.Where("workspace_id = ? AND service_type = ?", wsUUID, "smtp").First(&smtpHealth)
 
 	c.JSON(http.StatusOK, gin.H{
 		"gmail":  gmailHealth,
 		"resend": resendHealth,
+		"smtp":   smtpHealth,
 	})
 }
 
+// CampaignAnalyticsStats summarizes delivery, engagement and A/B test results for a campaign
+type CampaignAnalyticsStats struct {
+	TotalSent       int64                           `json:"total_sent"`
+	TotalDelivered  int64                           `json:"total_delivered"`
+	TotalOpened     int64                           `json:"total_opened"`
+	TotalClicked    int64                           `json:"total_clicked"`
+	TotalBounced    int64                           `json:"total_bounced"`
+	DeliveryRate    float64                         `json:"delivery_rate"`
+	OpenRate        float64                         `json:"open_rate"`
+	ClickRate       float64                         `json:"click_rate"`
+	BounceRate      float64                         `json:"bounce_rate"`
+	Links           []services.LinkClickStats       `json:"links"`                       // Per-link clicks from click tracking
+	Variants        []services.CampaignVariantStats `json:"variants,omitempty"`          // A/B test results per variant
+	WinnerMetric    string                          `json:"winner_metric,omitempty"`     // open_rate, click_rate
+	TestEndsAt      *time.Time                      `json:"test_ends_at,omitempty"`      // When the A/B test winner is picked
+	WinnerVariantID *uuid.UUID                      `json:"winner_variant_id,omitempty"` // Set once the winner is picked
+}
+
 // GetEmailCampaignAnalytics returns analytics for a specific campaign
 func GetEmailCampaignAnalytics(c *gin.Context) {
 	campaignID := c.Param("id")
@@ -126,17 +155,7 @@ func GetEmailCampaignAnalytics(c *gin.Context) {
 		return
 	}
 
-	var stats struct {
-		TotalSent      int64   `json:"total_sent"`
-		TotalDelivered int64   `json:"total_delivered"`
-		TotalOpened    int64   `json:"total_opened"`
-		TotalClicked   int64   `json:"total_clicked"`
-		TotalBounced   int64   `json:"total_bounced"`
-		DeliveryRate   float64 `json:"delivery_rate"`
-		OpenRate       float64 `json:"open_rate"`
-		ClickRate      float64 `json:"click_rate"`
-		BounceRate     float64 `json:"bounce_rate"`
-	}
+	var stats CampaignAnalyticsStats
 
 	database.DB.Model(&models.SentEmail{}).
 		Where("campaign_id = ?", campaign.ID).
@@ -164,6 +183,11 @@ func GetEmailCampaignAnalytics(c *gin.Context) {
 		stats.ClickRate = float64(stats.TotalClicked) / float64(stats.TotalSent) * 100
 		stats.BounceRate = float64(stats.TotalBounced) / float64(stats.TotalSent) * 100
 	}
+	stats.Links = services.CampaignLinkStats(campaign.ID.String())
+	stats.Variants = services.CampaignVariantResults(campaign)
+	stats.WinnerMetric = campaign.WinnerMetric
+	stats.TestEndsAt = campaign.TestEndsAt
+	stats.WinnerVariantID = campaign.WinnerVariantID
 
 	c.JSON(http.StatusOK, stats)
 }
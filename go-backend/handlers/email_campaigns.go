@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Email Campaign Control Endpoints
+
+// PauseEmailCampaign holds a scheduled or sending campaign
+func PauseEmailCampaign(c *gin.Context) {
+	changeEmailCampaignState(c, func(ctx context.Context, id uuid.UUID) (*models.EmailCampaign, error) {
+		return services.PauseCampaign(id)
+	})
+}
+
+// ResumeEmailCampaign continues a paused campaign
+func ResumeEmailCampaign(c *gin.Context) {
+	changeEmailCampaignState(c, func(ctx context.Context, id uuid.UUID) (*models.EmailCampaign, error) {
+		return services.ResumeCampaign(ctx, services.NewEmailRouter(), id)
+	})
+}
+
+// CancelEmailCampaign stops a campaign; emails not yet sent are cancelled
+func CancelEmailCampaign(c *gin.Context) {
+	changeEmailCampaignState(c, func(ctx context.Context, id uuid.UUID) (*models.EmailCampaign, error) {
+		return services.CancelCampaign(id)
+	})
+}
+
+// changeEmailCampaignState checks the campaign belongs to the workspace and
+// applies a state change, mapping invalid transitions to 409
+func changeEmailCampaignState(c *gin.Context, change func(ctx context.Context, id uuid.UUID) (*models.EmailCampaign, error)) {
+	workspaceID := c.Query("workspace_id")
+	if workspaceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+	campaignID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid campaign ID"})
+		return
+	}
+
+	var campaign models.EmailCampaign
+	if err := database.DB.Where("id = ? AND workspace_id = ?", campaignID, workspaceID).First(&campaign).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		return
+	}
+
+	updated, err := change(c.Request.Context(), campaign.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCampaignTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update campaign"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
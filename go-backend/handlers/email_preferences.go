@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Public Unsubscribe & Email Preference Endpoints
+// Recipients are identified by the signed token embedded in each email, so
+// no login is required.
+
+// EmailPreferenceCategory is one category on the preference page
+type EmailPreferenceCategory struct {
+	services.EmailCategoryInfo
+	Subscribed bool `json:"subscribed"`
+}
+
+// EmailPreferencesResponse describes a recipient's current preferences
+type EmailPreferencesResponse struct {
+	Email           string                    `json:"email"`
+	WorkspaceName   string                    `json:"workspace_name"`
+	UnsubscribedAll bool                      `json:"unsubscribed_all"`
+	Blocked         bool                      `json:"blocked"` // Suppressed for a bounce or complaint; can't be changed here
+	Categories      []EmailPreferenceCategory `json:"categories"`
+}
+
+// UpdateEmailPreferencesRequest changes a recipient's preferences
+type UpdateEmailPreferencesRequest struct {
+	Categories     map[string]bool `json:"categories"`      // category key -> subscribed
+	UnsubscribeAll *bool           `json:"unsubscribe_all"` // true unsubscribes from everything, false resubscribes
+}
+
+// OneClickUnsubscribe handles RFC 8058 List-Unsubscribe-Post requests. Mail
+// clients POST "List-Unsubscribe=One-Click" and expect no further interaction.
+func OneClickUnsubscribe(c *gin.Context) {
+	workspaceID, email, err := services.ParsePreferenceToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unsubscribe link"})
+		return
+	}
+
+	if err := services.Unsubscribe(workspaceID, email, "unsubscribe_link"); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to unsubscribe", "component", "email_preferences",
+			"workspace_id", workspaceID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "You have been unsubscribed"})
+}
+
+// UnsubscribeLanding sends people who open the List-Unsubscribe link in a
+// browser to the preference page. GET never unsubscribes, so link scanners
+// can't opt recipients out (RFC 8058 section 3.2).
+func UnsubscribeLanding(c *gin.Context) {
+	token := c.Param("token")
+	if _, _, err := services.ParsePreferenceToken(token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unsubscribe link"})
+		return
+	}
+	c.Redirect(http.StatusFound, services.PreferencesURL(token))
+}
+
+// GetEmailPreferences returns the preference page data for a token
+func GetEmailPreferences(c *gin.Context) {
+	workspaceID, email, err := services.ParsePreferenceToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preference link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildEmailPreferencesResponse(workspaceID, email))
+}
+
+// UpdateEmailPreferences saves category opt-outs and the unsubscribe-all flag
+func UpdateEmailPreferences(c *gin.Context) {
+	workspaceID, email, err := services.ParsePreferenceToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preference link"})
+		return
+	}
+
+	var req UpdateEmailPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Categories != nil {
+		// Start from the current opt-outs so omitted categories keep their setting
+		optedOut := map[string]bool{}
+		for _, category := range services.OptedOutCategories(workspaceID, email) {
+			optedOut[category] = true
+		}
+		for category, subscribed := range req.Categories {
+			if !services.IsValidEmailCategory(category) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown email category: " + category})
+				return
+			}
+			optedOut[category] = !subscribed
+		}
+
+		categories := []string{}
+		for _, info := range services.EmailCategories {
+			if optedOut[info.Key] {
+				categories = append(categories, info.Key)
+			}
+		}
+		if err := services.SetOptedOutCategories(workspaceID, email, categories); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+			return
+		}
+	}
+
+	if req.UnsubscribeAll != nil {
+		if *req.UnsubscribeAll {
+			err = services.Unsubscribe(workspaceID, email, "preference_center")
+		} else {
+			err = services.Resubscribe(workspaceID, email)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, buildEmailPreferencesResponse(workspaceID, email))
+}
+
+func buildEmailPreferencesResponse(workspaceID uuid.UUID, email string) EmailPreferencesResponse {
+	var workspace models.Workspace
+	database.DB.Select("id", "name").Where("id = ?", workspaceID).First(&workspace)
+
+	response := EmailPreferencesResponse{
+		Email:         email,
+		WorkspaceName: workspace.Name,
+		Categories:    []EmailPreferenceCategory{},
+	}
+
+	if suppressed, entry := services.IsSuppressed(workspaceID, email); suppressed {
+		if entry.Reason == services.SuppressionUnsubscribe {
+			response.UnsubscribedAll = true
+		} else {
+			response.Blocked = true
+		}
+	}
+
+	optedOut := map[string]bool{}
+	for _, category := range services.OptedOutCategories(workspaceID, email) {
+		optedOut[category] = true
+	}
+	for _, info := range services.EmailCategories {
+		response.Categories = append(response.Categories, EmailPreferenceCategory{
+			EmailCategoryInfo: info,
+			Subscribed:        !optedOut[info.Key],
+		})
+	}
+	return response
+}
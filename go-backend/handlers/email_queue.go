@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/logging"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -67,6 +69,8 @@ func RetryEmailQueueItem(c *gin.Context) {
 	item.Status = "pending"
 	item.ScheduledFor = time.Now() // Retry immediately
 	item.ErrorMessage = ""
+	item.TraceParent = tracing.TraceParent(c.Request.Context())
+	item.RequestID = logging.RequestID(c.Request.Context())
 
 	if err := database.DB.Save(&item).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry queue item"})
@@ -103,6 +107,15 @@ func CancelEmailQueueItem(c *gin.Context) {
 	c.JSON(http.StatusOK, item)
 }
 
+// EmailQueueStats counts a workspace's queued email by status
+type EmailQueueStats struct {
+	Pending    int64 `json:"pending"`
+	Processing int64 `json:"processing"`
+	Sent       int64 `json:"sent"`
+	Failed     int64 `json:"failed"`
+	Retrying   int64 `json:"retrying"`
+}
+
 // GetEmailQueueStats returns queue statistics
 func GetEmailQueueStats(c *gin.Context) {
 	workspaceID := c.Query("workspace_id")
@@ -111,13 +124,7 @@ func GetEmailQueueStats(c *gin.Context) {
 		return
 	}
 
-	var stats struct {
-		Pending    int64 `json:"pending"`
-		Processing int64 `json:"processing"`
-		Sent       int64 `json:"sent"`
-		Failed     int64 `json:"failed"`
-		Retrying   int64 `json:"retrying"`
-	}
+	var stats EmailQueueStats
 
 	wsUUID, _ := uuid.Parse(workspaceID)
 
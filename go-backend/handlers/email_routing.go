@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Email Routing Policy Endpoints
+
+// EmailProviderInfo describes a provider available to the router
+type EmailProviderInfo struct {
+	Type         services.EmailServiceType     `json:"type"`
+	Capabilities services.ProviderCapabilities `json:"capabilities"`
+}
+
+// EmailRoutingPolicyResponse is the workspace's effective routing policy
+type EmailRoutingPolicyResponse struct {
+	Rules     services.RoutingRules     `json:"rules"`              // Effective rules (workspace rules over defaults)
+	Defaults  services.RoutingRules     `json:"defaults"`           // Built-in rules
+	Providers []EmailProviderInfo       `json:"providers"`          // Registered providers
+	Override  services.EmailServiceType `json:"override,omitempty"` // Set when EMAIL_PROVIDER_OVERRIDE forces one provider
+}
+
+// UpdateEmailRoutingPolicyRequest replaces the workspace's routing rules.
+// Omitted email types use the defaults; empty rules reset the policy.
+type UpdateEmailRoutingPolicyRequest struct {
+	Rules services.RoutingRules `json:"rules"`
+}
+
+// GetEmailRoutingPolicy returns the effective routing policy for a workspace
+func GetEmailRoutingPolicy(c *gin.Context) {
+	wsUUID, err := uuid.Parse(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildEmailRoutingPolicyResponse(wsUUID))
+}
+
+// UpdateEmailRoutingPolicy saves the workspace's routing rules
+func UpdateEmailRoutingPolicy(c *gin.Context) {
+	wsUUID, err := uuid.Parse(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	var req UpdateEmailRoutingPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateRoutingRules(services.DefaultEmailProviders(), req.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	updatedBy, _ := userID.(string)
+	if err := services.SaveRoutingRules(wsUUID, req.Rules, updatedBy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save routing policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildEmailRoutingPolicyResponse(wsUUID))
+}
+
+func buildEmailRoutingPolicyResponse(workspaceID uuid.UUID) EmailRoutingPolicyResponse {
+	registry := services.DefaultEmailProviders()
+	response := EmailRoutingPolicyResponse{
+		Rules:     services.WorkspaceRoutingRules(workspaceID),
+		Defaults:  services.DefaultRoutingRules(),
+		Providers: []EmailProviderInfo{},
+		Override:  services.EmailProviderOverride(),
+	}
+	for _, serviceType := range registry.Types() {
+		provider, _ := registry.Get(serviceType)
+		response.Providers = append(response.Providers, EmailProviderInfo{
+			Type:         serviceType,
+			Capabilities: provider.Capabilities(),
+		})
+	}
+	return response
+}
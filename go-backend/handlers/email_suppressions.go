@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Email Suppression List Endpoints
+
+// CreateEmailSuppressionRequest is the body for manually suppressing an address
+type CreateEmailSuppressionRequest struct {
+	Email   string `json:"email" binding:"required"`
+	Details string `json:"details,omitempty"`
+}
+
+// ListEmailSuppressions returns the workspace suppression list
+func ListEmailSuppressions(c *gin.Context) {
+	workspaceID := c.Query("workspace_id")
+	if workspaceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	query := database.DB.Where("workspace_id = ?", workspaceID)
+	if reason := c.Query("reason"); reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("email ILIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+
+	var suppressions []models.EmailSuppression
+	if err := query.Order("created_at DESC").Limit(500).Find(&suppressions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suppression list"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suppressions)
+}
+
+// CreateEmailSuppression manually adds an address to the suppression list
+func CreateEmailSuppression(c *gin.Context) {
+	workspaceID, err := uuid.Parse(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	var req CreateEmailSuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	createdBy, _ := userID.(string)
+
+	suppression, err := services.AddSuppression(services.SuppressionInput{
+		WorkspaceID: workspaceID,
+		Email:       req.Email,
+		Reason:      services.SuppressionManual,
+		Source:      "admin",
+		Details:     req.Details,
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, suppression)
+}
+
+// DeleteEmailSuppression removes an address from the suppression list so it
+// can receive email again
+func DeleteEmailSuppression(c *gin.Context) {
+	id := c.Param("id")
+	workspaceID := c.Query("workspace_id")
+	if workspaceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	result := database.DB.Where("id = ? AND workspace_id = ?", id, workspaceID).Delete(&models.EmailSuppression{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove suppression"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suppression not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Suppression removed"})
+}
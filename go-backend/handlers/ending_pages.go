@@ -8,6 +8,7 @@ import (
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -320,7 +321,11 @@ func FindMatchingEnding(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, endingPageToDTO(*matchingEnding))
+	dto := endingPageToDTO(*matchingEnding)
+	if len(req.SubmissionData) > 0 {
+		renderEndingMergeTags(dto.Blocks, req.SubmissionData)
+	}
+	c.JSON(http.StatusOK, dto)
 }
 
 // SetDefaultEnding - PUT /api/v1/ending-pages/:id/default
@@ -351,16 +356,19 @@ func SetDefaultEnding(c *gin.Context) {
 	c.JSON(http.StatusOK, endingPageToDTO(endingPage))
 }
 
+// ReorderEndingsRequest lists a form's ending pages in their new order
+type ReorderEndingsRequest struct {
+	FormID uuid.UUID `json:"form_id"`
+	Order  []struct {
+		EndingID string `json:"ending_id"`
+		Priority int    `json:"priority"`
+	} `json:"order"`
+}
+
 // ReorderEndings - PUT /api/v1/ending-pages/reorder
 // Updates the priority order of endings for a form
 func ReorderEndings(c *gin.Context) {
-	var req struct {
-		FormID uuid.UUID `json:"form_id"`
-		Order  []struct {
-			EndingID string `json:"ending_id"`
-			Priority int    `json:"priority"`
-		} `json:"order"`
-	}
+	var req ReorderEndingsRequest
 
 	if err := c.BindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
@@ -397,6 +405,38 @@ func ReorderEndings(c *gin.Context) {
 
 // Helper functions
 
+// renderEndingMergeTags fills merge tags in the blocks' text props with the
+// submission's answers. The page is rendered by the client, so values are
+// not HTML-escaped here.
+func renderEndingMergeTags(blocks []EndingBlock, submissionData map[string]interface{}) {
+	resolve := services.MapMergeResolver(submissionData)
+	var render func(value interface{}) interface{}
+	render = func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case string:
+			rendered, err := services.RenderMergeTags(v, resolve, services.MergeRenderOptions{KeepUnresolved: true})
+			if err != nil {
+				return v
+			}
+			return rendered
+		case []interface{}:
+			for i := range v {
+				v[i] = render(v[i])
+			}
+		case map[string]interface{}:
+			for key := range v {
+				v[key] = render(v[key])
+			}
+		}
+		return value
+	}
+	for i := range blocks {
+		for key, value := range blocks[i].Props {
+			blocks[i].Props[key] = render(value)
+		}
+	}
+}
+
 func endingPageToDTO(ep models.EndingPage) EndingPageDTO {
 	var settings EndingPageSettings
 	json.Unmarshal(ep.Settings, &settings)
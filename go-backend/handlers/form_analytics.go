@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"sort"
 	"time"
@@ -175,7 +176,7 @@ func GetFormAnalytics(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("📊 GetFormAnalytics: formID=%s usingNewSchema=%v tableID=%s\n", formIDStr, usingNewSchema, tableID)
+	slog.DebugContext(c.Request.Context(), "form analytics", "form_id", formIDStr, "using_new_schema", usingNewSchema, "table_id", tableID)
 
 	if usingNewSchema {
 		c.JSON(http.StatusOK, buildAnalyticsNewSchema(newForm))
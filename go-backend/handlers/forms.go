@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -10,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/logging"
 	"github.com/Jsanchez767/matic-platform/middleware"
 	"github.com/Jsanchez767/matic-platform/models"
 	"github.com/Jsanchez767/matic-platform/services"
@@ -33,7 +36,8 @@ func GetForm(c *gin.Context) {
 	if err := database.DB.Select("legacy_table_id").First(&newForm, "id = ?", id).Error; err == nil {
 		// Found in new forms table - use legacy_table_id to query data_tables
 		if newForm.LegacyTableID != nil {
-			fmt.Printf("📝 GetForm: Resolved new form ID %s -> legacy table ID %s\n", id, *newForm.LegacyTableID)
+			slog.DebugContext(c.Request.Context(), "resolved new form ID to legacy table ID",
+				"form_id", id, "table_id", *newForm.LegacyTableID)
 			id = newForm.LegacyTableID.String()
 		}
 	}
@@ -908,10 +912,31 @@ found:
 
 // Form Submission Handlers
 
+// SubmissionListItem is a form submission as listed by ListFormSubmissions
+type SubmissionListItem struct {
+	ID                uuid.UUID              `json:"id"`
+	TableID           uuid.UUID              `json:"table_id"`
+	FormID            string                 `json:"form_id"` // Alias for table_id for frontend compatibility
+	Data              map[string]interface{} `json:"data"`
+	Metadata          map[string]interface{} `json:"metadata"`
+	IsArchived        bool                   `json:"is_archived"`
+	Position          int64                  `json:"position"`
+	StageGroupID      *uuid.UUID             `json:"stage_group_id,omitempty"`
+	Tags              []interface{}          `json:"tags"`
+	BACreatedBy       *string                `json:"ba_created_by,omitempty"`
+	BAUpdatedBy       *string                `json:"ba_updated_by,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+	SubmittedAt       time.Time              `json:"submitted_at"` // Alias for created_at for frontend compatibility
+	ApplicantFullName string                 `json:"applicant_full_name,omitempty"`
+	// Status can be derived from metadata if needed
+	Status string `json:"status,omitempty"`
+}
+
 func ListFormSubmissions(c *gin.Context) {
 	formID := c.Param("id")
 	includeUser := c.Query("include_user") == "true"
-	fmt.Printf("Listing submissions for form: %s, include_user: %v\n", formID, includeUser)
+	slog.DebugContext(c.Request.Context(), "listing form submissions", "form_id", formID, "include_user", includeUser)
 
 	// ======================================================
 	// NEW SCHEMA PATH: Try form_submissions with raw_data first
@@ -928,7 +953,7 @@ func ListFormSubmissions(c *gin.Context) {
 		}
 
 		if found {
-			fmt.Printf("✅ ListFormSubmissions: Using new schema for form %s (actual ID: %s)\n", formID, form.ID)
+			slog.DebugContext(c.Request.Context(), "listing submissions from new schema", "form_id", formID, "resolved_form_id", form.ID)
 			listFormSubmissionsNewSchema(c, form, includeUser)
 			return
 		}
@@ -937,7 +962,7 @@ func ListFormSubmissions(c *gin.Context) {
 	// ======================================================
 	// LEGACY PATH: Fall back to table_rows
 	// ======================================================
-	fmt.Printf("⚠️ ListFormSubmissions: Falling back to legacy table_rows for form %s\n", formID)
+	slog.DebugContext(c.Request.Context(), "falling back to legacy table_rows for submissions", "form_id", formID)
 
 	// Get the table_id - formID might be a view_id
 	var tableID uuid.UUID
@@ -1042,7 +1067,7 @@ func ListFormSubmissions(c *gin.Context) {
 
 		rows, err := query.Rows()
 		if err != nil {
-			fmt.Printf("Error fetching submissions with users: %v\n", err)
+			slog.ErrorContext(c.Request.Context(), "failed to fetch submissions with users", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -1059,7 +1084,7 @@ func ListFormSubmissions(c *gin.Context) {
 				&result.CreatedAt, &result.UpdatedAt,
 				&baUserID, &baUserEmail, &baUserName,
 			); err != nil {
-				fmt.Printf("Error scanning submission row: %v\n", err)
+				slog.ErrorContext(c.Request.Context(), "failed to scan submission row", "error", err)
 				continue
 			}
 
@@ -1096,7 +1121,7 @@ func ListFormSubmissions(c *gin.Context) {
 			results = append(results, result)
 		}
 
-		fmt.Printf("Found %d submissions with users for form %s\n", len(results), formID)
+		slog.DebugContext(c.Request.Context(), "found submissions with users", "count", len(results), "form_id", formID)
 		c.JSON(http.StatusOK, results)
 		return
 	}
@@ -1113,11 +1138,11 @@ func ListFormSubmissions(c *gin.Context) {
 	query = query.Offset(offset).Limit(limit)
 
 	if err := query.Find(&rows).Error; err != nil {
-		fmt.Printf("Error fetching submissions: %v\n", err)
+		slog.ErrorContext(c.Request.Context(), "failed to fetch submissions", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	fmt.Printf("Found %d submissions for form %s (limit: %d, offset: %d)\n", len(rows), formID, limit, offset)
+	slog.DebugContext(c.Request.Context(), "found submissions", "count", len(rows), "form_id", formID, "limit", limit, "offset", offset)
 
 	// OPTIMIZATION: Get all portal applicants for this form to map emails to full_names
 	// portal_applicants.form_id can be either the table_id or any view_id for this table
@@ -1140,29 +1165,7 @@ func ListFormSubmissions(c *gin.Context) {
 		}
 	}
 
-	// OPTIMIZATION: Build response directly without double marshaling
-	// Use a type that matches the JSON structure we want (compatible with FormSubmission interface)
-	type SubmissionResponse struct {
-		ID                uuid.UUID              `json:"id"`
-		TableID           uuid.UUID              `json:"table_id"`
-		FormID            string                 `json:"form_id"` // Alias for table_id for frontend compatibility
-		Data              map[string]interface{} `json:"data"`
-		Metadata          map[string]interface{} `json:"metadata"`
-		IsArchived        bool                   `json:"is_archived"`
-		Position          int64                  `json:"position"`
-		StageGroupID      *uuid.UUID             `json:"stage_group_id,omitempty"`
-		Tags              []interface{}          `json:"tags"`
-		BACreatedBy       *string                `json:"ba_created_by,omitempty"`
-		BAUpdatedBy       *string                `json:"ba_updated_by,omitempty"`
-		CreatedAt         time.Time              `json:"created_at"`
-		UpdatedAt         time.Time              `json:"updated_at"`
-		SubmittedAt       time.Time              `json:"submitted_at"` // Alias for created_at for frontend compatibility
-		ApplicantFullName string                 `json:"applicant_full_name,omitempty"`
-		// Status can be derived from metadata if needed
-		Status string `json:"status,omitempty"`
-	}
-
-	result := make([]SubmissionResponse, len(rows))
+	result := make([]SubmissionListItem, len(rows))
 
 	// Process rows - extract email and lookup full_name efficiently
 	for i, row := range rows {
@@ -1218,7 +1221,7 @@ func ListFormSubmissions(c *gin.Context) {
 			status = s
 		}
 
-		result[i] = SubmissionResponse{
+		result[i] = SubmissionListItem{
 			ID:                row.ID,
 			TableID:           row.TableID,
 			FormID:            row.TableID.String(), // Frontend expects form_id
@@ -1337,7 +1340,7 @@ func listFormSubmissionsNewSchema(c *gin.Context, form models.Form, includeUser
 			Find(&rows).Error
 
 		if err != nil {
-			fmt.Printf("❌ listFormSubmissionsNewSchema: Error querying: %v\n", err)
+			slog.ErrorContext(c.Request.Context(), "failed to query submissions", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -1347,26 +1350,18 @@ func listFormSubmissionsNewSchema(c *gin.Context, form models.Form, includeUser
 			data := make(map[string]interface{})
 			if row.RawData != nil && len(row.RawData) > 2 {
 				if err := json.Unmarshal(row.RawData, &data); err != nil {
-					fmt.Printf("⚠️ listFormSubmissionsNewSchema: Error unmarshaling raw_data for submission %s: %v\n", row.ID, err)
-					fmt.Printf("   Raw data length: %d bytes\n", len(row.RawData))
-					fmt.Printf("   Raw data preview: %s\n", string(row.RawData[:min(100, len(row.RawData))]))
+					slog.WarnContext(c.Request.Context(), "failed to unmarshal submission raw_data",
+						"submission_id", row.ID, "bytes", len(row.RawData), "error", err)
 				} else {
-					fmt.Printf("✅ listFormSubmissionsNewSchema: Successfully unmarshaled submission %s with %d keys\n", row.ID, len(data))
-					if len(data) > 0 {
-						// Show first 3 keys for debugging
-						count := 0
-						for k, v := range data {
-							if count < 3 {
-								fmt.Printf("   Key: %s, Value: %v\n", k, v)
-								count++
-							}
-						}
-					} else {
-						fmt.Printf("   ⚠️ WARNING: Unmarshaled data is EMPTY for submission %s\n", row.ID)
+					slog.DebugContext(c.Request.Context(), "unmarshaled submission raw_data",
+						"submission_id", row.ID, "key_count", len(data))
+					if len(data) == 0 {
+						slog.WarnContext(c.Request.Context(), "submission raw_data is empty", "submission_id", row.ID)
 					}
 				}
 			} else {
-				fmt.Printf("⚠️ listFormSubmissionsNewSchema: Submission %s has no raw_data (nil=%v, len=%d)\n", row.ID, row.RawData == nil, len(row.RawData))
+				slog.WarnContext(c.Request.Context(), "submission has no raw_data",
+					"submission_id", row.ID, "nil", row.RawData == nil, "bytes", len(row.RawData))
 			}
 
 			result := SubmissionResult{
@@ -1407,8 +1402,8 @@ func listFormSubmissionsNewSchema(c *gin.Context, form models.Form, includeUser
 			data := make(map[string]interface{})
 			if sub.RawData != nil && len(sub.RawData) > 2 {
 				if err := json.Unmarshal(sub.RawData, &data); err != nil {
-					fmt.Printf("⚠️ listFormSubmissionsNewSchema: Error unmarshaling raw_data for submission %s: %v\n", sub.ID, err)
-					fmt.Printf("   Raw data length: %d bytes\n", len(sub.RawData))
+					slog.WarnContext(c.Request.Context(), "failed to unmarshal submission raw_data",
+						"submission_id", sub.ID, "bytes", len(sub.RawData), "error", err)
 				}
 			}
 			results = append(results, SubmissionResult{
@@ -1423,11 +1418,21 @@ func listFormSubmissionsNewSchema(c *gin.Context, form models.Form, includeUser
 		}
 	}
 
+	// Blind review hides the applicant from reviewers
+	var fields []models.FormField
+	database.DB.Where("form_id = ?", form.ID).Find(&fields)
+	if redactor := blindRedactorFor(form, fields, userID, ""); redactor != nil {
+		for i := range results {
+			results[i].Data = redactor.RedactData(results[i].ID, results[i].Data)
+			results[i].BAUser = nil
+		}
+	}
+
 	if results == nil {
 		results = []SubmissionResult{}
 	}
 
-	fmt.Printf("✅ listFormSubmissionsNewSchema: Returning %d submissions for form %s\n", len(results), form.ID)
+	slog.DebugContext(c.Request.Context(), "returning submissions", "count", len(results), "form_id", form.ID)
 	c.JSON(http.StatusOK, results)
 }
 
@@ -1436,7 +1441,7 @@ func DeleteFormSubmission(c *gin.Context) {
 	formID := c.Param("id")
 	submissionID := c.Param("submission_id")
 
-	fmt.Printf("🗑️ DeleteFormSubmission: formID=%s submissionID=%s\n", formID, submissionID)
+	slog.InfoContext(c.Request.Context(), "deleting form submission", "form_id", formID, "submission_id", submissionID)
 
 	// formID is typically the table_id, but might be a view_id in some cases
 	tableID := formID
@@ -1445,46 +1450,49 @@ func DeleteFormSubmission(c *gin.Context) {
 	var view models.View
 	if err := database.DB.Where("id = ?", formID).First(&view).Error; err == nil {
 		tableID = view.TableID.String()
-		fmt.Printf("🗑️ DeleteFormSubmission: Resolved view_id %s to table_id %s\n", formID, tableID)
+		slog.DebugContext(c.Request.Context(), "resolved view ID to table ID", "view_id", formID, "table_id", tableID)
 	} else {
 		// formID is the table_id itself
-		fmt.Printf("🗑️ DeleteFormSubmission: Using formID as table_id: %s\n", tableID)
+		slog.DebugContext(c.Request.Context(), "using form ID as table ID", "table_id", tableID)
 	}
 
 	// First try to find the row directly by ID (in case table_id doesn't match)
 	var row models.Row
 	if err := database.DB.Select(rowSelectColumns).Where("id = ?", submissionID).First(&row).Error; err != nil {
-		fmt.Printf("❌ DeleteFormSubmission: Row not found by ID %s: %v\n", submissionID, err)
+		slog.WarnContext(c.Request.Context(), "submission row not found", "submission_id", submissionID, "error", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
 		return
 	}
 
-	fmt.Printf("🗑️ DeleteFormSubmission: Found row %s, row.TableID=%s, expected tableID=%s\n", row.ID, row.TableID, tableID)
+	slog.DebugContext(c.Request.Context(), "found submission row", "row_id", row.ID, "row_table_id", row.TableID, "table_id", tableID)
 
 	// Verify the row belongs to the expected table
 	if row.TableID.String() != tableID {
-		fmt.Printf("⚠️ DeleteFormSubmission: Row table_id mismatch! row.TableID=%s, tableID=%s\n", row.TableID, tableID)
+		slog.WarnContext(c.Request.Context(), "submission row table mismatch", "row_table_id", row.TableID, "table_id", tableID)
 		// Still allow deletion if the row exists (table_id might be passed differently)
 	}
 
 	// Delete the submission (row) - use Unscoped to permanently delete
 	if err := database.DB.Unscoped().Delete(&row).Error; err != nil {
-		fmt.Printf("❌ DeleteFormSubmission: Failed to delete: %v\n", err)
+		slog.ErrorContext(c.Request.Context(), "failed to delete submission", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete submission"})
 		return
 	}
 
-	fmt.Printf("✅ DeleteFormSubmission: Successfully deleted submission %s\n", submissionID)
+	slog.InfoContext(c.Request.Context(), "deleted form submission", "submission_id", submissionID)
 	c.JSON(http.StatusOK, gin.H{"message": "Submission deleted successfully"})
 }
 
+// BulkDeleteSubmissionsInput names the submissions to delete
+type BulkDeleteSubmissionsInput struct {
+	SubmissionIDs []string `json:"submission_ids" binding:"required"`
+}
+
 // BulkDeleteFormSubmissions deletes multiple form submissions by their IDs
 func BulkDeleteFormSubmissions(c *gin.Context) {
 	formID := c.Param("id")
 
-	var input struct {
-		SubmissionIDs []string `json:"submission_ids" binding:"required"`
-	}
+	var input BulkDeleteSubmissionsInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
@@ -1496,7 +1504,7 @@ func BulkDeleteFormSubmissions(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("🗑️ BulkDeleteFormSubmissions: formID=%s count=%d\n", formID, len(input.SubmissionIDs))
+	slog.InfoContext(c.Request.Context(), "bulk deleting form submissions", "form_id", formID, "count", len(input.SubmissionIDs))
 
 	// formID is typically the table_id, but might be a view_id
 	tableID := formID
@@ -1504,19 +1512,19 @@ func BulkDeleteFormSubmissions(c *gin.Context) {
 	var view models.View
 	if err := database.DB.Where("id = ?", formID).First(&view).Error; err == nil {
 		tableID = view.TableID.String()
-		fmt.Printf("🗑️ BulkDeleteFormSubmissions: Resolved view_id %s to table_id %s\n", formID, tableID)
+		slog.DebugContext(c.Request.Context(), "resolved view ID to table ID", "view_id", formID, "table_id", tableID)
 	}
 
 	// Delete all submissions in one query - use Unscoped to permanently delete
 	result := database.DB.Unscoped().Where("id IN ?", input.SubmissionIDs).Delete(&models.Row{})
 
 	if result.Error != nil {
-		fmt.Printf("❌ BulkDeleteFormSubmissions: Failed to delete: %v\n", result.Error)
+		slog.ErrorContext(c.Request.Context(), "failed to bulk delete submissions", "error", result.Error)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete submissions"})
 		return
 	}
 
-	fmt.Printf("✅ BulkDeleteFormSubmissions: Successfully deleted %d submissions\n", result.RowsAffected)
+	slog.InfoContext(c.Request.Context(), "bulk deleted form submissions", "count", result.RowsAffected)
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Submissions deleted successfully",
 		"deleted_count": result.RowsAffected,
@@ -1531,7 +1539,7 @@ type SubmitFormInput struct {
 
 func SubmitForm(c *gin.Context) {
 	formID := c.Param("id")
-	fmt.Printf("📝 SubmitForm: incoming submission for form/table %s\n", formID)
+	slog.InfoContext(c.Request.Context(), "incoming form submission", "form_id", formID)
 
 	// Verify form exists and is published
 	var view models.View
@@ -1543,7 +1551,7 @@ func SubmitForm(c *gin.Context) {
 	var config map[string]interface{}
 	json.Unmarshal(view.Config, &config)
 	if val, ok := config["is_published"].(bool); !ok || !val {
-		fmt.Printf("🛑 SubmitForm: form %s not published, rejecting submission\n", formID)
+		slog.WarnContext(c.Request.Context(), "form not published, rejecting submission", "form_id", formID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Form is not published"})
 		return
 	}
@@ -1567,12 +1575,12 @@ func SubmitForm(c *gin.Context) {
 	// Check application status
 	if status, ok := tableSettings["applicationStatus"].(string); ok {
 		if status == "closed" {
-			fmt.Printf("🛑 SubmitForm: application %s is closed, rejecting submission\n", formID)
+			slog.WarnContext(c.Request.Context(), "application closed, rejecting submission", "form_id", formID)
 			c.JSON(http.StatusForbidden, gin.H{"error": "Applications are currently closed"})
 			return
 		}
 		if status == "draft" {
-			fmt.Printf("🛑 SubmitForm: application %s is in draft mode, rejecting submission\n", formID)
+			slog.WarnContext(c.Request.Context(), "application in draft mode, rejecting submission", "form_id", formID)
 			c.JSON(http.StatusForbidden, gin.H{"error": "This application is not accepting submissions"})
 			return
 		}
@@ -1582,7 +1590,8 @@ func SubmitForm(c *gin.Context) {
 	if deadline, ok := tableSettings["applicationDeadline"].(string); ok && deadline != "" {
 		deadlineTime, err := time.Parse(time.RFC3339, deadline)
 		if err == nil && time.Now().After(deadlineTime) {
-			fmt.Printf("🛑 SubmitForm: application %s deadline has passed (%s), rejecting submission\n", formID, deadline)
+			slog.WarnContext(c.Request.Context(), "application deadline passed, rejecting submission",
+				"form_id", formID, "deadline", deadline)
 			c.JSON(http.StatusForbidden, gin.H{"error": "The application deadline has passed"})
 			return
 		}
@@ -1590,11 +1599,11 @@ func SubmitForm(c *gin.Context) {
 
 	var input SubmitFormInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		fmt.Printf("❌ SubmitForm: bad request payload for %s: %v\n", formID, err)
+		slog.WarnContext(c.Request.Context(), "bad submission payload", "form_id", formID, "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	fmt.Printf("[DEBUG] SubmitForm: input.SaveDraft = %v\n", input.SaveDraft)
+	slog.DebugContext(c.Request.Context(), "submission mode", "save_draft", input.SaveDraft)
 
 	data := input.Data
 	if data == nil {
@@ -1611,14 +1620,14 @@ func SubmitForm(c *gin.Context) {
 		Data:    data,
 	})
 	if err != nil {
-		fmt.Printf("❌ SubmitForm: normalization failed for %s: %v\n", formID, err)
+		slog.ErrorContext(c.Request.Context(), "submission normalization failed", "form_id", formID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to normalize data: " + err.Error()})
 		return
 	}
 
 	// Check for validation errors
 	if len(normalizeResult.Errors) > 0 {
-		fmt.Printf("⚠️ SubmitForm: validation errors for %s: %+v\n", formID, normalizeResult.Errors)
+		slog.WarnContext(c.Request.Context(), "submission validation errors", "form_id", formID, "errors", normalizeResult.Errors)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Validation errors",
 			"details": normalizeResult.Errors,
@@ -1671,7 +1680,7 @@ func SubmitForm(c *gin.Context) {
 			"table_id = ? AND data->>'email' = ?",
 		}
 
-		fmt.Printf("📝 SubmitForm: Looking for existing submission with email=%s, formID=%s\n", email, formID)
+		slog.DebugContext(c.Request.Context(), "looking for existing submission", "email", email, "form_id", formID)
 
 		// Use a transaction-level advisory lock to prevent race conditions
 		// This ensures only one submission can be processed at a time for a given email+form
@@ -1691,17 +1700,17 @@ func SubmitForm(c *gin.Context) {
 			time.Sleep(100 * time.Millisecond)
 		}
 		if !lockAcquired {
-			fmt.Printf("⚠️ SubmitForm: Could not acquire lock for %s, proceeding without lock\n", lockKey)
+			slog.WarnContext(c.Request.Context(), "could not acquire submission lock, proceeding without lock", "lock_key", lockKey)
 		}
 
 		var found bool
 		for _, query := range queries {
 			if err := database.DB.Select(rowSelectColumns).Where(query, formID, email).First(&existingRow).Error; err == nil {
-				fmt.Printf("📝 SubmitForm: Found existing row using query: %s\n", query)
+				slog.DebugContext(c.Request.Context(), "found existing submission row", "query", query)
 				found = true
 				break
 			} else {
-				fmt.Printf("📝 SubmitForm: Query '%s' did not find row\n", query)
+				slog.DebugContext(c.Request.Context(), "existing submission query did not find a row", "query", query)
 			}
 		}
 
@@ -1726,12 +1735,13 @@ func SubmitForm(c *gin.Context) {
 
 			// Block edit if submissions are not allowed and already submitted
 			if !allowEdits && isSubmitted && !input.SaveDraft {
-				fmt.Printf("🛑 SubmitForm: edits not allowed for submitted application %s, rejecting\n", formID)
+				slog.WarnContext(c.Request.Context(), "edits not allowed after submission, rejecting", "form_id", formID)
 				c.JSON(http.StatusForbidden, gin.H{"error": "Edits are not allowed after submission"})
 				return
 			}
 
-			fmt.Printf("🔄 SubmitForm: existing submission found for %s (email=%s), updating row %s\n", formID, email, existingRow.ID)
+			slog.InfoContext(c.Request.Context(), "updating existing submission",
+				"form_id", formID, "email", email, "row_id", existingRow.ID)
 			// Update existing row with transaction - create version in same transaction
 			tx := database.DB.Begin()
 			defer func() {
@@ -1750,7 +1760,8 @@ func SubmitForm(c *gin.Context) {
 				}
 				if err := database.DB.Raw("SELECT id FROM ba_users WHERE email = ? LIMIT 1", email).Scan(&baUser).Error; err == nil && baUser.ID != "" {
 					existingRow.BACreatedBy = &baUser.ID
-					fmt.Printf("🔗 SubmitForm: Linked existing submission to Better Auth user %s (email=%s)\n", baUser.ID, email)
+					slog.InfoContext(c.Request.Context(), "linked existing submission to better auth user",
+						"user_id", baUser.ID, "email", email)
 				}
 			}
 
@@ -1763,27 +1774,27 @@ func SubmitForm(c *gin.Context) {
 				existingMetadata = make(map[string]interface{})
 			}
 			if input.SaveDraft {
-				fmt.Printf("[DEBUG] SubmitForm: Saving as draft (existing row)\n")
+				slog.DebugContext(c.Request.Context(), "saving existing submission as draft")
 				// Draft save - keep current status or set to draft if no status
 				if _, hasStatus := existingMetadata["status"]; !hasStatus {
 					existingMetadata["status"] = "draft"
 				}
 				existingMetadata["draft_saved_at"] = time.Now()
 			} else {
-				fmt.Printf("[DEBUG] SubmitForm: Submitting (existing row)\n")
+				slog.DebugContext(c.Request.Context(), "submitting existing submission")
 				// Full submission - mark as submitted
 				existingMetadata["status"] = "submitted"
 				existingMetadata["resubmitted_at"] = time.Now()
 			}
 			// Enforce: status must be draft if SaveDraft, submitted if not
 			if input.SaveDraft && existingMetadata["status"] != "draft" {
-				fmt.Printf("[ERROR] SubmitForm: Expected status 'draft' but got '%v'\n", existingMetadata["status"])
+				slog.ErrorContext(c.Request.Context(), "draft save did not set status to draft", "status", existingMetadata["status"])
 				tx.Rollback()
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Draft save did not set status to draft"})
 				return
 			}
 			if !input.SaveDraft && existingMetadata["status"] != "submitted" {
-				fmt.Printf("[ERROR] SubmitForm: Expected status 'submitted' but got '%v'\n", existingMetadata["status"])
+				slog.ErrorContext(c.Request.Context(), "submit did not set status to submitted", "status", existingMetadata["status"])
 				tx.Rollback()
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Submit did not set status to submitted"})
 				return
@@ -1812,7 +1823,7 @@ func SubmitForm(c *gin.Context) {
 
 			// REMOVED: portal_applicants write (deprecated table, removed as per architecture audit)
 			// All submission data is now stored ONLY in table_rows (single source of truth)
-			fmt.Printf("✅ SubmitForm: Updated EXISTING submission in table_rows (id=%s) for email=%s\n", existingRow.ID, email)
+			slog.DebugContext(c.Request.Context(), "updated existing submission in table_rows", "row_id", existingRow.ID, "email", email)
 
 			if err := tx.Commit().Error; err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
@@ -1829,17 +1840,27 @@ func SubmitForm(c *gin.Context) {
 				`, existingRow.ID)
 			}()
 
+			if !input.SaveDraft && !isSubmitted {
+				recordSubmissionEvent(c.Request.Context(), submittedEvent(existingRow.ID, parsedFormID, existingRow.UpdatedAt))
+			}
+
 			// Process recommendation fields and create recommendation requests (only for non-draft submissions)
 			if !input.SaveDraft {
-				go processRecommendationFields(parsedFormID, existingRow.ID, data)
+				go processRecommendationFields(logging.Detach(c.Request.Context()), parsedFormID, existingRow.ID, data)
+				fireEmailTrigger(c, services.EmailTriggerEvent{
+					Trigger:      services.EmailTriggerSubmission,
+					FormID:       parsedFormID,
+					SubmissionID: existingRow.ID,
+					Data:         data,
+				})
 			}
 
-			fmt.Printf("✅ SubmitForm: updated submission row %s for form %s\n", existingRow.ID, formID)
+			slog.InfoContext(c.Request.Context(), "updated submission", "row_id", existingRow.ID, "form_id", formID)
 			c.JSON(http.StatusOK, existingRow)
 			return
 		}
 
-		fmt.Printf("📝 SubmitForm: No existing row found for email=%s, will create new\n", email)
+		slog.DebugContext(c.Request.Context(), "no existing submission found, creating new", "email", email)
 	}
 
 	// Check max submissions limit ONLY for new submissions (not updates)
@@ -1848,13 +1869,14 @@ func SubmitForm(c *gin.Context) {
 		var currentCount int64
 		database.DB.Model(&models.Row{}).Where("table_id = ?", formID).Count(&currentCount)
 		if currentCount >= int64(maxSubs) {
-			fmt.Printf("🛑 SubmitForm: application %s has reached max submissions (%d/%d), rejecting NEW submission\n", formID, currentCount, int(maxSubs))
+			slog.WarnContext(c.Request.Context(), "application reached max submissions, rejecting new submission",
+				"form_id", formID, "count", currentCount, "max", int(maxSubs))
 			c.JSON(http.StatusForbidden, gin.H{"error": "Maximum number of submissions has been reached"})
 			return
 		}
 	}
 
-	fmt.Printf("📝 SubmitForm: Creating NEW row for form %s (email=%s, save_draft=%v)\n", formID, email, input.SaveDraft)
+	slog.InfoContext(c.Request.Context(), "creating new submission", "form_id", formID, "email", email, "save_draft", input.SaveDraft)
 
 	// Create new row with transaction
 	tx := database.DB.Begin()
@@ -1869,23 +1891,23 @@ func SubmitForm(c *gin.Context) {
 		"created_at": time.Now(),
 	}
 	if input.SaveDraft {
-		fmt.Printf("[DEBUG] SubmitForm: Creating new row as draft\n")
+		slog.DebugContext(c.Request.Context(), "creating new submission as draft")
 		initialMetadata["status"] = "draft"
 		initialMetadata["draft_saved_at"] = time.Now()
 	} else {
-		fmt.Printf("[DEBUG] SubmitForm: Creating new row as submitted\n")
+		slog.DebugContext(c.Request.Context(), "creating new submission as submitted")
 		initialMetadata["status"] = "submitted"
 		initialMetadata["submitted_at"] = time.Now()
 	}
 	// Enforce: status must be draft if SaveDraft, submitted if not
 	if input.SaveDraft && initialMetadata["status"] != "draft" {
-		fmt.Printf("[ERROR] SubmitForm: Expected status 'draft' but got '%v'\n", initialMetadata["status"])
+		slog.ErrorContext(c.Request.Context(), "draft save did not set status to draft", "status", initialMetadata["status"])
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Draft save did not set status to draft"})
 		return
 	}
 	if !input.SaveDraft && initialMetadata["status"] != "submitted" {
-		fmt.Printf("[ERROR] SubmitForm: Expected status 'submitted' but got '%v'\n", initialMetadata["status"])
+		slog.ErrorContext(c.Request.Context(), "submit did not set status to submitted", "status", initialMetadata["status"])
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Submit did not set status to submitted"})
 		return
@@ -1909,13 +1931,13 @@ func SubmitForm(c *gin.Context) {
 		if err := database.DB.Raw("SELECT id FROM ba_users WHERE email = ? LIMIT 1", email).Scan(&baUser).Error; err == nil && baUser.ID != "" {
 			row.BACreatedBy = &baUser.ID
 			userIDStr = baUser.ID
-			fmt.Printf("📝 SubmitForm: Linked submission to Better Auth user %s (email=%s)\n", baUser.ID, email)
+			slog.InfoContext(c.Request.Context(), "linked submission to better auth user", "user_id", baUser.ID, "email", email)
 		} else {
-			fmt.Printf("⚠️ SubmitForm: No Better Auth user found for email=%s, creating unlinked submission\n", email)
+			slog.WarnContext(c.Request.Context(), "no better auth user found, creating unlinked submission", "email", email)
 		}
 	}
 	if err := tx.Create(&row).Error; err != nil {
-		fmt.Printf("❌ SubmitForm: failed to create row for %s: %v\n", formID, err)
+		slog.ErrorContext(c.Request.Context(), "failed to create submission row", "form_id", formID, "error", err)
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -1923,7 +1945,7 @@ func SubmitForm(c *gin.Context) {
 	// REMOVED: application_submissions write (duplicate data, removed as per architecture audit)
 	// REMOVED: portal_applicants write (deprecated table, data now only in table_rows)
 	// All submission data is now stored ONLY in table_rows (single source of truth)
-	fmt.Printf("✅ SubmitForm: Created NEW submission in table_rows (id=%s) for email=%s\n", row.ID, email)
+	slog.DebugContext(c.Request.Context(), "created new submission in table_rows", "row_id", row.ID, "email", email)
 
 	// Create initial version for version history (synchronous, in transaction)
 	versionService := services.NewVersionService()
@@ -1959,38 +1981,61 @@ func SubmitForm(c *gin.Context) {
 		`, row.ID)
 	}()
 
+	recordSubmissionEvent(c.Request.Context(), startedEvent(row.ID, parsedFormID, row.CreatedAt))
+	if !input.SaveDraft {
+		recordSubmissionEvent(c.Request.Context(), submittedEvent(row.ID, parsedFormID, row.CreatedAt))
+	}
+
 	// Process recommendation fields and create recommendation requests (only for non-draft submissions)
 	if !input.SaveDraft {
-		go processRecommendationFields(parsedFormID, row.ID, data)
+		go processRecommendationFields(logging.Detach(c.Request.Context()), parsedFormID, row.ID, data)
+		fireEmailTrigger(c, services.EmailTriggerEvent{
+			Trigger:      services.EmailTriggerSubmission,
+			FormID:       parsedFormID,
+			SubmissionID: row.ID,
+			Data:         data,
+		})
 	}
 
-	fmt.Printf("✅ SubmitForm: created new submission row %s for form %s\n", row.ID, formID)
+	slog.InfoContext(c.Request.Context(), "created submission", "row_id", row.ID, "form_id", formID)
 	c.JSON(http.StatusCreated, row)
 }
 
+// fireEmailTrigger queues the automated templates for a submission event in
+// the background; the request doesn't wait on, or fail because of, email
+func fireEmailTrigger(c *gin.Context, event services.EmailTriggerEvent) {
+	ctx := logging.Detach(c.Request.Context())
+	go func() {
+		if _, err := services.FireEmailTrigger(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "failed to queue triggered emails", "component", "email_triggers",
+				"trigger", event.Trigger, "submission_id", event.SubmissionID, "error", err)
+		}
+	}()
+}
+
 // processRecommendationFields finds recommendation fields in the form and creates recommendation requests
 // for each recommender. This is called asynchronously after form submission.
-func processRecommendationFields(formID uuid.UUID, submissionID uuid.UUID, data map[string]interface{}) {
-	fmt.Printf("📧 processRecommendationFields: Processing recommendations for submission %s\n", submissionID)
+func processRecommendationFields(ctx context.Context, formID uuid.UUID, submissionID uuid.UUID, data map[string]interface{}) {
+	slog.DebugContext(ctx, "processing recommendation fields", "component", "recommendations", "submission_id", submissionID)
 
 	// Get all fields for this form to find recommendation fields
 	var fields []models.Field
 	if err := database.DB.Where("table_id = ?", formID).Find(&fields).Error; err != nil {
-		fmt.Printf("❌ processRecommendationFields: Failed to get fields for form %s: %v\n", formID, err)
+		slog.ErrorContext(ctx, "failed to get form fields", "component", "recommendations", "form_id", formID, "error", err)
 		return
 	}
 
 	// Get the form info for email context
 	var form models.Table
 	if err := database.DB.First(&form, "id = ?", formID).Error; err != nil {
-		fmt.Printf("❌ processRecommendationFields: Failed to get form %s: %v\n", formID, err)
+		slog.ErrorContext(ctx, "failed to get form", "component", "recommendations", "form_id", formID, "error", err)
 		return
 	}
 
 	// Get the submission for context
 	var submission models.Row
 	if err := database.DB.First(&submission, "id = ?", submissionID).Error; err != nil {
-		fmt.Printf("❌ processRecommendationFields: Failed to get submission %s: %v\n", submissionID, err)
+		slog.ErrorContext(ctx, "failed to get submission", "component", "recommendations", "submission_id", submissionID, "error", err)
 		return
 	}
 
@@ -2002,19 +2047,19 @@ func processRecommendationFields(formID uuid.UUID, submissionID uuid.UUID, data
 		}
 
 		fieldIDStr := field.ID.String()
-		fmt.Printf("📧 processRecommendationFields: Found recommendation field %s\n", fieldIDStr)
+		slog.DebugContext(ctx, "found recommendation field", "component", "recommendations", "field_id", fieldIDStr)
 
 		// Get the recommender data from the submission
 		fieldData, exists := data[fieldIDStr]
 		if !exists {
-			fmt.Printf("📧 processRecommendationFields: No data for field %s\n", fieldIDStr)
+			slog.DebugContext(ctx, "no data for recommendation field", "component", "recommendations", "field_id", fieldIDStr)
 			continue
 		}
 
 		// The recommender data should be an array of recommender objects
 		recommenders, ok := fieldData.([]interface{})
 		if !ok {
-			fmt.Printf("📧 processRecommendationFields: Field %s data is not an array\n", fieldIDStr)
+			slog.WarnContext(ctx, "recommendation field data is not an array", "component", "recommendations", "field_id", fieldIDStr)
 			continue
 		}
 
@@ -2042,7 +2087,7 @@ func processRecommendationFields(formID uuid.UUID, submissionID uuid.UUID, data
 			recommenderRelationship := getStringValue(rec, "relationship")
 
 			if recommenderName == "" || recommenderEmail == "" {
-				fmt.Printf("📧 processRecommendationFields: Skipping recommender with missing name/email\n")
+				slog.DebugContext(ctx, "skipping recommender with missing name or email", "component", "recommendations")
 				continue
 			}
 
@@ -2052,7 +2097,8 @@ func processRecommendationFields(formID uuid.UUID, submissionID uuid.UUID, data
 				"submission_id = ? AND field_id = ? AND recommender_email = ? AND status != ?",
 				submissionID, fieldIDStr, recommenderEmail, "cancelled",
 			).First(&existingRequest).Error; err == nil {
-				fmt.Printf("📧 processRecommendationFields: Request already exists for %s\n", recommenderEmail)
+				slog.DebugContext(ctx, "recommendation request already exists", "component", "recommendations",
+					"recommender_email", recommenderEmail)
 				continue
 			}
 
@@ -2077,18 +2123,21 @@ func processRecommendationFields(formID uuid.UUID, submissionID uuid.UUID, data
 			}
 
 			if err := database.DB.Create(&request).Error; err != nil {
-				fmt.Printf("❌ processRecommendationFields: Failed to create request for %s: %v\n", recommenderEmail, err)
+				slog.ErrorContext(ctx, "failed to create recommendation request", "component", "recommendations",
+					"recommender_email", recommenderEmail, "error", err)
 				continue
 			}
 
-			fmt.Printf("✅ processRecommendationFields: Created request %s for %s (%s)\n", request.ID, recommenderName, recommenderEmail)
+			slog.InfoContext(ctx, "created recommendation request", "component", "recommendations",
+				"recommendation_request_id", request.ID, "recommender_name", recommenderName, "recommender_email", recommenderEmail)
+			recordSubmissionEvent(ctx, recommendationRequestedEvent(request))
 
 			// Update the recommender data in the submission with the request ID
 			rec["request_id"] = request.ID.String()
 			rec["request_status"] = "pending"
 
 			// Send the email asynchronously
-			go sendRecommendationRequestEmail(&request, &submission, &form, &fieldConfig)
+			go sendRecommendationRequestEmail(ctx, &request, &submission, &form, &fieldConfig)
 		}
 
 		// Update the submission data with the request IDs
@@ -2156,9 +2205,9 @@ func UpdateFormStructure(c *gin.Context) {
 
 	// DEBUG: Log received translations
 	if input.Translations != nil {
-		fmt.Printf("📝 UpdateFormStructure: Received %d translation languages\n", len(input.Translations))
+		slog.DebugContext(c.Request.Context(), "received form translations", "language_count", len(input.Translations))
 	} else {
-		fmt.Println("⚠️ UpdateFormStructure: No translations received in input")
+		slog.DebugContext(c.Request.Context(), "no translations received in form structure update")
 	}
 
 	// Start transaction
@@ -2235,15 +2284,13 @@ func UpdateFormStructure(c *gin.Context) {
 	for _, section := range input.Sections {
 		for _, fieldInput := range section.Fields {
 			// DEBUG: Log what we received from frontend
-			fmt.Printf("📥 Field received: type=%s, label=%s, config=%+v\n", fieldInput.Type, fieldInput.Label, fieldInput.Config)
+			slog.DebugContext(c.Request.Context(), "form field received",
+				"field_type", fieldInput.Type, "label", fieldInput.Label, "config", fieldInput.Config)
 
 			// Extra debug for recommendation fields
 			if fieldInput.Type == "recommendation" {
-				fmt.Printf("🔔 RECOMMENDATION FIELD CONFIG RECEIVED:\n")
-				fmt.Printf("   deadlineType=%v\n", fieldInput.Config["deadlineType"])
-				fmt.Printf("   fixedDeadline=%v\n", fieldInput.Config["fixedDeadline"])
-				fmt.Printf("   deadlineDays=%v\n", fieldInput.Config["deadlineDays"])
-				fmt.Printf("   Full config: %+v\n", fieldInput.Config)
+				slog.DebugContext(c.Request.Context(), "recommendation field config received",
+					"deadline_type", fieldInput.Config["deadlineType"], "fixed_deadline", fieldInput.Config["fixedDeadline"], "deadline_days", fieldInput.Config["deadlineDays"])
 			}
 
 			// Construct config JSON - start with any config from the frontend
@@ -2307,7 +2354,8 @@ func UpdateFormStructure(c *gin.Context) {
 			}
 
 			// DEBUG: Log what we're saving
-			fmt.Printf("💾 Saving field: type=%s, label=%s, config=%s\n", field.Type, field.Label, string(mapToJSON(config)))
+			slog.DebugContext(c.Request.Context(), "saving form field",
+				"field_type", field.Type, "label", field.Label, "config", string(mapToJSON(config)))
 
 			// Use ID from frontend if valid UUID, else generate new
 			if uid, err := uuid.Parse(fieldInput.ID); err == nil {
@@ -2378,7 +2426,7 @@ func GetFormSubmission(c *gin.Context) {
 	for _, query := range queries {
 		if err := database.DB.Select(rowSelectColumns).Where(query, formID, email).First(&row).Error; err == nil {
 			found = true
-			fmt.Printf("✅ GetFormSubmission: Found in table_rows using query: %s\n", query)
+			slog.DebugContext(c.Request.Context(), "found submission in table_rows", "query", query)
 			break
 		}
 	}
@@ -2403,7 +2451,7 @@ func GetFormSubmission(c *gin.Context) {
 
 	// PRIORITY 2: Fallback to portal_applicants (LEGACY - for migration period only)
 	// This should only happen for old submissions not yet migrated
-	fmt.Printf("⚠️ GetFormSubmission: Not found in table_rows, checking legacy portal_applicants for email=%s\n", email)
+	slog.WarnContext(c.Request.Context(), "submission not in table_rows, checking legacy portal_applicants", "email", email)
 
 	var allViews []models.View
 	database.DB.Where("table_id = ? AND type = ?", formID, "form").Find(&allViews)
@@ -2420,7 +2468,7 @@ func GetFormSubmission(c *gin.Context) {
 		LIMIT 1
 	`, pq.Array(formIDs), email).Scan(&applicant).Error; err == nil && applicant.ID != uuid.Nil {
 		// Found in portal_applicants - return this data
-		fmt.Printf("⚠️ GetFormSubmission: Found in LEGACY portal_applicants table for email=%s (migration needed)\n", email)
+		slog.WarnContext(c.Request.Context(), "submission found in legacy portal_applicants table, migration needed", "email", email)
 		var submissionData map[string]interface{}
 		json.Unmarshal(applicant.SubmissionData, &submissionData)
 
@@ -2472,6 +2520,7 @@ func UpdateSubmissionMetadata(c *gin.Context) {
 	if row.Metadata != nil {
 		json.Unmarshal(row.Metadata, &existingMetadata)
 	}
+	previousStatus, _ := existingMetadata["status"].(string)
 
 	// Merge input metadata into existing
 	for k, v := range input.Metadata {
@@ -2485,5 +2534,19 @@ func UpdateSubmissionMetadata(c *gin.Context) {
 		return
 	}
 
+	// Moving the submission to a new status may fire automated emails
+	if status, _ := existingMetadata["status"].(string); status != previousStatus {
+		if trigger := services.EmailTriggerForStatus(status); trigger != "" {
+			var data map[string]interface{}
+			json.Unmarshal(row.Data, &data)
+			fireEmailTrigger(c, services.EmailTriggerEvent{
+				Trigger:      trigger,
+				FormID:       row.TableID,
+				SubmissionID: row.ID,
+				Data:         data,
+			})
+		}
+	}
+
 	c.JSON(http.StatusOK, row)
 }
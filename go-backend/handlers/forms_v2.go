@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,10 +13,12 @@ import (
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/middleware"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ============================================
@@ -148,6 +153,9 @@ func GetFormBySlugV2(c *gin.Context) {
 		return
 	}
 
+	// Applicants see the published version, not unpublished edits
+	services.ApplyFormVersion(database.DB, &form, form.Version)
+
 	c.JSON(http.StatusOK, form)
 }
 
@@ -178,7 +186,7 @@ func CreateFormV2(c *gin.Context) {
 
 	// Get user ID from context (TEXT, not UUID)
 	var createdBy *string
-	if userID := c.GetString("ba_user_id"); userID != "" {
+	if userID := requestUserID(c); userID != "" {
 		createdBy = &userID
 	}
 
@@ -240,7 +248,9 @@ func UpdateFormV2(c *gin.Context) {
 		MaxSubmissions           *int            `json:"max_submissions"`
 		AllowMultipleSubmissions *bool           `json:"allow_multiple_submissions"`
 		RequireAuth              *bool           `json:"require_auth"`
-		ClosesAt                 *time.Time      `json:"closes_at"`
+		Timezone                 *string         `json:"timezone"`
+		OpensAt                  *string         `json:"opens_at"`  // RFC 3339, or wall-clock time in the form's timezone; "" clears
+		ClosesAt                 *string         `json:"closes_at"` // RFC 3339, or wall-clock time in the form's timezone; "" clears
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -261,12 +271,10 @@ func UpdateFormV2(c *gin.Context) {
 	if input.Settings != nil {
 		form.Settings = datatypes.JSON(input.Settings)
 	}
-	if input.Status != nil {
+	// Publishing goes through the same snapshot as PublishFormV2
+	publish := input.Status != nil && *input.Status == "published" && form.Status != "published"
+	if input.Status != nil && !publish {
 		form.Status = *input.Status
-		if *input.Status == "published" && form.PublishedAt == nil {
-			now := time.Now()
-			form.PublishedAt = &now
-		}
 	}
 	if input.MaxSubmissions != nil {
 		form.MaxSubmissions = input.MaxSubmissions
@@ -277,8 +285,32 @@ func UpdateFormV2(c *gin.Context) {
 	if input.RequireAuth != nil {
 		form.RequireAuth = *input.RequireAuth
 	}
+	if input.Timezone != nil {
+		if _, err := time.LoadLocation(*input.Timezone); err != nil || *input.Timezone == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone"})
+			return
+		}
+		form.Timezone = *input.Timezone
+	}
+	if input.OpensAt != nil {
+		opensAt, err := services.ParseFormTime(*input.OpensAt, form.Timezone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid opens_at"})
+			return
+		}
+		form.OpensAt = opensAt
+	}
 	if input.ClosesAt != nil {
-		form.ClosesAt = input.ClosesAt
+		closesAt, err := services.ParseFormTime(*input.ClosesAt, form.Timezone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid closes_at"})
+			return
+		}
+		form.ClosesAt = closesAt
+	}
+	if form.OpensAt != nil && form.ClosesAt != nil && !form.ClosesAt.After(*form.OpensAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "closes_at must be after opens_at"})
+		return
 	}
 
 	if err := database.DB.Save(&form).Error; err != nil {
@@ -286,6 +318,17 @@ func UpdateFormV2(c *gin.Context) {
 		return
 	}
 
+	if publish {
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			published, _, err := services.PublishFormVersion(tx, form.ID, optionalString(requestUserID(c)))
+			form = published
+			return err
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	// Sync to legacy table if linked
 	if form.LegacyTableID != nil {
 		database.DB.Model(&models.Table{}).Where("id = ?", form.LegacyTableID).Updates(map[string]interface{}{
@@ -322,23 +365,43 @@ func DeleteFormV2(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Form deleted"})
 }
 
-// PublishFormV2 publishes a form
+// PublishFormInput controls whether in-progress drafts move to the new version
+type PublishFormInput struct {
+	MigrateDrafts bool `json:"migrate_drafts"`
+}
+
+// PublishFormV2 snapshots the form's current sections and fields as its next
+// immutable version. With migrate_drafts, unsubmitted submissions move to
+// the new version; otherwise they stay on the version they started on.
 // POST /api/v2/forms/:id/publish
 func PublishFormV2(c *gin.Context) {
-	formID := c.Param("id")
-
-	var form models.Form
-	if err := database.DB.First(&form, "id = ?", formID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
+	form, ok := loadStaffFormV2(c)
+	if !ok {
 		return
 	}
 
-	now := time.Now()
-	form.Status = "published"
-	form.PublishedAt = &now
-	form.Version++
+	var input PublishFormInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
-	if err := database.DB.Save(&form).Error; err != nil {
+	var version models.FormVersion
+	var migrated int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		form, version, err = services.PublishFormVersion(tx, form.ID, optionalString(requestUserID(c)))
+		if err != nil {
+			return err
+		}
+		if input.MigrateDrafts {
+			migrated, err = services.MigrateDraftsToVersion(tx, form.ID, version.Version)
+		}
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -348,9 +411,96 @@ func PublishFormV2(c *gin.Context) {
 		database.DB.Model(&models.Table{}).Where("id = ?", form.LegacyTableID).Update("is_published", true)
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"form":            form,
+		"version":         version,
+		"migrated_drafts": migrated,
+	})
+}
+
+// ListFormVersionsV2 lists a form's published versions, newest first
+// GET /api/v2/forms/:id/versions
+func ListFormVersionsV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+
+	var versions []models.FormVersion
+	if err := database.DB.
+		Select("id", "form_id", "version", "published_at", "published_by", "created_at").
+		Where("form_id = ?", form.ID).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetFormVersionV2 gets the form as it was published in a version
+// GET /api/v2/forms/:id/versions/:version
+func GetFormVersionV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+	if !services.ApplyFormVersion(database.DB, &form, version) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return
+	}
+
 	c.JSON(http.StatusOK, form)
 }
 
+// DiffFormVersionsV2 lists the changes between two published versions
+// GET /api/v2/forms/:id/versions/:version/diff?from=N (from defaults to the previous version)
+func DiffFormVersionsV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+
+	to, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+	from := to - 1
+	if fromParam := c.Query("from"); fromParam != "" {
+		if from, err = strconv.Atoi(fromParam); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from version"})
+			return
+		}
+	}
+
+	toVersion, _, err := services.LoadFormVersion(database.DB, form.ID, to)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return
+	}
+	fromVersion, _, err := services.LoadFormVersion(database.DB, form.ID, from)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "From version not found"})
+		return
+	}
+
+	diff, err := services.DiffFormVersions(fromVersion, toVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
 // ==================== FORM FIELDS ====================
 
 // ListFormFieldsV2 lists all fields for a form
@@ -382,18 +532,20 @@ func CreateFormFieldV2(c *gin.Context) {
 	}
 
 	var input struct {
-		SectionID   *string         `json:"section_id"`
-		FieldKey    string          `json:"field_key" binding:"required"`
-		FieldType   string          `json:"field_type" binding:"required"`
-		Label       string          `json:"label" binding:"required"`
-		Description *string         `json:"description"`
-		Placeholder *string         `json:"placeholder"`
-		Required    bool            `json:"required"`
-		Validation  json.RawMessage `json:"validation"`
-		Options     json.RawMessage `json:"options"`
-		Conditions  json.RawMessage `json:"conditions"`
-		SortOrder   int             `json:"sort_order"`
-		Width       string          `json:"width"`
+		SectionID       *string         `json:"section_id"`
+		FieldKey        string          `json:"field_key" binding:"required"`
+		FieldType       string          `json:"field_type" binding:"required"`
+		Label           string          `json:"label" binding:"required"`
+		Description     *string         `json:"description"`
+		Placeholder     *string         `json:"placeholder"`
+		Required        bool            `json:"required"`
+		Validation      json.RawMessage `json:"validation"`
+		Options         json.RawMessage `json:"options"`
+		Conditions      json.RawMessage `json:"conditions"`
+		PrefillValue    *string         `json:"prefill_value"`    // Literal, or a formula starting with "="
+		CalculationRule *string         `json:"calculation_rule"` // Formula; the server computes the answer
+		SortOrder       int             `json:"sort_order"`
+		Width           string          `json:"width"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -422,12 +574,20 @@ func CreateFormFieldV2(c *gin.Context) {
 		Conditions:  datatypes.JSON(input.Conditions),
 		SortOrder:   input.SortOrder,
 		Width:       input.Width,
+
+		PrefillValue:    input.PrefillValue,
+		CalculationRule: input.CalculationRule,
 	}
 
 	if field.Width == "" {
 		field.Width = "full"
 	}
 
+	if err := services.ValidateFieldLogic(field); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := database.DB.Create(&field).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -470,18 +630,20 @@ func UpdateFormFieldV2(c *gin.Context) {
 	}
 
 	var input struct {
-		SectionID   *string         `json:"section_id"`
-		FieldKey    *string         `json:"field_key"`
-		FieldType   *string         `json:"field_type"`
-		Label       *string         `json:"label"`
-		Description *string         `json:"description"`
-		Placeholder *string         `json:"placeholder"`
-		Required    *bool           `json:"required"`
-		Validation  json.RawMessage `json:"validation"`
-		Options     json.RawMessage `json:"options"`
-		Conditions  json.RawMessage `json:"conditions"`
-		SortOrder   *int            `json:"sort_order"`
-		Width       *string         `json:"width"`
+		SectionID       *string         `json:"section_id"`
+		FieldKey        *string         `json:"field_key"`
+		FieldType       *string         `json:"field_type"`
+		Label           *string         `json:"label"`
+		Description     *string         `json:"description"`
+		Placeholder     *string         `json:"placeholder"`
+		Required        *bool           `json:"required"`
+		Validation      json.RawMessage `json:"validation"`
+		Options         json.RawMessage `json:"options"`
+		Conditions      json.RawMessage `json:"conditions"`
+		PrefillValue    *string         `json:"prefill_value"`    // "" clears
+		CalculationRule *string         `json:"calculation_rule"` // "" clears
+		SortOrder       *int            `json:"sort_order"`
+		Width           *string         `json:"width"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -523,6 +685,17 @@ func UpdateFormFieldV2(c *gin.Context) {
 	if input.Width != nil {
 		field.Width = *input.Width
 	}
+	if input.PrefillValue != nil {
+		field.PrefillValue = optionalString(*input.PrefillValue)
+	}
+	if input.CalculationRule != nil {
+		field.CalculationRule = optionalString(*input.CalculationRule)
+	}
+
+	if err := services.ValidateFieldLogic(field); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	field.Version++
 
@@ -575,7 +748,7 @@ func DeleteFormFieldV2(c *gin.Context) {
 // GetMySubmissionsV2 gets current user's submissions across all forms
 // GET /api/v2/submissions/me
 func GetMySubmissionsV2(c *gin.Context) {
-	userID := c.GetString("ba_user_id")
+	userID := requestUserID(c)
 	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
 		return
@@ -635,7 +808,7 @@ func GetMySubmissionsV2(c *gin.Context) {
 // POST /api/v2/forms/:id/submissions/start
 func StartSubmissionV2(c *gin.Context) {
 	formID := c.Param("id")
-	userID := c.GetString("ba_user_id")
+	userID := requestUserID(c)
 
 	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
@@ -648,36 +821,64 @@ func StartSubmissionV2(c *gin.Context) {
 		return
 	}
 
-	// Check if form exists and is published
 	var form models.Form
-	if err := database.DB.First(&form, "id = ? AND status = 'published'", formID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Form not found or not published"})
+	if err := database.DB.First(&form, "id = ?", formID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
 		return
 	}
 
-	// Check for existing submission
 	var submission models.FormSubmission
-	err = database.DB.
-		Preload("Responses").
-		Preload("Responses.Field").
-		First(&submission, "form_id = ? AND user_id = ? AND status != 'withdrawn'", formID, userID).Error
-
-	if err == nil {
-		// Return existing submission
-		c.JSON(http.StatusOK, submission)
-		return
+	created := false
+	// Resume the user's submission: any one when the form takes a single
+	// submission per user, otherwise an unfinished one
+	findExisting := func(tx *gorm.DB, form models.Form) bool {
+		existing := tx.Where("form_id = ? AND user_id = ? AND status != 'withdrawn'", formID, userID)
+		if form.AllowMultipleSubmissions {
+			existing = existing.Where("submitted_at IS NULL")
+		}
+		return existing.Order("created_at DESC").First(&submission).Error == nil
 	}
 
-	// Create new submission (UserID is string/TEXT to match ba_users.id)
-	submission = models.FormSubmission{
-		FormID:      formUUID,
-		UserID:      userID,
-		Status:      "draft",
-		FormVersion: form.Version,
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if findExisting(tx, form) {
+			return nil
+		}
+
+		locked, err := services.EnforceFormLifecycle(tx, formUUID, userID, nil, false)
+		if err != nil {
+			return err
+		}
+		form = locked
+		// Another request may have started one while we waited for the lock
+		if findExisting(tx, form) {
+			return nil
+		}
+
+		// Create new submission (UserID is string/TEXT to match ba_users.id)
+		submission = models.FormSubmission{
+			FormID:      formUUID,
+			UserID:      userID,
+			Status:      "draft",
+			FormVersion: form.Version,
+		}
+		if err := tx.Create(&submission).Error; err != nil {
+			return err
+		}
+		created = true
+		return services.RecordSubmissionEvent(tx, startedEvent(submission.ID, submission.FormID, submission.StartedAt))
+	})
+	if err != nil {
+		respondFormUnavailable(c, err)
+		return
 	}
 
-	if err := database.DB.Create(&submission).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if !created {
+		// Return existing submission
+		database.DB.
+			Preload("Responses").
+			Preload("Responses.Field").
+			First(&submission, "id = ?", submission.ID)
+		c.JSON(http.StatusOK, submission)
 		return
 	}
 
@@ -704,7 +905,7 @@ func StartSubmissionV2(c *gin.Context) {
 // GET /api/v2/submissions/:id
 func GetSubmissionV2(c *gin.Context) {
 	submissionID := c.Param("id")
-	userID := c.GetString("ba_user_id")
+	userID := requestUserID(c)
 
 	var submission models.FormSubmission
 	query := database.DB.
@@ -720,13 +921,24 @@ func GetSubmissionV2(c *gin.Context) {
 		return
 	}
 
-	// Check ownership (unless admin) - UserID is string now
-	if userID != "" && submission.UserID != userID {
-		// TODO: Check if user is admin of workspace
+	// Applicants see their own; workspace owners and editors and assigned reviewers see any
+	if userID != "" && submission.UserID != userID &&
+		!(submission.Form != nil && checkWorkspaceRole(submission.Form.WorkspaceID, userID, reviewManagerRoles...)) &&
+		!isAssignedReviewer(submission.ID, userID) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		return
 	}
 
+	// Render against the version the submission was started on
+	if submission.Form != nil {
+		services.ApplyFormVersion(database.DB, submission.Form, submission.FormVersion)
+
+		// Blind review hides the applicant from reviewers
+		if redactor := blindRedactorFor(*submission.Form, submission.Form.Fields, userID, submission.UserID); redactor != nil {
+			redactor.RedactSubmission(&submission)
+		}
+	}
+
 	c.JSON(http.StatusOK, submission)
 }
 
@@ -734,7 +946,7 @@ func GetSubmissionV2(c *gin.Context) {
 // PUT /api/v2/submissions/:id/responses
 func SaveResponsesV2(c *gin.Context) {
 	submissionID := c.Param("id")
-	userID := c.GetString("ba_user_id")
+	userID := requestUserID(c)
 
 	submissionUUID, err := uuid.Parse(submissionID)
 	if err != nil {
@@ -757,6 +969,13 @@ func SaveResponsesV2(c *gin.Context) {
 		return
 	}
 
+	// Closed or not-yet-open forms take no more answers, unless the
+	// applicant was given an extension
+	if _, err := services.EnforceFormLifecycle(database.DB, submission.FormID, submission.UserID, &submission, false); err != nil {
+		respondFormUnavailable(c, err)
+		return
+	}
+
 	var input struct {
 		Responses map[string]any `json:"responses"` // field_key -> value
 	}
@@ -766,17 +985,18 @@ func SaveResponsesV2(c *gin.Context) {
 		return
 	}
 
-	// Build field lookup by key
+	// Build field lookup by key from the submission's pinned version
+	fields := services.PinnedFormFields(database.DB, *submission.Form, submission.FormVersion)
 	fieldsByKey := make(map[string]models.FormField)
-	for _, f := range submission.Form.Fields {
+	for _, f := range fields {
 		fieldsByKey[f.FieldKey] = f
 	}
 
 	// Process each response
 	for fieldKey, value := range input.Responses {
 		field, exists := fieldsByKey[fieldKey]
-		if !exists {
-			continue // Skip unknown fields
+		if !exists || services.IsCalculatedField(field) {
+			continue // Skip unknown fields and answers the server computes
 		}
 
 		// Find or create response
@@ -809,7 +1029,9 @@ func SaveResponsesV2(c *gin.Context) {
 		}
 
 		// Set the typed value
+		response.ResetValue()
 		response.SetValue(value, field.FieldType)
+		response.ValueSource = services.ValueSourceUser
 
 		// Save response
 		if err := database.DB.Save(&response).Error; err != nil {
@@ -824,25 +1046,17 @@ func SaveResponsesV2(c *gin.Context) {
 		submission.Status = "in_progress"
 	}
 
-	// Calculate completion percentage
-	var requiredCount, answeredCount int64
-	database.DB.Model(&models.FormField{}).
-		Where("form_id = ? AND required = true", submission.FormID).
-		Count(&requiredCount)
+	storeComputedResponses(c.Request.Context(), database.DB, fields, submission.ID, userID)
 
-	database.DB.Model(&models.FormResponse{}).
-		Joins("JOIN form_fields ON form_responses.field_id = form_fields.id").
-		Where("form_responses.submission_id = ? AND form_fields.required = true", submissionID).
-		Where("form_responses.value_text IS NOT NULL OR form_responses.value_number IS NOT NULL OR form_responses.value_boolean IS NOT NULL OR form_responses.value_date IS NOT NULL OR form_responses.value_datetime IS NOT NULL OR form_responses.value_json IS NOT NULL").
-		Count(&answeredCount)
+	// Completion follows the applicant's path: branching, hidden sections and
+	// conditionally required fields
+	path := submissionPath(submission, fields)
+	submission.CompletionPercentage = path.CompletionPercentage
 
-	if requiredCount > 0 {
-		submission.CompletionPercentage = int(float64(answeredCount) / float64(requiredCount) * 100)
-	} else {
-		submission.CompletionPercentage = 100
-	}
+	database.DB.Omit(clause.Associations).Save(&submission)
 
-	database.DB.Save(&submission)
+	// Autosave never fails validation, but reports what would block submitting
+	submission.ValidationWarnings = recordValidationWarnings(database.DB, fields, path, submission.ID)
 
 	// Sync to legacy table_rows
 	if submission.LegacyRowID != nil {
@@ -856,7 +1070,7 @@ func SaveResponsesV2(c *gin.Context) {
 // POST /api/v2/submissions/:id/submit
 func SubmitSubmissionV2(c *gin.Context) {
 	submissionID := c.Param("id")
-	userID := c.GetString("ba_user_id")
+	userID := requestUserID(c)
 
 	var submission models.FormSubmission
 	if err := database.DB.
@@ -874,43 +1088,58 @@ func SubmitSubmissionV2(c *gin.Context) {
 		return
 	}
 
-	// Validate all required fields are filled
-	fieldsByID := make(map[uuid.UUID]models.FormField)
-	for _, f := range submission.Form.Fields {
-		fieldsByID[f.ID] = f
-	}
-
-	responsesByField := make(map[uuid.UUID]models.FormResponse)
-	for _, r := range submission.Responses {
-		responsesByField[r.FieldID] = r
+	// Run the full validation pipeline: conditions, then type checks on visible fields
+	// Recompute server-owned answers so the submitted values can't be stale or tampered with
+	fields := services.PinnedFormFields(database.DB, *submission.Form, submission.FormVersion)
+	if failures := storeComputedResponses(c.Request.Context(), database.DB, fields, submission.ID, userID); len(failures) > 0 {
+		slog.WarnContext(c.Request.Context(), "calculation errors on submission", "component", "forms_v2",
+			"submission_id", submission.ID, "errors", failures)
 	}
+	database.DB.Where("submission_id = ?", submission.ID).Find(&submission.Responses)
 
-	var missingFields []string
-	for _, field := range submission.Form.Fields {
-		if field.Required {
-			response, exists := responsesByField[field.ID]
-			if !exists || response.GetValue() == nil {
-				missingFields = append(missingFields, field.Label)
-			}
-		}
-	}
-
-	if len(missingFields) > 0 {
+	// Only the sections on the applicant's path are validated; required
+	// fields in sections branching skipped can't block submitting
+	data := services.SubmissionValues(fields, submission.Responses)
+	path := services.ResolveSubmissionPath(database.DB, *submission.Form, submission.FormVersion, data)
+	validation := services.NewFormValidationService().ValidateSubmission(path.Fields(fields), data)
+	if !validation.Valid() {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error":          "Missing required fields",
-			"missing_fields": missingFields,
+			"error":          "Submission has invalid or missing fields",
+			"field_errors":   validation.Errors,
+			"missing_fields": validation.MissingFields,
 		})
 		return
 	}
 
-	// Update submission status
 	now := time.Now()
-	submission.Status = "submitted"
-	submission.SubmittedAt = &now
-	submission.CompletionPercentage = 100
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := services.EnforceFormLifecycle(tx, submission.FormID, submission.UserID, &submission, true); err != nil {
+			return err
+		}
 
-	if err := database.DB.Save(&submission).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// Update submission status; one already in review stays there
+		if submission.CurrentStageID == nil {
+			submission.Status = "submitted"
+		}
+		submission.SubmittedAt = &now
+		submission.CompletionPercentage = 100
+		if err := tx.Omit(clause.Associations).Save(&submission).Error; err != nil {
+			return err
+		}
+		if err := services.RecordSubmissionEvent(tx, submittedEvent(submission.ID, submission.FormID, now)); err != nil {
+			return err
+		}
+
+		// Place it in the first review stage that takes it
+		if submission.CurrentStageID == nil {
+			if _, err := services.EnterReviewPipeline(tx, &submission); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondFormUnavailable(c, err)
 		return
 	}
 
@@ -922,9 +1151,36 @@ func SubmitSubmissionV2(c *gin.Context) {
 		})
 	}
 
+	fireEmailTrigger(c, services.EmailTriggerEvent{
+		Trigger:      services.EmailTriggerSubmission,
+		FormID:       submission.FormID,
+		SubmissionID: submission.ID,
+		Data:         data,
+	})
+
 	c.JSON(http.StatusOK, submission)
 }
 
+// GetSubmissionPathV2 gets the sections on the applicant's path, the next
+// section to fill in and the required fields still missing
+// GET /api/v2/submissions/:id/path
+func GetSubmissionPathV2(c *gin.Context) {
+	userID := requestUserID(c)
+
+	var submission models.FormSubmission
+	if err := database.DB.Preload("Form").First(&submission, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+	if userID != "" && submission.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	fields := services.PinnedFormFields(database.DB, *submission.Form, submission.FormVersion)
+	c.JSON(http.StatusOK, submissionPath(submission, fields))
+}
+
 // ==================== ADMIN SUBMISSIONS ====================
 
 // ListFormSubmissionsV2 lists all submissions for a form (admin)
@@ -977,12 +1233,260 @@ func ListFormSubmissionsV2(c *gin.Context) {
 	})
 }
 
+// ==================== DEADLINE EXTENSIONS ====================
+
+// ListFormExtensionsV2 lists applicants allowed to keep working after the form closes
+// GET /api/v2/forms/:id/extensions
+func ListFormExtensionsV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+
+	var extensions []models.FormDeadlineExtension
+	if err := database.DB.Where("form_id = ?", form.ID).Order("extended_until ASC").Find(&extensions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, extensions)
+}
+
+// GrantFormExtensionV2 lets one applicant start, save and submit until a
+// later time than the form's closes_at. Granting again replaces the extension.
+// PUT /api/v2/forms/:id/extensions/:user_id
+func GrantFormExtensionV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		ExtendedUntil string  `json:"extended_until" binding:"required"` // RFC 3339, or wall-clock time in the form's timezone
+		Reason        *string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	extendedUntil, err := services.ParseFormTime(input.ExtendedUntil, form.Timezone)
+	if err != nil || extendedUntil == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid extended_until"})
+		return
+	}
+	if !extendedUntil.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "extended_until must be in the future"})
+		return
+	}
+
+	applicantID := c.Param("user_id")
+	var applicant models.BetterAuthUser
+	if err := database.DB.Select("id").First(&applicant, "id = ?", applicantID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Applicant not found"})
+		return
+	}
+
+	extension := models.FormDeadlineExtension{
+		FormID:        form.ID,
+		UserID:        applicant.ID,
+		ExtendedUntil: *extendedUntil,
+		Reason:        input.Reason,
+	}
+	if staffID := requestUserID(c); staffID != "" {
+		extension.GrantedBy = &staffID
+	}
+
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "form_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"extended_until", "reason", "granted_by", "updated_at"}),
+	}).Create(&extension).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	database.DB.First(&extension, "form_id = ? AND user_id = ?", form.ID, applicant.ID)
+	c.JSON(http.StatusOK, extension)
+}
+
+// RevokeFormExtensionV2 removes an applicant's extension
+// DELETE /api/v2/forms/:id/extensions/:user_id
+func RevokeFormExtensionV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("form_id = ? AND user_id = ?", form.ID, c.Param("user_id")).Delete(&models.FormDeadlineExtension{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Extension not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Extension revoked"})
+}
+
 // ==================== HELPERS ====================
 
+// requestUserID returns the authenticated user's ID (TEXT to match ba_users.id)
+func requestUserID(c *gin.Context) string {
+	if userID := c.GetString("ba_user_id"); userID != "" {
+		return userID
+	}
+	userID, _ := middleware.GetUserID(c)
+	return userID
+}
+
+// loadStaffFormV2 loads the :id form for a member of its workspace, writing
+// the error response when it can't
+func loadStaffFormV2(c *gin.Context) (models.Form, bool) {
+	var form models.Form
+	if err := database.DB.First(&form, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
+		return form, false
+	}
+	if _, ok := checkWorkspaceMembership(form.WorkspaceID, requestUserID(c)); !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return form, false
+	}
+	return form, true
+}
+
+// respondFormUnavailable answers a form lifecycle refusal with 403 (not
+// open) or 409 (full, already submitted); other errors are a 500
+func respondFormUnavailable(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrFormFull), errors.Is(err, services.ErrAlreadySubmitted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFormNotPublished), errors.Is(err, services.ErrFormNotYetOpen), errors.Is(err, services.ErrFormClosed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// optionalString returns nil for an empty string
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
 
+// submissionPath resolves the sections and required fields on a
+// submission's path from its saved answers
+func submissionPath(submission models.FormSubmission, fields []models.FormField) services.FormPath {
+	var responses []models.FormResponse
+	database.DB.Where("submission_id = ?", submission.ID).Find(&responses)
+	data := services.SubmissionValues(fields, responses)
+
+	form := models.Form{ID: submission.FormID}
+	if submission.Form != nil {
+		form = *submission.Form
+	}
+	return services.ResolveSubmissionPath(database.DB, form, submission.FormVersion, data)
+}
+
+// storeComputedResponses applies the form's prefill and calculate rules to a
+// submission's saved answers and stores the results, replacing anything the
+// client sent for calculated fields. Returns formula errors by field key.
+func storeComputedResponses(ctx context.Context, db *gorm.DB, fields []models.FormField, submissionID uuid.UUID, userID string) map[string]string {
+	var responses []models.FormResponse
+	if err := db.Where("submission_id = ?", submissionID).Find(&responses).Error; err != nil {
+		return nil
+	}
+	saved := services.SubmissionValues(fields, responses)
+	data := make(map[string]interface{}, len(saved))
+	for key, value := range saved {
+		data[key] = value
+	}
+	computed, failures := services.NewFormLogicService().ApplyComputedValues(fields, data)
+
+	responsesByField := make(map[uuid.UUID]*models.FormResponse, len(responses))
+	for i := range responses {
+		responsesByField[responses[i].FieldID] = &responses[i]
+	}
+	for _, field := range fields {
+		value, ok := computed[field.FieldKey]
+		if !ok {
+			continue
+		}
+		response, exists := responsesByField[field.ID]
+		if exists {
+			previous, _ := json.Marshal(saved[field.FieldKey])
+			next, _ := json.Marshal(value.Value)
+			if string(previous) == string(next) && response.ValueSource == value.Source {
+				continue
+			}
+			history := models.FormResponseHistory{
+				ResponseID:            response.ID,
+				PreviousValueText:     response.ValueText,
+				PreviousValueNumber:   response.ValueNumber,
+				PreviousValueBoolean:  response.ValueBoolean,
+				PreviousValueDate:     response.ValueDate,
+				PreviousValueDatetime: response.ValueDatetime,
+				PreviousValueJSON:     response.ValueJSON,
+				PreviousValueType:     &response.ValueType,
+				ChangeReason:          stringPtr(value.Source),
+			}
+			if userID != "" {
+				history.ChangedBy = &userID
+			}
+			db.Create(&history)
+		} else {
+			response = &models.FormResponse{SubmissionID: submissionID, FieldID: field.ID}
+		}
+
+		response.ResetValue()
+		response.SetValue(value.Value, field.FieldType)
+		response.ValueSource = value.Source
+		if err := db.Save(response).Error; err != nil {
+			slog.ErrorContext(ctx, "failed to store computed value", "component", "forms_v2",
+				"submission_id", submissionID, "field_key", field.FieldKey, "source", value.Source, "error", err)
+		}
+	}
+	return failures
+}
+
+// recordValidationWarnings runs the validation pipeline over a submission's
+// saved answers for the fields on its path and flags each response with its
+// problems. Returns the per-field messages.
+func recordValidationWarnings(db *gorm.DB, fields []models.FormField, path services.FormPath, submissionID uuid.UUID) map[string][]string {
+	var responses []models.FormResponse
+	if err := db.Where("submission_id = ?", submissionID).Find(&responses).Error; err != nil {
+		return nil
+	}
+	validation := services.NewFormValidationService().ValidateSubmission(path.Fields(fields), services.SubmissionValues(fields, responses))
+
+	keys := make(map[uuid.UUID]string, len(fields))
+	for _, field := range fields {
+		keys[field.ID] = field.FieldKey
+	}
+	for _, response := range responses {
+		messages := validation.Errors[keys[response.FieldID]]
+		if len(messages) == 0 && response.IsValid {
+			continue
+		}
+		if messages == nil {
+			messages = []string{}
+		}
+		messagesJSON, _ := json.Marshal(messages)
+		db.Model(&models.FormResponse{}).Where("id = ?", response.ID).Updates(map[string]interface{}{
+			"is_valid":          len(messages) == 0,
+			"validation_errors": datatypes.JSON(messagesJSON),
+		})
+	}
+	return validation.Errors
+}
+
 // syncToLegacyRow syncs submission responses to legacy table_rows.data
 func syncToLegacyRow(submission models.FormSubmission) {
 	if submission.LegacyRowID == nil {
@@ -1033,9 +1537,26 @@ func GetFormSubmissionByID(c *gin.Context) {
 		return
 	}
 
+	// Blind review hides the applicant from reviewers
+	var form models.Form
+	if err := database.DB.Preload("Fields").First(&form, "id = ?", submission.FormID).Error; err == nil {
+		if redactor := blindRedactorFor(form, form.Fields, requestUserID(c), submission.UserID); redactor != nil {
+			redactor.RedactSubmission(&submission)
+		}
+	}
+
 	c.JSON(http.StatusOK, submission)
 }
 
+// LegacyField is a form field in the pre-v2 field format
+type LegacyField struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	FieldType string                 `json:"field_type"`
+	Position  int                    `json:"position"`
+	Config    map[string]interface{} `json:"config,omitempty"`
+}
+
 // ListFormFields gets all fields for a form
 // GET /api/v1/form-fields?form_id=xxx
 func ListFormFields(c *gin.Context) {
@@ -1051,15 +1572,6 @@ func ListFormFields(c *gin.Context) {
 		return
 	}
 
-	// Convert to old format for compatibility
-	type LegacyField struct {
-		ID        string                 `json:"id"`
-		Name      string                 `json:"name"`
-		FieldType string                 `json:"field_type"`
-		Position  int                    `json:"position"`
-		Config    map[string]interface{} `json:"config,omitempty"`
-	}
-
 	legacyFields := make([]LegacyField, len(fields))
 	for i, field := range fields {
 		config := map[string]interface{}{
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Liveness and readiness probes.
+//
+// /healthz only says the process is serving HTTP. /readyz checks each
+// dependency and background loop and returns a per-component report:
+//   - "down" on a critical component (the database)          -> 503
+//   - "degraded" on failed migrations, a stalled worker or
+//     email providers                                         -> 200
+//     (503 with ?strict=true, for probes that should drain on any problem)
+//
+// Migrations are not critical: startup only warns when AutoMigrate fails
+// and the instance keeps serving, so failing readiness would take it out of
+// rotation until the next deploy.
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+	HealthStatusDown     = "down"
+	HealthStatusDisabled = "disabled"
+)
+
+// Heartbeat age after which a background loop is considered stalled
+var heartbeatStallAfter = map[string]time.Duration{
+	services.HeartbeatEmailQueueWorker: 30 * time.Second, // ticks every 5s
+	services.HeartbeatJobProcessor:     2 * time.Minute,  // ticks every 10s when idle
+	services.HeartbeatEmbeddingService: 15 * time.Minute, // runs on demand; only checked with a backlog
+	services.HeartbeatReplyPoller:      10 * time.Minute, // ticks every 2m; a tick polls every workspace
+	services.HeartbeatDecisionReleaser: 5 * time.Minute,  // ticks every minute
+}
+
+// processStartedAt anchors the startup grace period for heartbeats
+var processStartedAt = time.Now()
+
+// providerOutageWindow limits the email provider check to recent health records
+const providerOutageWindow = time.Hour
+
+// ComponentHealth is one entry of the readiness report
+type ComponentHealth struct {
+	Status             string                 `json:"status"`
+	Critical           bool                   `json:"critical"`
+	Message            string                 `json:"message,omitempty"`
+	LatencyMs          int64                  `json:"latency_ms,omitempty"`
+	LastTickAt         *time.Time             `json:"last_tick_at,omitempty"`
+	LastTickAgeSeconds *float64               `json:"last_tick_age_seconds,omitempty"`
+	Details            map[string]interface{} `json:"details,omitempty"`
+}
+
+// ReadinessReport is the /readyz response body
+type ReadinessReport struct {
+	Status     string                     `json:"status"`
+	Service    string                     `json:"service"`
+	Version    string                     `json:"version"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// Healthz is the liveness probe
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  HealthStatusOK,
+		"service": "matic-platform-go",
+	})
+}
+
+// Readyz is the readiness probe
+func Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+	defer cancel()
+
+	dbHealth := checkDatabase(ctx)
+	components := map[string]ComponentHealth{
+		"database":           dbHealth,
+		"migrations":         checkMigrations(),
+		"email_queue_worker": checkHeartbeat(services.HeartbeatEmailQueueWorker),
+		"job_processor":      checkJobProcessor(),
+		"reply_poller":       checkReplyPoller(),
+		"decision_releaser":  checkHeartbeat(services.HeartbeatDecisionReleaser),
+	}
+	// The remaining checks query the database; skip them when it is down
+	if dbHealth.Status == HealthStatusOK {
+		components["embedding_service"] = checkEmbeddingService(ctx)
+		components["email_providers"] = checkEmailProviders(ctx)
+	}
+
+	report := ReadinessReport{
+		Status:     HealthStatusOK,
+		Service:    "matic-platform-go",
+		Version:    "1.0.0",
+		CheckedAt:  time.Now().UTC(),
+		Components: components,
+	}
+	for _, component := range components {
+		switch {
+		case component.Status == HealthStatusDown && component.Critical:
+			report.Status = HealthStatusDown
+		case component.Status == HealthStatusDown || component.Status == HealthStatusDegraded:
+			if report.Status == HealthStatusOK {
+				report.Status = HealthStatusDegraded
+			}
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status == HealthStatusDown || (report.Status == HealthStatusDegraded && c.Query("strict") == "true") {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+func checkDatabase(ctx context.Context) ComponentHealth {
+	health := ComponentHealth{Status: HealthStatusOK, Critical: true}
+	if database.DB == nil {
+		health.Status = HealthStatusDown
+		health.Message = "database not initialized"
+		return health
+	}
+
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		health.Status = HealthStatusDown
+		health.Message = err.Error()
+		return health
+	}
+
+	start := time.Now()
+	err = sqlDB.PingContext(ctx)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		health.Status = HealthStatusDown
+		health.Message = "ping failed: " + err.Error()
+		return health
+	}
+
+	stats := sqlDB.Stats()
+	health.Details = map[string]interface{}{
+		"open_connections": stats.OpenConnections,
+		"in_use":           stats.InUse,
+		"idle":             stats.Idle,
+		"max_open":         stats.MaxOpenConnections,
+	}
+	return health
+}
+
+func checkMigrations() ComponentHealth {
+	health := ComponentHealth{Status: HealthStatusOK}
+	ranAt, err := database.MigrationStatus()
+	switch {
+	case ranAt.IsZero():
+		health.Status = HealthStatusDegraded
+		health.Message = "migrations have not run"
+	case err != nil:
+		health.Status = HealthStatusDegraded
+		health.Message = err.Error()
+	default:
+		health.Details = map[string]interface{}{"completed_at": ranAt.UTC()}
+	}
+	return health
+}
+
+func checkHeartbeat(component string) ComponentHealth {
+	health := ComponentHealth{Status: HealthStatusOK}
+	stallAfter := heartbeatStallAfter[component]
+
+	lastTick, ok := services.LastHeartbeat(component)
+	if !ok {
+		// Give loops one interval to tick after startup
+		if time.Since(processStartedAt) < stallAfter {
+			health.Message = "starting"
+			return health
+		}
+		health.Status = HealthStatusDegraded
+		health.Message = "no heartbeat recorded"
+		return health
+	}
+
+	age := time.Since(lastTick)
+	ageSeconds := age.Seconds()
+	health.LastTickAt = &lastTick
+	health.LastTickAgeSeconds = &ageSeconds
+	if age > stallAfter {
+		health.Status = HealthStatusDegraded
+		health.Message = "stalled: last tick older than " + stallAfter.String()
+	}
+	return health
+}
+
+func checkJobProcessor() ComponentHealth {
+	if !services.JobProcessorRunning() {
+		return ComponentHealth{Status: HealthStatusDisabled, Message: "job processor not started"}
+	}
+	return checkHeartbeat(services.HeartbeatJobProcessor)
+}
+
+func checkReplyPoller() ComponentHealth {
+	if !services.ReplyPollingEnabled() {
+		return ComponentHealth{Status: HealthStatusDisabled, Message: "EMAIL_REPLY_POLLING is off"}
+	}
+	return checkHeartbeat(services.HeartbeatReplyPoller)
+}
+
+// checkEmbeddingService flags a backlog of pending embeddings that nothing is
+// draining. The service runs on demand, so an old heartbeat alone is fine.
+func checkEmbeddingService(ctx context.Context) ComponentHealth {
+	if EmbeddingService == nil {
+		return ComponentHealth{Status: HealthStatusDisabled, Message: "COHERE_API_KEY not set"}
+	}
+
+	stallAfter := heartbeatStallAfter[services.HeartbeatEmbeddingService]
+	var backlog struct {
+		Pending int64
+		Oldest  *time.Time
+	}
+	if err := database.DB.WithContext(ctx).Table("embedding_queue").
+		Select("COUNT(*) AS pending, MIN(created_at) AS oldest").
+		Where("status = ?", "pending").
+		Scan(&backlog).Error; err != nil {
+		return ComponentHealth{Status: HealthStatusDegraded, Message: "failed to read embedding queue: " + err.Error()}
+	}
+
+	health := checkHeartbeat(services.HeartbeatEmbeddingService)
+	health.Details = map[string]interface{}{"pending": backlog.Pending}
+	if backlog.Oldest == nil || time.Since(*backlog.Oldest) < stallAfter {
+		// Nothing waiting long enough to call it stalled
+		health.Status = HealthStatusOK
+		health.Message = ""
+		return health
+	}
+
+	health.Details["oldest_pending_at"] = backlog.Oldest.UTC()
+	if health.Status == HealthStatusOK {
+		return health
+	}
+	health.Message = "pending embeddings are not being processed"
+	return health
+}
+
+// checkEmailProviders reports workspaces whose Gmail and Resend services are
+// both down according to recent EmailServiceHealth records
+func checkEmailProviders(ctx context.Context) ComponentHealth {
+	// Workspaces where every provider with a recent health record is down
+	var workspaceIDs []string
+	err := database.DB.WithContext(ctx).Table("email_service_health").
+		Select("workspace_id").
+		Where("last_checked_at > ?", time.Now().Add(-providerOutageWindow)).
+		Where("service_type IN ?", []string{string(services.ServiceTypeGmail), string(services.ServiceTypeResend), string(services.ServiceTypeSMTP)}).
+		Group("workspace_id").
+		Having("COUNT(DISTINCT service_type) >= 2 AND BOOL_AND(status = ?)", "down").
+		Pluck("workspace_id", &workspaceIDs).Error
+	if err != nil {
+		return ComponentHealth{Status: HealthStatusDegraded, Message: "failed to read email service health: " + err.Error()}
+	}
+
+	if len(workspaceIDs) == 0 {
+		return ComponentHealth{Status: HealthStatusOK}
+	}
+	return ComponentHealth{
+		Status:  HealthStatusDegraded,
+		Message: "all email providers are down for some workspaces",
+		Details: map[string]interface{}{"affected_workspaces": len(workspaceIDs)},
+	}
+}
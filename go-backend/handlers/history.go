@@ -159,6 +159,11 @@ func CompareVersions(c *gin.Context) {
 	})
 }
 
+// RestoreVersionInput explains why a row version is restored
+type RestoreVersionInput struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
 // RestoreVersion restores a row to a previous version
 func RestoreVersion(c *gin.Context) {
 	rowID := c.Param("row_id")
@@ -176,9 +181,7 @@ func RestoreVersion(c *gin.Context) {
 		return
 	}
 
-	var input struct {
-		Reason string `json:"reason" binding:"required"`
-	}
+	var input RestoreVersionInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason is required"})
 		return
@@ -390,6 +393,12 @@ func GetApproval(c *gin.Context) {
 	c.JSON(http.StatusOK, approval)
 }
 
+// ReviewApprovalInput approves or rejects a pending change
+type ReviewApprovalInput struct {
+	Action string `json:"action" binding:"required"` // approve or reject
+	Notes  string `json:"notes"`
+}
+
 // ReviewApproval approves or rejects a change request
 func ReviewApproval(c *gin.Context) {
 	approvalID := c.Param("approval_id")
@@ -400,10 +409,7 @@ func ReviewApproval(c *gin.Context) {
 		return
 	}
 
-	var input struct {
-		Action string `json:"action" binding:"required"` // approve or reject
-		Notes  string `json:"notes"`
-	}
+	var input ReviewApprovalInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -469,6 +475,24 @@ func ReviewApproval(c *gin.Context) {
 
 	database.DB.Save(&approval)
 
+	// Tell the submitter about the decision; each change request emails once
+	trigger := services.EmailTriggerApproval
+	if input.Action == "reject" {
+		trigger = services.EmailTriggerRejection
+	}
+	var row models.Row
+	if err := database.DB.Select(rowSelectColumns).First(&row, "id = ?", approval.RowID).Error; err == nil {
+		var data map[string]interface{}
+		json.Unmarshal(row.Data, &data)
+		fireEmailTrigger(c, services.EmailTriggerEvent{
+			Trigger:      trigger,
+			FormID:       approval.TableID,
+			SubmissionID: approval.RowID,
+			Data:         data,
+			EventID:      approval.ID.String(),
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
 		"version_id": versionID,
@@ -522,6 +546,12 @@ func GetTableSuggestions(c *gin.Context) {
 	})
 }
 
+// ApplySuggestionInput accepts or rejects an AI suggestion
+type ApplySuggestionInput struct {
+	Apply bool   `json:"apply"`
+	Notes string `json:"notes"`
+}
+
 // ApplySuggestion applies or rejects an AI suggestion
 func ApplySuggestion(c *gin.Context) {
 	suggestionID := c.Param("suggestion_id")
@@ -532,10 +562,7 @@ func ApplySuggestion(c *gin.Context) {
 		return
 	}
 
-	var input struct {
-		Apply bool   `json:"apply"`
-		Notes string `json:"notes"`
-	}
+	var input ApplySuggestionInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxInboundWebhookBody bounds parsed mail payloads, which carry base64 attachments
+const maxInboundWebhookBody = 40 << 20
+
+// svixTimestampTolerance rejects replayed webhook deliveries
+const svixTimestampTolerance = 5 * time.Minute
+
+// InboundEmailWebhook is a parsed inbound email (Resend "email.received" style)
+type InboundEmailWebhook struct {
+	Type string           `json:"type"` // email.received
+	Data InboundEmailData `json:"data"`
+}
+
+// InboundEmailData is the parsed message of an inbound email webhook
+type InboundEmailData struct {
+	MessageID   string                   `json:"message_id"`
+	From        string                   `json:"from"`
+	To          []string                 `json:"to"`
+	Cc          []string                 `json:"cc"`
+	Subject     string                   `json:"subject"`
+	Text        string                   `json:"text"`
+	HTML        string                   `json:"html"`
+	CreatedAt   string                   `json:"created_at"`
+	Attachments []InboundEmailAttachment `json:"attachments"`
+}
+
+// InboundEmailAttachment is an attachment with base64 content
+type InboundEmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"`
+}
+
+// HandleInboundEmailWebhook ingests a reply delivered to the plus-addressed
+// inbound address. Unmatched mail is acknowledged so the sender doesn't retry.
+func HandleInboundEmailWebhook(c *gin.Context) {
+	// Deliveries must be signed; unsigned ones are only accepted when
+	// EMAIL_INBOUND_ALLOW_UNSIGNED is set for local development
+	secret := os.Getenv("EMAIL_INBOUND_WEBHOOK_SECRET")
+	if secret == "" && os.Getenv("EMAIL_INBOUND_ALLOW_UNSIGNED") != "true" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Inbound email webhook is not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if secret != "" && !verifySvixSignature(secret, c.GetHeader("svix-id"), c.GetHeader("svix-timestamp"), c.GetHeader("svix-signature"), body) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook signature"})
+		return
+	}
+
+	var event InboundEmailWebhook
+	if err := json.Unmarshal(body, &event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook payload"})
+		return
+	}
+	if event.Type != "" && event.Type != "email.received" {
+		c.JSON(http.StatusOK, gin.H{"success": true, "ignored": true})
+		return
+	}
+
+	addresses := append(append([]string{}, event.Data.To...), event.Data.Cc...)
+	sentEmail, err := services.FindSentEmailByReplyAddress(addresses)
+	if err != nil {
+		slog.InfoContext(c.Request.Context(), "no sent email matches inbound reply", "component", "inbound_email",
+			"from", event.Data.From)
+		c.JSON(http.StatusOK, gin.H{"success": true, "matched": false})
+		return
+	}
+
+	reply := services.InboundReply{
+		Source:    "webhook",
+		MessageID: event.Data.MessageID,
+		From:      event.Data.From,
+		Subject:   event.Data.Subject,
+		Text:      event.Data.Text,
+		HTML:      event.Data.HTML,
+	}
+	if receivedAt, err := time.Parse(time.RFC3339, event.Data.CreatedAt); err == nil {
+		reply.ReceivedAt = receivedAt
+	}
+	for _, attachment := range event.Data.Attachments {
+		content, err := base64.StdEncoding.DecodeString(attachment.Content)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "skipping undecodable attachment", "component", "inbound_email",
+				"filename", attachment.Filename)
+			continue
+		}
+		reply.Attachments = append(reply.Attachments, services.InboundAttachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Content:     content,
+		})
+	}
+
+	activity, err := services.IngestReply(c.Request.Context(), sentEmail, reply)
+	switch {
+	case errors.Is(err, services.ErrReplyAlreadyIngested):
+		c.JSON(http.StatusOK, gin.H{"success": true, "matched": true, "duplicate": true})
+	case errors.Is(err, services.ErrReplyNotMatched):
+		c.JSON(http.StatusOK, gin.H{"success": true, "matched": false})
+	case err != nil:
+		slog.ErrorContext(c.Request.Context(), "failed to ingest reply", "component", "inbound_email",
+			"sent_email_id", sentEmail.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store reply"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"success": true, "matched": true, "activity_id": activity.ID})
+	}
+}
+
+// SyncEmailReplies polls the workspace's Gmail threads for replies now
+// instead of waiting for the background poller
+func SyncEmailReplies(c *gin.Context) {
+	wsUUID, err := uuid.Parse(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	ingested, err := services.PollGmailReplies(c.Request.Context(), wsUUID, time.Time{})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to check Gmail for replies: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "ingested": ingested})
+}
+
+// verifySvixSignature checks a Svix-signed webhook (used by Resend): the
+// signature is an HMAC-SHA256 of "id.timestamp.body" keyed with the
+// base64 part of the whsec_ secret
+func verifySvixSignature(secret, id, timestamp, signatures string, body []byte) bool {
+	if id == "" || timestamp == "" || signatures == "" {
+		return false
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > svixTimestampTolerance || age < -svixTimestampTolerance {
+		return false
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	// The header lists space-separated "v1,<signature>" entries
+	for _, entry := range strings.Fields(signatures) {
+		version, signature, ok := strings.Cut(entry, ",")
+		if ok && version == "v1" && hmac.Equal([]byte(signature), []byte(expected)) {
+			return true
+		}
+	}
+	return false
+}
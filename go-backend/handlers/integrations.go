@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"strings"
@@ -40,12 +41,12 @@ func InitGoogleDriveService() {
 	}
 
 	if clientID == "" || clientSecret == "" {
-		fmt.Println("⚠️  Google OAuth credentials not configured (GOOGLE_CLIENT_ID, GOOGLE_CLIENT_SECRET). Drive integration disabled.")
+		log.Println("⚠️  Google OAuth credentials not configured (GOOGLE_CLIENT_ID, GOOGLE_CLIENT_SECRET). Drive integration disabled.")
 		return
 	}
 
 	googleDriveService = services.NewGoogleDriveService(clientID, clientSecret, redirectURI)
-	fmt.Println("✅ Google Drive service initialized (using shared Google OAuth credentials)")
+	log.Println("✅ Google Drive service initialized (using shared Google OAuth credentials)")
 }
 
 // ========== Workspace Integration Handlers ==========
@@ -453,6 +454,14 @@ func CreateApplicantFolder(c *gin.Context) {
 	})
 }
 
+// SyncFileToDriveInput names the file to copy to Google Drive
+type SyncFileToDriveInput struct {
+	FileID   string `json:"file_id"`
+	FileURL  string `json:"file_url" binding:"required"`
+	FileName string `json:"file_name" binding:"required"`
+	MimeType string `json:"mime_type"`
+}
+
 // SyncFileToDrive - POST /api/v1/rows/:row_id/integrations/google_drive/sync-file
 // Syncs a file from the row to Google Drive
 func SyncFileToDrive(c *gin.Context) {
@@ -462,12 +471,7 @@ func SyncFileToDrive(c *gin.Context) {
 		return
 	}
 
-	var input struct {
-		FileID   string `json:"file_id"`
-		FileURL  string `json:"file_url" binding:"required"`
-		FileName string `json:"file_name" binding:"required"`
-		MimeType string `json:"mime_type"`
-	}
+	var input SyncFileToDriveInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -15,6 +15,7 @@ import (
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -805,7 +806,7 @@ Powered by MaticsApp - https://maticsapp.com`, workspaceName, workspaceName, inv
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send the request
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := tracing.HTTPClient("resend", 30*time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Printf("⚠️ Failed to send HTTP request to Resend: %v", err)
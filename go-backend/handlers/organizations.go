@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/Jsanchez767/matic-platform/database"
@@ -118,11 +118,12 @@ func CreateOrganization(c *gin.Context) {
 	}
 
 	// Log the attempt for debugging
-	fmt.Printf("Attempting to add member: OrgID=%s, UserID=%s, BAUserID=%s, Role=%s\n", organization.ID, legacyUserID, baUserID, member.Role)
+	slog.DebugContext(c.Request.Context(), "adding organization member",
+		"organization_id", organization.ID, "user_id", legacyUserID, "ba_user_id", baUserID, "role", member.Role)
 
 	if err := tx.Create(&member).Error; err != nil {
 		tx.Rollback()
-		fmt.Printf("Error adding member: %v\n", err)
+		slog.ErrorContext(c.Request.Context(), "failed to add organization member", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add user as owner", "details": err.Error()})
 		return
 	}
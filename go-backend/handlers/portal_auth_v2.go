@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -26,16 +27,18 @@ import (
 // Main platform uses Better Auth SDK (portalBetterAuthClient.signUp.email())
 // followed by sync endpoint (/portal/sync-better-auth-applicant).
 
+// PortalSignupV2Request is the body for the legacy portal signup
+type PortalSignupV2Request struct {
+	FormID   string `json:"form_id" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8"`
+	FullName string `json:"full_name"`
+}
+
 // PortalSignupV2 creates a new applicant account using Better Auth tables
 // DEPRECATED: Use Better Auth SDK instead (see PublicPortalV2.tsx)
 // POST /api/v1/portal/v2/signup
 func PortalSignupV2(c *gin.Context) {
-	type PortalSignupV2Request struct {
-		FormID   string `json:"form_id" binding:"required"`
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required,min=8"`
-		FullName string `json:"full_name"`
-	}
 	var req PortalSignupV2Request
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -124,7 +127,8 @@ func PortalSignupV2(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link existing submission"})
 				return
 			}
-			fmt.Printf("🔗 Linked existing submission %s to existing user %s\n", rowID, existingUser.ID)
+			slog.InfoContext(c.Request.Context(), "linked existing submission to existing user",
+				"row_id", rowID, "user_id", existingUser.ID)
 		} else {
 			// Create new table_row for the form submission
 			rowID = uuid.New().String()
@@ -143,7 +147,7 @@ func PortalSignupV2(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create form submission"})
 				return
 			}
-			fmt.Printf("📝 Created new submission %s for existing user %s\n", rowID, existingUser.ID)
+			slog.InfoContext(c.Request.Context(), "created submission for existing user", "row_id", rowID, "user_id", existingUser.ID)
 		}
 
 		// Create portal_applicants record for this new form application
@@ -233,7 +237,7 @@ func PortalSignupV2(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link existing submission: " + err.Error()})
 			return
 		}
-		fmt.Printf("🔗 Linked existing submission %s to new user %s\n", rowID, userID)
+		slog.InfoContext(c.Request.Context(), "linked existing submission to new user", "row_id", rowID, "user_id", userID)
 	} else {
 		// Create new table_row for the form submission
 		rowID = uuid.New().String()
@@ -254,7 +258,7 @@ func PortalSignupV2(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create form submission: " + err.Error()})
 			return
 		}
-		fmt.Printf("📝 Created new submission %s for user %s\n", rowID, userID)
+		slog.InfoContext(c.Request.Context(), "created submission for new user", "row_id", rowID, "user_id", userID)
 	}
 
 	// Create portal_applicants record linking user to form and their table_row
@@ -273,7 +277,7 @@ func PortalSignupV2(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("✅ Created new applicant: %s (%s) for form %s\n", userID, req.Email, req.FormID)
+	slog.InfoContext(c.Request.Context(), "created applicant", "user_id", userID, "email", req.Email, "form_id", req.FormID)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"id":            userID,
@@ -284,15 +288,17 @@ func PortalSignupV2(c *gin.Context) {
 	})
 }
 
+// PortalLoginV2Request is the body for the legacy portal login
+type PortalLoginV2Request struct {
+	FormID   string `json:"form_id" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
 // PortalLoginV2 authenticates an applicant and returns session
 // DEPRECATED: Login now handled by Better Auth (/api/portal-auth/sign-in)
 // POST /api/v1/portal/v2/login
 func PortalLoginV2(c *gin.Context) {
-	type PortalLoginV2Request struct {
-		FormID   string `json:"form_id" binding:"required"`
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required"`
-	}
 	var req PortalLoginV2Request
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -387,7 +393,8 @@ func PortalLoginV2(c *gin.Context) {
 				SET ba_created_by = $1, ba_updated_by = $1, updated_at = NOW()
 				WHERE id = $2
 			`, user.ID, existingRow.ID).Error; err == nil {
-				fmt.Printf("🔗 Linked existing submission %s to logged-in user %s\n", existingRow.ID, user.ID)
+				slog.InfoContext(c.Request.Context(), "linked existing submission to logged-in user",
+					"row_id", existingRow.ID, "user_id", user.ID)
 			}
 		}
 	}
@@ -405,7 +412,7 @@ func PortalLoginV2(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("✅ Applicant logged in: %s (%s)\n", user.ID, req.Email)
+	slog.InfoContext(c.Request.Context(), "applicant logged in", "user_id", user.ID, "email", req.Email)
 
 	c.JSON(http.StatusOK, gin.H{
 		"id":            user.ID,
@@ -482,6 +489,19 @@ func PortalLogoutV2(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
+// ApplicantSubmission is one of a portal applicant's submissions with its form name
+type ApplicantSubmission struct {
+	ID                   uuid.UUID  `json:"id"`
+	FormID               uuid.UUID  `json:"form_id"`
+	FormName             string     `json:"form_name"`
+	Status               string     `json:"status"`
+	CompletionPercentage int        `json:"completion_percentage"`
+	SubmittedAt          *time.Time `json:"submitted_at"`
+	LastSavedAt          time.Time  `json:"last_saved_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
 // GetApplicantSubmissions gets all submissions for the authenticated applicant
 // GET /api/v1/portal/v2/submissions
 func GetApplicantSubmissions(c *gin.Context) {
@@ -492,20 +512,7 @@ func GetApplicantSubmissions(c *gin.Context) {
 		return
 	}
 
-	// Query form_submissions (new unified schema)
-	type EnrichedSubmission struct {
-		ID                   uuid.UUID  `json:"id"`
-		FormID               uuid.UUID  `json:"form_id"`
-		FormName             string     `json:"form_name"`
-		Status               string     `json:"status"`
-		CompletionPercentage int        `json:"completion_percentage"`
-		SubmittedAt          *time.Time `json:"submitted_at"`
-		LastSavedAt          time.Time  `json:"last_saved_at"`
-		CreatedAt            time.Time  `json:"created_at"`
-		UpdatedAt            time.Time  `json:"updated_at"`
-	}
-
-	var results []EnrichedSubmission
+	var results []ApplicantSubmission
 	if err := database.DB.Table("form_submissions").
 		Select("form_submissions.id, form_submissions.form_id, forms.name as form_name, form_submissions.status, form_submissions.completion_percentage, form_submissions.submitted_at, form_submissions.last_saved_at, form_submissions.created_at, form_submissions.updated_at").
 		Joins("LEFT JOIN forms ON form_submissions.form_id = forms.id").
@@ -604,7 +611,8 @@ func PortalSyncBetterAuthApplicant(c *gin.Context) {
 
 	if err == nil {
 		// It's a v2 Form! Use the new form_submissions system
-		fmt.Printf("📋 Syncing Better Auth user %s with v2 form %s\n", req.BetterAuthUserID, req.FormID)
+		slog.DebugContext(c.Request.Context(), "syncing better auth applicant with v2 form",
+			"user_id", req.BetterAuthUserID, "form_id", req.FormID)
 
 		// Check for existing submission by this user
 		var submission models.FormSubmission
@@ -612,7 +620,7 @@ func PortalSyncBetterAuthApplicant(c *gin.Context) {
 
 		if err == nil {
 			// Submission exists - return it
-			fmt.Printf("✅ Found existing v2 submission for user %s on form %s\n", req.BetterAuthUserID, req.FormID)
+			slog.DebugContext(c.Request.Context(), "found existing v2 submission", "user_id", req.BetterAuthUserID, "form_id", req.FormID)
 			c.JSON(http.StatusOK, gin.H{
 				"id":                    submission.ID,
 				"email":                 req.Email,
@@ -651,7 +659,8 @@ func PortalSyncBetterAuthApplicant(c *gin.Context) {
 
 	// It's a legacy table-based form
 	// Check for existing portal_applicants record first
-	fmt.Printf("📋 Syncing Better Auth user %s with legacy form (table) %s\n", req.BetterAuthUserID, req.FormID)
+	slog.DebugContext(c.Request.Context(), "syncing better auth applicant with legacy form",
+		"user_id", req.BetterAuthUserID, "form_id", req.FormID)
 
 	var portalApplicant struct {
 		ID    string `gorm:"column:id"`
@@ -690,7 +699,8 @@ func PortalSyncBetterAuthApplicant(c *gin.Context) {
 			}
 		}
 
-		fmt.Printf("✅ Found existing portal_applicants record for user %s on form %s\n", req.BetterAuthUserID, req.FormID)
+		slog.DebugContext(c.Request.Context(), "found existing portal_applicants record",
+			"user_id", req.BetterAuthUserID, "form_id", req.FormID)
 		c.JSON(http.StatusOK, gin.H{
 			"id":              req.BetterAuthUserID,
 			"email":           req.Email,
@@ -706,7 +716,7 @@ func PortalSyncBetterAuthApplicant(c *gin.Context) {
 	}
 
 	// No existing portal_applicants record - create one for new signup
-	fmt.Printf("🆕 Creating new portal records for user %s on form %s\n", req.BetterAuthUserID, req.FormID)
+	slog.InfoContext(c.Request.Context(), "creating portal records", "user_id", req.BetterAuthUserID, "form_id", req.FormID)
 
 	tx := database.DB.Begin()
 	defer func() {
@@ -730,7 +740,7 @@ func PortalSyncBetterAuthApplicant(c *gin.Context) {
 
 	if err != nil {
 		tx.Rollback()
-		fmt.Printf("⚠️ Failed to update ba_users metadata: %v\n", err)
+		slog.WarnContext(c.Request.Context(), "failed to update ba_users metadata", "error", err)
 		// Don't fail - this is not critical
 	}
 
@@ -774,8 +784,8 @@ func PortalSyncBetterAuthApplicant(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("✅ Created portal records for user %s: row_id=%s, portal_applicant_id=%s\n",
-		req.BetterAuthUserID, rowID, portalApplicantID)
+	slog.InfoContext(c.Request.Context(), "created portal records",
+		"user_id", req.BetterAuthUserID, "row_id", rowID, "portal_applicant_id", portalApplicantID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"id":                    req.BetterAuthUserID,
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -145,7 +146,7 @@ type ApplicationInfo struct {
 
 type TimelineEvent struct {
 	ID        uuid.UUID `json:"id"`
-	Type      string    `json:"type"` // submitted, stage_change, message, status_update
+	Type      string    `json:"type"` // A submission event type, e.g. submitted, stage_changed
 	Title     string    `json:"title"`
 	Content   string    `json:"content,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
@@ -242,7 +243,7 @@ func GetApplicantDashboard(c *gin.Context) {
 		},
 		Layout:     layout,
 		Activities: convertActivitiesToDTO(activities),
-		Timeline:   buildTimelineFromActivities(row.CreatedAt, activities),
+		Timeline:   buildTimeline(row.ID),
 	}
 
 	c.JSON(http.StatusOK, dashboard)
@@ -281,16 +282,19 @@ func ListPortalActivities(c *gin.Context) {
 	c.JSON(http.StatusOK, convertActivitiesToDTO(activities))
 }
 
+// CreatePortalActivityInput is a message or event posted to an application's activity feed
+type CreatePortalActivityInput struct {
+	ActivityType string                 `json:"activity_type"`
+	Content      string                 `json:"content"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Visibility   string                 `json:"visibility"`
+}
+
 // CreatePortalActivity - POST /api/v1/portal/applications/:id/activities
 func CreatePortalActivity(c *gin.Context) {
 	applicationID := c.Param("id")
 
-	var input struct {
-		ActivityType string                 `json:"activity_type"`
-		Content      string                 `json:"content"`
-		Metadata     map[string]interface{} `json:"metadata,omitempty"`
-		Visibility   string                 `json:"visibility"`
-	}
+	var input CreatePortalActivityInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
@@ -331,18 +335,24 @@ func CreatePortalActivity(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create activity"})
 		return
 	}
+	if event, ok := services.ActivityEvent(activity); ok {
+		recordSubmissionEvent(c.Request.Context(), event)
+	}
 
 	c.JSON(http.StatusCreated, convertActivityToDTO(activity))
 }
 
+// MarkActivitiesReadInput names the activities to mark as read
+type MarkActivitiesReadInput struct {
+	ActivityIDs []string `json:"activity_ids"`
+	ReaderType  string   `json:"reader_type"` // applicant or staff
+}
+
 // MarkActivitiesRead - POST /api/v1/portal/applications/:id/activities/read
 func MarkActivitiesRead(c *gin.Context) {
 	applicationID := c.Param("id")
 
-	var input struct {
-		ActivityIDs []string `json:"activity_ids"`
-		ReaderType  string   `json:"reader_type"` // applicant or staff
-	}
+	var input MarkActivitiesReadInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
@@ -406,45 +416,22 @@ func convertActivityToDTO(a models.PortalActivity) PortalActivityDTO {
 	}
 }
 
-func buildTimelineFromActivities(submittedAt time.Time, activities []models.PortalActivity) []TimelineEvent {
+// buildTimeline renders the applicant-visible part of an application's
+// submission event log
+func buildTimeline(rowID uuid.UUID) []TimelineEvent {
 	events := []TimelineEvent{}
-
-	// Add submission event
-	events = append(events, TimelineEvent{
-		ID:        uuid.New(),
-		Type:      "submitted",
-		Title:     "Application Submitted",
-		Timestamp: submittedAt,
-	})
-
-	// Add status updates and messages from activities
-	for _, a := range activities {
-		if a.ActivityType == "status_update" || a.ActivityType == "message" {
-			events = append(events, TimelineEvent{
-				ID:        a.ID,
-				Type:      a.ActivityType,
-				Title:     getActivityTitle(a),
-				Content:   a.Content,
-				Timestamp: a.CreatedAt,
-			})
-		}
+	entries, err := services.SubmissionTimeline(database.DB, services.LinkedSubmissionIDs(database.DB, rowID), true)
+	if err != nil {
+		return events
 	}
-
-	return events
-}
-
-func getActivityTitle(a models.PortalActivity) string {
-	switch a.ActivityType {
-	case "message":
-		if a.ApplicantID != nil {
-			return "You sent a message"
-		}
-		return "New message from staff"
-	case "status_update":
-		return "Status Updated"
-	case "file_request":
-		return "Document Requested"
-	default:
-		return "Activity"
+	for _, e := range entries {
+		events = append(events, TimelineEvent{
+			ID:        e.ID,
+			Type:      e.EventType,
+			Title:     e.Title,
+			Content:   e.Content,
+			Timestamp: e.OccurredAt,
+		})
 	}
+	return events
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -155,6 +156,16 @@ func UploadPortalDocument(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save document record"})
 		return
 	}
+	recordSubmissionEvent(c.Request.Context(), models.SubmissionEvent{
+		SubmissionID: rowID,
+		FormID:       row.TableID,
+		EventType:    models.SubmissionEventDocumentUploaded,
+		Title:        "Document uploaded",
+		Content:      document.Name,
+		Metadata:     services.EventMetadata(map[string]interface{}{"document_id": document.ID, "source": "portal"}),
+		DedupeKey:    services.EventDedupeKey(models.SubmissionEventDocumentUploaded, document.ID),
+		OccurredAt:   document.UploadedAt,
+	})
 
 	c.JSON(http.StatusCreated, gin.H{
 		"id":         document.ID,
@@ -3,13 +3,16 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // ========== Portal Form Handlers (New Unified Schema) ==========
@@ -110,6 +113,13 @@ func GetPortalSubmission(c *gin.Context) {
 	})
 }
 
+// UpdatePortalSubmissionInput is a portal applicant's saved answers
+type UpdatePortalSubmissionInput struct {
+	Data                 map[string]interface{} `json:"data" binding:"required"`
+	Status               *string                `json:"status"`
+	CompletionPercentage *int                   `json:"completion_percentage"`
+}
+
 // UpdatePortalSubmission - PUT /api/v1/portal/v2/submissions/:id
 // Updates a submission by saving/updating individual field responses
 func UpdatePortalSubmission(c *gin.Context) {
@@ -129,11 +139,7 @@ func UpdatePortalSubmission(c *gin.Context) {
 	}
 
 	// Parse request body
-	var input struct {
-		Data                 map[string]interface{} `json:"data" binding:"required"`
-		Status               *string                `json:"status"`
-		CompletionPercentage *int                   `json:"completion_percentage"`
-	}
+	var input UpdatePortalSubmissionInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
@@ -160,13 +166,24 @@ func UpdatePortalSubmission(c *gin.Context) {
 		}
 	}()
 
+	submitting := input.Status != nil && *input.Status == "submitted"
+	form, err := services.EnforceFormLifecycle(tx, submission.FormID, userID, &submission, submitting)
+	if err != nil {
+		tx.Rollback()
+		respondFormUnavailable(c, err)
+		return
+	}
+
 	// Update or create responses for each field in data
 	for fieldKey, value := range input.Data {
 		field, exists := fieldMap[fieldKey]
 		if !exists {
-			fmt.Printf("Warning: Field %s not found in form schema, skipping\n", fieldKey)
+			slog.WarnContext(c.Request.Context(), "field not found in form schema, skipping", "field_key", fieldKey)
 			continue
 		}
+		if services.IsCalculatedField(field) {
+			continue // The server computes these
+		}
 
 		// Determine value type
 		var valueType string
@@ -221,16 +238,24 @@ func UpdatePortalSubmission(c *gin.Context) {
 		}
 	}
 
+	// Recompute calculated answers from the saved ones
+	if failures := storeComputedResponses(c.Request.Context(), tx, services.PinnedFormFields(database.DB, form, submission.FormVersion), submission.ID, userID); len(failures) > 0 {
+		slog.WarnContext(c.Request.Context(), "calculation errors on portal submission", "submission_id", submission.ID, "errors", failures)
+	}
+
 	// Update submission metadata
 	updates := map[string]interface{}{
 		"last_saved_at": time.Now(),
 		"updated_at":    time.Now(),
 	}
 
+	var submittedAt *time.Time
 	if input.Status != nil {
 		updates["status"] = *input.Status
 		if *input.Status == "submitted" && submission.SubmittedAt == nil {
-			updates["submitted_at"] = time.Now()
+			now := time.Now()
+			submittedAt = &now
+			updates["submitted_at"] = now
 		}
 	}
 
@@ -243,6 +268,13 @@ func UpdatePortalSubmission(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update submission"})
 		return
 	}
+	if submittedAt != nil {
+		if err := services.RecordSubmissionEvent(tx, submittedEvent(submission.ID, submission.FormID, *submittedAt)); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update submission"})
+			return
+		}
+	}
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
@@ -250,6 +282,15 @@ func UpdatePortalSubmission(c *gin.Context) {
 		return
 	}
 
+	if submitting {
+		fireEmailTrigger(c, services.EmailTriggerEvent{
+			Trigger:      services.EmailTriggerSubmission,
+			FormID:       submission.FormID,
+			SubmissionID: submission.ID,
+			Data:         input.Data,
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"id":         submission.ID,
 		"status":     submission.Status,
@@ -289,44 +330,54 @@ func GetOrCreatePortalSubmission(c *gin.Context) {
 		return
 	}
 
-	// Check if form exists and allows submissions
-	var form models.Form
-	if err := database.DB.First(&form, "id = ?", formID).Error; err != nil {
+	// Check if form exists
+	formUUID, err := uuid.Parse(formID)
+	if err != nil || database.DB.Select("id").First(&models.Form{}, "id = ?", formUUID).Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
 		return
 	}
 
-	if form.Status != "published" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Form is not accepting submissions"})
-		return
-	}
-
-	// Check if form has closed
-	if form.ClosesAt != nil && time.Now().After(*form.ClosesAt) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Form submissions are closed"})
-		return
-	}
-
 	// Create new submission
-	newSubmission := models.FormSubmission{
-		FormID:               form.ID,
-		UserID:               userID,
-		Status:               "draft",
-		CompletionPercentage: 0,
-		FormVersion:          form.Version,
-	}
+	var newSubmission models.FormSubmission
+	created := false
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		form, err := services.EnforceFormLifecycle(tx, formUUID, userID, nil, false)
+		if err != nil {
+			return err
+		}
+		// Another request may have started one while we waited for the lock
+		if tx.Where("form_id = ? AND user_id = ? AND status != ?", formUUID, userID, "withdrawn").First(&newSubmission).Error == nil {
+			return nil
+		}
 
-	if err := database.DB.Create(&newSubmission).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create submission"})
+		newSubmission = models.FormSubmission{
+			FormID:               form.ID,
+			UserID:               userID,
+			Status:               "draft",
+			CompletionPercentage: 0,
+			FormVersion:          form.Version,
+		}
+		if err := tx.Create(&newSubmission).Error; err != nil {
+			return err
+		}
+		created = true
+		return services.RecordSubmissionEvent(tx, startedEvent(newSubmission.ID, newSubmission.FormID, newSubmission.StartedAt))
+	})
+	if err != nil {
+		respondFormUnavailable(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
 		"id":                    newSubmission.ID,
 		"form_id":               newSubmission.FormID,
 		"status":                newSubmission.Status,
 		"completion_percentage": newSubmission.CompletionPercentage,
 		"started_at":            newSubmission.StartedAt,
-		"existing":              false,
+		"existing":              !created,
 	})
 }
@@ -1,13 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
@@ -33,7 +35,7 @@ func resolveForm(c *gin.Context, formIDStr string) (*models.Form, bool) {
 	// Fallback: try legacy_table_id
 	err = database.DB.Where("legacy_table_id = ?", parsedFormID).First(&form).Error
 	if err == nil {
-		fmt.Printf("✅ Resolved form via legacy_table_id %s -> %s\n", formIDStr, form.ID)
+		slog.DebugContext(c.Request.Context(), "resolved form via legacy_table_id", "table_id", formIDStr, "form_id", form.ID)
 		return &form, true
 	}
 
@@ -47,18 +49,17 @@ func getDataFields(formID uuid.UUID) []models.FormField {
 	return models.DataFieldsOnly(fields)
 }
 
-// computeCompletion calculates completion percentage based on filled data fields
-func computeCompletion(data map[string]interface{}, dataFields []models.FormField) int {
-	if len(dataFields) == 0 {
-		return 0
-	}
-	filled := 0
+// computeCompletion resolves the applicant's path through the form (branching,
+// hidden sections and fields) and returns it with the completion of the
+// visible required fields on it. data is keyed by field ID.
+func computeCompletion(form *models.Form, version int, data map[string]interface{}, dataFields []models.FormField) services.FormPath {
+	keyed := make(map[string]interface{}, len(data))
 	for _, f := range dataFields {
-		if v, exists := data[f.ID.String()]; exists && v != nil && v != "" {
-			filled++
+		if v, exists := data[f.ID.String()]; exists {
+			keyed[f.FieldKey] = v
 		}
 	}
-	return (filled * 100) / len(dataFields)
+	return services.ResolveSubmissionPath(database.DB, *form, version, keyed)
 }
 
 // buildLegacyKeyMap builds a mapping of field_key -> legacy field UUID string
@@ -100,7 +101,7 @@ func buildLegacyUUIDToFieldID(form *models.Form, fieldKeyToID map[string]uuid.UU
 // remapToLegacyKeys remaps raw_data keyed by V2 form_field UUIDs to V1 table_field UUIDs.
 // This is needed because GetFormBySlug returns V1 table_fields IDs in the config,
 // but form_submissions.raw_data uses V2 form_field UUIDs.
-func remapToLegacyKeys(form *models.Form, data map[string]interface{}) map[string]interface{} {
+func remapToLegacyKeys(ctx context.Context, form *models.Form, data map[string]interface{}) map[string]interface{} {
 	if form.LegacyTableID == nil {
 		return data
 	}
@@ -134,7 +135,7 @@ func remapToLegacyKeys(form *models.Form, data map[string]interface{}) map[strin
 		remapped[dataKey] = value
 	}
 
-	fmt.Printf("🔄 remapToLegacyKeys: Remapped %d keys for legacy form %s\n", len(remapped), form.LegacyTableID)
+	slog.DebugContext(ctx, "remapped keys for legacy form", "count", len(remapped), "table_id", form.LegacyTableID)
 	return remapped
 }
 
@@ -149,11 +150,11 @@ func GetMyPortalSubmission(c *gin.Context) {
 	}
 
 	formID := c.Param("form_id")
-	fmt.Printf("📋 GetMyPortalSubmission: User %s requesting submission for form %s\n", userID, formID)
+	slog.DebugContext(c.Request.Context(), "applicant requesting portal submission", "user_id", userID, "form_id", formID)
 
 	form, ok := resolveForm(c, formID)
 	if !ok {
-		fmt.Printf("❌ GetMyPortalSubmission: Form %s not found\n", formID)
+		slog.WarnContext(c.Request.Context(), "form not found", "form_id", formID)
 		// Form not found at all — return empty state
 		c.JSON(http.StatusOK, gin.H{
 			"id":         nil,
@@ -165,13 +166,13 @@ func GetMyPortalSubmission(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("✅ Form resolved: %s (legacy_table_id: %v)\n", form.ID, form.LegacyTableID)
+	slog.DebugContext(c.Request.Context(), "form resolved", "form_id", form.ID, "table_id", form.LegacyTableID)
 
 	// Query submission
 	var submission models.FormSubmission
 	err := database.DB.Where("form_id = ? AND user_id = ?", form.ID, userID).First(&submission).Error
 	if err != nil {
-		fmt.Printf("ℹ️ GetMyPortalSubmission: No submission found for user %s and form %s (error: %v)\n", userID, form.ID, err)
+		slog.DebugContext(c.Request.Context(), "no portal submission found", "user_id", userID, "form_id", form.ID, "error", err)
 		c.JSON(http.StatusOK, gin.H{
 			"id":         nil,
 			"data":       map[string]interface{}{},
@@ -190,10 +191,10 @@ func GetMyPortalSubmission(c *gin.Context) {
 	if submission.RawData != nil && len(submission.RawData) > 2 { // > 2 means more than '{}'
 		if err := json.Unmarshal(submission.RawData, &data); err == nil && len(data) > 0 {
 			hasRawData = true
-			fmt.Printf("✅ GetMyPortalSubmission: Read %d fields from raw_data\n", len(data))
+			slog.DebugContext(c.Request.Context(), "read portal submission fields from raw_data", "count", len(data))
 			// Remap V2 field UUIDs to V1 table_field UUIDs for legacy forms
 			// so keys match the field IDs returned by GetFormBySlug
-			data = remapToLegacyKeys(form, data)
+			data = remapToLegacyKeys(c.Request.Context(), form, data)
 		}
 	}
 
@@ -225,7 +226,7 @@ func GetMyPortalSubmission(c *gin.Context) {
 			}
 			data[dataKey] = value
 		}
-		fmt.Printf("✅ GetMyPortalSubmission: Built %d fields from form_responses (legacy)\n", len(data))
+		slog.DebugContext(c.Request.Context(), "built portal submission fields from form_responses", "count", len(data))
 	}
 
 	// Build metadata
@@ -237,8 +238,11 @@ func GetMyPortalSubmission(c *gin.Context) {
 		metadata["submitted_at"] = submission.SubmittedAt
 	}
 	metadata["last_saved_at"] = submission.LastSavedAt
+	if decision := services.PortalDecisionFor(database.DB, submission.ID); decision != nil {
+		metadata["decision"] = decision
+	}
 
-	fmt.Printf("✅ GetMyPortalSubmission: Returning submission %s with %d data fields\n", submission.ID, len(data))
+	slog.DebugContext(c.Request.Context(), "returning portal submission", "submission_id", submission.ID, "field_count", len(data))
 
 	c.JSON(http.StatusOK, gin.H{
 		"id":         submission.ID,
@@ -273,7 +277,7 @@ func SaveMyPortalSubmission(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("📝 SaveMyPortalSubmission: User %s saving for form %s (draft=%v)\n", userID, formID, input.SaveDraft)
+	slog.InfoContext(c.Request.Context(), "saving portal submission", "user_id", userID, "form_id", formID, "save_draft", input.SaveDraft)
 
 	form, ok := resolveForm(c, formID)
 	if !ok {
@@ -296,6 +300,7 @@ func SaveMyPortalSubmission(c *gin.Context) {
 	legacyUUIDToID := buildLegacyUUIDToFieldID(form, fieldKeyToID)
 
 	// --- Normalize incoming data keys to field IDs ---
+	// Calculated answers are dropped; the server computes them below
 	normalized := make(map[string]interface{})
 	for dataKey, value := range input.Data {
 		// Try field_key first
@@ -315,7 +320,12 @@ func SaveMyPortalSubmission(c *gin.Context) {
 			normalized[dataKey] = value
 			continue
 		}
-		fmt.Printf("⚠️ SaveMyPortalSubmission: Data key '%s' not resolved, skipping\n", dataKey)
+		slog.WarnContext(c.Request.Context(), "portal submission data key not resolved, skipping", "data_key", dataKey)
+	}
+	for fieldIDStr := range normalized {
+		if fieldID, err := uuid.Parse(fieldIDStr); err == nil && services.IsCalculatedField(fieldIDToField[fieldID]) {
+			delete(normalized, fieldIDStr)
+		}
 	}
 
 	// Marshal raw_data JSONB
@@ -325,9 +335,6 @@ func SaveMyPortalSubmission(c *gin.Context) {
 		return
 	}
 
-	// Compute completion
-	completion := computeCompletion(normalized, dataFields)
-
 	// Begin transaction
 	tx := database.DB.Begin()
 	defer func() {
@@ -338,11 +345,43 @@ func SaveMyPortalSubmission(c *gin.Context) {
 
 	// Check for existing submission
 	var existingSubmission models.FormSubmission
-	err = database.DB.Where("form_id = ? AND user_id = ?", form.ID, userID).First(&existingSubmission).Error
+	findExisting := func() bool {
+		return tx.Where("form_id = ? AND user_id = ?", form.ID, userID).First(&existingSubmission).Error == nil
+	}
+	exists := findExisting()
+	if !exists {
+		// Starting one locks the form; look again in case another save
+		// created it while we waited
+		if _, err := services.EnforceFormLifecycle(tx, form.ID, userID, nil, false); err != nil {
+			tx.Rollback()
+			respondFormUnavailable(c, err)
+			return
+		}
+		exists = findExisting()
+	}
+	if exists || !input.SaveDraft {
+		var current *models.FormSubmission
+		if exists {
+			current = &existingSubmission
+		}
+		if _, err := services.EnforceFormLifecycle(tx, form.ID, userID, current, !input.SaveDraft); err != nil {
+			tx.Rollback()
+			respondFormUnavailable(c, err)
+			return
+		}
+	}
+
+	// Compute completion along the applicant's path through the pinned version
+	version := form.Version
+	if exists {
+		version = existingSubmission.FormVersion
+	}
+	path := computeCompletion(form, version, normalized, dataFields)
+	completion := path.CompletionPercentage
 
 	var submissionID uuid.UUID
 
-	if err == nil {
+	if exists {
 		// --- Update existing ---
 		submissionID = existingSubmission.ID
 
@@ -350,6 +389,7 @@ func SaveMyPortalSubmission(c *gin.Context) {
 		existingSubmission.CompletionPercentage = completion
 		existingSubmission.LastSavedAt = time.Now()
 
+		firstSubmit := !input.SaveDraft && existingSubmission.SubmittedAt == nil
 		if input.SaveDraft {
 			if existingSubmission.Status != "submitted" {
 				existingSubmission.Status = "draft"
@@ -365,7 +405,14 @@ func SaveMyPortalSubmission(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update submission"})
 			return
 		}
-		fmt.Printf("🔄 Updated submission %s (completion=%d%%)\n", submissionID, completion)
+		if firstSubmit {
+			if err := services.RecordSubmissionEvent(tx, submittedEvent(submissionID, form.ID, *existingSubmission.SubmittedAt)); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update submission"})
+				return
+			}
+		}
+		slog.InfoContext(c.Request.Context(), "updated portal submission", "submission_id", submissionID, "completion", completion)
 	} else {
 		// --- Create new ---
 		status := "draft"
@@ -384,6 +431,7 @@ func SaveMyPortalSubmission(c *gin.Context) {
 			CompletionPercentage: completion,
 			LastSavedAt:          time.Now(),
 			SubmittedAt:          submittedAt,
+			FormVersion:          version,
 		}
 
 		if err := tx.Create(&newSubmission).Error; err != nil {
@@ -392,7 +440,19 @@ func SaveMyPortalSubmission(c *gin.Context) {
 			return
 		}
 		submissionID = newSubmission.ID
-		fmt.Printf("📝 Created submission %s (completion=%d%%)\n", submissionID, completion)
+		slog.InfoContext(c.Request.Context(), "created portal submission", "submission_id", submissionID, "completion", completion)
+
+		events := []models.SubmissionEvent{startedEvent(submissionID, form.ID, newSubmission.StartedAt)}
+		if submittedAt != nil {
+			events = append(events, submittedEvent(submissionID, form.ID, *submittedAt))
+		}
+		for _, event := range events {
+			if err := services.RecordSubmissionEvent(tx, event); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create submission"})
+				return
+			}
+		}
 	}
 
 	// --- Dual-write to form_responses (backward compat) ---
@@ -464,14 +524,52 @@ func SaveMyPortalSubmission(c *gin.Context) {
 		}
 	}
 
+	// Recompute calculated answers from the saved ones and keep raw_data in step
+	pinnedFields := services.PinnedFormFields(database.DB, *form, version)
+	if failures := storeComputedResponses(c.Request.Context(), tx, pinnedFields, submissionID, userID); len(failures) > 0 {
+		slog.WarnContext(c.Request.Context(), "calculation errors on portal submission", "submission_id", submissionID, "errors", failures)
+	}
+	var responses []models.FormResponse
+	tx.Where("submission_id = ?", submissionID).Find(&responses)
+	values := services.SubmissionValues(pinnedFields, responses)
+	for _, field := range pinnedFields {
+		if value, ok := values[field.FieldKey]; ok && services.IsCalculatedField(field) {
+			normalized[field.ID.String()] = value
+		}
+	}
+	if rawDataBytes, err = json.Marshal(normalized); err == nil {
+		tx.Model(&models.FormSubmission{}).Where("id = ?", submissionID).Update("raw_data", datatypes.JSON(rawDataBytes))
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
-	fmt.Printf("✅ SaveMyPortalSubmission: Saved submission %s with %d fields\n", submissionID, len(normalized))
+	slog.DebugContext(c.Request.Context(), "saved portal submission", "submission_id", submissionID, "field_count", len(normalized))
+
+	if !input.SaveDraft {
+		// Merge tags use field keys
+		data := make(map[string]interface{}, len(normalized))
+		for fieldIDStr, value := range normalized {
+			if fieldID, err := uuid.Parse(fieldIDStr); err == nil {
+				if field, ok := fieldIDToField[fieldID]; ok {
+					data[field.FieldKey] = value
+				}
+			}
+		}
+		fireEmailTrigger(c, services.EmailTriggerEvent{
+			Trigger:      services.EmailTriggerSubmission,
+			FormID:       form.ID,
+			SubmissionID: submissionID,
+			Data:         data,
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"id":         submissionID,
-		"updated_at": time.Now(),
+		"id":                    submissionID,
+		"updated_at":            time.Now(),
+		"completion_percentage": completion,
+		"next_section_id":       path.NextSectionID,
 	})
 }
@@ -8,17 +8,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"log/slog"
 	"net/http"
 	urlpkg "net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/logging"
 	"github.com/Jsanchez767/matic-platform/models"
 	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/Jsanchez767/matic-platform/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -221,55 +224,11 @@ func isLegacyRecommendationReminderHTML(body string) bool {
 }
 
 func normalizeMergeTagName(name string) string {
-	name = strings.TrimSpace(strings.ToLower(name))
-	name = strings.ReplaceAll(name, "_", "")
-	name = strings.ReplaceAll(name, "-", "")
-	name = strings.ReplaceAll(name, " ", "")
-	return name
+	return services.NormalizeMergeTagName(name)
 }
 
 func stringifyMergeTagValue(value interface{}) string {
-	switch v := value.(type) {
-	case nil:
-		return ""
-	case string:
-		return strings.TrimSpace(v)
-	case float64:
-		return fmt.Sprintf("%.0f", v)
-	case bool:
-		if v {
-			return "Yes"
-		}
-		return "No"
-	case []interface{}:
-		parts := make([]string, 0, len(v))
-		for _, item := range v {
-			itemValue := stringifyMergeTagValue(item)
-			if itemValue != "" {
-				parts = append(parts, itemValue)
-			}
-		}
-		return strings.Join(parts, ", ")
-	case map[string]interface{}:
-		for _, key := range []string{"label", "name", "title", "text", "display", "display_value"} {
-			if raw, ok := v[key]; ok {
-				if s := stringifyMergeTagValue(raw); s != "" {
-					return s
-				}
-			}
-		}
-		if raw, ok := v["value"]; ok {
-			if s := stringifyMergeTagValue(raw); s != "" {
-				return s
-			}
-		}
-		encoded, err := json.Marshal(v)
-		if err == nil {
-			return string(encoded)
-		}
-	}
-
-	return fmt.Sprintf("%v", value)
+	return services.MergeValueString(value)
 }
 
 func normalizeMergeFieldID(fieldID string) string {
@@ -373,52 +332,31 @@ func resolveSubmissionFieldValue(formID uuid.UUID, fieldID string, submissionDat
 	return "", false
 }
 
-func applyRecommendationMergeTags(content string, mergeData map[string]string, submissionData map[string]interface{}, formID uuid.UUID) string {
+// applyRecommendationMergeTags renders merge tags from the recommendation
+// merge data, falling back to the applicant's submission fields. With html
+// set, values are escaped for an HTML body.
+func applyRecommendationMergeTags(ctx context.Context, content string, mergeData map[string]string, submissionData map[string]interface{}, formID uuid.UUID, html bool) string {
 	if content == "" {
 		return content
 	}
 
+	recommendationData := make(map[string]interface{}, len(mergeData))
 	for key, value := range mergeData {
-		content = strings.ReplaceAll(content, fmt.Sprintf("{{%s}}", key), value)
-		content = strings.ReplaceAll(content, fmt.Sprintf("{{{%s}}}", key), value)
+		recommendationData[key] = value
+	}
+	submissionFields := func(name string) (interface{}, bool) {
+		return resolveSubmissionFieldValue(formID, name, submissionData)
 	}
 
-	mergeTagRegex := regexp.MustCompile(`\{\{\{?\s*([^{}]+?)\s*\}\}\}?`)
-	return mergeTagRegex.ReplaceAllStringFunc(content, func(match string) string {
-		parts := mergeTagRegex.FindStringSubmatch(match)
-		if len(parts) < 2 {
-			return match
-		}
-
-		tagKey := strings.TrimSpace(parts[1])
-		cleanTagKey := normalizeMergeFieldID(tagKey)
-		if tagKey == "" {
-			return match
-		}
-		if cleanTagKey == "" {
-			return match
-		}
-
-		if value, ok := mergeData[tagKey]; ok {
-			return value
-		}
-		if value, ok := mergeData[cleanTagKey]; ok {
-			return value
-		}
-
-		normalizedTagKey := normalizeMergeTagName(cleanTagKey)
-		for key, value := range mergeData {
-			if normalizeMergeTagName(key) == normalizedTagKey {
-				return value
-			}
-		}
-
-		if value, ok := resolveSubmissionFieldValue(formID, cleanTagKey, submissionData); ok {
-			return value
-		}
-
-		return match
-	})
+	rendered, err := services.RenderMergeTags(content, services.ChainMergeResolvers(
+		services.MapMergeResolver(recommendationData),
+		submissionFields,
+	), services.MergeRenderOptions{HTML: html, KeepUnresolved: true})
+	if err != nil {
+		slog.WarnContext(ctx, "merge tags not applied", "component", "recommendations", "error", err)
+		return content
+	}
+	return rendered
 }
 
 func findFirstMatchingSubmissionValue(submissionData map[string]interface{}, keys []string) string {
@@ -628,7 +566,7 @@ func syncRecommendationDocumentToGoogleDrive(request *models.RecommendationReque
 				"external_folder_id":  formFolderID,
 				"external_folder_url": formFolderURL,
 			}).Error; saveErr != nil {
-			fmt.Printf("[Recommendations] Warning: failed to persist form folder for form %s: %v\n", request.FormID.String(), saveErr)
+			log.Printf("[Recommendations] Warning: failed to persist form folder for form %s: %v", request.FormID.String(), saveErr)
 		}
 	}
 
@@ -1108,7 +1046,9 @@ type recommendationDriveSyncResult struct {
 	Error      string                 `json:"error,omitempty"`
 }
 
-type recommendationSubmissionBackfillSummary struct {
+// RecommendationBackfillSummary reports what syncing a submission's
+// recommendation documents to Google Drive found and did
+type RecommendationBackfillSummary struct {
 	SubmissionID      uuid.UUID                       `json:"submission_id"`
 	RequestsChecked   int                             `json:"requests_checked"`
 	SubmissionFiles   int                             `json:"submission_files"`
@@ -1119,7 +1059,7 @@ type recommendationSubmissionBackfillSummary struct {
 	SyncResults       []recommendationDriveSyncResult `json:"sync_results"`
 }
 
-func backfillRecommendationDocumentsForSubmission(submissionID uuid.UUID, fallbackFormID *uuid.UUID) (*recommendationSubmissionBackfillSummary, error) {
+func backfillRecommendationDocumentsForSubmission(submissionID uuid.UUID, fallbackFormID *uuid.UUID) (*RecommendationBackfillSummary, error) {
 	var submission models.FormSubmission
 	hasFormSubmission := false
 	if err := database.DB.Select("id", "form_id", "legacy_row_id", "raw_data").Where("id = ?", submissionID).First(&submission).Error; err == nil {
@@ -1360,7 +1300,7 @@ func backfillRecommendationDocumentsForSubmission(submissionID uuid.UUID, fallba
 		})
 	}
 
-	summary := &recommendationSubmissionBackfillSummary{
+	summary := &RecommendationBackfillSummary{
 		SubmissionID:      submissionID,
 		RequestsChecked:   len(requests),
 		SubmissionFiles:   len(rowFiles),
@@ -1472,6 +1412,19 @@ func SyncSubmissionRecommendationDocumentsToGoogleDrive(c *gin.Context) {
 	c.JSON(http.StatusOK, summary)
 }
 
+// RecommendationBackfillResult is one submission's outcome in a form-wide Google Drive backfill
+type RecommendationBackfillResult struct {
+	SubmissionID      string                          `json:"submission_id"`
+	RequestsChecked   int                             `json:"requests_checked"`
+	SubmissionFiles   int                             `json:"submission_files"`
+	DocumentsFound    int                             `json:"documents_found"`
+	DocumentsSynced   int                             `json:"documents_synced"`
+	DocumentsExisting int                             `json:"documents_existing"`
+	DocumentsFailed   int                             `json:"documents_failed"`
+	SyncResults       []recommendationDriveSyncResult `json:"sync_results"`
+	Error             string                          `json:"error,omitempty"`
+}
+
 // BackfillFormRecommendationDocumentsToGoogleDrive syncs historical recommendation and uploaded docs for all submissions in a form.
 // POST /api/v1/recommendations/form/:formId/google-drive/backfill
 func BackfillFormRecommendationDocumentsToGoogleDrive(c *gin.Context) {
@@ -1487,19 +1440,7 @@ func BackfillFormRecommendationDocumentsToGoogleDrive(c *gin.Context) {
 		return
 	}
 
-	type submissionResult struct {
-		SubmissionID      string                          `json:"submission_id"`
-		RequestsChecked   int                             `json:"requests_checked"`
-		SubmissionFiles   int                             `json:"submission_files"`
-		DocumentsFound    int                             `json:"documents_found"`
-		DocumentsSynced   int                             `json:"documents_synced"`
-		DocumentsExisting int                             `json:"documents_existing"`
-		DocumentsFailed   int                             `json:"documents_failed"`
-		SyncResults       []recommendationDriveSyncResult `json:"sync_results"`
-		Error             string                          `json:"error,omitempty"`
-	}
-
-	results := make([]submissionResult, 0, len(submissionIDs))
+	results := make([]RecommendationBackfillResult, 0, len(submissionIDs))
 	totalFound := 0
 	totalSynced := 0
 	totalExisting := 0
@@ -1508,7 +1449,7 @@ func BackfillFormRecommendationDocumentsToGoogleDrive(c *gin.Context) {
 	for _, submissionID := range submissionIDs {
 		summary, syncErr := backfillRecommendationDocumentsForSubmission(submissionID, &formID)
 		if syncErr != nil {
-			results = append(results, submissionResult{
+			results = append(results, RecommendationBackfillResult{
 				SubmissionID: submissionID.String(),
 				Error:        syncErr.Error(),
 			})
@@ -1516,7 +1457,7 @@ func BackfillFormRecommendationDocumentsToGoogleDrive(c *gin.Context) {
 			continue
 		}
 
-		results = append(results, submissionResult{
+		results = append(results, RecommendationBackfillResult{
 			SubmissionID:      summary.SubmissionID.String(),
 			RequestsChecked:   summary.RequestsChecked,
 			SubmissionFiles:   summary.SubmissionFiles,
@@ -1659,7 +1600,7 @@ func UpdateRecommendationRequest(c *gin.Context) {
 func GetRecommendationByToken(c *gin.Context) {
 	token := c.Param("token")
 
-	fmt.Printf("[Recommendations] GetByToken called with token: %s\n", token)
+	slog.DebugContext(c.Request.Context(), "looking up recommendation request by token", "component", "recommendations")
 
 	// Special preview token used in test emails
 	if token == "sample-token-preview" {
@@ -1682,13 +1623,13 @@ func GetRecommendationByToken(c *gin.Context) {
 
 	var request models.RecommendationRequest
 	if err := database.DB.First(&request, "token = ?", token).Error; err != nil {
-		fmt.Printf("[Recommendations] Token not found in database: %s\n", token)
+		slog.InfoContext(c.Request.Context(), "recommendation token not found", "component", "recommendations")
 		c.JSON(http.StatusNotFound, gin.H{"error": "Recommendation request not found or link has expired"})
 		return
 	}
 
-	fmt.Printf("[Recommendations] Found request ID: %s, Status: %s, SubmissionID: %s\n",
-		request.ID.String(), request.Status, request.SubmissionID.String())
+	slog.DebugContext(c.Request.Context(), "found recommendation request", "component", "recommendations",
+		"recommendation_request_id", request.ID, "status", request.Status, "submission_id", request.SubmissionID)
 
 	// Get form info
 	var form models.Table
@@ -1797,15 +1738,17 @@ func CreateRecommendationRequest(c *gin.Context) {
 	var field models.Field
 	if err := database.DB.Where("table_id = ? AND id = ?", input.FormID, input.FieldID).First(&field).Error; err == nil {
 		if field.Config != nil {
-			fmt.Printf("[Recommendations] Raw field.Config: %s\n", string(field.Config))
+			slog.DebugContext(c.Request.Context(), "recommendation field config", "component", "recommendations",
+				"config", string(field.Config))
 			if err := json.Unmarshal(field.Config, &fieldConfig); err != nil {
-				fmt.Printf("[Recommendations] Failed to unmarshal field config: %v\n", err)
+				slog.WarnContext(c.Request.Context(), "failed to unmarshal recommendation field config", "component", "recommendations",
+					"error", err)
 			}
 		} else {
-			fmt.Printf("[Recommendations] Field config is nil\n")
+			slog.WarnContext(c.Request.Context(), "recommendation field config is nil", "component", "recommendations")
 		}
 	} else {
-		fmt.Printf("[Recommendations] Could not find field: %v\n", err)
+		slog.WarnContext(c.Request.Context(), "recommendation field not found", "component", "recommendations", "error", err)
 	}
 
 	// Set defaults if not configured
@@ -1862,12 +1805,14 @@ func CreateRecommendationRequest(c *gin.Context) {
 				expiry := now.AddDate(0, 0, deadlineDays)
 				newExpiresAt = &expiry
 				existingRequest.ExpiresAt = newExpiresAt
-				fmt.Printf("[Recommendations] Extended expiration date to %v for reminder\n", newExpiresAt)
+				slog.InfoContext(c.Request.Context(), "extended recommendation request expiry for reminder", "component", "recommendations",
+					"expires_at", newExpiresAt)
 			}
 
 			// Send reminder email (no specific sender account for initial creation)
-			if err := sendRecommendationReminderEmail(&existingRequest, &submission, &form, &fieldConfig, nil); err != nil {
-				fmt.Printf("[Recommendations] Failed to send reminder email: %v\n", err)
+			if err := sendRecommendationReminderEmail(c.Request.Context(), &existingRequest, &submission, &form, &fieldConfig, nil); err != nil {
+				slog.ErrorContext(c.Request.Context(), "failed to send recommendation reminder email", "component", "recommendations",
+					"error", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reminder email"})
 				return
 			}
@@ -1877,7 +1822,8 @@ func CreateRecommendationRequest(c *gin.Context) {
 			existingRequest.RemindedAt = &now
 			database.DB.Save(&existingRequest)
 
-			fmt.Printf("[Recommendations] Sent reminder for existing request ID: %s\n", existingRequest.ID.String())
+			slog.InfoContext(c.Request.Context(), "sent reminder for existing recommendation request", "component", "recommendations",
+				"recommendation_request_id", existingRequest.ID)
 			c.JSON(http.StatusOK, gin.H{
 				"message":     "Reminder sent successfully",
 				"request":     existingRequest,
@@ -1894,26 +1840,31 @@ func CreateRecommendationRequest(c *gin.Context) {
 
 	// Debug: Log the field config to see what we're getting
 	configJSON, _ := json.Marshal(fieldConfig)
-	fmt.Printf("[Recommendations] Field config: %s\n", string(configJSON))
-	fmt.Printf("[Recommendations] DeadlineType: '%s', FixedDeadline: '%s'\n", fieldConfig.DeadlineType, fieldConfig.FixedDeadline)
+	slog.DebugContext(c.Request.Context(), "recommendation field config", "component", "recommendations", "config", string(configJSON))
+	slog.DebugContext(c.Request.Context(), "recommendation deadline settings", "component", "recommendations",
+		"deadline_type", fieldConfig.DeadlineType, "fixed_deadline", fieldConfig.FixedDeadline)
 
 	if fieldConfig.DeadlineType == "fixed" && fieldConfig.FixedDeadline != "" {
 		// Parse the fixed deadline (ISO format from datetime-local input)
 		// Handle both formats: with and without seconds
-		fmt.Printf("[Recommendations] Using fixed deadline: %s\n", fieldConfig.FixedDeadline)
+		slog.DebugContext(c.Request.Context(), "using fixed recommendation deadline", "component", "recommendations",
+			"fixed_deadline", fieldConfig.FixedDeadline)
 		parsed, err := time.Parse("2006-01-02T15:04", fieldConfig.FixedDeadline)
 		if err != nil {
-			fmt.Printf("[Recommendations] Failed to parse with format 2006-01-02T15:04: %v\n", err)
+			slog.DebugContext(c.Request.Context(), "fixed deadline is not in 2006-01-02T15:04 format", "component", "recommendations",
+				"error", err)
 			parsed, err = time.Parse("2006-01-02T15:04:05", fieldConfig.FixedDeadline)
 		}
 		if err == nil {
 			expiresAt = &parsed
-			fmt.Printf("[Recommendations] Parsed deadline: %v\n", expiresAt)
+			slog.DebugContext(c.Request.Context(), "parsed recommendation deadline", "component", "recommendations",
+				"expires_at", expiresAt)
 		} else {
-			fmt.Printf("[Recommendations] Failed to parse fixed deadline: %v\n", err)
+			slog.WarnContext(c.Request.Context(), "failed to parse fixed recommendation deadline", "component", "recommendations",
+				"error", err)
 		}
 	} else {
-		fmt.Printf("[Recommendations] Using relative deadline\n")
+		slog.DebugContext(c.Request.Context(), "using relative recommendation deadline", "component", "recommendations")
 		// Relative deadline (days from now)
 		deadlineDays := fieldConfig.DeadlineDays
 		if deadlineDays == 0 {
@@ -1947,12 +1898,15 @@ func CreateRecommendationRequest(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("[Recommendations] Created recommendation request ID: %s for email: %s\n", request.ID.String(), request.RecommenderEmail)
+	slog.InfoContext(c.Request.Context(), "created recommendation request", "component", "recommendations",
+		"recommendation_request_id", request.ID, "recommender_email", request.RecommenderEmail)
+	recordSubmissionEvent(c.Request.Context(), recommendationRequestedEvent(request))
 
-	// Send email asynchronously
+	// Send email asynchronously (keeping the request's correlation IDs for logs)
+	logCtx := logging.Detach(c.Request.Context())
 	go func() {
-		if err := sendRecommendationRequestEmail(&request, &submission, &form, &fieldConfig); err != nil {
-			fmt.Printf("[Recommendations] Email send failed: %v\n", err)
+		if err := sendRecommendationRequestEmail(logCtx, &request, &submission, &form, &fieldConfig); err != nil {
+			slog.ErrorContext(logCtx, "recommendation request email failed", "recommendation_request_id", request.ID, "error", err)
 		}
 	}()
 
@@ -1960,8 +1914,8 @@ func CreateRecommendationRequest(c *gin.Context) {
 }
 
 // sendRecommendationRequestEmail sends the recommendation request email via Gmail (or Resend fallback)
-func sendRecommendationRequestEmail(request *models.RecommendationRequest, submission *models.Row, form *models.Table, config *models.RecommendationFieldConfig) error {
-	fmt.Printf("[Recommendations] Starting to send email to: %s\n", request.RecommenderEmail)
+func sendRecommendationRequestEmail(ctx context.Context, request *models.RecommendationRequest, submission *models.Row, form *models.Table, config *models.RecommendationFieldConfig) error {
+	slog.DebugContext(ctx, "sending recommendation request email", "component", "recommendations", "to", request.RecommenderEmail)
 
 	applicantName, applicantEmail, err := resolveApplicantInfo(request.SubmissionID)
 	if err != nil {
@@ -2027,7 +1981,7 @@ func sendRecommendationRequestEmail(request *models.RecommendationRequest, submi
 	}
 	if customBody != "" {
 		// Use custom body with merge tags
-		body = applyRecommendationMergeTags(customBody, mergeData, submissionData, form.ID)
+		body = applyRecommendationMergeTags(ctx, customBody, mergeData, submissionData, form.ID, strings.Contains(customBody, "<"))
 
 		// Wrap plain text in clean HTML if it doesn't contain HTML tags
 		if !strings.Contains(body, "<") {
@@ -2036,7 +1990,7 @@ func sendRecommendationRequestEmail(request *models.RecommendationRequest, submi
 		}
 
 		// Also process subject merge tags
-		subject = applyRecommendationMergeTags(subject, mergeData, submissionData, form.ID)
+		subject = applyRecommendationMergeTags(ctx, subject, mergeData, submissionData, form.ID, false)
 	} else {
 		// Default template
 		logoURL := getFormLogoURL(form)
@@ -2051,7 +2005,7 @@ func sendRecommendationRequestEmail(request *models.RecommendationRequest, submi
 		if emailSettings, ok := formSettings["emailSettings"].(map[string]interface{}); ok {
 			if customSenderName, ok := emailSettings["senderName"].(string); ok && customSenderName != "" {
 				senderName = customSenderName
-				fmt.Printf("[Recommendations] Using custom sender name from settings: %s\n", senderName)
+				slog.DebugContext(ctx, "using custom sender name from settings", "component", "recommendations", "sender_name", senderName)
 			}
 		}
 	}
@@ -2066,12 +2020,13 @@ func sendRecommendationRequestEmail(request *models.RecommendationRequest, submi
 		if emailSettings, ok := formSettings["emailSettings"].(map[string]interface{}); ok {
 			if replyToEmail, ok := emailSettings["replyToEmail"].(string); ok && replyToEmail != "" {
 				replyTo = replyToEmail
-				fmt.Printf("[Recommendations] Using reply-to from settings: %s\n", replyTo)
+				slog.DebugContext(ctx, "using reply-to from settings", "component", "recommendations", "reply_to", replyTo)
 			}
 		}
 	}
 
-	fmt.Printf("[Recommendations] Sending email from: %s to: %s, subject: %s\n", fromEmail, request.RecommenderEmail, subject)
+	slog.DebugContext(ctx, "sending recommendation request email", "component", "recommendations",
+		"from", fromEmail, "to", request.RecommenderEmail, "subject", subject)
 
 	emailReq := services.EmailSendRequest{
 		WorkspaceID: form.WorkspaceID,
@@ -2088,22 +2043,28 @@ func sendRecommendationRequestEmail(request *models.RecommendationRequest, submi
 		TrackOpens:  true,
 	}
 
-	fmt.Printf("[Recommendations] Sending via EmailRouter (Gmail preferred)...\n")
+	slog.DebugContext(ctx, "sending via email router", "component", "recommendations")
 	router := services.NewEmailRouter()
 	result, err := router.SendEmail(context.Background(), emailReq)
 	if err != nil {
-		fmt.Printf("[Recommendations] ERROR: Failed to send email: %v\n", err)
+		slog.ErrorContext(ctx, "failed to send recommendation request email", "component", "recommendations", "error", err)
 		return err
 	}
 	if !result.Success {
-		fmt.Printf("[Recommendations] ERROR: Email sending failed: %s\n", result.ErrorMessage)
+		slog.ErrorContext(ctx, "recommendation request email failed", "component", "recommendations", "error", result.ErrorMessage)
 		return fmt.Errorf("email sending failed: %s", result.ErrorMessage)
 	}
 
-	fmt.Printf("[Recommendations] SUCCESS: Email sent to %s via %s\n", request.RecommenderEmail, result.ServiceType)
+	slog.InfoContext(ctx, "sent recommendation request email", "component", "recommendations",
+		"to", request.RecommenderEmail, "service_type", result.ServiceType)
 	return nil
 }
 
+// RecommendationResponseInput is a recommender's answers when submitted as JSON
+type RecommendationResponseInput struct {
+	Response map[string]interface{} `json:"response" binding:"required"`
+}
+
 // SubmitRecommendation handles the recommender submitting their recommendation
 func SubmitRecommendation(c *gin.Context) {
 	token := c.Param("token")
@@ -2200,9 +2161,7 @@ func SubmitRecommendation(c *gin.Context) {
 		}
 	} else {
 		// Handle regular JSON request
-		var input struct {
-			Response map[string]interface{} `json:"response" binding:"required"`
-		}
+		var input RecommendationResponseInput
 		if err := c.ShouldBindJSON(&input); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -2222,6 +2181,7 @@ func SubmitRecommendation(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save recommendation"})
 		return
 	}
+	recordSubmissionEvent(c.Request.Context(), recommendationReceivedEvent(request))
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Recommendation submitted successfully",
@@ -2229,14 +2189,17 @@ func SubmitRecommendation(c *gin.Context) {
 	})
 }
 
+// RecommendationReminderInput optionally customizes a reminder email
+type RecommendationReminderInput struct {
+	SenderAccountID *string `json:"sender_account_id,omitempty"`
+}
+
 // SendRecommendationReminder sends a reminder email
 func SendRecommendationReminder(c *gin.Context) {
 	id := c.Param("id")
 
 	// Parse request body for sender account ID
-	var requestBody struct {
-		SenderAccountID *string `json:"sender_account_id,omitempty"`
-	}
+	var requestBody RecommendationReminderInput
 	if err := c.ShouldBindJSON(&requestBody); err != nil {
 		// Ignore binding errors, sender account is optional
 	}
@@ -2296,11 +2259,12 @@ func SendRecommendationReminder(c *gin.Context) {
 		expiry := now.AddDate(0, 0, deadlineDays)
 		newExpiresAt = &expiry
 		request.ExpiresAt = newExpiresAt
-		fmt.Printf("[Recommendations] Extended expiration date to %v for reminder\n", newExpiresAt)
+		slog.InfoContext(c.Request.Context(), "extended recommendation request expiry for reminder", "component", "recommendations",
+			"expires_at", newExpiresAt)
 	}
 
 	// Send reminder email
-	if err := sendRecommendationReminderEmail(&request, &submission, &form, &fieldConfig, requestBody.SenderAccountID); err != nil {
+	if err := sendRecommendationReminderEmail(c.Request.Context(), &request, &submission, &form, &fieldConfig, requestBody.SenderAccountID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reminder email"})
 		return
 	}
@@ -2313,7 +2277,7 @@ func SendRecommendationReminder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Reminder sent successfully"})
 }
 
-func sendRecommendationReminderEmail(request *models.RecommendationRequest, submission *models.Row, form *models.Table, config *models.RecommendationFieldConfig, senderAccountID *string) error {
+func sendRecommendationReminderEmail(ctx context.Context, request *models.RecommendationRequest, submission *models.Row, form *models.Table, config *models.RecommendationFieldConfig, senderAccountID *string) error {
 	// Get workspace ID from form
 	workspaceID := form.WorkspaceID
 
@@ -2373,7 +2337,7 @@ func sendRecommendationReminderEmail(request *models.RecommendationRequest, subm
 			}
 		}
 
-		body = applyRecommendationMergeTags(body, mergeData, submissionData, form.ID)
+		body = applyRecommendationMergeTags(ctx, body, mergeData, submissionData, form.ID, strings.Contains(body, "<"))
 
 		if !strings.Contains(body, "<") {
 			body = buildRecommendationEmailHTML(request.RecommenderName, body, form.Name, logoURL, recommendationLink, deadline, true)
@@ -2381,7 +2345,7 @@ func sendRecommendationReminderEmail(request *models.RecommendationRequest, subm
 			body = buildRecommendationEmailHTML(request.RecommenderName, defaultMainText, form.Name, logoURL, recommendationLink, deadline, true)
 		}
 
-		subject = applyRecommendationMergeTags(subject, mergeData, submissionData, form.ID)
+		subject = applyRecommendationMergeTags(ctx, subject, mergeData, submissionData, form.ID, false)
 	}
 
 	// Determine sender email and name
@@ -2504,17 +2468,20 @@ func GetRecommendationsForReview(c *gin.Context) {
 	c.JSON(http.StatusOK, requests)
 }
 
+// TestRecommendationEmailInput describes a test recommendation email
+type TestRecommendationEmailInput struct {
+	ToEmail       string `json:"to_email" binding:"required"`
+	ToName        string `json:"to_name" binding:"required"`
+	ApplicantName string `json:"applicant_name"`
+	FormTitle     string `json:"form_title"`
+	LogoURL       string `json:"logo_url"`
+	WorkspaceID   string `json:"workspace_id"`
+	FormID        string `json:"form_id"`
+}
+
 // SendTestRecommendationEmail sends a test recommendation email without needing a real submission
 func SendTestRecommendationEmail(c *gin.Context) {
-	var input struct {
-		ToEmail       string `json:"to_email" binding:"required"`
-		ToName        string `json:"to_name" binding:"required"`
-		ApplicantName string `json:"applicant_name"`
-		FormTitle     string `json:"form_title"`
-		LogoURL       string `json:"logo_url"`
-		WorkspaceID   string `json:"workspace_id"`
-		FormID        string `json:"form_id"`
-	}
+	var input TestRecommendationEmailInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -2601,7 +2568,7 @@ func SendTestRecommendationEmail(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "RESEND_API_KEY not configured and no workspace_id provided"})
 		return
 	}
-	client := resend.NewClient(apiKey)
+	client := resend.NewCustomClient(tracing.HTTPClient("resend", 30*time.Second), apiKey)
 	params := &resend.SendEmailRequest{
 		From:    "Matic <hello@notifications.maticsapp.com>",
 		To:      []string{input.ToEmail},
@@ -1,17 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -59,9 +62,9 @@ func HandleResendWebhook(c *gin.Context) {
 	}
 
 	// Process the event
-	err = processResendEvent(event)
+	err = processResendEvent(c.Request.Context(), event)
 	if err != nil {
-		fmt.Printf("[Resend Webhook] Error processing event: %v\n", err)
+		slog.ErrorContext(c.Request.Context(), "failed to process resend webhook event", "component", "resend_webhook", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process event"})
 		return
 	}
@@ -70,7 +73,7 @@ func HandleResendWebhook(c *gin.Context) {
 }
 
 // processResendEvent processes a Resend webhook event
-func processResendEvent(event ResendWebhookEvent) error {
+func processResendEvent(ctx context.Context, event ResendWebhookEvent) error {
 	emailID := event.Data.EmailID
 	if emailID == "" {
 		return fmt.Errorf("email_id is required")
@@ -80,7 +83,7 @@ func processResendEvent(event ResendWebhookEvent) error {
 	var sentEmail models.SentEmail
 	if err := database.DB.Where("resend_message_id = ?", emailID).First(&sentEmail).Error; err != nil {
 		// Email not found - might be from a different workspace or not tracked
-		fmt.Printf("[Resend Webhook] Email not found for message_id: %s\n", emailID)
+		slog.WarnContext(ctx, "sent email not found for resend message", "component", "resend_webhook", "message_id", emailID)
 		return nil // Not an error - just log and continue
 	}
 
@@ -100,7 +103,7 @@ func processResendEvent(event ResendWebhookEvent) error {
 	case "email.delivery_delayed":
 		// Delivery was delayed (keep as sent, but could track separately)
 		// For now, just log it
-		fmt.Printf("[Resend Webhook] Delivery delayed for email: %s\n", emailID)
+		slog.InfoContext(ctx, "resend delivery delayed", "component", "resend_webhook", "message_id", emailID)
 		return nil
 
 	case "email.opened":
@@ -127,17 +130,38 @@ func processResendEvent(event ResendWebhookEvent) error {
 		sentEmail.BouncedAt = &bounceTime
 		sentEmail.BounceReason = event.Data.Reason
 		sentEmail.Status = "bounced"
-		return database.DB.Save(&sentEmail).Error
+		if err := database.DB.Save(&sentEmail).Error; err != nil {
+			return err
+		}
+		// Stop future sends to the address (soft bounces only once they repeat)
+		if err := services.RecordBounce(sentEmail, event.Data.BounceType, event.Data.Reason); err != nil {
+			slog.ErrorContext(ctx, "failed to record suppression for bounced email", "component", "resend_webhook",
+				"sent_email_id", sentEmail.ID, "error", err)
+		}
+		return nil
 
 	case "email.complained":
 		// Recipient marked as spam
 		// Similar to bounce - mark as failed
 		sentEmail.Status = "failed"
 		sentEmail.BounceReason = "Marked as spam"
-		return database.DB.Save(&sentEmail).Error
+		if err := database.DB.Save(&sentEmail).Error; err != nil {
+			return err
+		}
+		if _, err := services.AddSuppression(services.SuppressionInput{
+			WorkspaceID: sentEmail.WorkspaceID,
+			Email:       sentEmail.RecipientEmail,
+			Reason:      services.SuppressionComplaint,
+			Source:      "resend_webhook",
+			SentEmailID: &sentEmail.ID,
+		}); err != nil {
+			slog.ErrorContext(ctx, "failed to record suppression for complaint", "component", "resend_webhook",
+				"sent_email_id", sentEmail.ID, "error", err)
+		}
+		return nil
 
 	default:
-		fmt.Printf("[Resend Webhook] Unknown event type: %s\n", event.Type)
+		slog.DebugContext(ctx, "unknown resend webhook event type", "component", "resend_webhook", "event_type", event.Type)
 		return nil
 	}
 }
@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -60,6 +64,8 @@ func GetReviewExportData(c *gin.Context) {
 		CompletionPercentage int        `db:"completion_percentage"`
 		WorkflowID           *uuid.UUID `db:"workflow_id"`
 		AssignedReviewerID   *string    `db:"assigned_reviewer_id"`
+		CurrentStageID       *uuid.UUID `db:"current_stage_id"`
+		CurrentStage         *string    `db:"current_stage"`
 		CreatedAt            time.Time  `db:"created_at"`
 		UpdatedAt            time.Time  `db:"updated_at"`
 	}
@@ -84,11 +90,14 @@ func GetReviewExportData(c *gin.Context) {
 			fs.completion_percentage,
 			fs.workflow_id,
 			fs.assigned_reviewer_id,
+			fs.current_stage_id,
+			rs.name as current_stage,
 			fs.created_at,
 			fs.updated_at
 		`).
 		Joins("LEFT JOIN forms f ON f.id = fs.form_id").
 		Joins("LEFT JOIN ba_users u ON u.id = fs.user_id").
+		Joins("LEFT JOIN review_stages rs ON rs.id = fs.current_stage_id").
 		Where("f.workspace_id = ?", workspaceID).
 		Order("fs.created_at DESC")
 
@@ -136,7 +145,7 @@ func GetReviewExportData(c *gin.Context) {
 		Order("submission_id, created_at").
 		Find(&recommendations).Error; err != nil {
 		// Log error but don't fail the request
-		fmt.Printf("Warning: Failed to fetch recommendations: %v\n", err)
+		slog.WarnContext(c.Request.Context(), "failed to fetch recommendations", "error", err)
 	}
 
 	// Group recommendations by submission_id
@@ -157,6 +166,31 @@ func GetReviewExportData(c *gin.Context) {
 		recsBySubmission[rec.SubmissionID] = append(recsBySubmission[rec.SubmissionID], summary)
 	}
 
+	// Aggregate review scores and collect reviewer notes at each submission's current stage
+	submissionsByStage := make(map[uuid.UUID][]uuid.UUID)
+	for _, row := range rows {
+		if row.CurrentStageID != nil {
+			submissionsByStage[*row.CurrentStageID] = append(submissionsByStage[*row.CurrentStageID], row.SubmissionID)
+		}
+	}
+	reviewScores, reviewNotes := currentStageReviews(c.Request.Context(), submissionsByStage)
+
+	// Blind-review forms hide applicants from anyone who doesn't manage them
+	userID := requestUserID(c)
+	redactors := make(map[uuid.UUID]*services.BlindRedactor)
+	for _, row := range rows {
+		if _, ok := redactors[row.FormID]; ok {
+			continue
+		}
+		var form models.Form
+		if err := database.DB.Preload("Fields").First(&form, "id = ?", row.FormID).Error; err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to load form for blind review", "component", "review_export",
+				"form_id", row.FormID, "error", err)
+			continue
+		}
+		redactors[row.FormID] = blindRedactorFor(form, form.Fields, userID, "")
+	}
+
 	// Build the final result set
 	results := make([]models.ReviewSubmissionExport, 0, len(rows))
 	for _, row := range rows {
@@ -165,13 +199,21 @@ func GetReviewExportData(c *gin.Context) {
 		if len(row.FormData) > 0 && string(row.FormData) != "null" {
 			if err := json.Unmarshal(row.FormData, &formData); err != nil {
 				// Log error but continue with empty data
-				fmt.Printf("Warning: Failed to parse form_data for submission %s: %v\n", row.SubmissionID, err)
+				slog.WarnContext(c.Request.Context(), "failed to parse submission form_data",
+					"submission_id", row.SubmissionID, "error", err)
 				formData = make(map[string]interface{})
 			}
 		} else {
 			formData = make(map[string]interface{})
 		}
 
+		if redactor := redactors[row.FormID]; redactor != nil {
+			formData = redactor.RedactData(row.SubmissionID, formData)
+			row.ApplicantID = ""
+			row.ApplicantEmail = ""
+			row.ApplicantName = ""
+		}
+
 		// Get recommendations for this submission
 		recs := recsBySubmission[row.SubmissionID]
 
@@ -204,6 +246,9 @@ func GetReviewExportData(c *gin.Context) {
 			CompletionPercentage:     row.CompletionPercentage,
 			WorkflowID:               row.WorkflowID,
 			AssignedReviewerID:       row.AssignedReviewerID,
+			CurrentStage:             row.CurrentStage,
+			ReviewScore:              reviewScores[row.SubmissionID],
+			ReviewNotes:              reviewNotes[row.SubmissionID],
 			RecommendationsCount:     len(recs),
 			RecommendationsPending:   pendingCount,
 			RecommendationsSubmitted: submittedCount,
@@ -226,6 +271,53 @@ func GetReviewExportData(c *gin.Context) {
 	})
 }
 
+// currentStageReviews returns the aggregated score and the submitted
+// reviewer notes for each submission at the stage it is in
+func currentStageReviews(ctx context.Context, submissionsByStage map[uuid.UUID][]uuid.UUID) (map[uuid.UUID]*float64, map[uuid.UUID]*string) {
+	scores := make(map[uuid.UUID]*float64)
+	notes := make(map[uuid.UUID]*string)
+	if len(submissionsByStage) == 0 {
+		return scores, notes
+	}
+
+	stageIDs := make([]uuid.UUID, 0, len(submissionsByStage))
+	for stageID := range submissionsByStage {
+		stageIDs = append(stageIDs, stageID)
+	}
+	var stages []models.ReviewStage
+	if err := database.DB.Where("id IN ?", stageIDs).Find(&stages).Error; err != nil {
+		slog.ErrorContext(ctx, "failed to fetch review stages", "component", "review_export", "error", err)
+		return scores, notes
+	}
+
+	for _, stage := range stages {
+		submissionIDs := submissionsByStage[stage.ID]
+		aggregates, err := services.AggregateStageScores(database.DB, stage, submissionIDs)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to aggregate stage scores", "component", "review_export",
+				"stage_id", stage.ID, "error", err)
+			continue
+		}
+		for id, aggregate := range aggregates {
+			scores[id] = aggregate.Score
+		}
+
+		var sheets []models.ReviewScoreSheet
+		database.DB.Where("stage_id = ? AND submission_id IN ? AND status = ? AND notes IS NOT NULL AND notes <> ''",
+			stage.ID, submissionIDs, models.ScoreSheetSubmitted).
+			Order("submitted_at ASC").Find(&sheets)
+		collected := make(map[uuid.UUID][]string)
+		for _, sheet := range sheets {
+			collected[sheet.SubmissionID] = append(collected[sheet.SubmissionID], *sheet.Notes)
+		}
+		for id, parts := range collected {
+			joined := strings.Join(parts, "\n\n")
+			notes[id] = &joined
+		}
+	}
+	return scores, notes
+}
+
 // GetReviewExportCSV generates a CSV export of review data
 // This endpoint provides the same data as GetReviewExportData but formatted as CSV
 //
@@ -0,0 +1,1019 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ============================================
+// REVIEW PIPELINE HANDLERS
+// Stages, rubrics, reviewer queues and score sheets
+// ============================================
+
+// reviewManagerRoles may configure a form's pipeline, assign reviewers and
+// see every score; other workspace members only review what they're assigned
+var reviewManagerRoles = []string{"owner", "editor"}
+
+// ==================== RUBRICS ====================
+
+// ListReviewRubricsV2 lists a form's rubrics
+// GET /api/v2/forms/:id/rubrics
+func ListReviewRubricsV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+
+	var rubrics []models.ReviewRubric
+	if err := database.DB.Where("form_id = ?", form.ID).Order("created_at ASC").Find(&rubrics).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rubrics)
+}
+
+// CreateReviewRubricV2 creates a rubric of weighted criteria
+// POST /api/v2/forms/:id/rubrics
+func CreateReviewRubricV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name        string          `json:"name" binding:"required"`
+		Description *string         `json:"description"`
+		Criteria    json.RawMessage `json:"criteria"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := services.ParseRubricCriteria(datatypes.JSON(input.Criteria)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rubric := models.ReviewRubric{
+		FormID:      form.ID,
+		Name:        input.Name,
+		Description: input.Description,
+		Criteria:    datatypes.JSON(input.Criteria),
+	}
+	if len(input.Criteria) == 0 {
+		rubric.Criteria = datatypes.JSON("[]")
+	}
+	if err := database.DB.Create(&rubric).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rubric)
+}
+
+// UpdateReviewRubricV2 updates a rubric. Sheets already submitted keep the
+// totals they were scored with.
+// PATCH /api/v2/forms/:id/rubrics/:rubric_id
+func UpdateReviewRubricV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var rubric models.ReviewRubric
+	if err := database.DB.First(&rubric, "id = ? AND form_id = ?", c.Param("rubric_id"), form.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rubric not found"})
+		return
+	}
+
+	var input struct {
+		Name        *string         `json:"name"`
+		Description *string         `json:"description"`
+		Criteria    json.RawMessage `json:"criteria"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if input.Name != nil {
+		updates["name"] = *input.Name
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if input.Criteria != nil {
+		if _, err := services.ParseRubricCriteria(datatypes.JSON(input.Criteria)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["criteria"] = datatypes.JSON(input.Criteria)
+	}
+
+	if err := database.DB.Model(&rubric).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	database.DB.First(&rubric, "id = ?", rubric.ID)
+	c.JSON(http.StatusOK, rubric)
+}
+
+// DeleteReviewRubricV2 deletes a rubric; stages using it are left without one
+// DELETE /api/v2/forms/:id/rubrics/:rubric_id
+func DeleteReviewRubricV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND form_id = ?", c.Param("rubric_id"), form.ID).Delete(&models.ReviewRubric{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rubric not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rubric deleted"})
+}
+
+// ==================== STAGES ====================
+
+// reviewStageInput is the body for creating or updating a stage
+type reviewStageInput struct {
+	Name       *string         `json:"name"`
+	StageType  *string         `json:"stage_type"`
+	SortOrder  *int            `json:"sort_order"`
+	RubricID   *string         `json:"rubric_id"` // Empty string clears it
+	EntryRules json.RawMessage `json:"entry_rules"`
+	ExitRules  json.RawMessage `json:"exit_rules"`
+
+	AssignmentRules json.RawMessage `json:"assignment_rules"`
+}
+
+// apply copies the input onto stage, checking the stage type, rubric and
+// rules, and that the reviewer pool belongs to the workspace
+func (input reviewStageInput) apply(stage *models.ReviewStage, workspaceID uuid.UUID) error {
+	if input.Name != nil {
+		if strings.TrimSpace(*input.Name) == "" {
+			return errors.New("name is required")
+		}
+		stage.Name = *input.Name
+	}
+	if input.StageType != nil {
+		switch *input.StageType {
+		case models.ReviewStageScreening, models.ReviewStageCommittee, models.ReviewStageFinal:
+			stage.StageType = *input.StageType
+		default:
+			return errors.New("stage_type must be screening, committee or final")
+		}
+	}
+	if input.SortOrder != nil {
+		stage.SortOrder = *input.SortOrder
+	}
+	if input.RubricID != nil {
+		stage.RubricID = nil
+		if *input.RubricID != "" {
+			var rubric models.ReviewRubric
+			if err := database.DB.Select("id").First(&rubric, "id = ? AND form_id = ?", *input.RubricID, stage.FormID).Error; err != nil {
+				return errors.New("rubric not found on this form")
+			}
+			stage.RubricID = &rubric.ID
+		}
+	}
+	if input.EntryRules != nil {
+		stage.EntryRules = datatypes.JSON(input.EntryRules)
+	}
+	if input.ExitRules != nil {
+		stage.ExitRules = datatypes.JSON(input.ExitRules)
+	}
+	if input.AssignmentRules != nil {
+		stage.AssignmentRules = datatypes.JSON(input.AssignmentRules)
+		rules, err := services.ParseAssignmentRules(*stage)
+		if err != nil {
+			return err
+		}
+		for _, reviewerID := range rules.ReviewerIDs {
+			if _, ok := checkWorkspaceMembership(workspaceID, reviewerID); !ok {
+				return errors.New("reviewer " + reviewerID + " is not a member of this workspace")
+			}
+		}
+	}
+	_, exit, err := services.ParseStageRules(*stage)
+	if err != nil {
+		return err
+	}
+	// Sheets at a stage without a rubric have no score to compare
+	if exit.MinScore != nil && stage.RubricID == nil {
+		return errors.New("exit_rules.min_score needs a rubric on the stage")
+	}
+	return nil
+}
+
+// ListReviewStagesV2 lists a form's stages in pipeline order
+// GET /api/v2/forms/:id/review-stages
+func ListReviewStagesV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+
+	stages, err := services.ReviewStages(database.DB.Preload("Rubric"), form.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stages)
+}
+
+// CreateReviewStageV2 adds a stage to a form's pipeline
+// POST /api/v2/forms/:id/review-stages
+func CreateReviewStageV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var input reviewStageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Name == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	stage := models.ReviewStage{FormID: form.ID, StageType: models.ReviewStageScreening}
+	if input.SortOrder == nil {
+		var last int
+		database.DB.Model(&models.ReviewStage{}).Where("form_id = ?", form.ID).
+			Select("COALESCE(MAX(sort_order), -1)").Scan(&last)
+		stage.SortOrder = last + 1
+	}
+	if err := input.apply(&stage, form.WorkspaceID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&stage).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, stage)
+}
+
+// UpdateReviewStageV2 updates a stage's name, order, rubric or rules
+// PATCH /api/v2/forms/:id/review-stages/:stage_id
+func UpdateReviewStageV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var stage models.ReviewStage
+	if err := database.DB.First(&stage, "id = ? AND form_id = ?", c.Param("stage_id"), form.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stage not found"})
+		return
+	}
+
+	var input reviewStageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.apply(&stage, form.WorkspaceID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Omit(clause.Associations).Save(&stage).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stage)
+}
+
+// DeleteReviewStageV2 removes a stage. Submissions in it leave the pipeline
+// and its assignments and score sheets are deleted.
+// DELETE /api/v2/forms/:id/review-stages/:stage_id
+func DeleteReviewStageV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND form_id = ?", c.Param("stage_id"), form.ID).Delete(&models.ReviewStage{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stage not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Stage deleted"})
+}
+
+// RankedSubmission is a submission's score aggregate for a review stage
+type RankedSubmission struct {
+	SubmissionID uuid.UUID `json:"submission_id"`
+	services.ScoreAggregate
+}
+
+// GetReviewStageScoresV2 ranks the submissions currently in a stage by
+// their aggregated score
+// GET /api/v2/forms/:id/review-stages/:stage_id/scores
+func GetReviewStageScoresV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var stage models.ReviewStage
+	if err := database.DB.First(&stage, "id = ? AND form_id = ?", c.Param("stage_id"), form.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stage not found"})
+		return
+	}
+
+	var submissionIDs []uuid.UUID
+	if err := database.DB.Model(&models.FormSubmission{}).Where("current_stage_id = ?", stage.ID).
+		Pluck("id", &submissionIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	aggregates, err := services.AggregateStageScores(database.DB, stage, submissionIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ranked := make([]RankedSubmission, 0, len(submissionIDs))
+	for _, id := range submissionIDs {
+		ranked = append(ranked, RankedSubmission{SubmissionID: id, ScoreAggregate: aggregates[id]})
+	}
+	// Scored submissions first, highest score first
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Score, ranked[j].Score
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a > *b
+	})
+
+	c.JSON(http.StatusOK, gin.H{"stage": stage, "submissions": ranked})
+}
+
+// ==================== SUBMISSION REVIEW ====================
+
+// GetSubmissionReviewV2 gets a submission's stage, reviewers, score sheets
+// and aggregated score
+// GET /api/v2/submissions/:id/review
+func GetSubmissionReviewV2(c *gin.Context) {
+	submission, ok := loadReviewManagerSubmissionV2(c)
+	if !ok {
+		return
+	}
+	if submission.CurrentStageID == nil {
+		c.JSON(http.StatusOK, gin.H{"stage": nil, "assignments": []models.ReviewAssignment{}, "score_sheets": []models.ReviewScoreSheet{}})
+		return
+	}
+
+	var stage models.ReviewStage
+	if err := database.DB.Preload("Rubric").First(&stage, "id = ?", *submission.CurrentStageID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var assignments []models.ReviewAssignment
+	database.DB.Where("submission_id = ? AND stage_id = ?", submission.ID, stage.ID).Order("assigned_at ASC").Find(&assignments)
+	var sheets []models.ReviewScoreSheet
+	database.DB.Where("submission_id = ?", submission.ID).Order("created_at ASC").Find(&sheets)
+
+	aggregate, exitErr := services.CheckExitRules(database.DB, stage, submission.ID)
+	if exitErr != nil && !errors.Is(exitErr, services.ErrExitRulesNotMet) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": exitErr.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stage":        stage,
+		"assignments":  assignments,
+		"score_sheets": sheets,
+		"aggregate":    aggregate,
+		"can_advance":  exitErr == nil,
+	})
+}
+
+// AssignReviewersV2 adds reviewers to a submission at its current stage.
+// Reviewers must be members of the form's workspace; existing assignments
+// are left as they are. A declared conflict of interest refuses the
+// assignment unless override is set.
+// POST /api/v2/submissions/:id/review/assignments
+func AssignReviewersV2(c *gin.Context) {
+	submission, ok := loadReviewManagerSubmissionV2(c)
+	if !ok {
+		return
+	}
+	if submission.CurrentStageID == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrNotInReview.Error()})
+		return
+	}
+
+	var input struct {
+		ReviewerIDs []string `json:"reviewer_ids" binding:"required,min=1"`
+		Override    bool     `json:"override"` // Assign despite a declared conflict
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, reviewerID := range input.ReviewerIDs {
+		if _, ok := checkWorkspaceMembership(submission.Form.WorkspaceID, reviewerID); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reviewer " + reviewerID + " is not a member of this workspace"})
+			return
+		}
+	}
+
+	var stage models.ReviewStage
+	if err := database.DB.First(&stage, "id = ?", *submission.CurrentStageID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID := optionalString(requestUserID(c))
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, reviewerID := range input.ReviewerIDs {
+			if _, err := services.AssignReviewer(tx, submission, stage, reviewerID, actorID, input.Override); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	var assignments []models.ReviewAssignment
+	database.DB.Where("submission_id = ? AND stage_id = ?", submission.ID, stage.ID).Order("assigned_at ASC").Find(&assignments)
+	c.JSON(http.StatusOK, assignments)
+}
+
+// UnassignReviewerV2 removes a reviewer from a submission's current stage.
+// A score sheet they already submitted still counts.
+// DELETE /api/v2/submissions/:id/review/assignments/:reviewer_id
+func UnassignReviewerV2(c *gin.Context) {
+	submission, ok := loadReviewManagerSubmissionV2(c)
+	if !ok {
+		return
+	}
+	if submission.CurrentStageID == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrNotInReview.Error()})
+		return
+	}
+
+	var removed bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		removed, err = services.UnassignReviewer(tx, submission, *submission.CurrentStageID, c.Param("reviewer_id"), optionalString(requestUserID(c)))
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reviewer unassigned"})
+}
+
+// AdvanceSubmissionInput forces a submission past its stage's exit rules
+type AdvanceSubmissionInput struct {
+	Force bool `json:"force"`
+}
+
+// AdvanceSubmissionV2 moves a submission to the next stage that accepts it.
+// The current stage's exit rules must hold unless force is set.
+// POST /api/v2/submissions/:id/review/advance
+func AdvanceSubmissionV2(c *gin.Context) {
+	submission, ok := loadReviewManagerSubmissionV2(c)
+	if !ok {
+		return
+	}
+
+	var input AdvanceSubmissionInput
+	c.ShouldBindJSON(&input)
+
+	var next *models.ReviewStage
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		next, err = services.AdvanceSubmission(tx, submission.ID, input.Force)
+		return err
+	})
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stage": next})
+}
+
+// MoveSubmissionStageInput names the stage to move a submission to
+type MoveSubmissionStageInput struct {
+	StageID string `json:"stage_id" binding:"required"`
+}
+
+// MoveSubmissionStageV2 puts a submission in any stage of its form,
+// bypassing entry and exit rules
+// PUT /api/v2/submissions/:id/review/stage
+func MoveSubmissionStageV2(c *gin.Context) {
+	submission, ok := loadReviewManagerSubmissionV2(c)
+	if !ok {
+		return
+	}
+	if submission.SubmittedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only submitted submissions can be reviewed"})
+		return
+	}
+
+	var input MoveSubmissionStageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stageID, err := uuid.Parse(input.StageID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stage_id"})
+		return
+	}
+
+	var stage models.ReviewStage
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		stage, err = services.MoveSubmissionToStage(tx, &submission, stageID)
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stage not found"})
+		return
+	}
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stage": stage})
+}
+
+// ==================== ASSIGNMENT ====================
+
+// AutoAssignStageReviewersV2 runs the assignment engine over the
+// submissions in a stage (or the listed ones), filling open reviewer slots
+// from the stage's pool
+// POST /api/v2/forms/:id/review-stages/:stage_id/auto-assign
+func AutoAssignStageReviewersV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var stage models.ReviewStage
+	if err := database.DB.First(&stage, "id = ? AND form_id = ?", c.Param("stage_id"), form.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Stage not found"})
+		return
+	}
+
+	var input struct {
+		SubmissionIDs []uuid.UUID `json:"submission_ids"` // Defaults to every submission in the stage
+	}
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(input.SubmissionIDs) == 0 {
+		if err := database.DB.Model(&models.FormSubmission{}).Where("current_stage_id = ?", stage.ID).
+			Pluck("id", &input.SubmissionIDs).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var result services.AssignmentResult
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = services.AutoAssignReviewers(tx, stage, input.SubmissionIDs, optionalString(requestUserID(c)))
+		return err
+	})
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "auto-assigned reviewers", "component", "review_pipeline",
+		"stage_id", stage.ID, "assigned", result.Assigned, "unfilled", len(result.Unfilled))
+	c.JSON(http.StatusOK, result)
+}
+
+// DropReviewerV2 takes a reviewer off all their open assignments on a form
+// and hands each one to the next eligible reviewer in the stage's pool
+// POST /api/v2/forms/:id/reviewers/:reviewer_id/drop
+func DropReviewerV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	c.ShouldBindJSON(&input)
+	if input.Reason == "" {
+		input.Reason = "reviewer dropped out"
+	}
+
+	var result services.AssignmentResult
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = services.DeclineAssignments(tx, form.ID, c.Param("reviewer_id"), nil, optionalString(requestUserID(c)), input.Reason)
+		return err
+	})
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListReviewerConflictsV2 lists reviewers' declared conflicts of interest
+// GET /api/v2/forms/:id/reviewer-conflicts
+func ListReviewerConflictsV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	var conflicts []models.ReviewerConflict
+	if err := database.DB.Where("form_id = ?", form.ID).Order("created_at ASC").Find(&conflicts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, conflicts)
+}
+
+// SaveReviewerConflictV2 declares a reviewer's conflicts of interest:
+// their own values for the conflict fields and applicants to exclude.
+// Reviewers may declare their own; owners and editors anyone's.
+// PUT /api/v2/forms/:id/reviewer-conflicts/:reviewer_id
+func SaveReviewerConflictV2(c *gin.Context) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return
+	}
+	reviewerID := c.Param("reviewer_id")
+	if reviewerID != requestUserID(c) && !checkWorkspaceRole(form.WorkspaceID, requestUserID(c), reviewManagerRoles...) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only workspace owners and editors can declare another reviewer's conflicts"})
+		return
+	}
+
+	var input struct {
+		Attributes      map[string]interface{} `json:"attributes"`
+		ExcludedUserIDs []string               `json:"excluded_user_ids"`
+		Notes           *string                `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Attributes == nil {
+		input.Attributes = map[string]interface{}{}
+	}
+	if input.ExcludedUserIDs == nil {
+		input.ExcludedUserIDs = []string{}
+	}
+	attributes, _ := json.Marshal(input.Attributes)
+	excluded, _ := json.Marshal(input.ExcludedUserIDs)
+
+	conflict := models.ReviewerConflict{
+		FormID:          form.ID,
+		ReviewerID:      reviewerID,
+		Attributes:      datatypes.JSON(attributes),
+		ExcludedUserIDs: datatypes.JSON(excluded),
+		Notes:           input.Notes,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "form_id"}, {Name: "reviewer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"attributes", "excluded_user_ids", "notes", "updated_at"}),
+	}).Create(&conflict).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	database.DB.First(&conflict, "form_id = ? AND reviewer_id = ?", form.ID, reviewerID)
+	c.JSON(http.StatusOK, conflict)
+}
+
+// DeleteReviewerConflictV2 removes a reviewer's declaration
+// DELETE /api/v2/forms/:id/reviewer-conflicts/:reviewer_id
+func DeleteReviewerConflictV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("form_id = ? AND reviewer_id = ?", form.ID, c.Param("reviewer_id")).Delete(&models.ReviewerConflict{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conflict declaration not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conflict declaration deleted"})
+}
+
+// ListReviewAssignmentAuditsV2 lists assignment decisions, newest first
+// GET /api/v2/forms/:id/review-assignment-audits?submission_id=&reviewer_id=&action=
+func ListReviewAssignmentAuditsV2(c *gin.Context) {
+	form, ok := loadReviewManagerFormV2(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := database.DB.Where("form_id = ?", form.ID)
+	if submissionID := c.Query("submission_id"); submissionID != "" {
+		query = query.Where("submission_id = ?", submissionID)
+	}
+	if reviewerID := c.Query("reviewer_id"); reviewerID != "" {
+		query = query.Where("reviewer_id = ?", reviewerID)
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	var audits []models.ReviewAssignmentAudit
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&audits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, audits)
+}
+
+// ==================== REVIEWERS ====================
+
+// GetReviewQueueV2 lists the submissions waiting on the current user's
+// review. Only assignments at a submission's current stage are listed.
+// GET /api/v2/reviews/queue?form_id=&status=assigned
+func GetReviewQueueV2(c *gin.Context) {
+	reviewerID := requestUserID(c)
+	if reviewerID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	status := c.DefaultQuery("status", models.ReviewAssignmentAssigned)
+	query := database.DB.Table("review_assignments ra").
+		Select(`
+			ra.id AS assignment_id,
+			ra.status AS assignment_status,
+			ra.assigned_at,
+			fs.id AS submission_id,
+			fs.submitted_at,
+			fs.user_id AS applicant_id,
+			COALESCE(u.name, '') AS applicant_name,
+			f.id AS form_id,
+			f.name AS form_name,
+			rs.id AS stage_id,
+			rs.name AS stage_name,
+			rs.stage_type,
+			ss.status AS sheet_status,
+			ss.total_score
+		`).
+		Joins("JOIN form_submissions fs ON fs.id = ra.submission_id AND fs.current_stage_id = ra.stage_id").
+		Joins("JOIN forms f ON f.id = fs.form_id").
+		Joins("JOIN review_stages rs ON rs.id = ra.stage_id").
+		Joins("LEFT JOIN ba_users u ON u.id = fs.user_id").
+		Joins("LEFT JOIN review_score_sheets ss ON ss.submission_id = ra.submission_id AND ss.stage_id = ra.stage_id AND ss.reviewer_id = ra.reviewer_id").
+		Where("ra.reviewer_id = ?", reviewerID).
+		Order("ra.assigned_at ASC")
+	if status != "all" {
+		query = query.Where("ra.status = ?", status)
+	}
+	if formID := c.Query("form_id"); formID != "" {
+		query = query.Where("fs.form_id = ?", formID)
+	}
+
+	var queue []models.ReviewQueueItem
+	if err := query.Scan(&queue).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if queue == nil {
+		queue = []models.ReviewQueueItem{}
+	}
+	redactReviewQueue(queue, reviewerID)
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// SubmitReviewScoreInput is a reviewer's score sheet
+type SubmitReviewScoreInput struct {
+	Scores         map[string]float64 `json:"scores"`
+	Notes          *string            `json:"notes"`
+	Recommendation *string            `json:"recommendation"`
+	Submit         bool               `json:"submit"`
+}
+
+// SubmitReviewScoreV2 saves the current user's score sheet for a
+// submission at its current stage. With submit set every criterion must be
+// scored; the sheet then counts toward the stage's aggregate and may move
+// the submission on.
+// PUT /api/v2/submissions/:id/review/score
+func SubmitReviewScoreV2(c *gin.Context) {
+	reviewerID := requestUserID(c)
+	if reviewerID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid submission ID"})
+		return
+	}
+
+	var input SubmitReviewScoreInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var sheet models.ReviewScoreSheet
+	var next *models.ReviewStage
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		sheet, next, err = services.SaveScoreSheet(tx, submissionID, reviewerID, input.Scores, input.Notes, input.Recommendation, input.Submit)
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+	if next != nil {
+		slog.InfoContext(c.Request.Context(), "submission advanced", "component", "review_pipeline",
+			"submission_id", submissionID, "stage_id", next.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"score_sheet": sheet, "advanced_to": next})
+}
+
+// DeclineReviewInput gives the reviewer's reason for declining
+type DeclineReviewInput struct {
+	Reason string `json:"reason"`
+}
+
+// DeclineReviewV2 lets the current user step away from a submission they
+// were assigned, e.g. on discovering a conflict; the slot is refilled from
+// the stage's pool
+// POST /api/v2/submissions/:id/review/decline
+func DeclineReviewV2(c *gin.Context) {
+	reviewerID := requestUserID(c)
+	if reviewerID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+
+	var submission models.FormSubmission
+	if err := database.DB.First(&submission, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+
+	var input DeclineReviewInput
+	c.ShouldBindJSON(&input)
+	if input.Reason == "" {
+		input.Reason = "declined by reviewer"
+	}
+
+	var result services.AssignmentResult
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = services.DeclineAssignments(tx, submission.FormID, reviewerID, &submission.ID, &reviewerID, input.Reason)
+		return err
+	})
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+	if result.Declined == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have no open assignment for this submission"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review declined", "reassigned": result.Assigned > 0})
+}
+
+// ==================== HELPERS ====================
+
+// loadReviewManagerFormV2 loads the :id form for a workspace owner or
+// editor, writing the error response when it can't
+func loadReviewManagerFormV2(c *gin.Context) (models.Form, bool) {
+	form, ok := loadStaffFormV2(c)
+	if !ok {
+		return form, false
+	}
+	if !checkWorkspaceRole(form.WorkspaceID, requestUserID(c), reviewManagerRoles...) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only workspace owners and editors can manage reviews"})
+		return form, false
+	}
+	return form, true
+}
+
+// loadReviewManagerSubmissionV2 loads the :id submission, with its form,
+// for a workspace owner or editor
+func loadReviewManagerSubmissionV2(c *gin.Context) (models.FormSubmission, bool) {
+	var submission models.FormSubmission
+	if err := database.DB.Preload("Form").First(&submission, "id = ?", c.Param("id")).Error; err != nil || submission.Form == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return submission, false
+	}
+	if !checkWorkspaceRole(submission.Form.WorkspaceID, requestUserID(c), reviewManagerRoles...) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return submission, false
+	}
+	return submission, true
+}
+
+// isAssignedReviewer reports whether the user has ever been assigned to
+// review the submission
+func isAssignedReviewer(submissionID uuid.UUID, userID string) bool {
+	var count int64
+	database.DB.Model(&models.ReviewAssignment{}).
+		Where("submission_id = ? AND reviewer_id = ? AND status <> ?", submissionID, userID, models.ReviewAssignmentDeclined).
+		Count(&count)
+	return count > 0
+}
+
+// respondReviewError answers a refused review action with 403 (not
+// assigned), 409 (wrong pipeline state, conflict of interest) or 400 (bad
+// scores)
+func respondReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotAssigned):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotInReview), errors.Is(err, services.ErrExitRulesNotMet), errors.Is(err, services.ErrNoNextStage),
+		errors.Is(err, services.ErrReviewerConflict), errors.Is(err, services.ErrNoReviewerPool):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSheetIncomplete), errors.Is(err, services.ErrInvalidScore),
+		errors.Is(err, services.ErrStageNotOnForm), errors.Is(err, services.ErrUnknownAggregation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
@@ -597,6 +598,30 @@ func SearchFormSubmissions(c *gin.Context) {
 		fieldFilter = strings.Split(fieldsParam, ",")
 	}
 
+	// Blind review: reviewers can't search or see the hidden identity fields
+	var redactor *services.BlindRedactor
+	var v2Form models.Form
+	if err := database.DB.Preload("Fields").Where("legacy_table_id = ?", formUUID).First(&v2Form).Error; err == nil {
+		redactor = blindRedactorFor(v2Form, v2Form.Fields, requestUserID(c), "")
+	}
+	if redactor != nil && len(fieldFilter) > 0 {
+		hidden := make(map[string]bool)
+		for _, key := range redactor.HiddenKeys() {
+			hidden[key] = true
+		}
+		visible := fieldFilter[:0]
+		for _, field := range fieldFilter {
+			if !hidden[field] {
+				visible = append(visible, field)
+			}
+		}
+		if len(visible) == 0 {
+			c.JSON(http.StatusOK, SearchResponse{Results: []SearchResult{}, Query: query, Took: int(time.Since(startTime).Milliseconds())})
+			return
+		}
+		fieldFilter = visible
+	}
+
 	// Build query
 	dbQuery := database.DB.Where("table_id = ?", formUUID)
 
@@ -609,6 +634,9 @@ func SearchFormSubmissions(c *gin.Context) {
 			args = append(args, searchPattern)
 		}
 		dbQuery = dbQuery.Where(strings.Join(conditions, " OR "), args...)
+	} else if redactor != nil && len(redactor.HiddenKeys()) > 0 {
+		// Search in all data except the hidden fields
+		dbQuery = dbQuery.Where("LOWER((data - ARRAY[?]::text[])::text) LIKE ?", redactor.HiddenKeys(), searchPattern)
 	} else {
 		// Search in all data
 		dbQuery = dbQuery.Where("LOWER(data::text) LIKE ?", searchPattern)
@@ -621,11 +649,23 @@ func SearchFormSubmissions(c *gin.Context) {
 
 	dbQuery.Order("created_at DESC").Limit(limit).Find(&submissions)
 
+	// Redacted uploads are served from the v2 submission linked to each row
+	submissionByRow := make(map[uuid.UUID]uuid.UUID)
+	if redactor != nil && len(submissions) > 0 {
+		rowIDs := make([]uuid.UUID, len(submissions))
+		for i, submission := range submissions {
+			rowIDs[i] = submission.ID
+		}
+		var linked []models.FormSubmission
+		database.DB.Select("id", "legacy_row_id").Where("legacy_row_id IN ?", rowIDs).Find(&linked)
+		for _, sub := range linked {
+			submissionByRow[*sub.LegacyRowID] = sub.ID
+		}
+	}
+
 	// Convert to search results
 	var results []SearchResult
 	for _, submission := range submissions {
-		subtitle := extractRowPreview(submission.Data)
-
 		// Extract IP and UserAgent from data if available
 		var dataMap map[string]interface{}
 		json.Unmarshal(submission.Data, &dataMap)
@@ -638,9 +678,19 @@ func SearchFormSubmissions(c *gin.Context) {
 			userAgent = val
 		}
 
+		title := fmt.Sprintf("Submission from %s", ipAddress)
+		if redactor != nil {
+			redacted, _ := json.Marshal(redactor.RedactData(submissionByRow[submission.ID], dataMap))
+			submission.Data = datatypes.JSON(redacted)
+			title = "Submission"
+			ipAddress = ""
+			userAgent = ""
+		}
+		subtitle := extractRowPreview(submission.Data)
+
 		results = append(results, SearchResult{
 			ID:          submission.ID.String(),
-			Title:       fmt.Sprintf("Submission from %s", ipAddress),
+			Title:       title,
 			Subtitle:    subtitle,
 			Type:        "submission",
 			URL:         fmt.Sprintf("/workspace/%s/form/%s/submission/%s", workspace.Slug, form.ID, submission.ID),
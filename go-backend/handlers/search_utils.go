@@ -129,14 +129,17 @@ func GetRecentSearches(c *gin.Context) {
 	})
 }
 
+// SaveSearchHistoryInput is a search to remember for the user
+type SaveSearchHistoryInput struct {
+	WorkspaceID string `json:"workspace_id" binding:"required"`
+	Query       string `json:"query" binding:"required"`
+	ResultCount int    `json:"result_count"`
+	UserID      string `json:"user_id"`
+}
+
 // SaveSearchHistory saves a search query to history
 func SaveSearchHistory(c *gin.Context) {
-	var request struct {
-		WorkspaceID string `json:"workspace_id" binding:"required"`
-		Query       string `json:"query" binding:"required"`
-		ResultCount int    `json:"result_count"`
-		UserID      string `json:"user_id"`
-	}
+	var request SaveSearchHistoryInput
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -174,6 +177,12 @@ func SaveSearchHistory(c *gin.Context) {
 	})
 }
 
+// PopularSearch is a search query and how often it was run
+type PopularSearch struct {
+	Query string `json:"query"`
+	Count int    `json:"count"`
+}
+
 // GetPopularSearches returns most popular searches in a workspace
 func GetPopularSearches(c *gin.Context) {
 	workspaceID := c.Query("workspace_id")
@@ -197,12 +206,6 @@ func GetPopularSearches(c *gin.Context) {
 		return
 	}
 
-	// Get popular searches (grouped by query, counted)
-	type PopularSearch struct {
-		Query string `json:"query"`
-		Count int    `json:"count"`
-	}
-
 	var popularSearches []PopularSearch
 	database.DB.Raw(`
 		SELECT query, COUNT(*) as count 
@@ -106,7 +106,7 @@ func HybridSearch(c *gin.Context) {
 	// Generate query embedding if semantic search is enabled and service is available
 	var queryEmbedding []float32
 	if EmbeddingService != nil {
-		embedding, err := EmbeddingService.GenerateQueryEmbedding(req.Query)
+		embedding, err := EmbeddingService.GenerateQueryEmbeddingContext(c.Request.Context(), req.Query)
 		if err == nil {
 			queryEmbedding = embedding
 			usedSemantic = true
@@ -136,7 +136,7 @@ func HybridSearch(c *gin.Context) {
 
 	if queryEmbedding != nil {
 		// Use hybrid search with embeddings
-		rows, err := database.DB.Raw(`
+		rows, err := database.DB.WithContext(c.Request.Context()).Raw(`
 			SELECT * FROM hybrid_search($1, $2, $3::vector, $4::jsonb, $5, 0.4, 0.6)
 		`, workspaceUUID, req.Query, pgVectorString(queryEmbedding), string(filtersJSON), req.Limit).Rows()
 
@@ -159,7 +159,7 @@ func HybridSearch(c *gin.Context) {
 		}
 	} else {
 		// Fallback to smart_search (keyword + fuzzy)
-		rows, err := database.DB.Raw(`
+		rows, err := database.DB.WithContext(c.Request.Context()).Raw(`
 			SELECT * FROM smart_search($1, $2, $3::jsonb, $4)
 		`, workspaceUUID, req.Query, string(filtersJSON), req.Limit).Rows()
 
@@ -194,6 +194,17 @@ func HybridSearch(c *gin.Context) {
 	})
 }
 
+// SimilarItem is an entity found similar to another by embedding distance
+type SimilarItem struct {
+	EntityID   uuid.UUID              `json:"entity_id"`
+	EntityType string                 `json:"entity_type"`
+	TableID    *uuid.UUID             `json:"table_id"`
+	Title      string                 `json:"title"`
+	Subtitle   string                 `json:"subtitle"`
+	Similarity float64                `json:"similarity"`
+	Metadata   map[string]interface{} `json:"metadata"`
+}
+
 // FindSimilar finds similar items to a given entity
 func FindSimilar(c *gin.Context) {
 	entityID := c.Param("entity_id")
@@ -215,15 +226,7 @@ func FindSimilar(c *gin.Context) {
 		limit = l
 	}
 
-	var results []struct {
-		EntityID   uuid.UUID              `json:"entity_id"`
-		EntityType string                 `json:"entity_type"`
-		TableID    *uuid.UUID             `json:"table_id"`
-		Title      string                 `json:"title"`
-		Subtitle   string                 `json:"subtitle"`
-		Similarity float64                `json:"similarity"`
-		Metadata   map[string]interface{} `json:"metadata"`
-	}
+	var results []SimilarItem
 
 	database.DB.Raw(`
 		SELECT * FROM find_similar($1, $2, $3)
@@ -277,13 +280,16 @@ func GetEmbeddingStats(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"stats": stats})
 }
 
+// QueueEmbeddingInput names an entity to (re)generate embeddings for
+type QueueEmbeddingInput struct {
+	EntityID   string `json:"entity_id" binding:"required"`
+	EntityType string `json:"entity_type" binding:"required"`
+	Priority   int    `json:"priority"`
+}
+
 // QueueForEmbedding adds an item to the embedding queue
 func QueueForEmbedding(c *gin.Context) {
-	var req struct {
-		EntityID   string `json:"entity_id" binding:"required"`
-		EntityType string `json:"entity_type" binding:"required"`
-		Priority   int    `json:"priority"`
-	}
+	var req QueueEmbeddingInput
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
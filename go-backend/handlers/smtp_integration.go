@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SMTP Integration Management Endpoints
+// Lets a workspace relay email through its own SMTP server.
+
+// CreateSMTPIntegrationRequest creates or replaces a workspace's SMTP settings
+type CreateSMTPIntegrationRequest struct {
+	Host      string `json:"host" binding:"required"`
+	Port      int    `json:"port" binding:"required"`
+	Security  string `json:"security"` // starttls (default), tls, none
+	Username  string `json:"username"`
+	Password  string `json:"password"` // Keeps the stored password when empty on update
+	FromEmail string `json:"from_email" binding:"required"`
+	FromName  string `json:"from_name"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+// UpdateSMTPIntegrationRequest updates only the provided fields
+type UpdateSMTPIntegrationRequest struct {
+	Host      string  `json:"host"`
+	Port      int     `json:"port"`
+	Security  string  `json:"security"`
+	Username  *string `json:"username"`
+	Password  *string `json:"password"`
+	FromEmail string  `json:"from_email"`
+	FromName  *string `json:"from_name"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+// TestSMTPIntegrationRequest optionally sends a test email after the connection check
+type TestSMTPIntegrationRequest struct {
+	To string `json:"to"`
+}
+
+// GetSMTPIntegration returns the SMTP integration for a workspace
+func GetSMTPIntegration(c *gin.Context) {
+	workspaceID := c.Query("workspace_id")
+	if workspaceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	var integration models.SMTPIntegration
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&integration).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SMTP integration not configured"})
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+// CreateSMTPIntegration creates or updates the SMTP integration
+func CreateSMTPIntegration(c *gin.Context) {
+	wsUUID, err := uuid.Parse(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	var req CreateSMTPIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Security == "" {
+		req.Security = services.SMTPSecuritySTARTTLS
+	}
+
+	var integration models.SMTPIntegration
+	exists := database.DB.Where("workspace_id = ?", wsUUID).First(&integration).Error == nil
+	if !exists {
+		integration = models.SMTPIntegration{ID: uuid.New(), WorkspaceID: wsUUID}
+	}
+
+	integration.Host = strings.TrimSpace(req.Host)
+	integration.Port = req.Port
+	integration.Security = req.Security
+	integration.Username = req.Username
+	if req.Password != "" || !exists {
+		integration.Password = req.Password
+	}
+	integration.FromEmail = req.FromEmail
+	integration.FromName = req.FromName
+	integration.IsActive = req.IsActive == nil || *req.IsActive
+
+	if msg := validateSMTPIntegration(c.Request.Context(), integration); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if err := database.DB.Save(&integration).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save SMTP integration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+// UpdateSMTPIntegration updates an existing SMTP integration
+func UpdateSMTPIntegration(c *gin.Context) {
+	workspaceID := c.Query("workspace_id")
+	if workspaceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	var req UpdateSMTPIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var integration models.SMTPIntegration
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&integration).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SMTP integration not found"})
+		return
+	}
+
+	// Update only provided fields
+	if req.Host != "" {
+		integration.Host = strings.TrimSpace(req.Host)
+	}
+	if req.Port != 0 {
+		integration.Port = req.Port
+	}
+	if req.Security != "" {
+		integration.Security = req.Security
+	}
+	if req.Username != nil {
+		integration.Username = *req.Username
+	}
+	if req.Password != nil {
+		integration.Password = *req.Password
+	}
+	if req.FromEmail != "" {
+		integration.FromEmail = req.FromEmail
+	}
+	if req.FromName != nil {
+		integration.FromName = *req.FromName
+	}
+	if req.IsActive != nil {
+		integration.IsActive = *req.IsActive
+	}
+
+	if msg := validateSMTPIntegration(c.Request.Context(), integration); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if err := database.DB.Save(&integration).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update SMTP integration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, integration)
+}
+
+// DeleteSMTPIntegration deletes the SMTP integration
+func DeleteSMTPIntegration(c *gin.Context) {
+	wsUUID, err := uuid.Parse(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace_id"})
+		return
+	}
+
+	if err := database.DB.Where("workspace_id = ?", wsUUID).Delete(&models.SMTPIntegration{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete SMTP integration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// TestSMTPIntegration connects and authenticates against the SMTP server and,
+// when "to" is given, sends a test email. The result updates the SMTP entry in
+// the workspace's email service health so routing can use it. Private and
+// loopback hosts are refused unless SMTP_ALLOW_LOCAL_RELAYS is set.
+func TestSMTPIntegration(c *gin.Context) {
+	wsUUID, err := uuid.Parse(c.Query("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "workspace_id is required"})
+		return
+	}
+
+	var req TestSMTPIntegrationRequest
+	// Body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	var integration models.SMTPIntegration
+	if err := database.DB.Where("workspace_id = ?", wsUUID).First(&integration).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SMTP integration not configured"})
+		return
+	}
+	if !integration.IsActive {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "Integration is inactive",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	if req.To != "" {
+		_, err = services.SendSMTPEmail(ctx, integration, services.SMTPMessage{
+			To:      req.To,
+			Subject: "SMTP test email",
+			Body:    "This is a test email confirming your SMTP settings work.",
+		})
+	} else {
+		err = services.CheckSMTPConnection(ctx, integration)
+	}
+
+	if errors.Is(err, services.ErrSMTPHostNotAllowed) || errors.Is(err, services.ErrSMTPSecurityNotAllowed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	router := services.NewEmailRouter()
+	if err != nil {
+		router.UpdateServiceHealth(ctx, wsUUID, services.ServiceTypeSMTP, "down", err.Error())
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	router.UpdateServiceHealth(ctx, wsUUID, services.ServiceTypeSMTP, "healthy", "")
+
+	message := "Connected and authenticated"
+	if req.To != "" {
+		message = "Test email sent to " + req.To
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+	})
+}
+
+// validateSMTPIntegration returns a user-facing error for invalid settings
+func validateSMTPIntegration(ctx context.Context, integration models.SMTPIntegration) string {
+	if integration.Host == "" {
+		return "host is required"
+	}
+	if integration.Port < 1 || integration.Port > 65535 {
+		return "port must be between 1 and 65535"
+	}
+	if !services.IsValidSMTPSecurity(integration.Security) {
+		return "security must be one of starttls, tls, none"
+	}
+	if integration.Security == services.SMTPSecurityNone && !services.SMTPLocalRelaysAllowed() {
+		return services.ErrSMTPSecurityNotAllowed.Error()
+	}
+	if err := services.CheckSMTPRelayHost(ctx, integration.Host); err != nil {
+		return err.Error()
+	}
+	if integration.FromEmail == "" || !strings.Contains(integration.FromEmail, "@") {
+		return "a valid from_email is required"
+	}
+	return ""
+}
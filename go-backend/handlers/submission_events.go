@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListSubmissionEventsV2 returns a submission's event log: everything to
+// workspace owners and editors, the applicant-visible events to the applicant
+// GET /api/v2/submissions/:id/events
+func ListSubmissionEventsV2(c *gin.Context) {
+	userID := requestUserID(c)
+
+	var submission models.FormSubmission
+	if err := database.DB.Preload("Form").First(&submission, "id = ?", c.Param("id")).Error; err != nil || submission.Form == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+
+	applicantOnly := true
+	switch {
+	case checkWorkspaceRole(submission.Form.WorkspaceID, userID, reviewManagerRoles...):
+		applicantOnly = false
+	case userID == "" || submission.UserID != userID:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	events, err := services.SubmissionTimeline(database.DB, services.LinkedSubmissionIDs(database.DB, submission.ID), applicantOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// recordSubmissionEvent logs an event outside any transaction. The event
+// log never fails the request that caused the event.
+func recordSubmissionEvent(ctx context.Context, event models.SubmissionEvent) {
+	if err := services.RecordSubmissionEvent(database.DB, event); err != nil {
+		slog.ErrorContext(ctx, "failed to record submission event", "component", "submission_events",
+			"event_type", event.EventType, "submission_id", event.SubmissionID, "error", err)
+	}
+}
+
+// startedEvent is the event for an applicant starting an application
+func startedEvent(submissionID, formID uuid.UUID, at time.Time) models.SubmissionEvent {
+	return models.SubmissionEvent{
+		SubmissionID: submissionID,
+		FormID:       formID,
+		EventType:    models.SubmissionEventStarted,
+		Title:        "Application started",
+		DedupeKey:    services.EventDedupeKey(models.SubmissionEventStarted, submissionID),
+		OccurredAt:   at,
+	}
+}
+
+// submittedEvent is the event for an application being submitted
+func submittedEvent(submissionID, formID uuid.UUID, at time.Time) models.SubmissionEvent {
+	return models.SubmissionEvent{
+		SubmissionID: submissionID,
+		FormID:       formID,
+		EventType:    models.SubmissionEventSubmitted,
+		Title:        "Application submitted",
+		DedupeKey:    services.EventDedupeKey(models.SubmissionEventSubmitted, submissionID, at.Unix()),
+		OccurredAt:   at,
+	}
+}
+
+// recommendationRequestedEvent is the event for a recommendation request
+// going out to a recommender
+func recommendationRequestedEvent(request models.RecommendationRequest) models.SubmissionEvent {
+	return models.SubmissionEvent{
+		SubmissionID: request.SubmissionID,
+		FormID:       request.FormID,
+		EventType:    models.SubmissionEventRecommendationRequested,
+		Title:        "Recommendation requested",
+		Content:      request.RecommenderName,
+		Metadata:     services.EventMetadata(map[string]interface{}{"request_id": request.ID, "field_id": request.FieldID}),
+		DedupeKey:    services.EventDedupeKey(models.SubmissionEventRecommendationRequested, request.ID),
+		OccurredAt:   request.CreatedAt,
+	}
+}
+
+// recommendationReceivedEvent is the event for a recommender submitting
+// their letter
+func recommendationReceivedEvent(request models.RecommendationRequest) models.SubmissionEvent {
+	event := models.SubmissionEvent{
+		SubmissionID: request.SubmissionID,
+		FormID:       request.FormID,
+		EventType:    models.SubmissionEventRecommendationReceived,
+		Title:        "Recommendation received",
+		Content:      request.RecommenderName,
+		Metadata:     services.EventMetadata(map[string]interface{}{"request_id": request.ID, "field_id": request.FieldID}),
+		DedupeKey:    services.EventDedupeKey(models.SubmissionEventRecommendationReceived, request.ID),
+	}
+	if request.SubmittedAt != nil {
+		event.OccurredAt = *request.SubmittedAt
+	}
+	return event
+}
@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/Jsanchez767/matic-platform/database"
@@ -66,8 +66,8 @@ func CreateTableLink(c *gin.Context) {
 	}
 
 	// Debug logging
-	fmt.Printf("🔗 Creating table link: source_table=%s, source_column=%s, target_table=%s, link_type=%s\n",
-		input.SourceTableID, input.SourceColumnID, input.TargetTableID, input.LinkType)
+	slog.InfoContext(c.Request.Context(), "creating table link",
+		"source_table_id", input.SourceTableID, "source_column_id", input.SourceColumnID, "target_table_id", input.TargetTableID, "link_type", input.LinkType)
 
 	// Validate link type
 	if input.LinkType != "one_to_many" && input.LinkType != "many_to_many" {
@@ -186,6 +186,13 @@ func DeleteTableLink(c *gin.Context) {
 
 // Table Row Links Handlers - for managing actual row-to-row connections
 
+// LinkedRowResponse is a linked row with its link metadata
+type LinkedRowResponse struct {
+	Row      models.Row             `json:"row"`
+	LinkData map[string]interface{} `json:"link_data"`
+	LinkID   uuid.UUID              `json:"row_link_id"`
+}
+
 // GetLinkedRows - Get all rows linked to a specific row
 func GetLinkedRows(c *gin.Context) {
 	rowID := c.Param("row_id")
@@ -205,7 +212,7 @@ func GetLinkedRows(c *gin.Context) {
 		return
 	}
 
-	fmt.Printf("🔗 GetLinkedRows: Found %d row links for row %s with link %s\n", len(rowLinks), rowID, linkID)
+	slog.DebugContext(c.Request.Context(), "found row links", "count", len(rowLinks), "row_id", rowID, "link_id", linkID)
 
 	// Get the linked row IDs and create a map for quick lookup
 	linkedRowMap := make(map[uuid.UUID]models.TableRowLink)
@@ -231,13 +238,6 @@ func GetLinkedRows(c *gin.Context) {
 		}
 	}
 
-	// Return rows with their link metadata
-	type LinkedRowResponse struct {
-		Row      models.Row             `json:"row"`
-		LinkData map[string]interface{} `json:"link_data"`
-		LinkID   uuid.UUID              `json:"row_link_id"`
-	}
-
 	response := make([]LinkedRowResponse, 0)
 	for _, row := range rows {
 		// Find the corresponding row link
@@ -60,6 +60,14 @@ func ListWorkspaces(c *gin.Context) {
 	c.JSON(http.StatusOK, workspaces)
 }
 
+// WorkspacesInitResponse is what the app loads on start: the user's workspaces and the active one
+type WorkspacesInitResponse struct {
+	Workspaces        []models.Workspace `json:"workspaces"`
+	ActiveWorkspaceID *uuid.UUID         `json:"active_workspace_id"`
+	ActiveWorkspace   *models.Workspace  `json:"active_workspace"`
+	OrganizationID    *uuid.UUID         `json:"organization_id"`
+}
+
 // GetWorkspacesInit returns all data needed for workspace page initialization
 // Optimized endpoint that combines workspaces, organizations, and active workspace data
 // in a single response to eliminate waterfall requests
@@ -72,18 +80,11 @@ func GetWorkspacesInit(c *gin.Context) {
 		return
 	}
 
-	type WorkspacesInitResponse struct {
-		Workspaces        []models.Workspace `json:"workspaces"`
-		ActiveWorkspaceID *uuid.UUID         `json:"active_workspace_id"`
-		ActiveWorkspace   *models.Workspace  `json:"active_workspace"`
-		OrganizationID    *uuid.UUID         `json:"organization_id"`
-	}
-
 	var response WorkspacesInitResponse
 
 	// Get all user's workspaces with members (optimized with Preload)
 	var workspaces []models.Workspace
-	if err := database.DB.
+	if err := database.DB.WithContext(c.Request.Context()).
 		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
 		Where("(workspace_members.user_id::text = ? OR workspace_members.ba_user_id = ?) AND workspace_members.status = ? AND workspaces.is_archived = ?", userID, userID, "active", false).
 		Preload("Members", func(db *gorm.DB) *gorm.DB {
@@ -686,6 +687,21 @@ func RemoveWorkspaceMember(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
 }
 
+// MemberWithAuth is a workspace member with their Better Auth profile
+type MemberWithAuth struct {
+	ID                string  `json:"id"`
+	WorkspaceID       string  `json:"workspace_id"`
+	BAUserID          *string `json:"ba_user_id"`
+	Role              string  `json:"role"`
+	CreatedAt         string  `json:"created_at"`
+	UpdatedAt         string  `json:"updated_at"`
+	UserName          *string `json:"user_name"`
+	UserEmail         string  `json:"user_email"`
+	UserImage         *string `json:"user_image"`
+	UserEmailVerified bool    `json:"user_email_verified"`
+	UserCreatedAt     string  `json:"user_created_at"`
+}
+
 // GetWorkspaceMembersWithAuth returns workspace members with Better Auth user data
 func GetWorkspaceMembersWithAuth(c *gin.Context) {
 	workspaceID := c.Param("id")
@@ -714,20 +730,6 @@ func GetWorkspaceMembersWithAuth(c *gin.Context) {
 		return
 	}
 
-	type MemberWithAuth struct {
-		ID                string  `json:"id"`
-		WorkspaceID       string  `json:"workspace_id"`
-		BAUserID          *string `json:"ba_user_id"`
-		Role              string  `json:"role"`
-		CreatedAt         string  `json:"created_at"`
-		UpdatedAt         string  `json:"updated_at"`
-		UserName          *string `json:"user_name"`
-		UserEmail         string  `json:"user_email"`
-		UserImage         *string `json:"user_image"`
-		UserEmailVerified bool    `json:"user_email_verified"`
-		UserCreatedAt     string  `json:"user_created_at"`
-	}
-
 	var members []MemberWithAuth
 	err = database.DB.Raw(`
 		SELECT 
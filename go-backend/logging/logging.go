@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Structured logging built on log/slog.
+//
+// Init installs the handler as the slog default, which also routes the
+// standard library log package through it, so existing log.Printf calls come
+// out as structured records. Records logged with a context (slog.InfoContext
+// and friends) pick up request_id, workspace_id and trace_id automatically.
+// Secrets and PII are redacted from messages and attributes before writing.
+
+// Init configures the default logger. level is debug, info, warn or error;
+// format is json (default) or text.
+func Init(level, format string) {
+	slog.SetDefault(slog.New(NewHandler(os.Stdout, level, format)))
+	// slog.SetDefault already bridges the log package; drop its own timestamp
+	// since the record carries one
+	log.SetFlags(0)
+}
+
+// NewHandler builds the redacting, context-aware handler used by Init
+func NewHandler(w io.Writer, level, format string) slog.Handler {
+	opts := &slog.HandlerOptions{
+		Level:       ParseLevel(level),
+		ReplaceAttr: redactAttr,
+	}
+
+	var base slog.Handler
+	if strings.EqualFold(format, "text") {
+		base = slog.NewTextHandler(w, opts)
+	} else {
+		base = slog.NewJSONHandler(w, opts)
+	}
+	return &contextHandler{Handler: base}
+}
+
+// ParseLevel converts a LOG_LEVEL value, falling back to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// contextHandler adds correlation IDs from the context and redacts the message
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		record.AddAttrs(a)
+		return true
+	})
+
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			record.AddAttrs(slog.String("request_id", id))
+		}
+		if id := WorkspaceID(ctx); id != "" {
+			record.AddAttrs(slog.String("workspace_id", id))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			record.AddAttrs(
+				slog.String("trace_id", sc.TraceID().String()),
+				slog.String("span_id", sc.SpanID().String()),
+			)
+		}
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// ==================== Context ====================
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	workspaceIDKey
+)
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithWorkspaceID returns a context carrying the workspace ID
+func WithWorkspaceID(ctx context.Context, workspaceID string) context.Context {
+	return context.WithValue(ctx, workspaceIDKey, workspaceID)
+}
+
+// WorkspaceID returns the workspace ID carried by ctx, if any
+func WorkspaceID(ctx context.Context) string {
+	id, _ := ctx.Value(workspaceIDKey).(string)
+	return id
+}
+
+// Detach copies the correlation IDs (and span) of ctx onto a fresh background
+// context, for goroutines that must outlive the request
+func Detach(ctx context.Context) context.Context {
+	detached := trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+	if id := RequestID(ctx); id != "" {
+		detached = WithRequestID(detached, id)
+	}
+	if id := WorkspaceID(ctx); id != "" {
+		detached = WithWorkspaceID(detached, id)
+	}
+	return detached
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never logged
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"passwd":        true,
+	"secret":        true,
+	"client_secret": true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"api_key":       true,
+	"apikey":        true,
+	"private_key":   true,
+	"authorization": true,
+	"cookie":        true,
+	"set_cookie":    true,
+	"credentials":   true,
+}
+
+var (
+	emailPattern    = regexp.MustCompile(`([A-Za-z0-9._%+\-])[A-Za-z0-9._%+\-]*@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+	bearerPattern   = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern      = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`)
+	keyValuePattern = regexp.MustCompile(`(?i)\b(password|passwd|secret|client_secret|token|access_token|refresh_token|api_key|apikey)(["']?\s*[:=]\s*["']?)[^\s&"',}]+`)
+)
+
+// Redact masks email addresses, bearer tokens, JWTs and key=value secrets in s
+func Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	s = keyValuePattern.ReplaceAllString(s, "${1}${2}"+redacted)
+	s = emailPattern.ReplaceAllString(s, "${1}***@${2}")
+	return s
+}
+
+// MaskEmail keeps the first character and domain of an address (j***@example.com)
+func MaskEmail(email string) string {
+	return emailPattern.ReplaceAllString(email, "${1}***@${2}")
+}
+
+// isSensitiveKey matches keys like "api_key", "X-Api-Key" or "user.password"
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	key = strings.NewReplacer("-", "_", ".", "_").Replace(key)
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, suffix := range []string{"_password", "_secret", "_token", "_api_key", "_apikey"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr is the slog ReplaceAttr hook
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if value := a.Value.String(); value != "" {
+			return slog.String(a.Key, Redact(value))
+		}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok && err != nil {
+			return slog.String(a.Key, Redact(err.Error()))
+		}
+	}
+	return a
+}
@@ -10,8 +10,10 @@ import (
 	"github.com/Jsanchez767/matic-platform/config"
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/handlers"
+	"github.com/Jsanchez767/matic-platform/logging"
 	"github.com/Jsanchez767/matic-platform/router"
 	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/Jsanchez767/matic-platform/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -25,11 +27,26 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Structured logging (also captures log.Printf output)
+	logging.Init(cfg.LogLevel, cfg.LogFormat)
+
 	// Set Gin mode
 	if cfg.GinMode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Initialize tracing before anything creates HTTP clients or DB callbacks
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Options{
+		Exporter:    cfg.TracingExporter,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		log.Printf("⚠️  Tracing disabled: %v", err)
+	} else {
+		defer shutdownTracing(context.Background())
+	}
+
 	// Initialize database with direct PostgreSQL connection (IPv4 enabled)
 	if err := database.InitDB(cfg.DatabaseURL); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -60,6 +77,16 @@ func main() {
 	emailQueueWorker.Start(ctx)
 	log.Println("📧 Email queue worker started")
 
+	// Poll Gmail threads for replies to emails sent to submissions
+	if services.ReplyPollingEnabled() {
+		services.NewReplyPoller().Start(ctx)
+		log.Println("📥 Email reply poller started")
+	}
+
+	// Release scheduled decisions to applicants
+	services.NewDecisionReleaser().Start(ctx)
+	log.Println("📣 Decision release worker started")
+
 	// Setup router
 	r := router.SetupRouter(cfg)
 
@@ -72,6 +99,7 @@ func main() {
 	log.Printf("🚀 Server starting on port %s", port)
 	log.Printf("� API: http://localhost:%s/api/v1", port)
 	log.Printf("❤️  Health: http://localhost:%s/health", port)
+	log.Printf("📈 Metrics: http://localhost:%s/metrics", port)
 
 	if err := r.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
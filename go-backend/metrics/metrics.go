@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Prometheus metrics exposed on /metrics.
+// All metrics are registered on the default registry (which also carries the
+// Go runtime and process collectors).
+
+const namespace = "matic"
+
+var (
+	// ==================== HTTP ====================
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route"})
+
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	// ==================== Job Processor ====================
+
+	JobQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_queue_depth",
+		Help:      "Jobs waiting in the in-memory job processor queue.",
+	})
+
+	JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Background job execution time by job type and outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8), // 10ms .. ~164s
+	}, []string{"type", "status"})
+
+	JobsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_total",
+		Help:      "Background jobs processed by job type and outcome (completed, retrying, failed, dropped).",
+	}, []string{"type", "status"})
+
+	// ==================== Email Queue ====================
+
+	EmailQueueItemsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "email_queue_items_total",
+		Help:      "Email queue items processed by outcome (sent, failed, retrying) and service.",
+	}, []string{"status", "service"})
+
+	// ==================== Outbound Providers ====================
+
+	ProviderRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_request_duration_seconds",
+		Help:      "Latency of outbound calls to external providers (cohere, gemini, ...).",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"provider"})
+
+	ProviderRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_request_errors_total",
+		Help:      "Outbound provider calls that failed (transport error or HTTP status >= 400).",
+	}, []string{"provider", "reason"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		HTTPRequestDuration,
+		HTTPRequestsTotal,
+		JobQueueDepth,
+		JobDuration,
+		JobsTotal,
+		EmailQueueItemsTotal,
+		ProviderRequestDuration,
+		ProviderRequestErrors,
+	)
+}
+
+// RegisterDBStats exposes connection pool statistics (open, in-use, idle,
+// wait count/duration) for the Postgres pool
+func RegisterDBStats(db *sql.DB) {
+	if err := prometheus.Register(collectors.NewDBStatsCollector(db, "postgres")); err != nil {
+		log.Printf("⚠️  Failed to register DB stats collector: %v", err)
+	}
+}
+
+// ==================== Provider Transport ====================
+
+// providerTransport records latency and errors for every request sent
+// through an http.Client
+type providerTransport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+// InstrumentTransport wraps base (or http.DefaultTransport when nil) so calls
+// to the named provider are recorded in the provider_* metrics
+func InstrumentTransport(provider string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &providerTransport{provider: provider, base: base}
+}
+
+func (t *providerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	ProviderRequestDuration.WithLabelValues(t.provider).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		ProviderRequestErrors.WithLabelValues(t.provider, "transport").Inc()
+	} else if resp.StatusCode >= 400 {
+		ProviderRequestErrors.WithLabelValues(t.provider, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	return resp, err
+}
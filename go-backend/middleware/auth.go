@@ -1,8 +1,8 @@
 package middleware
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"log/slog"
 	"net/http"
 	"strings"
 	"sync"
@@ -66,7 +66,7 @@ func init() {
 
 // validateBetterAuthSessionToken checks if a token is a valid Better Auth session token
 // by looking it up in the ba_sessions table with caching for performance
-func validateBetterAuthSessionToken(token string) (*models.BetterAuthSession, bool) {
+func validateBetterAuthSessionToken(ctx context.Context, token string) (*models.BetterAuthSession, bool) {
 	// Check cache first (5 minute TTL matches Better Auth cookie cache)
 	if cached, ok := sessionCache.Load(token); ok {
 		cachedSession := cached.(*CachedSession)
@@ -89,7 +89,7 @@ func validateBetterAuthSessionToken(token string) (*models.BetterAuthSession, bo
 
 	// Check if session is expired
 	if session.IsExpired() {
-		fmt.Println("⚠️ Better Auth session expired")
+		slog.DebugContext(ctx, "better auth session expired")
 		return nil, false
 	}
 
@@ -156,7 +156,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		// PRIMARY: Validate as Better Auth session token (database lookup)
 		// Session tokens are random strings stored in ba_sessions table (not JWTs)
 		// This is the most common case for Better Auth
-		if session, valid := validateBetterAuthSessionToken(tokenString); valid {
+		if session, valid := validateBetterAuthSessionToken(c.Request.Context(), tokenString); valid {
 			c.Set("user_id", session.UserID)
 			c.Set("userID", session.UserID)
 			c.Set("user_email", session.User.Email)
@@ -225,7 +225,7 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// First, try to validate as a Better Auth session token (database lookup)
-		if session, valid := validateBetterAuthSessionToken(tokenString); valid {
+		if session, valid := validateBetterAuthSessionToken(c.Request.Context(), tokenString); valid {
 			c.Set("user_id", session.UserID)
 			c.Set("userID", session.UserID)
 			c.Set("user_email", session.User.Email)
@@ -295,7 +295,7 @@ func GetUserEmail(c *gin.Context) (string, bool) {
 	return e, ok
 }
 
-// DebugTokenMiddleware logs token information (use only in development)
+// DebugTokenMiddleware logs which token a request carries (use only in development)
 func DebugTokenMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -304,9 +304,12 @@ func DebugTokenMiddleware() gin.HandlerFunc {
 			if len(parts) == 2 {
 				// Decode token without validation to see claims
 				token, _, _ := new(jwt.Parser).ParseUnverified(parts[1], &BetterAuthClaims{})
-				if claims, ok := token.Claims.(*BetterAuthClaims); ok {
-					claimsJSON, _ := json.MarshalIndent(claims, "", "  ")
-					fmt.Printf("🔐 Token Claims:\n%s\n", string(claimsJSON))
+				if token != nil {
+					if claims, ok := token.Claims.(*BetterAuthClaims); ok {
+						// Identifiers only; the claims also carry the user's email and name
+						slog.DebugContext(c.Request.Context(), "token claims", "sub", claims.Sub, "iss", claims.Iss,
+							"exp", claims.Exp, "org_id", claims.OrgId)
+					}
 				}
 			}
 		}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/Jsanchez767/matic-platform/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is accepted from upstream proxies and echoed on every response
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware assigns each request an ID (reusing a well-formed
+// X-Request-ID from the caller) and puts it, together with the workspace ID
+// when the request names one, into the request context so services and
+// queued work log with the same correlation IDs
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := logging.WithRequestID(c.Request.Context(), requestID)
+		if workspaceID := workspaceIDFromRequest(c); workspaceID != "" {
+			ctx = logging.WithWorkspaceID(ctx, workspaceID)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// RecoveryMiddleware logs panics as structured errors instead of gin's plain
+// text stack dump
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "panic recovered",
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"panic", recovered,
+			"stack", string(debug.Stack()),
+		)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	})
+}
+
+// validRequestID rejects empty, oversized or non-token IDs so callers can't
+// inject arbitrary content into logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// workspaceIDFromRequest finds the workspace a request targets, if it names one
+func workspaceIDFromRequest(c *gin.Context) string {
+	if id := c.Param("workspace_id"); id != "" {
+		return id
+	}
+	if strings.HasPrefix(c.FullPath(), "/api/v1/workspaces/:id") {
+		return c.Param("id")
+	}
+	return c.Query("workspace_id")
+}
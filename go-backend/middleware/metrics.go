@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsMiddleware records request latency and status counts per route
+// template (c.FullPath(), e.g. /api/v1/forms/:id) so label cardinality stays
+// bounded regardless of IDs in the URL
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+		metrics.HTTPRequestsTotal.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}
+
+// MetricsHandler serves the Prometheus exposition format. When token is set,
+// scrapers must send "Authorization: Bearer <token>". In gin release mode the
+// token is required: without one the endpoint answers 404 so metrics are
+// never public in production.
+func MetricsHandler(token string) gin.HandlerFunc {
+	handler := promhttp.Handler()
+	if token == "" && gin.Mode() == gin.ReleaseMode {
+		slog.Warn("METRICS_TOKEN not set; /metrics is disabled in release mode")
+		return func(c *gin.Context) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		}
+	}
+	return func(c *gin.Context) {
+		if token != "" {
+			expected := "Bearer " + token
+			if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte(expected)) != 1 {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				return
+			}
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
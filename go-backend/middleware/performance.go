@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"strconv"
 	"time"
 
@@ -30,26 +30,25 @@ func PerformanceLoggingMiddleware(thresholdMs int) gin.HandlerFunc {
 				userID = "anonymous"
 			}
 
-			log.Printf(
-				"[SLOW REQUEST] method=%s path=%s duration=%dms user_id=%s status=%d",
-				c.Request.Method,
-				c.Request.URL.Path,
-				duration.Milliseconds(),
-				userID,
-				c.Writer.Status(),
+			slog.WarnContext(c.Request.Context(), "slow request",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"route", c.FullPath(),
+				"duration_ms", duration.Milliseconds(),
+				"user_id", userID,
+				"status", c.Writer.Status(),
 			)
+			return
 		}
 
-		// Always log for debugging (can be conditionally enabled)
-		if gin.Mode() == gin.DebugMode {
-			log.Printf(
-				"[REQUEST] method=%s path=%s duration=%dms status=%d",
-				c.Request.Method,
-				c.Request.URL.Path,
-				duration.Milliseconds(),
-				c.Writer.Status(),
-			)
-		}
+		// Access log at debug level (enable with LOG_LEVEL=debug)
+		slog.DebugContext(c.Request.Context(), "request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"duration_ms", duration.Milliseconds(),
+			"status", c.Writer.Status(),
+		)
 	}
 }
 
@@ -0,0 +1,54 @@
+-- ============================================
+-- Migration 052: Form Lifecycle Limits
+--
+-- Goals:
+--   1. Add opens_at and timezone to forms (scheduled open/close windows)
+--   2. Add submission_count to forms (atomic counter for max_submissions)
+--   3. Backfill submission_count from already-submitted submissions
+--   4. Replace the one-submission-per-user unique index; the backend now
+--      enforces it unless allow_multiple_submissions is set
+--   5. Create form_deadline_extensions (per-applicant late submission)
+-- ============================================
+
+-- 1. Schedule columns
+ALTER TABLE forms
+  ADD COLUMN IF NOT EXISTS opens_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS timezone TEXT DEFAULT 'UTC';
+
+COMMENT ON COLUMN forms.timezone IS 'IANA timezone used for schedule times entered without an offset';
+
+-- 2. Submission counter
+ALTER TABLE forms
+  ADD COLUMN IF NOT EXISTS submission_count INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN forms.submission_count IS 'Number of submits counted against max_submissions. Incremented atomically on submit.';
+
+-- 3. Backfill the counter
+UPDATE forms f
+SET submission_count = (
+  SELECT COUNT(*)
+  FROM form_submissions fs
+  WHERE fs.form_id = f.id
+    AND fs.submitted_at IS NOT NULL
+    AND fs.status != 'withdrawn'
+);
+
+-- 4. Allow multiple submissions per user where the form permits it
+DROP INDEX IF EXISTS idx_form_submissions_unique_user;
+
+CREATE INDEX IF NOT EXISTS idx_form_submissions_form_user
+  ON form_submissions (form_id, user_id);
+
+-- 5. Deadline extensions
+CREATE TABLE IF NOT EXISTS form_deadline_extensions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES ba_users(id) ON DELETE CASCADE,
+    extended_until TIMESTAMPTZ NOT NULL,
+    reason TEXT,
+    granted_by TEXT REFERENCES ba_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(form_id, user_id)
+);
@@ -0,0 +1,40 @@
+-- ============================================
+-- Migration 053: Published Form Versions
+--
+-- Goals:
+--   1. Create form_versions: an immutable snapshot of a form's sections,
+--      fields, options, logic, settings and layout per publish
+--   2. Submissions keep rendering and validating against the version in
+--      form_submissions.form_version after the live form is edited
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS form_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+
+    -- Snapshot
+    settings JSONB DEFAULT '{}'::jsonb,
+    layout JSONB DEFAULT '[]'::jsonb,
+    schema JSONB NOT NULL,
+
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_by TEXT REFERENCES ba_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(form_id, version)
+);
+
+COMMENT ON COLUMN form_versions.schema IS 'Sections and fields as {"sections": [...], "fields": [...]}. Never updated after insert.';
+
+-- Snapshots are immutable
+CREATE OR REPLACE FUNCTION prevent_form_version_update() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'form_versions rows are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS form_versions_immutable ON form_versions;
+CREATE TRIGGER form_versions_immutable
+  BEFORE UPDATE ON form_versions
+  FOR EACH ROW EXECUTE FUNCTION prevent_form_version_update();
@@ -0,0 +1,14 @@
+-- ============================================
+-- Migration 054: Server-Computed Responses
+--
+-- Goals:
+--   1. Record where each response value came from: the applicant (user),
+--      a prefill rule, or a server-side calculation. Calculated values are
+--      written only by the backend; client writes to them are ignored.
+-- ============================================
+
+ALTER TABLE form_responses
+  ADD COLUMN IF NOT EXISTS value_source TEXT DEFAULT 'user'
+  CHECK (value_source IN ('user', 'prefill', 'calculated'));
+
+COMMENT ON COLUMN form_responses.value_source IS 'user, prefill or calculated. Calculated values are authoritative and recomputed on every save and submit.';
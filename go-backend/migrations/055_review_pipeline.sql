@@ -0,0 +1,94 @@
+-- ============================================
+-- Migration 055: Review Pipeline
+--
+-- Goals:
+--   1. Create review_rubrics (weighted scoring criteria per form)
+--   2. Create review_stages (screening, committee, final, ...) with entry
+--      and exit rules
+--   3. Create review_assignments (a reviewer's queue per stage)
+--   4. Create review_score_sheets (one reviewer's scores per stage)
+--   5. Point form_submissions.current_stage_id at review_stages
+-- ============================================
+
+-- 1. Rubrics
+CREATE TABLE IF NOT EXISTS review_rubrics (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    criteria JSONB DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+COMMENT ON COLUMN review_rubrics.criteria IS 'Array of {"id", "label", "description", "weight", "min_score", "max_score"}';
+
+CREATE INDEX IF NOT EXISTS idx_review_rubrics_form ON review_rubrics (form_id);
+
+-- 2. Stages
+CREATE TABLE IF NOT EXISTS review_stages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    stage_type TEXT DEFAULT 'screening',
+    sort_order INTEGER DEFAULT 0,
+    rubric_id UUID REFERENCES review_rubrics(id) ON DELETE SET NULL,
+    entry_rules JSONB DEFAULT '{}'::jsonb,
+    exit_rules JSONB DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+COMMENT ON COLUMN review_stages.entry_rules IS '{"conditions": [...], "logic": "and", "min_previous_score": 70}';
+COMMENT ON COLUMN review_stages.exit_rules IS '{"min_reviews": 2, "aggregation": "mean|trimmed_mean|normalized", "min_score": 70, "auto_advance": true}';
+
+CREATE INDEX IF NOT EXISTS idx_review_stages_form ON review_stages (form_id, sort_order);
+
+-- 3. Assignments
+CREATE TABLE IF NOT EXISTS review_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL REFERENCES form_submissions(id) ON DELETE CASCADE,
+    stage_id UUID NOT NULL REFERENCES review_stages(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES ba_users(id) ON DELETE CASCADE,
+    status TEXT DEFAULT 'assigned' CHECK (status IN ('assigned', 'completed', 'declined')),
+    assigned_by TEXT REFERENCES ba_users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMPTZ DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(submission_id, stage_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_assignments_reviewer ON review_assignments (reviewer_id, status);
+
+-- 4. Score sheets
+CREATE TABLE IF NOT EXISTS review_score_sheets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL REFERENCES form_submissions(id) ON DELETE CASCADE,
+    stage_id UUID NOT NULL REFERENCES review_stages(id) ON DELETE CASCADE,
+    rubric_id UUID REFERENCES review_rubrics(id) ON DELETE SET NULL,
+    reviewer_id TEXT NOT NULL REFERENCES ba_users(id) ON DELETE CASCADE,
+    scores JSONB DEFAULT '{}'::jsonb,
+    total_score NUMERIC,
+    notes TEXT,
+    recommendation TEXT,
+    status TEXT DEFAULT 'draft' CHECK (status IN ('draft', 'submitted')),
+    submitted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(submission_id, stage_id, reviewer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_score_sheets_stage ON review_score_sheets (stage_id, status);
+
+-- 5. Current stage
+ALTER TABLE form_submissions
+  DROP CONSTRAINT IF EXISTS form_submissions_current_stage_id_fkey;
+
+ALTER TABLE form_submissions
+  ADD CONSTRAINT form_submissions_current_stage_id_fkey
+  FOREIGN KEY (current_stage_id) REFERENCES review_stages(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_form_submissions_current_stage ON form_submissions (current_stage_id);
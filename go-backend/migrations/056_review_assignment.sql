@@ -0,0 +1,50 @@
+-- ============================================
+-- Migration 056: Automatic Reviewer Assignment
+--
+-- Goals:
+--   1. Add assignment_rules to review_stages (reviewer pool, reviewers per
+--      submission, load cap, conflict fields)
+--   2. Create reviewer_conflicts (declared conflicts of interest per form)
+--   3. Create review_assignment_audits (every assignment decision)
+-- ============================================
+
+-- 1. Assignment rules
+ALTER TABLE review_stages
+  ADD COLUMN IF NOT EXISTS assignment_rules JSONB DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN review_stages.assignment_rules IS '{"reviewer_ids": [...], "reviewers_per_submission": 2, "max_load": 40, "conflict_fields": ["school"], "auto_assign": true}';
+
+-- 2. Conflict declarations
+CREATE TABLE IF NOT EXISTS reviewer_conflicts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES ba_users(id) ON DELETE CASCADE,
+    attributes JSONB DEFAULT '{}'::jsonb,
+    excluded_user_ids JSONB DEFAULT '[]'::jsonb,
+    notes TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(form_id, reviewer_id)
+);
+
+COMMENT ON COLUMN reviewer_conflicts.attributes IS 'Field key -> the reviewer''s own value, e.g. {"school": "Lincoln High"}';
+
+-- 3. Audit trail
+CREATE TABLE IF NOT EXISTS review_assignment_audits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    submission_id UUID NOT NULL REFERENCES form_submissions(id) ON DELETE CASCADE,
+    stage_id UUID NOT NULL REFERENCES review_stages(id) ON DELETE CASCADE,
+    reviewer_id TEXT REFERENCES ba_users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    reason TEXT,
+    details JSONB DEFAULT '{}'::jsonb,
+    actor_id TEXT REFERENCES ba_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+COMMENT ON COLUMN review_assignment_audits.actor_id IS 'Staff member who made the decision; NULL when the assignment engine made it';
+
+CREATE INDEX IF NOT EXISTS idx_review_assignment_audits_form ON review_assignment_audits (form_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_review_assignment_audits_submission ON review_assignment_audits (submission_id);
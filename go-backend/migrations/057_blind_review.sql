@@ -0,0 +1,25 @@
+-- ============================================
+-- Migration 057: Blind Review
+--
+-- Goals:
+--   1. Create redacted_documents: PII-redacted copies of uploaded documents
+--      served to reviewers on forms with settings.blind_review enabled
+--
+-- Blind review itself is configured in forms.settings:
+--   {"blind_review": {"enabled": true, "hidden_fields": ["name", "email"], "redact_documents": true}}
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS redacted_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL REFERENCES form_submissions(id) ON DELETE CASCADE,
+    source_url TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    data BYTEA,
+    pii_count INTEGER DEFAULT 0,
+    pii_types JSONB DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+
+    UNIQUE(submission_id, source_url)
+);
+
+COMMENT ON TABLE redacted_documents IS 'Uploads with PII blacked out, served to blind reviewers in place of the original';
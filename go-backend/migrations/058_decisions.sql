@@ -0,0 +1,47 @@
+-- ============================================
+-- Migration 058: Decisions and Awards
+--
+-- Goals:
+--   1. Create award_pools (per-form award budgets)
+--   2. Create submission_decisions (accept / waitlist / reject with an
+--      award, released to the applicant on demand or at a scheduled time)
+-- ============================================
+
+-- 1. Award pools
+CREATE TABLE IF NOT EXISTS award_pools (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    budget NUMERIC(12,2) NOT NULL CHECK (budget >= 0),
+    currency TEXT DEFAULT 'USD',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_award_pools_form ON award_pools (form_id);
+
+-- 2. Decisions
+CREATE TABLE IF NOT EXISTS submission_decisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL UNIQUE REFERENCES form_submissions(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    decision TEXT NOT NULL CHECK (decision IN ('accepted', 'waitlisted', 'rejected')),
+    award_amount NUMERIC(12,2) CHECK (award_amount >= 0),
+    award_pool_id UUID REFERENCES award_pools(id) ON DELETE RESTRICT,
+    waitlist_rank INTEGER,
+    notes TEXT,
+    applicant_message TEXT,
+    decided_by TEXT REFERENCES ba_users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ DEFAULT NOW(),
+    promoted_at TIMESTAMPTZ,
+    release_at TIMESTAMPTZ,
+    released_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+COMMENT ON COLUMN submission_decisions.release_at IS 'When the decision release worker shows the decision to the applicant and sends decision emails';
+
+CREATE INDEX IF NOT EXISTS idx_submission_decisions_form ON submission_decisions (form_id, decision);
+CREATE INDEX IF NOT EXISTS idx_submission_decisions_pool ON submission_decisions (award_pool_id) WHERE decision = 'accepted';
+CREATE INDEX IF NOT EXISTS idx_submission_decisions_due ON submission_decisions (release_at) WHERE released_at IS NULL;
@@ -0,0 +1,45 @@
+-- ============================================
+-- Migration 059: Submission Event Log
+--
+-- Goals:
+--   1. Create submission_events: started, submitted, recommendation
+--      requested / received, document requested / uploaded, stage changed
+--      and decision released, each flagged applicant or internal
+--   2. Backfill started and submitted events for existing v2 submissions
+--
+-- submission_id holds a form_submissions ID or, for legacy applications, a
+-- table_rows ID (the same convention as recommendation_requests)
+-- ============================================
+
+-- 1. Event log
+CREATE TABLE IF NOT EXISTS submission_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    submission_id UUID NOT NULL,
+    form_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    visibility TEXT NOT NULL DEFAULT 'internal' CHECK (visibility IN ('applicant', 'internal')),
+    title TEXT NOT NULL,
+    content TEXT,
+    metadata JSONB DEFAULT '{}'::jsonb,
+    actor_id TEXT REFERENCES ba_users(id) ON DELETE SET NULL,
+    dedupe_key TEXT UNIQUE,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+COMMENT ON COLUMN submission_events.visibility IS 'applicant: shown on the portal timeline; internal: staff only';
+
+CREATE INDEX IF NOT EXISTS idx_submission_events_submission ON submission_events (submission_id, occurred_at);
+
+-- 2. Backfill
+INSERT INTO submission_events (submission_id, form_id, event_type, visibility, title, dedupe_key, occurred_at)
+SELECT id, form_id, 'started', 'applicant', 'Application started', 'started:' || id, started_at
+FROM form_submissions
+WHERE started_at IS NOT NULL
+ON CONFLICT (dedupe_key) DO NOTHING;
+
+INSERT INTO submission_events (submission_id, form_id, event_type, visibility, title, dedupe_key, occurred_at)
+SELECT id, form_id, 'submitted', 'applicant', 'Application submitted', 'submitted:' || id || ':' || EXTRACT(EPOCH FROM submitted_at)::bigint, submitted_at
+FROM form_submissions
+WHERE submitted_at IS NOT NULL
+ON CONFLICT (dedupe_key) DO NOTHING;
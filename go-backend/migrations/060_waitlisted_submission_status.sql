@@ -0,0 +1,16 @@
+-- ============================================
+-- Migration 060: Waitlisted Submission Status
+--
+-- Goals:
+--   1. Allow 'waitlisted' in form_submissions.status. Releasing a waitlist
+--      decision makes it the submission's status, which the original CHECK
+--      constraint rejected.
+-- ============================================
+
+-- 1. Widen the status constraint
+ALTER TABLE form_submissions
+  DROP CONSTRAINT IF EXISTS form_submissions_status_check;
+
+ALTER TABLE form_submissions
+  ADD CONSTRAINT form_submissions_status_check
+  CHECK (status IN ('draft', 'in_progress', 'submitted', 'under_review', 'accepted', 'waitlisted', 'rejected', 'withdrawn'));
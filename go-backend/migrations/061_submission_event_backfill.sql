@@ -0,0 +1,42 @@
+-- ============================================
+-- Migration 061: Submission Event Backfill
+--
+-- Goals:
+--   1. Backfill submitted events for legacy applications (table_rows of a
+--      form's table that no v2 submission already covers)
+--   2. Backfill events for existing portal messages, status updates and
+--      document requests, which the applicant timeline used to read from
+--      portal_activities directly
+--
+-- Safe to re-run: every event has a dedupe key
+-- ============================================
+
+-- 1. Legacy applications
+INSERT INTO submission_events (submission_id, form_id, event_type, visibility, title, dedupe_key, occurred_at)
+SELECT r.id, r.table_id, 'submitted', 'applicant', 'Application submitted',
+       'submitted:' || r.id || ':' || EXTRACT(EPOCH FROM r.created_at)::bigint, r.created_at
+FROM table_rows r
+WHERE (EXISTS (SELECT 1 FROM forms f WHERE f.legacy_table_id = r.table_id)
+       OR EXISTS (SELECT 1 FROM portal_activities a WHERE a.row_id = r.id))
+  AND NOT EXISTS (SELECT 1 FROM form_submissions fs WHERE fs.legacy_row_id = r.id AND fs.submitted_at IS NOT NULL)
+ON CONFLICT (dedupe_key) DO NOTHING;
+
+-- 2. Portal activities
+INSERT INTO submission_events (submission_id, form_id, event_type, visibility, title, content, metadata, dedupe_key, occurred_at)
+SELECT a.row_id,
+       a.form_id,
+       CASE a.activity_type WHEN 'file_request' THEN 'document_requested' ELSE a.activity_type END,
+       CASE WHEN a.visibility = 'internal' THEN 'internal' ELSE 'applicant' END,
+       CASE
+         WHEN a.activity_type = 'file_request' THEN 'Document requested'
+         WHEN a.activity_type = 'status_update' THEN 'Status updated'
+         WHEN a.applicant_id IS NOT NULL THEN 'You sent a message'
+         ELSE 'New message from staff'
+       END,
+       a.content,
+       jsonb_build_object('activity_id', a.id),
+       CASE a.activity_type WHEN 'file_request' THEN 'document_requested' ELSE a.activity_type END || ':' || a.id,
+       a.created_at
+FROM portal_activities a
+WHERE a.activity_type IN ('message', 'status_update', 'file_request')
+ON CONFLICT (dedupe_key) DO NOTHING;
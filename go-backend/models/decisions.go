@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Decision outcomes; released decisions become the submission's status
+const (
+	DecisionAccepted   = "accepted"
+	DecisionWaitlisted = "waitlisted"
+	DecisionRejected   = "rejected"
+)
+
+// AwardPool is a form's budget that accepted submissions' awards draw on
+type AwardPool struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FormID   uuid.UUID `gorm:"type:uuid;not null" json:"form_id"`
+	Name     string    `gorm:"not null" json:"name"`
+	Budget   float64   `gorm:"type:numeric(12,2);not null" json:"budget"`
+	Currency string    `gorm:"default:'USD'" json:"currency"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Computed from accepted decisions, not stored
+	Allocated float64 `gorm:"-" json:"allocated"`
+	Remaining float64 `gorm:"-" json:"remaining"`
+}
+
+func (AwardPool) TableName() string {
+	return "award_pools"
+}
+
+// SubmissionDecision is the outcome staff recorded for a submission. The
+// applicant sees nothing until it is released, either on demand or at
+// ReleaseAt.
+type SubmissionDecision struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SubmissionID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"submission_id"`
+	FormID       uuid.UUID  `gorm:"type:uuid;not null" json:"form_id"`
+	Decision     string     `gorm:"not null" json:"decision"` // accepted, waitlisted, rejected
+	AwardAmount  *float64   `gorm:"type:numeric(12,2)" json:"award_amount,omitempty"`
+	AwardPoolID  *uuid.UUID `gorm:"type:uuid" json:"award_pool_id,omitempty"`
+	WaitlistRank *int       `json:"waitlist_rank,omitempty"` // 1 is promoted first
+
+	Notes            *string `gorm:"type:text" json:"notes,omitempty"`             // Internal only
+	ApplicantMessage *string `gorm:"type:text" json:"applicant_message,omitempty"` // Shown in the portal and available to decision emails
+
+	DecidedBy  *string    `gorm:"type:text" json:"decided_by,omitempty"`
+	DecidedAt  time.Time  `json:"decided_at"`
+	PromotedAt *time.Time `json:"promoted_at,omitempty"` // Set when accepted off the waitlist
+	ReleaseAt  *time.Time `json:"release_at,omitempty"`  // Scheduled release; nil until scheduled
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relations
+	AwardPool *AwardPool `gorm:"foreignKey:AwardPoolID" json:"award_pool,omitempty"`
+}
+
+func (SubmissionDecision) TableName() string {
+	return "submission_decisions"
+}
+
+// PortalDecision is the part of a released decision the applicant sees
+type PortalDecision struct {
+	Decision    string    `json:"decision"`
+	AwardAmount *float64  `json:"award_amount,omitempty"`
+	Currency    string    `json:"currency,omitempty"`
+	Message     *string   `json:"message,omitempty"`
+	ReleasedAt  time.Time `json:"released_at"`
+}
@@ -41,10 +41,17 @@ type EmailCampaign struct {
 	BodyHTML            string         `gorm:"type:text" json:"body_html,omitempty"`
 	SenderEmail         string         `gorm:"not null" json:"sender_email"`
 	SenderName          string         `json:"sender_name,omitempty"`
-	Status              string         `gorm:"default:'draft'" json:"status"`       // draft, sending, sent, failed
+	Status              string         `gorm:"default:'draft'" json:"status"`       // draft, scheduled, sending, paused, cancelled, sent, failed
 	ServiceType         string         `gorm:"default:'gmail'" json:"service_type"` // gmail, resend
 	StaggerDelaySeconds int            `gorm:"default:0" json:"stagger_delay_seconds"`
 	ScheduledFor        *time.Time     `json:"scheduled_for,omitempty"`
+	QuietHoursStart     string         `json:"quiet_hours_start,omitempty"` // "21:00" in the recipient's timezone; no quiet hours when empty
+	QuietHoursEnd       string         `json:"quiet_hours_end,omitempty"`   // "08:00"
+	Timezone            string         `json:"timezone,omitempty"`          // IANA timezone for recipients without their own
+	WinnerMetric        string         `json:"winner_metric,omitempty"`     // A/B tests: open_rate, click_rate
+	TestEndsAt          *time.Time     `gorm:"index" json:"test_ends_at,omitempty"`
+	WinnerVariantID     *uuid.UUID     `gorm:"type:uuid" json:"winner_variant_id,omitempty"`
+	WinnerSelectedAt    *time.Time     `json:"winner_selected_at,omitempty"`
 	Metadata            datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"metadata,omitempty"`
 	SentAt              *time.Time     `json:"sent_at,omitempty"`
 	CreatedAt           time.Time      `gorm:"autoCreateTime" json:"created_at"`
@@ -60,10 +67,28 @@ func (e *EmailCampaign) TableName() string {
 	return "email_campaigns"
 }
 
+// EmailCampaignVariant is one subject/body version of an A/B tested campaign.
+// SplitPercent of the audience gets each variant; the rest get the winner.
+type EmailCampaignVariant struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CampaignID   uuid.UUID `gorm:"type:uuid;not null;index" json:"campaign_id"`
+	Label        string    `gorm:"not null" json:"label"` // A, B, ...
+	Subject      string    `gorm:"not null" json:"subject"`
+	Body         string    `gorm:"type:text;not null" json:"body"`
+	BodyHTML     string    `gorm:"type:text" json:"body_html,omitempty"`
+	SplitPercent int       `gorm:"not null" json:"split_percent"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (v *EmailCampaignVariant) TableName() string {
+	return "email_campaign_variants"
+}
+
 // SentEmail represents an individual sent email
 type SentEmail struct {
 	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
 	CampaignID      *uuid.UUID `gorm:"type:uuid;index" json:"campaign_id,omitempty"`
+	VariantID       *uuid.UUID `gorm:"type:uuid;index" json:"variant_id,omitempty"` // A/B test variant the recipient got
 	WorkspaceID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"workspace_id"`
 	FormID          *uuid.UUID `gorm:"type:uuid;index" json:"form_id,omitempty"`
 	SubmissionID    *uuid.UUID `gorm:"type:uuid;index" json:"submission_id,omitempty"`
@@ -78,7 +103,8 @@ type SentEmail struct {
 	GmailThreadID   string     `json:"gmail_thread_id,omitempty"`
 	ResendMessageID string     `json:"resend_message_id,omitempty"`
 	ResendEventID   string     `json:"resend_event_id,omitempty"`
-	ServiceType     string     `gorm:"default:'gmail'" json:"service_type"` // gmail, resend
+	SMTPMessageID   string     `json:"smtp_message_id,omitempty"`
+	ServiceType     string     `gorm:"default:'gmail'" json:"service_type"` // gmail, resend, smtp
 	TrackingID      string     `gorm:"uniqueIndex" json:"tracking_id"`
 	Status          string     `gorm:"default:'sent'" json:"status"` // sent, delivered, opened, clicked, bounced, failed
 	OpenedAt        *time.Time `json:"opened_at,omitempty"`
@@ -107,7 +133,7 @@ type EmailTemplate struct {
 	Body          string         `gorm:"type:text;not null" json:"body"`
 	BodyHTML      string         `gorm:"type:text" json:"body_html,omitempty"`
 	Type          string         `gorm:"default:'manual'" json:"type"` // manual, automated
-	TriggerOn     string         `json:"trigger_on,omitempty"`         // For automated: submission, approval, rejection
+	TriggerOn     string         `json:"trigger_on,omitempty"`         // For automated: submission, approval, rejection, waitlist
 	IsActive      bool           `gorm:"default:true" json:"is_active"`
 	Category      string         `json:"category,omitempty"`
 	UsageCount    int            `gorm:"default:0" json:"usage_count"`
@@ -169,6 +195,7 @@ type EmailQueueItem struct {
 	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
 	WorkspaceID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"workspace_id"`
 	CampaignID     *uuid.UUID `gorm:"type:uuid;index" json:"campaign_id,omitempty"`
+	VariantID      *uuid.UUID `gorm:"type:uuid" json:"variant_id,omitempty"`
 	RecipientEmail string     `gorm:"not null" json:"recipient_email"`
 	RecipientName  string     `json:"recipient_name,omitempty"`
 	Subject        string     `gorm:"not null" json:"subject"`
@@ -178,13 +205,22 @@ type EmailQueueItem struct {
 	SubmissionID   *uuid.UUID `gorm:"type:uuid;index" json:"submission_id,omitempty"`
 	FormID         *uuid.UUID `gorm:"type:uuid;index" json:"form_id,omitempty"`
 	ServiceType    string     `gorm:"default:'gmail'" json:"service_type"` // gmail, resend
+	Category       string     `json:"category,omitempty"`                  // reminders, announcements; empty for transactional
 	Priority       int        `gorm:"default:5" json:"priority"`           // 1-10
-	Status         string     `gorm:"default:'pending'" json:"status"`     // pending, processing, sent, failed, retrying
+	Status         string     `gorm:"default:'pending'" json:"status"`     // pending, scheduled, paused, held, processing, sent, failed, retrying, suppressed, cancelled
 	ScheduledFor   time.Time  `gorm:"index" json:"scheduled_for"`
+	RecipientTZ    string     `gorm:"column:recipient_timezone" json:"recipient_timezone,omitempty"` // IANA timezone used for quiet hours
+	TrackingID     string     `json:"tracking_id,omitempty"`                                         // Used for the sent email's open/click tracking
+	EmailType      string     `json:"email_type,omitempty"`                                          // Routing rule, e.g. applicant for automated template emails
+	DedupeKey      *string    `gorm:"uniqueIndex" json:"-"`                                          // Set by automated triggers so an event is only emailed once
+	TrackOpens     bool       `gorm:"default:false" json:"track_opens"`
+	TrackClicks    bool       `gorm:"default:false" json:"track_clicks"`
 	AttemptCount   int        `gorm:"default:0" json:"attempt_count"`
 	MaxAttempts    int        `gorm:"default:3" json:"max_attempts"`
 	ErrorMessage   string     `gorm:"type:text" json:"error_message,omitempty"`
 	SentAt         *time.Time `json:"sent_at,omitempty"`
+	TraceParent    string     `gorm:"type:text" json:"-"` // W3C traceparent of the request that queued the email
+	RequestID      string     `gorm:"type:text" json:"-"` // request ID of the request that queued the email
 	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -210,11 +246,47 @@ func (r *ResendIntegration) TableName() string {
 	return "resend_integrations"
 }
 
+// SMTPIntegration stores a workspace's own SMTP relay settings
+type SMTPIntegration struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"workspace_id"`
+	Host        string    `gorm:"not null" json:"host"`
+	Port        int       `gorm:"not null" json:"port"`
+	Security    string    `gorm:"not null;default:'starttls'" json:"security"` // starttls, tls, none
+	Username    string    `json:"username,omitempty"`
+	Password    string    `json:"-"` // Don't expose in JSON
+	FromEmail   string    `gorm:"not null" json:"from_email"`
+	FromName    string    `json:"from_name,omitempty"`
+	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (s *SMTPIntegration) TableName() string {
+	return "smtp_integrations"
+}
+
+// EmailRoutingPolicy overrides which providers a workspace's email goes
+// through. Rules map an email type (reminder, communication, ..., or
+// "default") to providers in the order they should be tried.
+type EmailRoutingPolicy struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"workspace_id"`
+	Rules       datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"rules"` // e.g. {"reminder": ["resend", "smtp"]}
+	UpdatedBy   string         `json:"updated_by,omitempty"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (p *EmailRoutingPolicy) TableName() string {
+	return "email_routing_policies"
+}
+
 // EmailServiceHealth tracks health status of email service providers
 type EmailServiceHealth struct {
 	ID            uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
 	WorkspaceID   uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_workspace_service" json:"workspace_id"`
-	ServiceType   string         `gorm:"not null;uniqueIndex:idx_workspace_service" json:"service_type"` // gmail, resend
+	ServiceType   string         `gorm:"not null;uniqueIndex:idx_workspace_service" json:"service_type"` // gmail, resend, smtp
 	Status        string         `gorm:"not null" json:"status"`                                         // healthy, degraded, down, unknown
 	LastCheckedAt time.Time      `gorm:"autoCreateTime" json:"last_checked_at"`
 	LastSuccessAt *time.Time     `json:"last_success_at,omitempty"`
@@ -229,3 +301,77 @@ type EmailServiceHealth struct {
 func (h *EmailServiceHealth) TableName() string {
 	return "email_service_health"
 }
+
+// EmailSuppression blocks future sends to an address within a workspace.
+// Fed by hard bounces, spam complaints and unsubscribe clicks.
+type EmailSuppression struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_suppression_workspace_email" json:"workspace_id"`
+	Email       string     `gorm:"not null;uniqueIndex:idx_suppression_workspace_email" json:"email"` // normalized (lowercase)
+	Reason      string     `gorm:"not null;index" json:"reason"`                                      // hard_bounce, repeated_bounce, complaint, unsubscribe, manual
+	Source      string     `json:"source,omitempty"`                                                  // resend_webhook, unsubscribe_link, admin
+	SentEmailID *uuid.UUID `gorm:"type:uuid" json:"sent_email_id,omitempty"`                          // Email that triggered the suppression
+	Details     string     `gorm:"type:text" json:"details,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (s *EmailSuppression) TableName() string {
+	return "email_suppressions"
+}
+
+// EmailPreference stores per-category opt-outs for a recipient in a workspace.
+// Unsubscribing from everything is recorded on the suppression list instead.
+type EmailPreference struct {
+	ID                 uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID        uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_email_preference_workspace_email" json:"workspace_id"`
+	Email              string         `gorm:"not null;uniqueIndex:idx_email_preference_workspace_email" json:"email"` // normalized (lowercase)
+	OptedOutCategories datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"opted_out_categories"`                    // e.g. ["reminders"]
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (p *EmailPreference) TableName() string {
+	return "email_preferences"
+}
+
+// EmailLink is a link in a sent email that was rewritten for click tracking.
+// The click endpoint only redirects to the URL stored here, never to a URL
+// taken from the request.
+type EmailLink struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	SentEmailID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_email_link_sent_email_link" json:"sent_email_id"`
+	LinkID         string     `gorm:"not null;uniqueIndex:idx_email_link_sent_email_link" json:"link_id"` // Position in the email body, e.g. "1"
+	CampaignID     *uuid.UUID `gorm:"type:uuid;index" json:"campaign_id,omitempty"`
+	WorkspaceID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	URL            string     `gorm:"type:text;not null" json:"url"`
+	ClickCount     int        `gorm:"default:0" json:"click_count"`
+	FirstClickedAt *time.Time `json:"first_clicked_at,omitempty"`
+	LastClickedAt  *time.Time `json:"last_clicked_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (l *EmailLink) TableName() string {
+	return "email_links"
+}
+
+// InboundEmail records a reply that was ingested into a submission's
+// activity, so the same message is never stored twice
+type InboundEmail struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	WorkspaceID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_inbound_email_message" json:"workspace_id"`
+	MessageID    string     `gorm:"not null;uniqueIndex:idx_inbound_email_message" json:"message_id"` // RFC 5322 Message-ID, or the Gmail message ID when missing
+	Source       string     `gorm:"not null" json:"source"`                                           // gmail, webhook
+	SentEmailID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"sent_email_id"`                    // The email that was replied to
+	SubmissionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"submission_id"`
+	ActivityID   *uuid.UUID `gorm:"type:uuid" json:"activity_id,omitempty"` // PortalActivity holding the reply
+	FromEmail    string     `json:"from_email"`
+	Subject      string     `json:"subject"`
+	ReceivedAt   time.Time  `json:"received_at"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (i *InboundEmail) TableName() string {
+	return "inbound_emails"
+}
@@ -36,10 +36,13 @@ type Form struct {
 	// Status
 	Status      string     `gorm:"default:'draft'" json:"status"` // draft, published, archived, closed
 	PublishedAt *time.Time `json:"published_at,omitempty"`
+	OpensAt     *time.Time `json:"opens_at,omitempty"`
 	ClosesAt    *time.Time `json:"closes_at,omitempty"`
+	Timezone    string     `gorm:"default:'UTC'" json:"timezone"` // IANA zone for schedule times entered without an offset
 
 	// Limits
 	MaxSubmissions           *int `json:"max_submissions,omitempty"`
+	SubmissionCount          int  `gorm:"default:0" json:"submission_count"` // Submits counted against MaxSubmissions
 	AllowMultipleSubmissions bool `gorm:"default:false" json:"allow_multiple_submissions"`
 	RequireAuth              bool `gorm:"default:true" json:"require_auth"`
 
@@ -60,6 +63,53 @@ func (Form) TableName() string {
 	return "forms"
 }
 
+// FormVersion is an immutable snapshot of a form's schema taken when it is
+// published. Submissions render and validate against the version they were
+// started on (FormSubmission.FormVersion).
+type FormVersion struct {
+	ID      uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FormID  uuid.UUID `gorm:"type:uuid;not null" json:"form_id"`
+	Version int       `gorm:"not null" json:"version"`
+
+	// Snapshot
+	Settings datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"settings"`
+	Layout   datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"layout"`
+	Schema   datatypes.JSON `gorm:"type:jsonb;not null" json:"schema"` // FormVersionSchema
+
+	PublishedAt time.Time `gorm:"not null" json:"published_at"`
+	PublishedBy *string   `gorm:"type:text" json:"published_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (FormVersion) TableName() string {
+	return "form_versions"
+}
+
+// FormVersionSchema is the sections and fields (with their options,
+// validation and logic) captured in a FormVersion
+type FormVersionSchema struct {
+	Sections []FormSection `json:"sections"`
+	Fields   []FormField   `json:"fields"`
+}
+
+// FormDeadlineExtension lets one applicant keep working on a form after it
+// closes
+type FormDeadlineExtension struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FormID        uuid.UUID `gorm:"type:uuid;not null" json:"form_id"`
+	UserID        string    `gorm:"type:text;not null" json:"user_id"` // TEXT to match ba_users.id
+	ExtendedUntil time.Time `gorm:"not null" json:"extended_until"`
+	Reason        *string   `json:"reason,omitempty"`
+	GrantedBy     *string   `gorm:"type:text" json:"granted_by,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (FormDeadlineExtension) TableName() string {
+	return "form_deadline_extensions"
+}
+
 // FormSettings represents the settings JSONB structure
 type FormSettings struct {
 	// Branding
@@ -90,6 +140,21 @@ type FormSettings struct {
 
 	// Rich text
 	EnableRichText bool `json:"enable_rich_text,omitempty"`
+
+	// Review
+	BlindReview *BlindReviewSettings `json:"blind_review,omitempty"`
+
+	// Portal timeline: submission event type -> applicant or internal,
+	// overriding DefaultEventVisibility
+	EventVisibility map[string]string `json:"event_visibility,omitempty"`
+}
+
+// BlindReviewSettings hide applicants' identity from reviewers. Workspace
+// owners and editors still see everything.
+type BlindReviewSettings struct {
+	Enabled         bool     `json:"enabled"`
+	HiddenFields    []string `json:"hidden_fields,omitempty"`    // Field keys, e.g. name, email, school
+	RedactDocuments bool     `json:"redact_documents,omitempty"` // Serve uploads with detected PII blacked out
 }
 
 // FormTheme defines visual styling
@@ -353,6 +418,7 @@ type FormSubmission struct {
 	// Review workflow integration
 	WorkflowID         *uuid.UUID `gorm:"type:uuid" json:"workflow_id,omitempty"`
 	AssignedReviewerID *string    `gorm:"type:text" json:"assigned_reviewer_id,omitempty"` // TEXT to match ba_users.id
+	CurrentStageID     *uuid.UUID `gorm:"type:uuid" json:"current_stage_id,omitempty"`     // ReviewStage; nil until submitted into the pipeline
 
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
@@ -362,6 +428,10 @@ type FormSubmission struct {
 	Form      *Form           `gorm:"foreignKey:FormID" json:"form,omitempty"`
 	User      *BetterAuthUser `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
 	Responses []FormResponse  `gorm:"foreignKey:SubmissionID" json:"responses,omitempty"`
+
+	// Validation problems found when the answers were last saved; saving
+	// still succeeds, only submitting is blocked
+	ValidationWarnings map[string][]string `gorm:"-" json:"validation_warnings,omitempty"`
 }
 
 func (FormSubmission) TableName() string {
@@ -385,6 +455,9 @@ type FormResponse struct {
 	// Which column has the data
 	ValueType string `gorm:"not null" json:"value_type"` // text, number, boolean, date, datetime, json
 
+	// Who set the value: user, prefill, or calculated (server-computed, clients can't overwrite)
+	ValueSource string `gorm:"default:'user'" json:"value_source"`
+
 	// Validation status
 	IsValid          bool           `gorm:"default:true" json:"is_valid"`
 	ValidationErrors datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"validation_errors"`
@@ -431,6 +504,16 @@ func (r *FormResponse) GetValue() any {
 	return nil
 }
 
+// ResetValue clears every typed value column before the response is set again
+func (r *FormResponse) ResetValue() {
+	r.ValueText = nil
+	r.ValueNumber = nil
+	r.ValueBoolean = nil
+	r.ValueDate = nil
+	r.ValueDatetime = nil
+	r.ValueJSON = nil
+}
+
 // SetValue sets the appropriate typed value based on field type
 func (r *FormResponse) SetValue(value any, fieldType string) {
 	// Determine value type from field type
@@ -0,0 +1,239 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Review stage types
+const (
+	ReviewStageScreening = "screening"
+	ReviewStageCommittee = "committee"
+	ReviewStageFinal     = "final"
+)
+
+// Score aggregation methods for a stage
+const (
+	AggregationMean        = "mean"
+	AggregationTrimmedMean = "trimmed_mean" // Drops the highest and lowest score when there are 3 or more
+	AggregationNormalized  = "normalized"   // Corrects for harsh and lenient reviewers using each reviewer's score distribution
+)
+
+// Reviewer assignment statuses
+const (
+	ReviewAssignmentAssigned  = "assigned"
+	ReviewAssignmentCompleted = "completed"
+	ReviewAssignmentDeclined  = "declined"
+)
+
+// Score sheet statuses
+const (
+	ScoreSheetDraft     = "draft"
+	ScoreSheetSubmitted = "submitted"
+)
+
+// ReviewStage is one step of a form's review pipeline. Submitted
+// submissions enter the first stage whose entry rules they meet and move on
+// once the stage's exit rules are met.
+type ReviewStage struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FormID    uuid.UUID  `gorm:"type:uuid;not null" json:"form_id"`
+	Name      string     `gorm:"not null" json:"name"`
+	StageType string     `gorm:"default:'screening'" json:"stage_type"` // screening, committee, final
+	SortOrder int        `gorm:"default:0" json:"sort_order"`
+	RubricID  *uuid.UUID `gorm:"type:uuid" json:"rubric_id,omitempty"`
+
+	EntryRules datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"entry_rules"` // ReviewEntryRules
+	ExitRules  datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"exit_rules"`  // ReviewExitRules
+
+	AssignmentRules datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"assignment_rules"` // ReviewAssignmentRules
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	Rubric *ReviewRubric `gorm:"foreignKey:RubricID" json:"rubric,omitempty"`
+}
+
+func (ReviewStage) TableName() string {
+	return "review_stages"
+}
+
+// ReviewEntryRules decide which submissions a stage takes. Conditions are
+// evaluated against the submission's answers; MinPreviousScore against the
+// aggregated score from the stage the submission is leaving.
+type ReviewEntryRules struct {
+	Conditions       []FieldCondition `json:"conditions,omitempty"`
+	Logic            string           `json:"logic,omitempty"` // and, or
+	MinPreviousScore *float64         `json:"min_previous_score,omitempty"`
+}
+
+// ReviewExitRules decide when a submission is done with a stage
+type ReviewExitRules struct {
+	MinReviews  int      `json:"min_reviews,omitempty"` // Submitted score sheets needed; defaults to 1
+	Aggregation string   `json:"aggregation,omitempty"` // mean, trimmed_mean, normalized; defaults to mean
+	MinScore    *float64 `json:"min_score,omitempty"`   // Aggregated score needed to move on
+	AutoAdvance bool     `json:"auto_advance,omitempty"`
+}
+
+// ReviewAssignmentRules drive automatic reviewer assignment at a stage
+type ReviewAssignmentRules struct {
+	ReviewerIDs            []string `json:"reviewer_ids,omitempty"`             // The pool; ba_users IDs
+	ReviewersPerSubmission int      `json:"reviewers_per_submission,omitempty"` // Defaults to 1
+	MaxLoad                int      `json:"max_load,omitempty"`                 // Open assignments a reviewer may hold; 0 = no cap
+	ConflictFields         []string `json:"conflict_fields,omitempty"`          // Field keys, e.g. "school", compared with reviewers' declarations
+	AutoAssign             bool     `json:"auto_assign,omitempty"`              // Assign as submissions enter the stage
+}
+
+// ReviewRubric is a set of weighted criteria reviewers score submissions on
+type ReviewRubric struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FormID      uuid.UUID      `gorm:"type:uuid;not null" json:"form_id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Description *string        `json:"description,omitempty"`
+	Criteria    datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"criteria"` // []RubricCriterion
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ReviewRubric) TableName() string {
+	return "review_rubrics"
+}
+
+// RubricCriterion is one scored line of a rubric. Weights are relative; a
+// sheet's total is the weighted average of its criteria scaled to 0-100.
+type RubricCriterion struct {
+	ID          string  `json:"id"`
+	Label       string  `json:"label"`
+	Description string  `json:"description,omitempty"`
+	Weight      float64 `json:"weight"`
+	MinScore    float64 `json:"min_score"`
+	MaxScore    float64 `json:"max_score"`
+}
+
+// ReviewAssignment puts a submission in a reviewer's queue for a stage
+type ReviewAssignment struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SubmissionID uuid.UUID  `gorm:"type:uuid;not null" json:"submission_id"`
+	StageID      uuid.UUID  `gorm:"type:uuid;not null" json:"stage_id"`
+	ReviewerID   string     `gorm:"type:text;not null" json:"reviewer_id"` // TEXT to match ba_users.id
+	Status       string     `gorm:"default:'assigned'" json:"status"`      // assigned, completed, declined
+	AssignedBy   *string    `gorm:"type:text" json:"assigned_by,omitempty"`
+	AssignedAt   time.Time  `gorm:"default:now()" json:"assigned_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ReviewAssignment) TableName() string {
+	return "review_assignments"
+}
+
+// ReviewerConflict is a reviewer's declared conflicts of interest for a
+// form: their own answers to the stages' conflict fields (e.g. their school)
+// and applicants they must never review
+type ReviewerConflict struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FormID          uuid.UUID      `gorm:"type:uuid;not null" json:"form_id"`
+	ReviewerID      string         `gorm:"type:text;not null" json:"reviewer_id"`            // TEXT to match ba_users.id
+	Attributes      datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"attributes"`        // Field key -> the reviewer's value
+	ExcludedUserIDs datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"excluded_user_ids"` // Applicant ba_users IDs
+	Notes           *string        `json:"notes,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ReviewerConflict) TableName() string {
+	return "reviewer_conflicts"
+}
+
+// Assignment audit actions
+const (
+	AssignmentAuditAssigned   = "assigned"
+	AssignmentAuditUnassigned = "unassigned"
+	AssignmentAuditDeclined   = "declined"
+	AssignmentAuditConflict   = "skipped_conflict" // Reviewer passed over for a conflict of interest
+	AssignmentAuditAtCapacity = "skipped_capacity" // Reviewer passed over at max_load
+	AssignmentAuditUnfilled   = "unfilled"         // Not enough eligible reviewers
+)
+
+// ReviewAssignmentAudit records one assignment decision, made by the
+// engine (ActorID nil) or by staff
+type ReviewAssignmentAudit struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	FormID       uuid.UUID      `gorm:"type:uuid;not null" json:"form_id"`
+	SubmissionID uuid.UUID      `gorm:"type:uuid;not null" json:"submission_id"`
+	StageID      uuid.UUID      `gorm:"type:uuid;not null" json:"stage_id"`
+	ReviewerID   *string        `gorm:"type:text" json:"reviewer_id,omitempty"`
+	Action       string         `gorm:"not null" json:"action"`
+	Reason       string         `json:"reason"`
+	Details      datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"details"`
+	ActorID      *string        `gorm:"type:text" json:"actor_id,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+func (ReviewAssignmentAudit) TableName() string {
+	return "review_assignment_audits"
+}
+
+// ReviewScoreSheet is one reviewer's scores for a submission at a stage
+type ReviewScoreSheet struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SubmissionID   uuid.UUID      `gorm:"type:uuid;not null" json:"submission_id"`
+	StageID        uuid.UUID      `gorm:"type:uuid;not null" json:"stage_id"`
+	RubricID       *uuid.UUID     `gorm:"type:uuid" json:"rubric_id,omitempty"`
+	ReviewerID     string         `gorm:"type:text;not null" json:"reviewer_id"` // TEXT to match ba_users.id
+	Scores         datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"scores"` // Criterion ID -> score
+	TotalScore     *float64       `json:"total_score,omitempty"`                 // Weighted, 0-100
+	Notes          *string        `json:"notes,omitempty"`
+	Recommendation *string        `json:"recommendation,omitempty"` // e.g. advance, hold, decline
+	Status         string         `gorm:"default:'draft'" json:"status"`
+	SubmittedAt    *time.Time     `json:"submitted_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ReviewScoreSheet) TableName() string {
+	return "review_score_sheets"
+}
+
+// ReviewQueueItem is one submission waiting in a reviewer's queue
+type ReviewQueueItem struct {
+	AssignmentID     uuid.UUID  `json:"assignment_id"`
+	AssignmentStatus string     `json:"assignment_status"`
+	AssignedAt       time.Time  `json:"assigned_at"`
+	SubmissionID     uuid.UUID  `json:"submission_id"`
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+	ApplicantID      string     `json:"applicant_id"`
+	ApplicantName    string     `json:"applicant_name"`
+	FormID           uuid.UUID  `json:"form_id"`
+	FormName         string     `json:"form_name"`
+	StageID          uuid.UUID  `json:"stage_id"`
+	StageName        string     `json:"stage_name"`
+	StageType        string     `json:"stage_type"`
+	SheetStatus      *string    `json:"sheet_status,omitempty"` // nil until the reviewer saves scores
+	TotalScore       *float64   `json:"total_score,omitempty"`
+}
+
+// RedactedDocument caches a blind-review copy of an uploaded document with
+// its PII blacked out, so each upload is only sent for redaction once
+type RedactedDocument struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SubmissionID uuid.UUID      `gorm:"type:uuid;not null" json:"submission_id"`
+	SourceURL    string         `gorm:"not null" json:"-"`
+	MimeType     string         `gorm:"not null" json:"mime_type"`
+	Data         []byte         `gorm:"type:bytea" json:"-"`
+	PIICount     int            `gorm:"default:0" json:"pii_count"`
+	PIITypes     datatypes.JSON `gorm:"type:jsonb;default:'[]'" json:"pii_types"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+func (RedactedDocument) TableName() string {
+	return "redacted_documents"
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Submission event types
+const (
+	SubmissionEventStarted                 = "started"
+	SubmissionEventSubmitted               = "submitted"
+	SubmissionEventRecommendationRequested = "recommendation_requested"
+	SubmissionEventRecommendationReceived  = "recommendation_received"
+	SubmissionEventDocumentRequested       = "document_requested"
+	SubmissionEventDocumentUploaded        = "document_uploaded"
+	SubmissionEventStageChanged            = "stage_changed"
+	SubmissionEventDecisionReleased        = "decision_released"
+	SubmissionEventMessage                 = "message"
+	SubmissionEventStatusUpdate            = "status_update"
+)
+
+// Who sees a submission event
+const (
+	EventVisibilityApplicant = "applicant" // Shown on the applicant's portal timeline, and to staff
+	EventVisibilityInternal  = "internal"  // Staff only
+)
+
+// DefaultEventVisibility is each event type's visibility unless the form
+// overrides it in settings.event_visibility
+var DefaultEventVisibility = map[string]string{
+	SubmissionEventStarted:                 EventVisibilityApplicant,
+	SubmissionEventSubmitted:               EventVisibilityApplicant,
+	SubmissionEventRecommendationRequested: EventVisibilityApplicant,
+	SubmissionEventRecommendationReceived:  EventVisibilityApplicant,
+	SubmissionEventDocumentRequested:       EventVisibilityApplicant,
+	SubmissionEventDocumentUploaded:        EventVisibilityApplicant,
+	SubmissionEventStageChanged:            EventVisibilityInternal, // Stage names are often internal; forms opt in
+	SubmissionEventDecisionReleased:        EventVisibilityApplicant,
+	SubmissionEventMessage:                 EventVisibilityApplicant,
+	SubmissionEventStatusUpdate:            EventVisibilityApplicant,
+}
+
+// SubmissionEvent is one entry of a submission's event log, which the
+// applicant's portal timeline is rendered from. Like recommendation
+// requests, SubmissionID is a form_submissions ID or, for applications
+// made through a legacy form, a table_rows ID.
+type SubmissionEvent struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SubmissionID uuid.UUID      `gorm:"type:uuid;not null" json:"submission_id"`
+	FormID       uuid.UUID      `gorm:"type:uuid;not null" json:"form_id"`
+	EventType    string         `gorm:"not null" json:"event_type"`
+	Visibility   string         `gorm:"not null;default:'internal'" json:"visibility"` // applicant, internal
+	Title        string         `gorm:"not null" json:"title"`
+	Content      string         `gorm:"type:text" json:"content,omitempty"`
+	Metadata     datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"metadata,omitempty"`
+	ActorID      *string        `gorm:"type:text" json:"actor_id,omitempty"` // Nil for the applicant, recommenders and the system
+	DedupeKey    *string        `gorm:"uniqueIndex" json:"-"`                // Set for events that happen once, e.g. started
+	OccurredAt   time.Time      `gorm:"not null" json:"occurred_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+func (SubmissionEvent) TableName() string {
+	return "submission_events"
+}
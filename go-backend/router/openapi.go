@@ -0,0 +1,512 @@
+package router
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ============================================================
+// OPENAPI 3 SPEC GENERATION
+// ============================================================
+//
+// The spec is built from the routes actually registered on the gin engine,
+// so it cannot drift from SetupRouter. Each route needs an entry in
+// routeSpecs (see openapi_routes.go) describing its summary and the Go
+// types used for the request body, query string and response; schemas are
+// derived from those types by reflection (json/form/binding tags).
+//
+// Run `go run ./cmd/openapi_check` to list routes without a spec or
+// without request/response types.
+
+const openAPIPath = "/api/openapi.json"
+
+// RouteSpec documents a single "METHOD /path" route
+type RouteSpec struct {
+	Summary     string
+	Description string
+	Tags        []string    // Defaults to the first path segment after the API version
+	Public      bool        // No bearer auth required
+	Request     interface{} // JSON request body type (zero value, e.g. models.Form{})
+	Query       interface{} // Struct whose `form` tags describe query parameters
+	QueryParams []string    // Additional plain query parameter names
+	Response    interface{} // JSON success response type
+	Produces    string      // Response content type when not JSON (e.g. "text/csv", "image/gif")
+	Status      int         // Success status when not 200; 204 and redirects have no body
+	Deprecated  bool
+}
+
+// hasBody reports whether the route's success response carries a body
+func (s RouteSpec) hasBody() bool {
+	return s.Status != http.StatusNoContent && (s.Status < 300 || s.Status >= 400)
+}
+
+var (
+	openAPICache     []byte
+	openAPICacheOnce sync.Once
+)
+
+// registerOpenAPIRoute serves the generated spec. It must be called after
+// every other route has been registered so the walk sees the full table.
+func registerOpenAPIRoute(r *gin.Engine) {
+	r.GET(openAPIPath, func(c *gin.Context) {
+		openAPICacheOnce.Do(func() {
+			openAPICache, _ = json.Marshal(BuildOpenAPISpec(r))
+		})
+		c.Data(http.StatusOK, "application/json; charset=utf-8", openAPICache)
+	})
+}
+
+// isStaticRoute reports whether a route is served by the static file server
+// rather than a handler, so it is not part of the API surface.
+func isStaticRoute(path string) bool {
+	return strings.HasPrefix(path, "/uploads/")
+}
+
+// routeKey builds the routeSpecs lookup key for a gin route
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// MissingRouteSpecs returns every registered route that has no RouteSpec
+func MissingRouteSpecs(r *gin.Engine) []string {
+	var missing []string
+	for _, route := range r.Routes() {
+		if isStaticRoute(route.Path) {
+			continue
+		}
+		if _, ok := routeSpecs[routeKey(route.Method, route.Path)]; !ok {
+			missing = append(missing, routeKey(route.Method, route.Path))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// StaleRouteSpecs returns RouteSpec entries that no longer match a registered route
+func StaleRouteSpecs(r *gin.Engine) []string {
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		registered[routeKey(route.Method, route.Path)] = true
+	}
+	var stale []string
+	for key := range routeSpecs {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// UntypedRouteSpecs returns JSON routes whose RouteSpec has neither a
+// Request nor a Response type, so clients only see an untyped object
+func UntypedRouteSpecs(r *gin.Engine) []string {
+	var untyped []string
+	for _, route := range r.Routes() {
+		spec, ok := routeSpecs[routeKey(route.Method, route.Path)]
+		if !ok || spec.Produces != "" || !spec.hasBody() {
+			continue
+		}
+		if spec.Request == nil && spec.Response == nil {
+			untyped = append(untyped, routeKey(route.Method, route.Path))
+		}
+	}
+	sort.Strings(untyped)
+	return untyped
+}
+
+// BuildOpenAPISpec generates an OpenAPI 3.0 document for every route on the engine
+func BuildOpenAPISpec(r *gin.Engine) map[string]interface{} {
+	gen := newSchemaGenerator()
+	paths := make(map[string]map[string]interface{})
+
+	routes := r.Routes()
+	handlerCounts := make(map[string]int)
+	for _, route := range routes {
+		handlerCounts[route.Handler]++
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+
+	for _, route := range routes {
+		if isStaticRoute(route.Path) {
+			continue
+		}
+
+		spec, ok := routeSpecs[routeKey(route.Method, route.Path)]
+		if !ok {
+			spec = RouteSpec{Summary: "Undocumented route"}
+		}
+
+		path, pathParams := openAPIPathFromGin(route.Path)
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(route.Method)] = buildOperation(gen, route, spec, pathParams, handlerCounts)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Matic Platform API",
+			"version":     "1.0.0",
+			"description": "Generated from the gin router. Do not edit by hand.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": gen.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+}
+
+func buildOperation(gen *schemaGenerator, route gin.RouteInfo, spec RouteSpec, pathParams []string, handlerCounts map[string]int) map[string]interface{} {
+	op := map[string]interface{}{
+		"operationId": operationID(route, handlerCounts),
+		"summary":     spec.Summary,
+	}
+	if spec.Description != "" {
+		op["description"] = spec.Description
+	}
+	if spec.Deprecated {
+		op["deprecated"] = true
+	}
+
+	tags := spec.Tags
+	if len(tags) == 0 {
+		tags = []string{defaultTag(route.Path)}
+	}
+	op["tags"] = tags
+
+	if !spec.Public {
+		op["security"] = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	var params []map[string]interface{}
+	for _, name := range pathParams {
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	if spec.Query != nil {
+		params = append(params, gen.queryParameters(reflect.TypeOf(spec.Query))...)
+	}
+	for _, name := range spec.QueryParams {
+		params = append(params, map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if spec.Request != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": gen.schemaFor(reflect.TypeOf(spec.Request)),
+				},
+			},
+		}
+	}
+
+	status := http.StatusOK
+	if spec.Status != 0 {
+		status = spec.Status
+	}
+	success := map[string]interface{}{"description": "Successful response"}
+	switch {
+	case !spec.hasBody():
+		success["description"] = http.StatusText(status)
+	case spec.Produces != "":
+		success["content"] = map[string]interface{}{
+			spec.Produces: map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string", "format": "binary"},
+			},
+		}
+	case spec.Response != nil:
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": gen.schemaFor(reflect.TypeOf(spec.Response)),
+			},
+		}
+	default:
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"type": "object"},
+			},
+		}
+	}
+
+	errorResponse := map[string]interface{}{
+		"description": "Error response",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+				},
+			},
+		},
+	}
+	op["responses"] = map[string]interface{}{
+		strconv.Itoa(status): success,
+		"default":            errorResponse,
+	}
+
+	return op
+}
+
+// openAPIPathFromGin converts /forms/:id/*path into /forms/{id}/{path}
+func openAPIPathFromGin(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			name := seg[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives a stable operation ID from the handler function name,
+// suffixed with the route when a handler serves several routes.
+func operationID(route gin.RouteInfo, handlerCounts map[string]int) string {
+	name := route.Handler
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	path, _ := openAPIPathFromGin(route.Path)
+	suffix := strings.ToLower(route.Method) + strings.NewReplacer("/", "_", "{", "", "}", "", "-", "_", ".", "_").Replace(path)
+
+	// Inline closures (func1, func2, ...) are named after their route instead
+	if name == "" || strings.HasPrefix(name, "func") {
+		return suffix
+	}
+	if handlerCounts[route.Handler] > 1 {
+		return name + "_" + suffix
+	}
+	return name
+}
+
+// defaultTag groups an operation by its first meaningful path segment
+func defaultTag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(segments); i++ {
+		seg := segments[i]
+		if seg == "api" || seg == "v1" || seg == "v2" || seg == "" {
+			continue
+		}
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			continue
+		}
+		return seg
+	}
+	return "meta"
+}
+
+// ==================== Schema Reflection ====================
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type schemaGenerator struct {
+	components map[string]interface{}
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{components: make(map[string]interface{})}
+}
+
+// componentName returns a unique component name like "models.Form"
+func componentName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if idx := strings.LastIndex(pkg, "/"); idx >= 0 {
+		pkg = pkg[idx+1:]
+	}
+	if pkg == "" {
+		return t.Name()
+	}
+	return pkg + "." + t.Name()
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	schema := g.baseSchema(t)
+	if nullable {
+		if _, isRef := schema["$ref"]; isRef {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+func (g *schemaGenerator) baseSchema(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !t.Implements(jsonMarshalerType):
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case t.Kind() == reflect.Struct && (t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType)):
+		// Custom JSON encodings (datatypes.JSON, sql.Null*, gorm.DeletedAt) are opaque
+		return map[string]interface{}{}
+	case t.Kind() != reflect.Slice && t.Kind() != reflect.Map && t.Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Implements(jsonMarshalerType) {
+			// datatypes.JSON, json.RawMessage and similar carry arbitrary JSON
+			return map[string]interface{}{}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := componentName(t)
+		if _, exists := g.components[name]; !exists {
+			// Reserve the name first so recursive types terminate
+			g.components[name] = map[string]interface{}{}
+			g.components[name] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	g.collectFields(t, properties, &required)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (g *schemaGenerator) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := parseTag(tag)
+
+		// Embedded structs without a json name are flattened, like encoding/json does
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.collectFields(ft, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.schemaFor(field.Type)
+		if strings.Contains(field.Tag.Get("binding"), "required") && !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// queryParameters turns a struct with `form` tags (e.g. models.ReviewExportFilters) into query parameters
+func (g *schemaGenerator) queryParameters(t reflect.Type) []map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var params []map[string]interface{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _ := parseTag(field.Tag.Get("form"))
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "query",
+			"required": strings.Contains(field.Tag.Get("binding"), "required"),
+			"schema":   g.schemaFor(field.Type),
+		})
+	}
+	return params
+}
+
+func parseTag(tag string) (string, string) {
+	if idx := strings.Index(tag, ","); idx >= 0 {
+		return tag[:idx], tag[idx+1:]
+	}
+	return tag, ""
+}
@@ -0,0 +1,436 @@
+package router
+
+import (
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/handlers"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+	"github.com/google/uuid"
+)
+
+// Response shapes for handlers that reply with gin.H. They exist only to
+// give those routes a schema in the OpenAPI document, so keep them in step
+// with the handlers' JSON keys.
+
+// ==================== Shared ====================
+
+type successResponse struct {
+	Success bool `json:"success"`
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type successMessageResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type authURLResponse struct {
+	AuthURL string `json:"auth_url"`
+	State   string `json:"state,omitempty"`
+}
+
+// ==================== Meta ====================
+
+type apiInfoResponse struct {
+	Service       string            `json:"service"`
+	Version       string            `json:"version"`
+	Status        string            `json:"status"`
+	Description   string            `json:"description"`
+	Endpoints     map[string]string `json:"endpoints"`
+	Documentation map[string]string `json:"documentation"`
+}
+
+type endpointIndexResponse struct {
+	APIVersion string                       `json:"api_version"`
+	Status     string                       `json:"status,omitempty"`
+	Service    string                       `json:"service,omitempty"`
+	Endpoints  map[string]map[string]string `json:"endpoints"`
+}
+
+type healthResponse struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+	Version string `json:"version,omitempty"`
+}
+
+// ==================== Forms / Submissions ====================
+
+// submissionDataResponse is a single submission's answers; every field is
+// null when the applicant hasn't started
+type submissionDataResponse struct {
+	ID        *uuid.UUID             `json:"id"`
+	Data      map[string]interface{} `json:"data"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	CreatedAt *time.Time             `json:"created_at"`
+	UpdatedAt *time.Time             `json:"updated_at"`
+}
+
+type bulkDeleteResponse struct {
+	Message      string `json:"message"`
+	DeletedCount int64  `json:"deleted_count"`
+}
+
+type publishFormResponse struct {
+	Form           models.Form        `json:"form"`
+	Version        models.FormVersion `json:"version"`
+	MigratedDrafts int64              `json:"migrated_drafts"`
+}
+
+// ==================== Portal ====================
+
+type portalUserResponse struct {
+	ID           string     `json:"id"`
+	Email        string     `json:"email"`
+	Name         string     `json:"name"`
+	UserType     string     `json:"user_type"`
+	FormsApplied []string   `json:"forms_applied"`
+	SessionToken string     `json:"session_token,omitempty"` // Login only
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`    // Login only
+}
+
+type portalSubmissionResponse struct {
+	ID                   uuid.UUID              `json:"id"`
+	FormID               uuid.UUID              `json:"form_id"`
+	FormName             string                 `json:"form_name"`
+	Status               string                 `json:"status"`
+	CompletionPercentage int                    `json:"completion_percentage"`
+	Data                 map[string]interface{} `json:"data"`
+	SubmittedAt          *time.Time             `json:"submitted_at"`
+	LastSavedAt          time.Time              `json:"last_saved_at"`
+	CreatedAt            time.Time              `json:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at"`
+}
+
+type portalSubmissionStartResponse struct {
+	ID                   uuid.UUID  `json:"id"`
+	FormID               uuid.UUID  `json:"form_id"`
+	Status               string     `json:"status"`
+	CompletionPercentage int        `json:"completion_percentage"`
+	StartedAt            time.Time  `json:"started_at"`
+	LastSavedAt          *time.Time `json:"last_saved_at,omitempty"` // Existing submissions only
+	SubmittedAt          *time.Time `json:"submitted_at,omitempty"`  // Existing submissions only
+	Existing             bool       `json:"existing"`
+}
+
+type portalSubmissionUpdateResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type autosaveResponse struct {
+	Version              int                 `json:"version"`
+	SavedAt              time.Time           `json:"saved_at"`
+	Conflict             bool                `json:"conflict"`
+	Warnings             map[string][]string `json:"warnings"`
+	CompletionPercentage int                 `json:"completion_percentage"`
+	NextSectionID        *uuid.UUID          `json:"next_section_id"`
+}
+
+type portalDocumentResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Size       int64     `json:"size"`
+	MimeType   string    `json:"mime_type"`
+	UploadedAt time.Time `json:"uploadedAt"`
+}
+
+// ==================== Invitations ====================
+
+type invitationByTokenResponse struct {
+	Invitation    handlers.InvitationResponse `json:"invitation"`
+	WorkspaceName string                      `json:"workspace_name"`
+}
+
+type acceptInvitationResponse struct {
+	Message   string `json:"message"`
+	Workspace struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+	Member models.WorkspaceMember `json:"member"`
+}
+
+// ==================== Recommendations ====================
+
+type recommendationFormResponse struct {
+	Request             models.RecommendationRequest    `json:"request"`
+	ApplicantName       string                          `json:"applicant_name"`
+	ApplicantEmail      string                          `json:"applicant_email"`
+	FormTitle           string                          `json:"form_title"`
+	Questions           []models.RecommendationQuestion `json:"questions"`
+	Instructions        string                          `json:"instructions"`
+	RequireRelationship bool                            `json:"require_relationship"`
+	ShowFileUpload      bool                            `json:"show_file_upload"`
+	LogoURL             string                          `json:"logo_url"`
+	AlreadySubmitted    bool                            `json:"already_submitted,omitempty"`
+}
+
+type recommendationSubmittedResponse struct {
+	Message string                       `json:"message"`
+	Request models.RecommendationRequest `json:"request"`
+}
+
+type testEmailResponse struct {
+	Message     string `json:"message"`
+	MessageID   string `json:"message_id,omitempty"`
+	ResendID    string `json:"resend_id,omitempty"`
+	ServiceUsed string `json:"service_used"`
+	To          string `json:"to"`
+}
+
+type recommendationBackfillResponse struct {
+	FormID             uuid.UUID                               `json:"form_id"`
+	SubmissionsChecked int                                     `json:"submissions_checked"`
+	DocumentsFound     int                                     `json:"documents_found"`
+	DocumentsSynced    int                                     `json:"documents_synced"`
+	DocumentsExisting  int                                     `json:"documents_existing"`
+	DocumentsFailed    int                                     `json:"documents_failed"`
+	Results            []handlers.RecommendationBackfillResult `json:"results"`
+}
+
+// ==================== Google Drive ====================
+
+type driveFolderResponse struct {
+	FolderID   string `json:"folder_id"`
+	FolderName string `json:"folder_name,omitempty"`
+	FolderURL  string `json:"folder_url"`
+	Name       string `json:"name,omitempty"`
+	Existing   bool   `json:"existing,omitempty"`
+}
+
+type driveFileResponse struct {
+	FileID   string `json:"file_id"`
+	FileURL  string `json:"file_url"`
+	FileName string `json:"file_name"`
+	Skipped  bool   `json:"skipped,omitempty"` // Already synced
+}
+
+type driveSyncAllResponse struct {
+	SyncedFiles []driveFileResponse `json:"synced_files"`
+	Total       int                 `json:"total"`
+	Errors      []string            `json:"errors,omitempty"`
+}
+
+// ==================== Email ====================
+
+type gmailConnectionResponse struct {
+	Connected       bool                     `json:"connected"`
+	Email           string                   `json:"email,omitempty"`
+	AccountsCount   int                      `json:"accounts_count,omitempty"`
+	Accounts        []models.GmailConnection `json:"accounts"`
+	NeedsReconnect  bool                     `json:"needs_reconnect,omitempty"`
+	ReconnectReason string                   `json:"reconnect_reason,omitempty"`
+}
+
+type emailServiceHealthResponse struct {
+	Gmail  models.EmailServiceHealth `json:"gmail"`
+	Resend models.EmailServiceHealth `json:"resend"`
+	SMTP   models.EmailServiceHealth `json:"smtp"`
+}
+
+type draftCleanupResponse struct {
+	Success      bool  `json:"success"`
+	DeletedCount int64 `json:"deleted_count"`
+}
+
+type replySyncResponse struct {
+	Success  bool `json:"success"`
+	Ingested int  `json:"ingested"`
+}
+
+// ==================== Files / Tables / History ====================
+
+type fileStatsResponse struct {
+	FileCount      int64  `json:"file_count"`
+	TotalSizeBytes int64  `json:"total_size_bytes"`
+	FormattedSize  string `json:"formatted_size"`
+}
+
+type tableRowsResponse struct {
+	Rows       []models.Row `json:"rows"`
+	Total      int64        `json:"total"`
+	Limit      int          `json:"limit"`
+	Offset     int          `json:"offset"`
+	HasMore    bool         `json:"has_more"`
+	TotalPages int64        `json:"total_pages"`
+}
+
+type rowHistoryResponse struct {
+	RowID         string                     `json:"row_id"`
+	TableID       string                     `json:"table_id"`
+	TotalVersions int64                      `json:"total_versions"`
+	Versions      []services.RowHistoryEntry `json:"versions"`
+}
+
+type rowVersionResponse struct {
+	Version         models.RowVersion      `json:"version"`
+	Data            map[string]interface{} `json:"data"`
+	Changes         []models.FieldChange   `json:"changes"`
+	PreviousVersion *int                   `json:"previous_version"`
+	NextVersion     *int                   `json:"next_version"`
+}
+
+type versionCompareResponse struct {
+	Version1   int                  `json:"version1"`
+	Version2   int                  `json:"version2"`
+	FieldDiffs []models.FieldChange `json:"field_diffs"`
+}
+
+type tableSuggestionsResponse struct {
+	TableID     string                     `json:"table_id"`
+	Suggestions []models.AIFieldSuggestion `json:"suggestions"`
+	Total       int64                      `json:"total"`
+}
+
+type rowSuggestionsResponse struct {
+	RowID       string                     `json:"row_id"`
+	Suggestions []models.AIFieldSuggestion `json:"suggestions"`
+}
+
+type versionResultResponse struct {
+	Success   bool       `json:"success"`
+	VersionID *uuid.UUID `json:"version_id"`
+}
+
+type restoreVersionResponse struct {
+	Success          bool      `json:"success"`
+	NewVersionID     uuid.UUID `json:"new_version_id"`
+	NewVersionNumber int       `json:"new_version_number"`
+}
+
+// ==================== Search ====================
+
+type popularSearchesResponse struct {
+	Searches []handlers.PopularSearch `json:"searches"`
+}
+
+type recentSearchesResponse struct {
+	Searches []string `json:"searches"`
+}
+
+type searchSuggestionsResponse struct {
+	Suggestions []string `json:"suggestions"`
+}
+
+type savedSearchResponse struct {
+	Message string    `json:"message"`
+	ID      uuid.UUID `json:"id"`
+}
+
+type similarItemsResponse struct {
+	SimilarItems []handlers.SimilarItem `json:"similar_items"`
+	Source       struct {
+		EntityID   string `json:"entity_id"`
+		EntityType string `json:"entity_type"`
+	} `json:"source"`
+}
+
+type embeddingStatsResponse struct {
+	Stats []models.EmbeddingStats `json:"stats"`
+}
+
+type generateEmbeddingsResponse struct {
+	Processed int    `json:"processed"`
+	Message   string `json:"message"`
+}
+
+type rebuildIndexResponse struct {
+	Message      string `json:"message"`
+	IndexedCount int    `json:"indexed_count"`
+	WorkspaceID  string `json:"workspace_id"`
+}
+
+// ==================== Reports / Diagnostics / CRM ====================
+
+type reportQueryResponse struct {
+	IsReport  bool   `json:"is_report"`
+	QueryType string `json:"query_type"`
+}
+
+type workspaceStatsResponse struct {
+	Stats     handlers.ReportStats `json:"stats"`
+	Generated time.Time            `json:"generated"`
+}
+
+type reviewExportCSVResponse struct {
+	Message  string `json:"message"`
+	Endpoint string `json:"endpoint"`
+}
+
+type discoveredFilesResponse struct {
+	Email        string                         `json:"email"`
+	UserID       string                         `json:"user_id"`
+	TotalFiles   int                            `json:"total_files"`
+	Files        []handlers.FileDiscoveryResult `json:"files"`
+	TableFiles   int                            `json:"table_files"`
+	RawDataFiles int                            `json:"raw_data_files"`
+	SyncedCount  int                            `json:"synced_count"`
+	FailedCount  int                            `json:"failed_count"`
+	PendingCount int                            `json:"pending_count"`
+}
+
+type submissionDiscoveredFilesResponse struct {
+	SubmissionID string                         `json:"submission_id"`
+	FormID       uuid.UUID                      `json:"form_id"`
+	UserID       string                         `json:"user_id"`
+	TotalFiles   int                            `json:"total_files"`
+	Files        []handlers.FileDiscoveryResult `json:"files"`
+}
+
+type applicantDetailResponse struct {
+	Applicant    handlers.ApplicantDetail     `json:"applicant"`
+	Applications []handlers.ApplicationDetail `json:"applications"`
+}
+
+type piiBatchResponse struct {
+	Results []services.PIIDetectionResponse `json:"results"`
+	Total   int                             `json:"total"`
+}
+
+// ==================== Review Pipeline / Decisions ====================
+
+type stageScoresResponse struct {
+	Stage       models.ReviewStage          `json:"stage"`
+	Submissions []handlers.RankedSubmission `json:"submissions"`
+}
+
+type submissionReviewResponse struct {
+	Stage       *models.ReviewStage       `json:"stage"`
+	Assignments []models.ReviewAssignment `json:"assignments"`
+	ScoreSheets []models.ReviewScoreSheet `json:"score_sheets"`
+	Aggregate   *services.ScoreAggregate  `json:"aggregate,omitempty"`
+	CanAdvance  bool                      `json:"can_advance,omitempty"`
+}
+
+type stageResponse struct {
+	Stage *models.ReviewStage `json:"stage"`
+}
+
+type scoreSheetResponse struct {
+	ScoreSheet models.ReviewScoreSheet `json:"score_sheet"`
+	AdvancedTo *models.ReviewStage     `json:"advanced_to"`
+}
+
+type declineReviewResponse struct {
+	Message    string `json:"message"`
+	Reassigned bool   `json:"reassigned"`
+}
+
+type promoteWaitlistResponse struct {
+	Promoted []models.SubmissionDecision `json:"promoted"`
+	Released int                         `json:"released"`
+}
+
+type releaseDecisionsResponse struct {
+	Scheduled int64     `json:"scheduled"`
+	Released  int       `json:"released"`
+	ReleaseAt time.Time `json:"release_at"`
+}
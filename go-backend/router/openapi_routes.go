@@ -0,0 +1,398 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Jsanchez767/matic-platform/handlers"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/services"
+)
+
+// routeSpecs documents every route registered in SetupRouter, keyed by
+// "METHOD /gin/path". Keep it in the same order as router.go; the
+// openapi_check command fails when a route is added without an entry here.
+var routeSpecs = map[string]RouteSpec{
+	// ==================== Meta ====================
+	"GET /":                 {Summary: "HTML API documentation", Public: true, Produces: "text/html", Tags: []string{"meta"}},
+	"GET /api-info":         {Summary: "API service info", Public: true, Response: apiInfoResponse{}, Tags: []string{"meta"}},
+	"GET /health":           {Summary: "Health check", Public: true, Response: healthResponse{}, Tags: []string{"meta"}},
+	"GET /healthz":          {Summary: "Liveness probe", Public: true, Response: healthResponse{}, Tags: []string{"meta"}},
+	"GET /readyz":           {Summary: "Readiness probe with per-component report (503 when a critical component is down; ?strict=true also fails on degraded)", Public: true, QueryParams: []string{"strict"}, Response: handlers.ReadinessReport{}, Tags: []string{"meta"}},
+	"GET /metrics":          {Summary: "Prometheus metrics (bearer METRICS_TOKEN; 404 in release mode without one)", Public: true, Produces: "text/plain", Tags: []string{"meta"}},
+	"GET /api/v1":           {Summary: "API v1 endpoint index", Public: true, Response: endpointIndexResponse{}, Tags: []string{"meta"}},
+	"GET /api/v1/docs":      {Summary: "Hand-written endpoint summary (superseded by /api/openapi.json)", Public: true, Response: endpointIndexResponse{}, Tags: []string{"meta"}, Deprecated: true},
+	"GET /api/openapi.json": {Summary: "OpenAPI 3 document generated from the router", Public: true, Response: map[string]interface{}{}, Tags: []string{"meta"}},
+
+	// ==================== Public Forms ====================
+	"GET /api/v1/forms/by-slug/:slug":                 {Summary: "Get a published form by slug", Public: true, Response: handlers.FormDTO{}},
+	"GET /api/v1/forms/by-subdomain/:subdomain/:slug": {Summary: "Resolve a form by workspace subdomain and slug", Public: true, Response: handlers.PortalFormDTO{}},
+	"POST /api/v1/forms/:id/submit":                   {Summary: "Submit a form", Public: true, Request: handlers.SubmitFormInput{}, Response: models.Row{}},
+	"GET /api/v1/forms/:id/submission":                {Summary: "Get a form submission by email", Public: true, QueryParams: []string{"email"}, Response: submissionDataResponse{}},
+
+	// ==================== Portal Auth V2 ====================
+	"POST /api/v1/portal/v2/signup":                     {Summary: "Portal applicant signup", Public: true, Deprecated: true, Request: handlers.PortalSignupV2Request{}, Response: portalUserResponse{}, Tags: []string{"portal"}},
+	"POST /api/v1/portal/v2/login":                      {Summary: "Portal applicant login", Public: true, Deprecated: true, Request: handlers.PortalLoginV2Request{}, Response: portalUserResponse{}, Tags: []string{"portal"}},
+	"POST /api/v1/portal/v2/logout":                     {Summary: "Portal applicant logout", Public: true, Response: messageResponse{}, Tags: []string{"portal"}},
+	"GET /api/v1/portal/v2/me":                          {Summary: "Get the current portal applicant", Response: portalUserResponse{}, Tags: []string{"portal"}},
+	"GET /api/v1/portal/v2/submissions":                 {Summary: "List the applicant's submissions", Response: []handlers.ApplicantSubmission{}, Tags: []string{"portal"}},
+	"POST /api/v1/portal/login":                         {Summary: "Legacy alias for portal v2 login", Public: true, Deprecated: true, Request: handlers.PortalLoginV2Request{}, Response: portalUserResponse{}, Tags: []string{"portal"}},
+	"POST /api/v1/portal/v2/forms/:form_id/submissions": {Summary: "Get or create the applicant's submission for a form", Response: portalSubmissionStartResponse{}, Tags: []string{"portal"}},
+	"GET /api/v1/portal/v2/submissions/:id":             {Summary: "Get a portal submission", Response: portalSubmissionResponse{}, Tags: []string{"portal"}},
+	"PUT /api/v1/portal/v2/submissions/:id":             {Summary: "Update a portal submission", Request: handlers.UpdatePortalSubmissionInput{}, Response: portalSubmissionUpdateResponse{}, Tags: []string{"portal"}},
+	"POST /api/v1/submissions/:id/autosave":             {Summary: "Autosave a portal submission", Request: handlers.AutosaveSubmissionInput{}, Response: autosaveResponse{}, Tags: []string{"portal"}},
+	"GET /api/v1/portal/forms/:form_id/my-submission":   {Summary: "Get the applicant's submission for a form", Response: submissionDataResponse{}, Tags: []string{"portal"}},
+	"POST /api/v1/portal/forms/:form_id/my-submission":  {Summary: "Save the applicant's submission for a form", Request: handlers.SaveMyPortalSubmissionInput{}, Tags: []string{"portal"}},
+
+	// ==================== Portal Dashboard ====================
+	"POST /api/v1/portal/dashboard/sync-better-auth-applicant":       {Summary: "Sync a Better Auth user into portal applicants", Public: true, Request: handlers.PortalSyncBetterAuthApplicantRequest{}, Tags: []string{"portal"}},
+	"GET /api/v1/portal/dashboard/applications/:id":                  {Summary: "Get the applicant dashboard for a submission", Public: true, Response: handlers.ApplicationDashboardDTO{}, Tags: []string{"portal"}},
+	"GET /api/v1/portal/dashboard/applications/:id/activities":       {Summary: "List portal activities for a submission", Public: true, Response: []handlers.PortalActivityDTO{}, Tags: []string{"portal"}},
+	"POST /api/v1/portal/dashboard/applications/:id/activities":      {Summary: "Create a portal activity", Public: true, Request: handlers.CreatePortalActivityInput{}, Response: handlers.PortalActivityDTO{}, Status: http.StatusCreated, Tags: []string{"portal"}},
+	"POST /api/v1/portal/dashboard/applications/:id/activities/read": {Summary: "Mark portal activities as read", Public: true, Request: handlers.MarkActivitiesReadInput{}, Response: messageResponse{}, Tags: []string{"portal"}},
+	"GET /api/v1/portal/dashboard/documents":                         {Summary: "List portal documents", Public: true, Response: []portalDocumentResponse{}, Tags: []string{"portal"}},
+	"POST /api/v1/portal/dashboard/documents":                        {Summary: "Upload a portal document", Public: true, Response: portalDocumentResponse{}, Status: http.StatusCreated, Tags: []string{"portal"}},
+	"DELETE /api/v1/portal/dashboard/documents/:id":                  {Summary: "Delete a portal document", Public: true, Response: messageResponse{}, Tags: []string{"portal"}},
+	"POST /api/v1/portal/dashboard/recommendations":                  {Summary: "Request a recommendation from the portal", Public: true, Request: models.CreateRecommendationRequestInput{}, Tags: []string{"portal"}},
+	"GET /api/v1/portal/dashboard/recommendations":                   {Summary: "List recommendation requests from the portal", Public: true, Response: []models.RecommendationRequest{}, Tags: []string{"portal"}},
+
+	// ==================== Public Email / Auth / Integrations ====================
+	"GET /api/v1/email/track/:tracking_id":           {Summary: "Email open tracking pixel", Public: true, Produces: "image/gif", Tags: []string{"email"}},
+	"GET /api/v1/email/click/:tracking_id/:link_id":  {Summary: "Record a tracked link click and redirect to its stored destination (302; 404 for unknown or unsigned links)", Public: true, QueryParams: []string{"sig"}, Status: http.StatusFound, Tags: []string{"email"}},
+	"GET /api/v1/email/oauth/callback":               {Summary: "Gmail OAuth callback", Public: true, QueryParams: []string{"code", "state"}, Status: http.StatusTemporaryRedirect, Tags: []string{"email"}},
+	"POST /api/v1/auth/generate-email":               {Summary: "Render an auth email template", Public: true, Request: handlers.SendAuthEmailRequest{}},
+	"GET /api/v1/auth/preview-email":                 {Summary: "Preview an auth email in the browser", Public: true, Produces: "text/html"},
+	"GET /api/v1/integrations/google-drive/callback": {Summary: "Google Drive OAuth callback", Public: true, QueryParams: []string{"code", "state"}, Status: http.StatusTemporaryRedirect},
+	"POST /api/v1/email/resend/webhook":              {Summary: "Resend delivery webhook", Public: true, Request: handlers.ResendWebhookEvent{}, Tags: []string{"email"}},
+	"POST /api/v1/email/inbound/webhook":             {Summary: "Ingest a parsed inbound reply into the submission's activity (Svix-signed with EMAIL_INBOUND_WEBHOOK_SECRET; 503 when unset)", Public: true, Request: handlers.InboundEmailWebhook{}, Tags: []string{"email"}},
+	"POST /api/v1/email/unsubscribe/:token":          {Summary: "RFC 8058 one-click unsubscribe", Public: true, Response: successMessageResponse{}, Tags: []string{"email"}},
+	"GET /api/v1/email/unsubscribe/:token":           {Summary: "Redirect an unsubscribe link to the preference page", Public: true, Status: http.StatusFound, Tags: []string{"email"}},
+	"GET /api/v1/email/preferences/:token":           {Summary: "Get a recipient's email preferences", Public: true, Response: handlers.EmailPreferencesResponse{}, Tags: []string{"email"}},
+	"PUT /api/v1/email/preferences/:token":           {Summary: "Update a recipient's email preferences", Public: true, Request: handlers.UpdateEmailPreferencesRequest{}, Response: handlers.EmailPreferencesResponse{}, Tags: []string{"email"}},
+
+	// ==================== Public Recommendations ====================
+	"GET /api/v1/recommend/:token":            {Summary: "Get a recommendation request by token", Public: true, Response: recommendationFormResponse{}, Tags: []string{"recommendations"}},
+	"POST /api/v1/recommend/:token/submit":    {Summary: "Submit a recommendation", Public: true, Request: handlers.RecommendationResponseInput{}, Response: recommendationSubmittedResponse{}, Tags: []string{"recommendations"}},
+	"POST /api/v1/recommendations/test-email": {Summary: "Send a test recommendation email", Public: true, Request: handlers.TestRecommendationEmailInput{}, Response: testEmailResponse{}, Tags: []string{"recommendations"}},
+
+	// ==================== Public Ending Pages / Field Types / Invitations ====================
+	"POST /api/v1/ending-pages/match":         {Summary: "Find the ending page matching submission data", Public: true, Response: handlers.EndingPageDTO{}},
+	"GET /api/v1/field-types":                 {Summary: "List field types", Public: true, Response: []models.FieldTypeRegistry{}},
+	"GET /api/v1/field-types/toolbox":         {Summary: "List field types grouped for the builder toolbox", Public: true, Response: map[string][]handlers.FieldTypeSummary{}},
+	"GET /api/v1/field-types/:type_id":        {Summary: "Get a field type", Public: true, Response: models.FieldTypeRegistry{}},
+	"GET /api/v1/forms/:id/dashboard":         {Summary: "Get the applicant dashboard layout", Public: true, Response: handlers.DashboardLayoutDTO{}},
+	"GET /api/v1/invitations/by-token/:token": {Summary: "Get an invitation by token", Public: true, Response: invitationByTokenResponse{}},
+
+	// ==================== Organizations ====================
+	"GET /api/v1/organizations":        {Summary: "List organizations", Response: []models.Organization{}},
+	"POST /api/v1/organizations":       {Summary: "Create an organization", Request: handlers.CreateOrganizationInput{}, Response: models.Organization{}},
+	"GET /api/v1/organizations/:id":    {Summary: "Get an organization", Response: models.Organization{}},
+	"PATCH /api/v1/organizations/:id":  {Summary: "Update an organization", Request: handlers.UpdateOrganizationInput{}, Response: models.Organization{}},
+	"DELETE /api/v1/organizations/:id": {Summary: "Delete an organization", Response: messageResponse{}},
+
+	// ==================== Workspaces ====================
+	"GET /api/v1/workspaces":                                           {Summary: "List workspaces", QueryParams: []string{"organization_id"}, Response: []models.Workspace{}},
+	"GET /api/v1/workspaces/init":                                      {Summary: "Workspace page bootstrap data", Response: handlers.WorkspacesInitResponse{}},
+	"POST /api/v1/workspaces":                                          {Summary: "Create a workspace", Request: handlers.CreateWorkspaceInput{}, Response: models.Workspace{}},
+	"GET /api/v1/workspaces/by-slug/:slug":                             {Summary: "Get a workspace by slug", Response: models.Workspace{}},
+	"GET /api/v1/workspaces/:id":                                       {Summary: "Get a workspace", Response: models.Workspace{}},
+	"PATCH /api/v1/workspaces/:id":                                     {Summary: "Update a workspace", Request: handlers.UpdateWorkspaceInput{}, Response: models.Workspace{}},
+	"DELETE /api/v1/workspaces/:id":                                    {Summary: "Delete a workspace", Status: http.StatusNoContent},
+	"GET /api/v1/workspaces/:id/members-with-auth":                     {Summary: "List workspace members with auth profiles", Response: []handlers.MemberWithAuth{}},
+	"GET /api/v1/workspaces/:id/invitations":                           {Summary: "List workspace invitations", Response: []handlers.InvitationResponse{}},
+	"POST /api/v1/workspaces/:id/invitations":                          {Summary: "Create a workspace invitation", Request: handlers.InvitationRequest{}, Response: handlers.InvitationResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/workspaces/:id/integrations":                          {Summary: "List workspace integrations", Response: []models.WorkspaceIntegration{}},
+	"POST /api/v1/workspaces/:id/integrations":                         {Summary: "Create a workspace integration", Response: models.WorkspaceIntegration{}},
+	"GET /api/v1/workspaces/:id/integrations/:type":                    {Summary: "Get a workspace integration", Response: models.WorkspaceIntegration{}},
+	"PATCH /api/v1/workspaces/:id/integrations/:type":                  {Summary: "Update a workspace integration", Response: models.WorkspaceIntegration{}},
+	"DELETE /api/v1/workspaces/:id/integrations/:type":                 {Summary: "Delete a workspace integration", Response: messageResponse{}},
+	"GET /api/v1/workspaces/:id/integrations/google_drive/auth-url":    {Summary: "Get the Google Drive OAuth URL", Response: authURLResponse{}},
+	"POST /api/v1/workspaces/:id/integrations/google_drive/disconnect": {Summary: "Disconnect Google Drive", Response: messageResponse{}},
+
+	// ==================== Workspace Members / Invitations ====================
+	"GET /api/v1/workspace-members":           {Summary: "List workspace members", QueryParams: []string{"workspace_id"}, Response: []models.WorkspaceMember{}},
+	"PATCH /api/v1/workspace-members/:id":     {Summary: "Update a workspace member", Response: models.WorkspaceMember{}},
+	"DELETE /api/v1/workspace-members/:id":    {Summary: "Remove a workspace member", Response: messageResponse{}},
+	"GET /api/v1/invitations":                 {Summary: "List invitations", QueryParams: []string{"workspace_id"}, Response: []handlers.InvitationResponse{}},
+	"POST /api/v1/invitations":                {Summary: "Create an invitation", Request: handlers.InvitationRequest{}},
+	"DELETE /api/v1/invitations/:id":          {Summary: "Revoke an invitation", Response: messageResponse{}},
+	"POST /api/v1/invitations/:id/resend":     {Summary: "Resend an invitation", Response: messageResponse{}},
+	"POST /api/v1/invitations/accept/:token":  {Summary: "Accept an invitation", Response: acceptInvitationResponse{}},
+	"POST /api/v1/invitations/decline/:token": {Summary: "Decline an invitation", Response: messageResponse{}},
+
+	// ==================== Data Tables ====================
+	"GET /api/v1/tables":                                            {Summary: "List tables", QueryParams: []string{"workspace_id"}, Response: []models.Table{}},
+	"POST /api/v1/tables":                                           {Summary: "Create a table", Request: handlers.CreateDataTableInput{}, Response: models.Table{}},
+	"GET /api/v1/tables/:id":                                        {Summary: "Get a table", Response: models.Table{}},
+	"PATCH /api/v1/tables/:id":                                      {Summary: "Update a table", Request: handlers.UpdateDataTableInput{}, Response: models.Table{}},
+	"DELETE /api/v1/tables/:id":                                     {Summary: "Delete a table", Status: http.StatusNoContent},
+	"GET /api/v1/tables/:id/rows":                                   {Summary: "List table rows", Response: tableRowsResponse{}},
+	"GET /api/v1/tables/:id/rows/:row_id":                           {Summary: "Get a table row", Response: models.Row{}},
+	"POST /api/v1/tables/:id/rows":                                  {Summary: "Create a table row", Request: handlers.CreateTableRowInput{}, Response: models.Row{}},
+	"PATCH /api/v1/tables/:id/rows/:row_id":                         {Summary: "Update a table row", Request: handlers.UpdateTableRowInput{}, Response: models.Row{}},
+	"DELETE /api/v1/tables/:id/rows/:row_id":                        {Summary: "Delete a table row", Status: http.StatusNoContent},
+	"GET /api/v1/tables/:id/rows/:row_id/history":                   {Summary: "Get row version history", Response: rowHistoryResponse{}},
+	"GET /api/v1/tables/:id/rows/:row_id/history/:version":          {Summary: "Get a row version", Response: rowVersionResponse{}},
+	"GET /api/v1/tables/:id/rows/:row_id/versions/:version":         {Summary: "Get a row version", Response: rowVersionResponse{}},
+	"GET /api/v1/tables/:id/rows/:row_id/diff/:v1/:v2":              {Summary: "Compare two row versions", Response: versionCompareResponse{}},
+	"GET /api/v1/tables/:id/rows/:row_id/compare-versions/:v1/:v2":  {Summary: "Compare two row versions", Response: versionCompareResponse{}},
+	"POST /api/v1/tables/:id/rows/:row_id/restore/:version":         {Summary: "Restore a row version", Request: handlers.RestoreVersionInput{}, Response: restoreVersionResponse{}},
+	"POST /api/v1/tables/:id/columns":                               {Summary: "Create a table column", Request: handlers.CreateTableColumnInput{}, Response: models.Field{}},
+	"PATCH /api/v1/tables/:id/columns/:column_id":                   {Summary: "Update a table column", Request: handlers.UpdateTableColumnInput{}, Response: models.Field{}},
+	"DELETE /api/v1/tables/:id/columns/:column_id":                  {Summary: "Delete a table column", Status: http.StatusNoContent},
+	"GET /api/v1/tables/:id/search":                                 {Summary: "Search table rows", QueryParams: []string{"q"}, Response: handlers.SearchResponse{}},
+	"GET /api/v1/tables/:id/approvals":                              {Summary: "List change approvals", Response: []models.ChangeApproval{}},
+	"GET /api/v1/tables/:id/approvals/:approval_id":                 {Summary: "Get a change approval", Response: models.ChangeApproval{}},
+	"POST /api/v1/tables/:id/approvals/:approval_id/review":         {Summary: "Approve or reject a change", Request: handlers.ReviewApprovalInput{}, Response: versionResultResponse{}},
+	"GET /api/v1/tables/:id/schema/ai":                              {Summary: "Get the table schema for AI prompts", Response: handlers.AITableSchema{}},
+	"GET /api/v1/tables/:id/ai/suggestions":                         {Summary: "List AI field suggestions", Response: tableSuggestionsResponse{}},
+	"POST /api/v1/tables/:id/ai/analyze":                            {Summary: "Analyze a table for AI suggestions", Response: services.AnalyzeTableResult{}},
+	"POST /api/v1/tables/:id/ai/rows/:row_id/analyze":               {Summary: "Analyze a row for AI suggestions", Response: rowSuggestionsResponse{}},
+	"POST /api/v1/tables/:id/ai/suggestions/:suggestion_id/apply":   {Summary: "Apply an AI suggestion", Request: handlers.ApplySuggestionInput{}, Response: versionResultResponse{}},
+	"POST /api/v1/tables/:id/ai/suggestions/:suggestion_id/dismiss": {Summary: "Dismiss an AI suggestion", Response: successResponse{}},
+	"GET /api/v1/tables/:id/suggestions":                            {Summary: "List AI field suggestions", Deprecated: true, Response: tableSuggestionsResponse{}},
+	"POST /api/v1/tables/:id/suggestions/:suggestion_id/apply":      {Summary: "Apply an AI suggestion", Deprecated: true, Request: handlers.ApplySuggestionInput{}, Response: versionResultResponse{}},
+	"POST /api/v1/tables/:id/suggestions/:suggestion_id/dismiss":    {Summary: "Dismiss an AI suggestion", Deprecated: true, Response: successResponse{}},
+	"GET /api/v1/tables/:id/views":                                  {Summary: "List table views", Response: []models.View{}},
+	"POST /api/v1/tables/:id/views":                                 {Summary: "Create a table view", Request: handlers.CreateViewInput{}, Response: models.View{}},
+	"GET /api/v1/tables/:id/views/portal":                           {Summary: "List portal views", Response: []models.View{}},
+	"GET /api/v1/tables/:id/files":                                  {Summary: "List files for a table", Response: []handlers.FileResponse{}},
+
+	// ==================== Views / Versions ====================
+	"GET /api/v1/views/:id":                     {Summary: "Get a view", Response: models.View{}},
+	"PATCH /api/v1/views/:id":                   {Summary: "Update a view", Request: handlers.UpdateViewInput{}, Response: models.View{}},
+	"DELETE /api/v1/views/:id":                  {Summary: "Delete a view", Response: messageResponse{}},
+	"PATCH /api/v1/views/:id/config":            {Summary: "Update view config", Response: models.View{}},
+	"POST /api/v1/views/:id/duplicate":          {Summary: "Duplicate a view", Response: models.View{}},
+	"POST /api/v1/versions/:version_id/archive": {Summary: "Archive a row version", Response: successResponse{}},
+	"DELETE /api/v1/versions/:version_id":       {Summary: "Delete a row version", Response: successResponse{}},
+
+	// ==================== Files ====================
+	"GET /api/v1/files":                    {Summary: "List files", QueryParams: []string{"table_id", "row_id", "field_id", "workspace_id"}, Response: []handlers.FileResponse{}},
+	"POST /api/v1/files":                   {Summary: "Create a file record", Request: handlers.CreateFileRequest{}},
+	"GET /api/v1/files/:id":                {Summary: "Get a file", Response: handlers.FileResponse{}},
+	"PATCH /api/v1/files/:id":              {Summary: "Update file metadata", Request: handlers.UpdateFileRequest{}},
+	"DELETE /api/v1/files/:id":             {Summary: "Soft delete a file", Response: messageResponse{}},
+	"GET /api/v1/files/:id/versions":       {Summary: "List file versions", Response: []handlers.FileResponse{}},
+	"POST /api/v1/files/:id/versions":      {Summary: "Create a file version", Request: handlers.CreateFileRequest{}},
+	"GET /api/v1/rows/:row_id/files":       {Summary: "List files for a row", Response: []handlers.FileResponse{}, Tags: []string{"files"}},
+	"POST /api/v1/rows/:row_id/files":      {Summary: "Attach a file to a row", Request: handlers.CreateFileRequest{}, Tags: []string{"files"}},
+	"GET /api/v1/rows/:row_id/files/stats": {Summary: "File statistics for a row", Response: fileStatsResponse{}, Tags: []string{"files"}},
+
+	// ==================== Google Drive (rows) ====================
+	"POST /api/v1/rows/:row_id/integrations/google_drive/folder":    {Summary: "Create an applicant folder in Google Drive", Response: driveFolderResponse{}, Tags: []string{"integrations"}},
+	"POST /api/v1/rows/:row_id/integrations/google_drive/sync-file": {Summary: "Sync a file to Google Drive", Request: handlers.SyncFileToDriveInput{}, Response: driveFileResponse{}, Tags: []string{"integrations"}},
+	"POST /api/v1/rows/:row_id/integrations/google_drive/sync-all":  {Summary: "Sync all row files to Google Drive", Response: driveSyncAllResponse{}, Tags: []string{"integrations"}},
+	"POST /api/v1/rows/:row_id/integrations/google_drive/summary":   {Summary: "Create an application summary document", Response: driveFileResponse{}, Tags: []string{"integrations"}},
+
+	// ==================== Documents (PII) ====================
+	"POST /api/v1/documents/analyze-pii":       {Summary: "Analyze a document for PII", Request: handlers.DocumentPIIRequest{}},
+	"POST /api/v1/documents/analyze-pii/batch": {Summary: "Analyze several documents for PII", Request: handlers.BatchAnalyzePIIRequest{}, Response: piiBatchResponse{}},
+	"POST /api/v1/documents/redact":            {Summary: "Get a redacted document", Request: handlers.DocumentPIIRequest{}},
+	"POST /api/v1/documents/redact/base64":     {Summary: "Get a redacted document as base64", Request: handlers.DocumentPIIRequest{}},
+
+	// ==================== Table / Row Links ====================
+	"GET /api/v1/table-links":                   {Summary: "List table links", QueryParams: []string{"table_id"}, Response: []models.TableLink{}},
+	"POST /api/v1/table-links":                  {Summary: "Create a table link", Request: handlers.CreateTableLinkInput{}, Response: models.TableLink{}},
+	"GET /api/v1/table-links/:id":               {Summary: "Get a table link", Response: models.TableLink{}},
+	"PATCH /api/v1/table-links/:id":             {Summary: "Update a table link", Request: handlers.UpdateTableLinkInput{}, Response: models.TableLink{}},
+	"DELETE /api/v1/table-links/:id":            {Summary: "Delete a table link", Status: http.StatusNoContent},
+	"GET /api/v1/row-links/rows/:row_id/linked": {Summary: "List rows linked to a row", QueryParams: []string{"link_id"}, Response: []handlers.LinkedRowResponse{}},
+	"POST /api/v1/row-links":                    {Summary: "Link two rows", Request: handlers.CreateTableRowLinkInput{}, Response: models.TableRowLink{}},
+	"PATCH /api/v1/row-links/:id":               {Summary: "Update a row link", Request: handlers.UpdateTableRowLinkInput{}, Response: models.TableRowLink{}},
+	"DELETE /api/v1/row-links/:id":              {Summary: "Delete a row link", Status: http.StatusNoContent},
+
+	// ==================== Forms ====================
+	"GET /api/v1/forms":                                       {Summary: "List forms", QueryParams: []string{"workspace_id"}, Response: []handlers.FormDTO{}},
+	"GET /api/v1/forms/list":                                  {Summary: "List forms for the Applications Hub", QueryParams: []string{"workspace_id"}, Response: []handlers.FormListItemDTO{}},
+	"POST /api/v1/forms":                                      {Summary: "Create a form", Request: handlers.CreateFormInput{}},
+	"GET /api/v1/forms/:id":                                   {Summary: "Get a form", Response: handlers.FormDTO{}},
+	"PATCH /api/v1/forms/:id":                                 {Summary: "Update a form", Request: handlers.UpdateFormInput{}},
+	"PUT /api/v1/forms/:id/structure":                         {Summary: "Replace a form's fields and sections", Request: handlers.UpdateFormStructureInput{}},
+	"PUT /api/v1/forms/:id/custom-slug":                       {Summary: "Set a form's custom URL slug", Request: handlers.UpdateFormCustomSlugInput{}},
+	"PUT /api/v1/forms/:id/dashboard":                         {Summary: "Update the applicant dashboard layout", Request: handlers.DashboardLayoutDTO{}, Response: handlers.DashboardLayoutDTO{}},
+	"DELETE /api/v1/forms/:id":                                {Summary: "Delete a form", Status: http.StatusNoContent},
+	"GET /api/v1/forms/:id/submissions":                       {Summary: "List form submissions", Response: []handlers.SubmissionListItem{}},
+	"GET /api/v1/forms/:id/fields":                            {Summary: "List form fields", Response: []models.FormField{}},
+	"DELETE /api/v1/forms/:id/submissions/:submission_id":     {Summary: "Delete a form submission", Response: messageResponse{}},
+	"POST /api/v1/forms/:id/submissions/bulk-delete":          {Summary: "Delete several form submissions", Request: handlers.BulkDeleteSubmissionsInput{}, Response: bulkDeleteResponse{}},
+	"GET /api/v1/forms/:id/analytics":                         {Summary: "Form analytics", Response: handlers.FormAnalyticsResponse{}},
+	"GET /api/v1/forms/:id/search":                            {Summary: "Search form submissions", QueryParams: []string{"q"}, Response: handlers.SearchResponse{}},
+	"GET /api/v1/forms/:id/integrations/google_drive":         {Summary: "Get form Google Drive settings", Response: models.FormIntegrationSetting{}},
+	"PATCH /api/v1/forms/:id/integrations/google_drive":       {Summary: "Update form Google Drive settings", Response: models.FormIntegrationSetting{}},
+	"POST /api/v1/forms/:id/integrations/google_drive/folder": {Summary: "Create the form's Google Drive folder", Response: driveFolderResponse{}},
+	"PATCH /api/v1/forms/:id/submissions/:submission_id":      {Summary: "Update submission metadata (status, stage, reviewer)", Response: models.Row{}},
+	"GET /api/v1/form-submissions/:id":                        {Summary: "Get a form submission", Response: models.FormSubmission{}, Tags: []string{"forms"}},
+	"GET /api/v1/form-fields":                                 {Summary: "List form fields", QueryParams: []string{"form_id", "table_id"}, Response: []handlers.LegacyField{}, Tags: []string{"forms"}},
+
+	// ==================== Review Export ====================
+	"GET /api/v1/review-export":     {Summary: "Export submissions for review", Query: models.ReviewExportFilters{}, Response: []models.ReviewSubmissionExport{}},
+	"GET /api/v1/review-export/csv": {Summary: "Export submissions for review as CSV", Query: models.ReviewExportFilters{}, Response: reviewExportCSVResponse{}},
+
+	// ==================== Ending Pages ====================
+	"GET /api/v1/ending-pages":             {Summary: "List ending pages", QueryParams: []string{"form_id"}, Response: []handlers.EndingPageDTO{}},
+	"POST /api/v1/ending-pages":            {Summary: "Create an ending page", Request: handlers.EndingPageDTO{}, Response: handlers.EndingPageDTO{}},
+	"GET /api/v1/ending-pages/:id":         {Summary: "Get an ending page", Response: handlers.EndingPageDTO{}},
+	"PUT /api/v1/ending-pages/:id":         {Summary: "Update an ending page", Request: handlers.EndingPageDTO{}, Response: handlers.EndingPageDTO{}},
+	"DELETE /api/v1/ending-pages/:id":      {Summary: "Delete an ending page", Response: messageResponse{}},
+	"PUT /api/v1/ending-pages/:id/default": {Summary: "Set the default ending page", Response: handlers.EndingPageDTO{}},
+	"PUT /api/v1/ending-pages/reorder":     {Summary: "Reorder ending page priorities", Request: handlers.ReorderEndingsRequest{}, Response: []handlers.EndingPageDTO{}},
+
+	// ==================== Search ====================
+	"GET /api/v1/search/smart":                    {Summary: "Smart search (full-text + fuzzy)", QueryParams: []string{"q", "workspace_id"}, Response: handlers.SearchResponse{}},
+	"POST /api/v1/search/hybrid":                  {Summary: "Hybrid keyword + semantic search", QueryParams: []string{"workspace_id"}, Request: handlers.SemanticSearchRequest{}, Response: handlers.SemanticSearchResponse{}},
+	"GET /api/v1/search/hybrid":                   {Summary: "Hybrid keyword + semantic search", QueryParams: []string{"workspace_id", "q", "limit"}, Response: handlers.SemanticSearchResponse{}},
+	"GET /api/v1/search/similar/:entity_id":       {Summary: "Find items similar to an entity", QueryParams: []string{"workspace_id", "limit"}, Response: similarItemsResponse{}},
+	"POST /api/v1/search/embeddings/generate":     {Summary: "Generate pending embeddings", QueryParams: []string{"workspace_id"}, Response: generateEmbeddingsResponse{}},
+	"GET /api/v1/search/embeddings/stats":         {Summary: "Embedding coverage stats", QueryParams: []string{"workspace_id"}, Response: embeddingStatsResponse{}},
+	"POST /api/v1/search/embeddings/queue":        {Summary: "Queue entities for embedding", Request: handlers.QueueEmbeddingInput{}, Response: messageResponse{}},
+	"POST /api/v1/search/rebuild-index":           {Summary: "Rebuild the search index", QueryParams: []string{"workspace_id"}, Response: rebuildIndexResponse{}},
+	"GET /api/v1/search/ai/table/:id":             {Summary: "Table schema for AI context", Response: json.RawMessage{}},
+	"GET /api/v1/search/ai/workspace/:id":         {Summary: "Workspace summary for AI context", Response: json.RawMessage{}},
+	"GET /api/v1/search":                          {Summary: "Universal workspace search", QueryParams: []string{"q", "workspace_id"}, Response: handlers.SearchResponse{}},
+	"GET /api/v1/search/suggestions":              {Summary: "Search suggestions", QueryParams: []string{"q", "workspace_id"}, Response: searchSuggestionsResponse{}},
+	"GET /api/v1/search/recent":                   {Summary: "Recent searches", QueryParams: []string{"workspace_id", "limit"}, Response: recentSearchesResponse{}},
+	"POST /api/v1/search/history":                 {Summary: "Save a search to history", Request: handlers.SaveSearchHistoryInput{}, Response: savedSearchResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/search/popular":                  {Summary: "Popular searches", QueryParams: []string{"workspace_id", "limit"}, Response: popularSearchesResponse{}},
+	"DELETE /api/v1/search/history/:workspace_id": {Summary: "Clear search history", Response: messageResponse{}},
+
+	// ==================== CRM ====================
+	"GET /api/v1/crm/applicants":                 {Summary: "List applicants", QueryParams: []string{"workspace_id"}, Response: []handlers.ApplicantCRM{}},
+	"GET /api/v1/crm/applicants/:id":             {Summary: "Get an applicant", Response: applicantDetailResponse{}},
+	"PATCH /api/v1/crm/applicants/:id":           {Summary: "Update an applicant", Request: handlers.UpdateApplicantRequest{}, Response: models.BetterAuthUser{}},
+	"POST /api/v1/crm/applicants/reset-password": {Summary: "Send an applicant password reset", Request: handlers.ResetApplicantPasswordRequest{}},
+	"POST /api/v1/crm/applicants/set-password":   {Summary: "Set an applicant password", Request: handlers.SetApplicantPasswordRequest{}},
+	"POST /api/v1/crm/import-users":              {Summary: "Import Better Auth users into a workspace", Request: handlers.ImportBAUsersRequest{}},
+
+	// ==================== Email ====================
+	"GET /api/v1/email/oauth/url":                {Summary: "Get the Gmail OAuth URL", QueryParams: []string{"workspace_id"}, Response: authURLResponse{}},
+	"GET /api/v1/email/connection":               {Summary: "Get the Gmail connection", QueryParams: []string{"workspace_id"}, Response: gmailConnectionResponse{}},
+	"DELETE /api/v1/email/connection":            {Summary: "Disconnect Gmail", QueryParams: []string{"workspace_id"}, Response: successResponse{}},
+	"GET /api/v1/email/accounts":                 {Summary: "List connected email accounts", QueryParams: []string{"workspace_id"}, Response: []models.GmailConnection{}},
+	"PATCH /api/v1/email/accounts/:id":           {Summary: "Update an email account", Response: models.GmailConnection{}},
+	"DELETE /api/v1/email/accounts/:id":          {Summary: "Delete an email account", Response: successResponse{}},
+	"GET /api/v1/email/signatures":               {Summary: "List email signatures", QueryParams: []string{"workspace_id"}, Response: []models.EmailSignature{}},
+	"POST /api/v1/email/signatures":              {Summary: "Create an email signature", Request: models.EmailSignature{}, Response: models.EmailSignature{}},
+	"PATCH /api/v1/email/signatures/:id":         {Summary: "Update an email signature", Response: models.EmailSignature{}},
+	"DELETE /api/v1/email/signatures/:id":        {Summary: "Delete an email signature", Response: successResponse{}},
+	"POST /api/v1/email/send":                    {Summary: "Send an email or campaign", QueryParams: []string{"workspace_id"}, Request: handlers.SendEmailRequest{}},
+	"GET /api/v1/email/history":                  {Summary: "Sent email history", QueryParams: []string{"workspace_id", "form_id"}, Response: []models.SentEmail{}},
+	"GET /api/v1/email/campaigns":                {Summary: "List email campaigns", QueryParams: []string{"workspace_id"}, Response: []models.EmailCampaign{}},
+	"GET /api/v1/email/templates":                {Summary: "List email templates", QueryParams: []string{"workspace_id"}, Response: []models.EmailTemplate{}},
+	"POST /api/v1/email/templates":               {Summary: "Create an email template", Request: models.EmailTemplate{}, Response: models.EmailTemplate{}},
+	"POST /api/v1/email/templates/validate":      {Summary: "Check an email template's merge tags", Request: handlers.ValidateEmailTemplateRequest{}},
+	"PATCH /api/v1/email/templates/:id":          {Summary: "Update an email template", Request: models.EmailTemplate{}, Response: models.EmailTemplate{}},
+	"DELETE /api/v1/email/templates/:id":         {Summary: "Delete an email template", Response: successResponse{}},
+	"GET /api/v1/email/submission/:id/history":   {Summary: "Email history for a submission", Description: "Mixes sent emails and Gmail thread messages, newest first.", Response: []interface{}{}},
+	"GET /api/v1/email/submission/:id/activity":  {Summary: "Email activity for a submission", Response: []handlers.SubmissionActivityItem{}},
+	"POST /api/v1/email/replies/sync":            {Summary: "Poll the workspace's Gmail threads for replies now", QueryParams: []string{"workspace_id"}, Response: replySyncResponse{}},
+	"GET /api/v1/email/analytics":                {Summary: "Workspace email analytics", QueryParams: []string{"workspace_id"}, Response: handlers.EmailAnalyticsStats{}},
+	"GET /api/v1/email/service-health":           {Summary: "Email provider health (refresh=true runs provider health checks first)", QueryParams: []string{"workspace_id", "refresh"}, Response: emailServiceHealthResponse{}},
+	"GET /api/v1/email/routing-policy":           {Summary: "Get the workspace email routing policy and available providers", QueryParams: []string{"workspace_id"}, Response: handlers.EmailRoutingPolicyResponse{}},
+	"PUT /api/v1/email/routing-policy":           {Summary: "Replace the workspace email routing rules", QueryParams: []string{"workspace_id"}, Request: handlers.UpdateEmailRoutingPolicyRequest{}, Response: handlers.EmailRoutingPolicyResponse{}},
+	"GET /api/v1/email/campaigns/:id/analytics":  {Summary: "Campaign analytics", Response: handlers.CampaignAnalyticsStats{}},
+	"POST /api/v1/email/campaigns/:id/pause":     {Summary: "Pause a scheduled or sending campaign", QueryParams: []string{"workspace_id"}, Response: models.EmailCampaign{}},
+	"POST /api/v1/email/campaigns/:id/resume":    {Summary: "Resume a paused campaign", QueryParams: []string{"workspace_id"}, Response: models.EmailCampaign{}},
+	"POST /api/v1/email/campaigns/:id/cancel":    {Summary: "Cancel a campaign and its unsent emails", QueryParams: []string{"workspace_id"}, Response: models.EmailCampaign{}},
+	"GET /api/v1/email/drafts":                   {Summary: "List email drafts", QueryParams: []string{"workspace_id"}, Response: []models.EmailDraft{}},
+	"GET /api/v1/email/drafts/:id":               {Summary: "Get an email draft", Response: models.EmailDraft{}},
+	"POST /api/v1/email/drafts":                  {Summary: "Create an email draft", Request: models.EmailDraft{}, Response: models.EmailDraft{}},
+	"PATCH /api/v1/email/drafts/:id":             {Summary: "Update an email draft", Response: models.EmailDraft{}},
+	"DELETE /api/v1/email/drafts/:id":            {Summary: "Delete an email draft", Response: successResponse{}},
+	"POST /api/v1/email/drafts/cleanup":          {Summary: "Delete old email drafts", Response: draftCleanupResponse{}},
+	"GET /api/v1/email/resend/integration":       {Summary: "Get the Resend integration", QueryParams: []string{"workspace_id"}, Response: models.ResendIntegration{}},
+	"POST /api/v1/email/resend/integration":      {Summary: "Create the Resend integration", Response: models.ResendIntegration{}},
+	"PATCH /api/v1/email/resend/integration":     {Summary: "Update the Resend integration", Response: models.ResendIntegration{}},
+	"DELETE /api/v1/email/resend/integration":    {Summary: "Delete the Resend integration", Response: successResponse{}},
+	"POST /api/v1/email/resend/integration/test": {Summary: "Send a Resend test email", Response: successMessageResponse{}},
+	"GET /api/v1/email/smtp/integration":         {Summary: "Get the SMTP relay integration", QueryParams: []string{"workspace_id"}, Response: models.SMTPIntegration{}},
+	"POST /api/v1/email/smtp/integration":        {Summary: "Create or replace the SMTP relay integration", QueryParams: []string{"workspace_id"}, Request: handlers.CreateSMTPIntegrationRequest{}, Response: models.SMTPIntegration{}},
+	"PATCH /api/v1/email/smtp/integration":       {Summary: "Update the SMTP relay integration", QueryParams: []string{"workspace_id"}, Request: handlers.UpdateSMTPIntegrationRequest{}, Response: models.SMTPIntegration{}},
+	"DELETE /api/v1/email/smtp/integration":      {Summary: "Delete the SMTP relay integration", QueryParams: []string{"workspace_id"}, Response: successResponse{}},
+	"POST /api/v1/email/smtp/integration/test":   {Summary: "Check the SMTP connection and optionally send a test email", QueryParams: []string{"workspace_id"}, Request: handlers.TestSMTPIntegrationRequest{}},
+	"GET /api/v1/email/queue":                    {Summary: "List email queue items", QueryParams: []string{"workspace_id", "status", "campaign_id"}, Response: []models.EmailQueueItem{}},
+	"GET /api/v1/email/queue/:id":                {Summary: "Get an email queue item", Response: models.EmailQueueItem{}},
+	"POST /api/v1/email/queue/:id/retry":         {Summary: "Retry an email queue item", Response: models.EmailQueueItem{}},
+	"POST /api/v1/email/queue/:id/cancel":        {Summary: "Cancel an email queue item", Response: models.EmailQueueItem{}},
+	"GET /api/v1/email/queue/stats":              {Summary: "Email queue statistics", QueryParams: []string{"workspace_id"}, Response: handlers.EmailQueueStats{}},
+	"GET /api/v1/email/suppressions":             {Summary: "List suppressed addresses (bounces, complaints, unsubscribes)", QueryParams: []string{"workspace_id", "reason", "search"}, Response: []models.EmailSuppression{}},
+	"POST /api/v1/email/suppressions":            {Summary: "Manually suppress an address", QueryParams: []string{"workspace_id"}, Request: handlers.CreateEmailSuppressionRequest{}, Response: models.EmailSuppression{}},
+	"DELETE /api/v1/email/suppressions/:id":      {Summary: "Remove an address from the suppression list", QueryParams: []string{"workspace_id"}, Response: messageResponse{}},
+
+	// ==================== Reports / Admin / AI ====================
+	"POST /api/v1/reports/generate":       {Summary: "Generate an AI report", Request: handlers.GenerateReportRequest{}},
+	"GET /api/v1/reports/stats":           {Summary: "Workspace statistics", QueryParams: []string{"workspace_id"}, Response: workspaceStatsResponse{}},
+	"GET /api/v1/reports/is-report-query": {Summary: "Detect whether a query asks for a report", QueryParams: []string{"q"}, Response: reportQueryResponse{}},
+	"GET /api/v1/admin/users":             {Summary: "List auth users", Description: "?type=better_auth lists Better Auth users instead, with their own shape.", Response: []handlers.AuthUser{}},
+	"DELETE /api/v1/admin/users":          {Summary: "Delete an auth user", Request: handlers.DeleteUserRequest{}},
+	"POST /api/v1/ai/translate":           {Summary: "Translate form content", Request: handlers.TranslateInput{}},
+
+	// ==================== Recommendations ====================
+	"GET /api/v1/recommendations":                                             {Summary: "List recommendation requests", QueryParams: []string{"submission_id"}, Response: []models.RecommendationRequest{}},
+	"POST /api/v1/recommendations":                                            {Summary: "Create a recommendation request and email the recommender", Request: models.CreateRecommendationRequestInput{}},
+	"GET /api/v1/recommendations/:id":                                         {Summary: "Get a recommendation request", Response: models.RecommendationRequest{}},
+	"PATCH /api/v1/recommendations/:id":                                       {Summary: "Update recommender info", Response: models.RecommendationRequest{}},
+	"POST /api/v1/recommendations/:id/remind":                                 {Summary: "Send a recommendation reminder", Request: handlers.RecommendationReminderInput{}, Response: messageResponse{}},
+	"DELETE /api/v1/recommendations/:id":                                      {Summary: "Cancel a recommendation request", Response: messageResponse{}},
+	"GET /api/v1/recommendations/submission/:submissionId":                    {Summary: "Recommendations for a submission (reviewers)", Response: []models.RecommendationRequest{}},
+	"POST /api/v1/recommendations/submission/:submissionId/google-drive/sync": {Summary: "Sync a submission's recommendation documents to Google Drive", Response: handlers.RecommendationBackfillSummary{}},
+	"POST /api/v1/recommendations/form/:formId/google-drive/backfill":         {Summary: "Backfill a form's recommendation documents to Google Drive", Response: recommendationBackfillResponse{}},
+
+	// ==================== API V2 ====================
+	"GET /api/v2/forms":                                              {Summary: "List forms", QueryParams: []string{"workspace_id"}, Response: []models.Form{}, Tags: []string{"forms-v2"}},
+	"POST /api/v2/forms":                                             {Summary: "Create a form", Response: models.Form{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/forms/:id":                                          {Summary: "Get a form with sections and fields", Response: models.Form{}, Tags: []string{"forms-v2"}},
+	"PATCH /api/v2/forms/:id":                                        {Summary: "Update a form", Response: models.Form{}, Tags: []string{"forms-v2"}},
+	"POST /api/v2/forms/:id/publish":                                 {Summary: "Publish the form's current schema as its next immutable version (migrate_drafts re-pins unsubmitted submissions)", Request: handlers.PublishFormInput{}, Response: publishFormResponse{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/forms/:id/versions":                                 {Summary: "List a form's published versions", Response: []models.FormVersion{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/forms/:id/versions/:version":                        {Summary: "Get a form as published in a version", Response: models.Form{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/forms/:id/versions/:version/diff":                   {Summary: "Diff a published version against another (default: the previous one)", QueryParams: []string{"from"}, Response: services.FormVersionDiff{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/forms/:id/extensions":                               {Summary: "List applicants' deadline extensions", Response: []models.FormDeadlineExtension{}, Tags: []string{"forms-v2"}},
+	"PUT /api/v2/forms/:id/extensions/:user_id":                      {Summary: "Grant or replace an applicant's deadline extension", Response: models.FormDeadlineExtension{}, Tags: []string{"forms-v2"}},
+	"DELETE /api/v2/forms/:id/extensions/:user_id":                   {Summary: "Revoke an applicant's deadline extension", Response: messageResponse{}, Tags: []string{"forms-v2"}},
+	"POST /api/v2/forms/:id/submissions/start":                       {Summary: "Start or resume the user's submission (403 when closed, 409 when full)", Response: models.FormSubmission{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/submissions/:id":                                    {Summary: "Get a submission with responses", Response: models.FormSubmission{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/submissions/:id/path":                               {Summary: "Get the sections on the applicant's path, the next section and missing required fields", Response: services.FormPath{}, Tags: []string{"forms-v2"}},
+	"PUT /api/v2/submissions/:id/responses":                          {Summary: "Save submission responses", Response: models.FormSubmission{}, Tags: []string{"forms-v2"}},
+	"POST /api/v2/submissions/:id/submit":                            {Summary: "Submit a submission", Response: models.FormSubmission{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/forms/:id/rubrics":                                  {Summary: "List a form's review rubrics", Response: []models.ReviewRubric{}, Tags: []string{"reviews"}},
+	"POST /api/v2/forms/:id/rubrics":                                 {Summary: "Create a rubric of weighted criteria (owners and editors)", Response: models.ReviewRubric{}, Tags: []string{"reviews"}},
+	"PATCH /api/v2/forms/:id/rubrics/:rubric_id":                     {Summary: "Update a rubric", Response: models.ReviewRubric{}, Tags: []string{"reviews"}},
+	"DELETE /api/v2/forms/:id/rubrics/:rubric_id":                    {Summary: "Delete a rubric", Response: messageResponse{}, Tags: []string{"reviews"}},
+	"GET /api/v2/forms/:id/review-stages":                            {Summary: "List a form's review stages in pipeline order", Response: []models.ReviewStage{}, Tags: []string{"reviews"}},
+	"POST /api/v2/forms/:id/review-stages":                           {Summary: "Add a review stage with entry and exit rules", Response: models.ReviewStage{}, Tags: []string{"reviews"}},
+	"PATCH /api/v2/forms/:id/review-stages/:stage_id":                {Summary: "Update a review stage", Response: models.ReviewStage{}, Tags: []string{"reviews"}},
+	"DELETE /api/v2/forms/:id/review-stages/:stage_id":               {Summary: "Delete a review stage", Response: messageResponse{}, Tags: []string{"reviews"}},
+	"GET /api/v2/forms/:id/review-stages/:stage_id/scores":           {Summary: "Rank the submissions in a stage by aggregated score", Response: stageScoresResponse{}, Tags: []string{"reviews"}},
+	"POST /api/v2/forms/:id/review-stages/:stage_id/auto-assign":     {Summary: "Fill open reviewer slots from the stage's pool, balancing load and skipping conflicts", Response: services.AssignmentResult{}, Tags: []string{"reviews"}},
+	"POST /api/v2/forms/:id/reviewers/:reviewer_id/drop":             {Summary: "Take a reviewer off their open assignments and reassign them", Response: services.AssignmentResult{}, Tags: []string{"reviews"}},
+	"GET /api/v2/forms/:id/reviewer-conflicts":                       {Summary: "List reviewers' declared conflicts of interest", Response: []models.ReviewerConflict{}, Tags: []string{"reviews"}},
+	"PUT /api/v2/forms/:id/reviewer-conflicts/:reviewer_id":          {Summary: "Declare a reviewer's conflicts of interest", Response: models.ReviewerConflict{}, Tags: []string{"reviews"}},
+	"DELETE /api/v2/forms/:id/reviewer-conflicts/:reviewer_id":       {Summary: "Delete a reviewer's conflict declaration", Response: messageResponse{}, Tags: []string{"reviews"}},
+	"GET /api/v2/forms/:id/review-assignment-audits":                 {Summary: "List reviewer assignment decisions, newest first", QueryParams: []string{"submission_id", "reviewer_id", "action", "limit", "offset"}, Response: []models.ReviewAssignmentAudit{}, Tags: []string{"reviews"}},
+	"GET /api/v2/submissions/:id/documents/:field_key/:index":        {Summary: "Download a blind-review copy of an upload with the applicant's PII redacted", Produces: "application/octet-stream", Tags: []string{"reviews"}},
+	"POST /api/v2/submissions/:id/review/decline":                    {Summary: "Decline your assignment to a submission; the slot is refilled from the pool", Request: handlers.DeclineReviewInput{}, Response: declineReviewResponse{}, Tags: []string{"reviews"}},
+	"GET /api/v2/submissions/:id/review":                             {Summary: "Get a submission's stage, reviewers, score sheets and aggregate", Response: submissionReviewResponse{}, Tags: []string{"reviews"}},
+	"POST /api/v2/submissions/:id/review/assignments":                {Summary: "Assign reviewers at the submission's current stage (409 on a declared conflict unless override)", Response: []models.ReviewAssignment{}, Tags: []string{"reviews"}},
+	"DELETE /api/v2/submissions/:id/review/assignments/:reviewer_id": {Summary: "Unassign a reviewer", Response: messageResponse{}, Tags: []string{"reviews"}},
+	"POST /api/v2/submissions/:id/review/advance":                    {Summary: "Move a submission to the next stage that accepts it (409 when exit rules aren't met)", Request: handlers.AdvanceSubmissionInput{}, Response: stageResponse{}, Tags: []string{"reviews"}},
+	"PUT /api/v2/submissions/:id/review/stage":                       {Summary: "Move a submission to any stage, bypassing rules", Request: handlers.MoveSubmissionStageInput{}, Response: stageResponse{}, Tags: []string{"reviews"}},
+	"PUT /api/v2/submissions/:id/review/score":                       {Summary: "Save or submit the current user's score sheet", Request: handlers.SubmitReviewScoreInput{}, Response: scoreSheetResponse{}, Tags: []string{"reviews"}},
+	"GET /api/v2/reviews/queue":                                      {Summary: "List submissions waiting on the current user's review", QueryParams: []string{"form_id", "status"}, Response: []models.ReviewQueueItem{}, Tags: []string{"reviews"}},
+	"GET /api/v2/forms/:id/award-pools":                              {Summary: "List a form's award pools with allocated and remaining budget", Response: []models.AwardPool{}, Tags: []string{"decisions"}},
+	"POST /api/v2/forms/:id/award-pools":                             {Summary: "Create an award pool (owners and editors)", Response: models.AwardPool{}, Tags: []string{"decisions"}},
+	"PATCH /api/v2/forms/:id/award-pools/:pool_id":                   {Summary: "Update an award pool (409 when the budget would drop below what is awarded)", Response: models.AwardPool{}, Tags: []string{"decisions"}},
+	"DELETE /api/v2/forms/:id/award-pools/:pool_id":                  {Summary: "Delete an award pool no decision draws on", Response: messageResponse{}, Tags: []string{"decisions"}},
+	"GET /api/v2/forms/:id/decisions":                                {Summary: "List a form's decisions", QueryParams: []string{"decision", "released"}, Response: []models.SubmissionDecision{}, Tags: []string{"decisions"}},
+	"POST /api/v2/forms/:id/decisions/release":                       {Summary: "Release unreleased decisions now or schedule them for release_at", Request: handlers.ReleaseDecisionsInput{}, Response: releaseDecisionsResponse{}, Tags: []string{"decisions"}},
+	"POST /api/v2/forms/:id/decisions/promote-waitlist":              {Summary: "Accept the next waitlisted submissions while their award pools can cover them", Request: handlers.PromoteWaitlistInput{}, Response: promoteWaitlistResponse{}, Tags: []string{"decisions"}},
+	"GET /api/v2/submissions/:id/decision":                           {Summary: "Get a submission's decision (applicants only see it once released)", Response: models.SubmissionDecision{}, Tags: []string{"decisions"}},
+	"PUT /api/v2/submissions/:id/decision":                           {Summary: "Accept, waitlist or reject a submission (409 when the award pool can't cover the award)", Request: services.DecisionInput{}, Response: models.SubmissionDecision{}, Tags: []string{"decisions"}},
+	"DELETE /api/v2/submissions/:id/decision":                        {Summary: "Clear an unreleased decision", Response: messageResponse{}, Tags: []string{"decisions"}},
+	"GET /api/v2/submissions/:id/events":                             {Summary: "A submission's event log (applicants only see applicant-visible events)", Response: []models.SubmissionEvent{}, Tags: []string{"forms-v2"}},
+	"GET /api/v2/forms/by-slug/:workspace_slug/:form_slug":           {Summary: "Get a published form by workspace and form slug", Public: true, Response: models.Form{}, Tags: []string{"forms-v2"}},
+
+	// ==================== Diagnostics ====================
+	"GET /api/v1/diagnostics/discovered-files":                           {Summary: "Files discovered for a user", Public: true, QueryParams: []string{"email"}, Response: discoveredFilesResponse{}},
+	"GET /api/v1/diagnostics/submissions/:submissionId/discovered-files": {Summary: "Files discovered for a submission", Public: true, Response: submissionDiscoveredFilesResponse{}},
+}
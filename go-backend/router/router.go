@@ -1,6 +1,7 @@
 package router
 
 import (
+	"log"
 	"net/http"
 	"strings"
 
@@ -9,10 +10,25 @@ import (
 	"github.com/Jsanchez767/matic-platform/middleware"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func SetupRouter(cfg *config.Config) *gin.Engine {
-	r := gin.Default()
+	// gin.New instead of gin.Default: requests and panics are logged through
+	// slog (see PerformanceLoggingMiddleware / RecoveryMiddleware)
+	r := gin.New()
+	r.Use(middleware.RecoveryMiddleware())
+
+	// TRACING: one server span per request, named by route template
+	r.Use(otelgin.Middleware(cfg.TracingServiceName,
+		otelgin.WithGinFilter(func(c *gin.Context) bool {
+			path := c.FullPath()
+			return path != "/metrics" && path != "/health" && path != "/healthz" && path != "/readyz"
+		}),
+	))
+
+	// LOGGING: request ID + workspace ID in the request context
+	r.Use(middleware.RequestIDMiddleware())
 
 	// CORS configuration with dynamic origin checking for subdomains
 	corsConfig := cors.Config{
@@ -39,8 +55,8 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			return false
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Cookie", "X-Portal-Token"},
-		ExposeHeaders:    []string{"Content-Length", "X-Response-Time", "X-Response-Time-Ms"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Cookie", "X-Portal-Token", "X-Request-ID", "traceparent", "tracestate"},
+		ExposeHeaders:    []string{"Content-Length", "X-Response-Time", "X-Response-Time-Ms", "X-Request-ID"},
 		AllowCredentials: true,
 	}
 	r.Use(cors.New(corsConfig))
@@ -48,6 +64,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	// PERFORMANCE MONITORING: Log slow requests (>500ms)
 	r.Use(middleware.PerformanceLoggingMiddleware(500))
 	r.Use(middleware.RequestTimingMiddleware())
+	r.Use(middleware.MetricsMiddleware())
 
 	// Load HTML templates
 	r.LoadHTMLGlob("templates/*")
@@ -75,13 +92,17 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				"forms":      "/api/v1/forms",
 			},
 			"documentation": gin.H{
-				"html":   "/",
-				"json":   "/api/v1/docs",
-				"health": "/health",
+				"html":    "/",
+				"json":    "/api/v1/docs",
+				"openapi": "/api/openapi.json",
+				"health":  "/health",
 			},
 		})
 	})
 
+	// Prometheus metrics (protected by METRICS_TOKEN; required in release mode)
+	r.GET("/metrics", middleware.MetricsHandler(cfg.MetricsToken))
+
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -91,6 +112,10 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		})
 	})
 
+	// Liveness / readiness probes (per-component JSON report on /readyz)
+	r.GET("/healthz", handlers.Healthz)
+	r.GET("/readyz", handlers.Readyz)
+
 	// API v1 routes
 	api := r.Group("/api/v1")
 	{
@@ -240,6 +265,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 
 		// Public Email Tracking (must be public for tracking pixel to work)
 		api.GET("/email/track/:tracking_id", handlers.TrackEmailOpen)
+		api.GET("/email/click/:tracking_id/:link_id", handlers.TrackEmailClick)
 		api.GET("/email/oauth/callback", handlers.HandleGmailCallback)
 
 		// Auth Email Generation (public - used by better-auth for professional email templates)
@@ -252,6 +278,15 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		// Public Resend Webhook (must be public for Resend to send events)
 		api.POST("/email/resend/webhook", handlers.HandleResendWebhook)
 
+		// Public Inbound Email Webhook (replies to the plus-addressed reply-to; the address token is signed)
+		api.POST("/email/inbound/webhook", handlers.HandleInboundEmailWebhook)
+
+		// Public Unsubscribe & Preference Center (signed token from the email)
+		api.POST("/email/unsubscribe/:token", handlers.OneClickUnsubscribe)
+		api.GET("/email/unsubscribe/:token", handlers.UnsubscribeLanding)
+		api.GET("/email/preferences/:token", handlers.GetEmailPreferences)
+		api.PUT("/email/preferences/:token", handlers.UpdateEmailPreferences)
+
 		// Recommendation Routes (Public with Token - for recommenders)
 		api.GET("/recommend/:token", handlers.GetRecommendationByToken)               // Get recommendation request details
 		api.POST("/recommend/:token/submit", handlers.SubmitRecommendation)           // Submit recommendation
@@ -595,20 +630,27 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				// History & Campaigns
 				email.GET("/history", handlers.GetEmailHistory)
 				email.GET("/campaigns", handlers.GetEmailCampaigns)
+				email.POST("/campaigns/:id/pause", handlers.PauseEmailCampaign)
+				email.POST("/campaigns/:id/resume", handlers.ResumeEmailCampaign)
+				email.POST("/campaigns/:id/cancel", handlers.CancelEmailCampaign)
 
 				// Templates
 				email.GET("/templates", handlers.GetEmailTemplates)
 				email.POST("/templates", handlers.CreateEmailTemplate)
+				email.POST("/templates/validate", handlers.ValidateEmailTemplate)
 				email.PATCH("/templates/:id", handlers.UpdateEmailTemplate)
 				email.DELETE("/templates/:id", handlers.DeleteEmailTemplate)
 
 				// Submission-specific email history
 				email.GET("/submission/:id/history", handlers.GetSubmissionEmailHistory)
 				email.GET("/submission/:id/activity", handlers.GetSubmissionActivity)
+				email.POST("/replies/sync", handlers.SyncEmailReplies)
 
 				// Analytics
 				email.GET("/analytics", handlers.GetEmailAnalytics)
 				email.GET("/service-health", handlers.GetEmailServiceHealth)
+				email.GET("/routing-policy", handlers.GetEmailRoutingPolicy)
+				email.PUT("/routing-policy", handlers.UpdateEmailRoutingPolicy)
 				email.GET("/campaigns/:id/analytics", handlers.GetEmailCampaignAnalytics)
 
 				// Email Drafts
@@ -625,6 +667,11 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				email.PATCH("/resend/integration", handlers.UpdateResendIntegration)
 				email.DELETE("/resend/integration", handlers.DeleteResendIntegration)
 				email.POST("/resend/integration/test", handlers.TestResendIntegration)
+				email.GET("/smtp/integration", handlers.GetSMTPIntegration)
+				email.POST("/smtp/integration", handlers.CreateSMTPIntegration)
+				email.PATCH("/smtp/integration", handlers.UpdateSMTPIntegration)
+				email.DELETE("/smtp/integration", handlers.DeleteSMTPIntegration)
+				email.POST("/smtp/integration/test", handlers.TestSMTPIntegration)
 
 				// Email Queue
 				email.GET("/queue", handlers.ListEmailQueueItems)
@@ -632,6 +679,11 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				email.POST("/queue/:id/retry", handlers.RetryEmailQueueItem)
 				email.POST("/queue/:id/cancel", handlers.CancelEmailQueueItem)
 				email.GET("/queue/stats", handlers.GetEmailQueueStats)
+
+				// Suppression List
+				email.GET("/suppressions", handlers.ListEmailSuppressions)
+				email.POST("/suppressions", handlers.CreateEmailSuppression)
+				email.DELETE("/suppressions/:id", handlers.DeleteEmailSuppression)
 			}
 
 			// AI Reports
@@ -719,9 +771,57 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		apiV2.POST("/forms", handlers.CreateFormV2)
 		apiV2.GET("/forms/:id", handlers.GetFormV2)
 		apiV2.PATCH("/forms/:id", handlers.UpdateFormV2)
+		apiV2.POST("/forms/:id/publish", handlers.PublishFormV2)
+		apiV2.GET("/forms/:id/versions", handlers.ListFormVersionsV2)
+		apiV2.GET("/forms/:id/versions/:version", handlers.GetFormVersionV2)
+		apiV2.GET("/forms/:id/versions/:version/diff", handlers.DiffFormVersionsV2)
+		apiV2.GET("/forms/:id/extensions", handlers.ListFormExtensionsV2)
+		apiV2.PUT("/forms/:id/extensions/:user_id", handlers.GrantFormExtensionV2)
+		apiV2.DELETE("/forms/:id/extensions/:user_id", handlers.RevokeFormExtensionV2)
+		apiV2.POST("/forms/:id/submissions/start", handlers.StartSubmissionV2)
 		apiV2.GET("/submissions/:id", handlers.GetSubmissionV2)
+		apiV2.GET("/submissions/:id/path", handlers.GetSubmissionPathV2)
 		apiV2.PUT("/submissions/:id/responses", handlers.SaveResponsesV2)
 		apiV2.POST("/submissions/:id/submit", handlers.SubmitSubmissionV2)
+		apiV2.GET("/submissions/:id/events", handlers.ListSubmissionEventsV2)
+
+		// Review pipeline
+		apiV2.GET("/forms/:id/rubrics", handlers.ListReviewRubricsV2)
+		apiV2.POST("/forms/:id/rubrics", handlers.CreateReviewRubricV2)
+		apiV2.PATCH("/forms/:id/rubrics/:rubric_id", handlers.UpdateReviewRubricV2)
+		apiV2.DELETE("/forms/:id/rubrics/:rubric_id", handlers.DeleteReviewRubricV2)
+		apiV2.GET("/forms/:id/review-stages", handlers.ListReviewStagesV2)
+		apiV2.POST("/forms/:id/review-stages", handlers.CreateReviewStageV2)
+		apiV2.PATCH("/forms/:id/review-stages/:stage_id", handlers.UpdateReviewStageV2)
+		apiV2.DELETE("/forms/:id/review-stages/:stage_id", handlers.DeleteReviewStageV2)
+		apiV2.GET("/forms/:id/review-stages/:stage_id/scores", handlers.GetReviewStageScoresV2)
+		apiV2.POST("/forms/:id/review-stages/:stage_id/auto-assign", handlers.AutoAssignStageReviewersV2)
+		apiV2.POST("/forms/:id/reviewers/:reviewer_id/drop", handlers.DropReviewerV2)
+		apiV2.GET("/forms/:id/reviewer-conflicts", handlers.ListReviewerConflictsV2)
+		apiV2.PUT("/forms/:id/reviewer-conflicts/:reviewer_id", handlers.SaveReviewerConflictV2)
+		apiV2.DELETE("/forms/:id/reviewer-conflicts/:reviewer_id", handlers.DeleteReviewerConflictV2)
+		apiV2.GET("/forms/:id/review-assignment-audits", handlers.ListReviewAssignmentAuditsV2)
+		apiV2.GET("/submissions/:id/review", handlers.GetSubmissionReviewV2)
+		apiV2.POST("/submissions/:id/review/assignments", handlers.AssignReviewersV2)
+		apiV2.DELETE("/submissions/:id/review/assignments/:reviewer_id", handlers.UnassignReviewerV2)
+		apiV2.POST("/submissions/:id/review/advance", handlers.AdvanceSubmissionV2)
+		apiV2.PUT("/submissions/:id/review/stage", handlers.MoveSubmissionStageV2)
+		apiV2.PUT("/submissions/:id/review/score", handlers.SubmitReviewScoreV2)
+		apiV2.POST("/submissions/:id/review/decline", handlers.DeclineReviewV2)
+		apiV2.GET("/submissions/:id/documents/:field_key/:index", handlers.GetRedactedSubmissionDocumentV2)
+		apiV2.GET("/reviews/queue", handlers.GetReviewQueueV2)
+
+		// Decisions and awards
+		apiV2.GET("/forms/:id/award-pools", handlers.ListAwardPoolsV2)
+		apiV2.POST("/forms/:id/award-pools", handlers.CreateAwardPoolV2)
+		apiV2.PATCH("/forms/:id/award-pools/:pool_id", handlers.UpdateAwardPoolV2)
+		apiV2.DELETE("/forms/:id/award-pools/:pool_id", handlers.DeleteAwardPoolV2)
+		apiV2.GET("/forms/:id/decisions", handlers.ListDecisionsV2)
+		apiV2.POST("/forms/:id/decisions/release", handlers.ReleaseDecisionsV2)
+		apiV2.POST("/forms/:id/decisions/promote-waitlist", handlers.PromoteWaitlistV2)
+		apiV2.GET("/submissions/:id/decision", handlers.GetSubmissionDecisionV2)
+		apiV2.PUT("/submissions/:id/decision", handlers.SaveSubmissionDecisionV2)
+		apiV2.DELETE("/submissions/:id/decision", handlers.DeleteSubmissionDecisionV2)
 	}
 
 	// Public V2 routes (no auth required for form viewing)
@@ -737,5 +837,11 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		diagnosticsPublic.GET("/submissions/:submissionId/discovered-files", handlers.ListSubmissionDiscoveredFiles)
 	}
 
+	// OpenAPI 3 document generated from the routes above (must be registered last)
+	registerOpenAPIRoute(r)
+	if missing := MissingRouteSpecs(r); len(missing) > 0 {
+		log.Printf("⚠️  OpenAPI: %d routes have no spec (run go run ./cmd/openapi_check): %v", len(missing), missing)
+	}
+
 	return r
 }
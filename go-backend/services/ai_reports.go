@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strings"
 )
@@ -382,9 +383,9 @@ func InitAIReports(cohereAPIKey string) {
 	if cohereAPIKey != "" {
 		cohereClient := NewCohereClient(cohereAPIKey)
 		AIReports = NewAIReportService(cohereClient)
-		fmt.Printf("[AIReports] Service initialized with Cohere\n")
+		log.Printf("[AIReports] Service initialized with Cohere")
 	} else {
-		fmt.Printf("[AIReports] Warning: No Cohere API key, AI reports disabled\n")
+		log.Printf("[AIReports] Warning: No Cohere API key, AI reports disabled")
 	}
 }
 
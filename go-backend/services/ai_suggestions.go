@@ -3,6 +3,7 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
 	"time"
@@ -580,7 +581,7 @@ func (s *AISuggestionService) AnalyzeWorkspace(workspaceID uuid.UUID) error {
 		})
 		if err != nil {
 			// Log but continue with other tables
-			fmt.Printf("Failed to analyze table %s: %v\n", table.Name, err)
+			log.Printf("Failed to analyze table %s: %v", table.Name, err)
 		}
 
 		// Rate limit
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// redactedValue replaces a hidden answer, matching VersionService.redactPIIFields
+const redactedValue = "[REDACTED]"
+
+// BlindRedactor hides a blind-review form's identity fields from reviewers
+// and points its uploads at PII-redacted copies
+type BlindRedactor struct {
+	Settings   models.BlindReviewSettings
+	hiddenKeys []string             // Data keys to redact: field keys and IDs, legacy field names and IDs
+	hiddenIDs  map[uuid.UUID]bool   // Hidden FormField IDs
+	fileKeys   map[string]bool      // File and image field keys and IDs
+	fileIDs    map[uuid.UUID]string // File and image field IDs -> key
+}
+
+// BlindReviewConfig reads the blind review settings from form settings JSON
+func BlindReviewConfig(settingsJSON datatypes.JSON) models.BlindReviewSettings {
+	var settings models.FormSettings
+	if len(settingsJSON) > 0 {
+		json.Unmarshal(settingsJSON, &settings)
+	}
+	if settings.BlindReview == nil {
+		return models.BlindReviewSettings{}
+	}
+	return *settings.BlindReview
+}
+
+// NewBlindRedactor returns the redactor for a form, or nil when the form
+// doesn't use blind review. Besides the configured hidden fields, columns
+// flagged is_pii on the form's legacy table stay hidden.
+func NewBlindRedactor(form models.Form, fields []models.FormField) *BlindRedactor {
+	settings := BlindReviewConfig(form.Settings)
+	if !settings.Enabled {
+		return nil
+	}
+
+	hidden := map[string]bool{}
+	for _, key := range settings.HiddenFields {
+		hidden[key] = true
+	}
+	if form.LegacyTableID != nil {
+		for _, name := range (&VersionService{}).getPIIFields(*form.LegacyTableID) {
+			hidden[name] = true
+		}
+	}
+
+	r := &BlindRedactor{
+		Settings:  settings,
+		hiddenIDs: map[uuid.UUID]bool{},
+		fileKeys:  map[string]bool{},
+		fileIDs:   map[uuid.UUID]string{},
+	}
+	for key := range hidden {
+		r.hiddenKeys = append(r.hiddenKeys, key)
+	}
+	for _, field := range fields {
+		if field.FieldType == "file" || field.FieldType == "image" {
+			r.fileKeys[field.FieldKey] = true
+			r.fileKeys[field.ID.String()] = true
+			r.fileIDs[field.ID] = field.FieldKey
+			if field.LegacyFieldID != nil {
+				r.fileKeys[field.LegacyFieldID.String()] = true
+			}
+		}
+		if !hidden[field.FieldKey] {
+			continue
+		}
+		r.hiddenIDs[field.ID] = true
+		r.hiddenKeys = append(r.hiddenKeys, field.ID.String())
+		if field.LegacyFieldID != nil {
+			r.hiddenKeys = append(r.hiddenKeys, field.LegacyFieldID.String())
+		}
+	}
+	return r
+}
+
+// HiddenKeys returns the data keys the redactor hides
+func (r *BlindRedactor) HiddenKeys() []string {
+	return r.hiddenKeys
+}
+
+// RedactData hides identity answers in data keyed by field key or ID and, when
+// documents are redacted, swaps upload URLs for their redacted copies. With
+// no submission ID to serve copies from, uploads are hidden outright.
+func (r *BlindRedactor) RedactData(submissionID uuid.UUID, data map[string]interface{}) map[string]interface{} {
+	redacted := (&VersionService{}).redactPIIFields(data, r.hiddenKeys)
+	if !r.Settings.RedactDocuments {
+		return redacted
+	}
+	for key := range r.fileKeys {
+		value, ok := redacted[key]
+		if !ok || value == nil || value == redactedValue {
+			continue
+		}
+		if submissionID == uuid.Nil {
+			redacted[key] = redactedValue
+		} else {
+			redacted[key] = redactFiles(submissionID, key, value)
+		}
+	}
+	return redacted
+}
+
+// RedactSubmission hides the applicant and their identity answers on a
+// submission about to be shown to a reviewer
+func (r *BlindRedactor) RedactSubmission(submission *models.FormSubmission) {
+	submission.UserID = ""
+	submission.User = nil
+
+	if len(submission.RawData) > 0 {
+		var data map[string]interface{}
+		if err := json.Unmarshal(submission.RawData, &data); err == nil {
+			if encoded, err := json.Marshal(r.RedactData(submission.ID, data)); err == nil {
+				submission.RawData = datatypes.JSON(encoded)
+			}
+		}
+	}
+
+	for i := range submission.Responses {
+		response := &submission.Responses[i]
+		switch {
+		case r.hiddenIDs[response.FieldID]:
+			response.ResetValue()
+			response.ValueType = "text"
+			response.ValueText = stringRef(redactedValue)
+			response.Attachments = nil
+		case r.Settings.RedactDocuments && r.fileIDs[response.FieldID] != "":
+			if len(response.ValueJSON) > 0 {
+				var value interface{}
+				if err := json.Unmarshal(response.ValueJSON, &value); err == nil {
+					if encoded, err := json.Marshal(redactFiles(submission.ID, r.fileIDs[response.FieldID], value)); err == nil {
+						response.ValueJSON = datatypes.JSON(encoded)
+					}
+				}
+			}
+			response.Attachments = nil
+		}
+	}
+}
+
+// KnownPII returns the applicant's hidden answers as text, for the document
+// redactor to black out wherever they appear in an upload
+func (r *BlindRedactor) KnownPII(data map[string]interface{}) map[string]string {
+	known := map[string]string{}
+	for _, key := range r.hiddenKeys {
+		switch value := data[key].(type) {
+		case nil:
+		case string:
+			if value != "" {
+				known[key] = value
+			}
+		case map[string]interface{}, []interface{}:
+			// Structured answers (addresses, multi-selects) are too noisy to match
+		default:
+			known[key] = fmt.Sprint(value)
+		}
+	}
+	return known
+}
+
+// RedactedDocumentPath is where a reviewer fetches the redacted copy of the
+// index-th upload in a file field, named by key or ID
+func RedactedDocumentPath(submissionID uuid.UUID, fieldKey string, index int) string {
+	return fmt.Sprintf("/api/v2/submissions/%s/documents/%s/%d", submissionID, fieldKey, index)
+}
+
+// redactFiles rewrites file objects ({url, name, size, mime_type}) to point
+// at their redacted copies. Original names are dropped as they often carry
+// the applicant's name.
+func redactFiles(submissionID uuid.UUID, fieldKey string, value interface{}) interface{} {
+	redactFile := func(file map[string]interface{}, index int) map[string]interface{} {
+		name, _ := file["name"].(string)
+		out := map[string]interface{}{
+			"url":      RedactedDocumentPath(submissionID, fieldKey, index),
+			"name":     fmt.Sprintf("document-%d%s", index+1, path.Ext(name)),
+			"redacted": true,
+		}
+		if size, ok := file["size"]; ok {
+			out["size"] = size
+		}
+		if mimeType, ok := file["mime_type"]; ok {
+			out["mime_type"] = mimeType
+		}
+		return out
+	}
+
+	switch files := value.(type) {
+	case map[string]interface{}:
+		return redactFile(files, 0)
+	case []interface{}:
+		out := make([]interface{}, len(files))
+		for i, item := range files {
+			if file, ok := item.(map[string]interface{}); ok {
+				out[i] = redactFile(file, i)
+			} else {
+				out[i] = redactedValue
+			}
+		}
+		return out
+	}
+	return value
+}
+
+// FileURLAt returns the URL of the index-th file in a file field's answer
+func FileURLAt(value interface{}, index int) (string, bool) {
+	var file map[string]interface{}
+	switch files := value.(type) {
+	case map[string]interface{}:
+		if index == 0 {
+			file = files
+		}
+	case []interface{}:
+		if index >= 0 && index < len(files) {
+			file, _ = files[index].(map[string]interface{})
+		}
+	}
+	url, _ := file["url"].(string)
+	return url, url != ""
+}
+
+// RedactDocumentForReview returns an upload with its PII blacked out,
+// running the document through the Gemini redactor the first time and
+// caching the result. Uploads with no PII found are cached unchanged.
+func RedactDocumentForReview(ctx context.Context, db *gorm.DB, submissionID uuid.UUID, sourceURL string, knownPII map[string]string) (models.RedactedDocument, error) {
+	var document models.RedactedDocument
+	err := db.Where("submission_id = ? AND source_url = ?", submissionID, sourceURL).First(&document).Error
+	if err == nil {
+		return document, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return document, err
+	}
+
+	gemini := NewGeminiClient()
+	result, err := gemini.RedactDocument(ctx, PIIDetectionRequest{
+		DocumentURL: sourceURL,
+		KnownPII:    knownPII,
+		RedactAll:   true,
+	})
+	if err != nil {
+		return document, fmt.Errorf("redact document: %w", err)
+	}
+
+	document = models.RedactedDocument{
+		SubmissionID: submissionID,
+		SourceURL:    sourceURL,
+		MimeType:     result.MimeType,
+		Data:         result.RedactedData,
+		PIICount:     result.PIICount,
+	}
+	if result.PIICount == 0 || len(result.RedactedData) == 0 {
+		data, mimeType, err := gemini.downloadDocument(sourceURL)
+		if err != nil {
+			return document, fmt.Errorf("download document: %w", err)
+		}
+		document.Data = data
+		document.MimeType = mimeType
+	}
+	piiTypes, _ := json.Marshal(result.PIITypes)
+	document.PIITypes = datatypes.JSON(piiTypes)
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&document).Error; err != nil {
+		return document, err
+	}
+	return document, nil
+}
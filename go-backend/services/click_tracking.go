@@ -0,0 +1,204 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"gorm.io/gorm"
+)
+
+// ErrUnknownEmailLink is returned when a click doesn't match a stored link
+var ErrUnknownEmailLink = errors.New("unknown email link")
+
+// TrackedLink is a link rewritten to go through the click endpoint
+type TrackedLink struct {
+	LinkID string
+	URL    string
+}
+
+// LinkClickStats summarizes clicks on one URL across a campaign
+type LinkClickStats struct {
+	URL          string `json:"url"`
+	TotalClicks  int64  `json:"total_clicks"`
+	UniqueClicks int64  `json:"unique_clicks"` // Recipients who clicked at least once
+}
+
+// Matches the href attribute of an anchor tag, capturing the prefix, quote and value
+var anchorHrefPattern = regexp.MustCompile(`(?is)(<a\b[^>]*?\bhref\s*=\s*)(["'])(.*?)(["'])`)
+
+// ClickTrackingEnabled reports whether click links can be signed
+func ClickTrackingEnabled() bool {
+	return emailLinkSecret() != ""
+}
+
+// ClickSignature signs a tracking ID and link ID so click counts can't be
+// inflated by guessing URLs
+func ClickSignature(trackingID, linkID string) string {
+	mac := hmac.New(sha256.New, []byte(emailLinkSecret()))
+	mac.Write([]byte("click:" + trackingID + ":" + linkID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// VerifyClickSignature checks a signature produced by ClickSignature
+func VerifyClickSignature(trackingID, linkID, signature string) bool {
+	if emailLinkSecret() == "" || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(ClickSignature(trackingID, linkID)))
+}
+
+// ClickURL is the tracked redirect URL for a link in an email
+func ClickURL(backendURL, trackingID, linkID string) string {
+	return fmt.Sprintf("%s/api/v1/email/click/%s/%s?sig=%s",
+		strings.TrimRight(backendURL, "/"), trackingID, linkID, ClickSignature(trackingID, linkID))
+}
+
+// IsTrackableURL reports whether a link may be rewritten and later redirected
+// to: only absolute http(s) URLs, excluding unsubscribe and preference links
+// which must keep working without the tracking endpoint
+func IsTrackableURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return false
+	}
+	if strings.Contains(parsed.Path, "/email-preferences/") || strings.Contains(parsed.Path, "/email/unsubscribe/") {
+		return false
+	}
+	return true
+}
+
+// RewriteLinks replaces trackable links in an HTML body with signed click
+// URLs and returns the links to store. Each occurrence gets its own link ID,
+// numbered in order of appearance.
+func RewriteLinks(bodyHTML, trackingID, backendURL string) (string, []TrackedLink) {
+	var links []TrackedLink
+	rewritten := anchorHrefPattern.ReplaceAllStringFunc(bodyHTML, func(match string) string {
+		parts := anchorHrefPattern.FindStringSubmatch(match)
+		if parts[2] != parts[4] {
+			return match
+		}
+		target := strings.TrimSpace(html.UnescapeString(parts[3]))
+		if !IsTrackableURL(target) {
+			return match
+		}
+
+		linkID := strconv.Itoa(len(links) + 1)
+		links = append(links, TrackedLink{LinkID: linkID, URL: target})
+		return parts[1] + parts[2] + html.EscapeString(ClickURL(backendURL, trackingID, linkID)) + parts[4]
+	})
+	return rewritten, links
+}
+
+// BackendURL is the public URL of this API used in tracking links
+func BackendURL() string {
+	if backendURL := os.Getenv("GO_BACKEND_URL"); backendURL != "" {
+		return strings.TrimRight(backendURL, "/")
+	}
+	return "http://localhost:8080"
+}
+
+// DecorateTrackedHTML rewrites links for click tracking and appends the open
+// tracking pixel, as requested, returning the links to store once the email
+// is recorded
+func DecorateTrackedHTML(bodyHTML, trackingID string, trackOpens, trackClicks bool) (string, []TrackedLink) {
+	if bodyHTML == "" {
+		return bodyHTML, nil
+	}
+	var links []TrackedLink
+	if trackClicks && ClickTrackingEnabled() {
+		bodyHTML, links = RewriteLinks(bodyHTML, trackingID, BackendURL())
+	}
+	if trackOpens {
+		bodyHTML += fmt.Sprintf(`<img src="%s/api/v1/email/track/%s" width="1" height="1" style="display:none" />`, BackendURL(), trackingID)
+	}
+	return bodyHTML, links
+}
+
+// SaveTrackedLinks stores the rewritten links of a sent email
+func SaveTrackedLinks(sentEmail models.SentEmail, links []TrackedLink) error {
+	if len(links) == 0 {
+		return nil
+	}
+	rows := make([]models.EmailLink, 0, len(links))
+	for _, link := range links {
+		rows = append(rows, models.EmailLink{
+			SentEmailID: sentEmail.ID,
+			LinkID:      link.LinkID,
+			CampaignID:  sentEmail.CampaignID,
+			WorkspaceID: sentEmail.WorkspaceID,
+			URL:         link.URL,
+		})
+	}
+	return database.DB.Create(&rows).Error
+}
+
+// RecordClick resolves a tracked link to its stored destination. When count
+// is false (e.g. link scanners) the destination is returned without recording
+// a click.
+func RecordClick(trackingID, linkID string, count bool) (string, error) {
+	var sentEmail models.SentEmail
+	if err := database.DB.Where("tracking_id = ?", trackingID).First(&sentEmail).Error; err != nil {
+		return "", ErrUnknownEmailLink
+	}
+	var link models.EmailLink
+	if err := database.DB.Where("sent_email_id = ? AND link_id = ?", sentEmail.ID, linkID).First(&link).Error; err != nil {
+		return "", ErrUnknownEmailLink
+	}
+	// Stored URLs were checked at send time; check again before redirecting
+	if !IsTrackableURL(link.URL) {
+		return "", ErrUnknownEmailLink
+	}
+	if !count {
+		return link.URL, nil
+	}
+
+	now := time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.EmailLink{}).Where("id = ?", link.ID).Updates(map[string]interface{}{
+			"click_count":      gorm.Expr("click_count + 1"),
+			"first_clicked_at": gorm.Expr("COALESCE(first_clicked_at, ?)", now),
+			"last_clicked_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"click_count": gorm.Expr("click_count + 1"),
+			"clicked_at":  gorm.Expr("COALESCE(clicked_at, ?)", now),
+			// A click implies the email was opened even if images were blocked
+			"opened_at": gorm.Expr("COALESCE(opened_at, ?)", now),
+		}
+		if sentEmail.Status == "sent" || sentEmail.Status == "delivered" || sentEmail.Status == "opened" {
+			updates["status"] = "clicked"
+		}
+		return tx.Model(&models.SentEmail{}).Where("id = ?", sentEmail.ID).Updates(updates).Error
+	})
+	return link.URL, err
+}
+
+// CampaignLinkStats returns per-URL click totals for a campaign, most clicked first
+func CampaignLinkStats(campaignID string) []LinkClickStats {
+	stats := []LinkClickStats{}
+	database.DB.Model(&models.EmailLink{}).
+		Select("url, COALESCE(SUM(click_count), 0) AS total_clicks, COUNT(DISTINCT sent_email_id) FILTER (WHERE click_count > 0) AS unique_clicks").
+		Where("campaign_id = ?", campaignID).
+		Group("url").
+		Order("total_clicks DESC, url").
+		Scan(&stats)
+	return stats
+}
@@ -2,11 +2,14 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/Jsanchez767/matic-platform/tracing"
 )
 
 // CohereClient handles communication with Cohere API
@@ -49,19 +52,25 @@ func NewCohereClient(apiKey string) *CohereClient {
 		APIKey:  apiKey,
 		BaseURL: "https://api.cohere.com", // Updated to new API domain
 		HTTPClient: &http.Client{
-			Timeout: 120 * time.Second, // Increased timeout for V2 API calls
+			Timeout:   120 * time.Second, // Increased timeout for V2 API calls
+			Transport: tracing.Transport("cohere", nil),
 		},
 	}
 }
 
 // EmbedDocuments generates embeddings for documents (for indexing)
 func (c *CohereClient) EmbedDocuments(texts []string) ([][]float32, error) {
-	return c.embed(texts, "search_document")
+	return c.embed(context.Background(), texts, "search_document")
 }
 
 // EmbedQuery generates embedding for a search query
 func (c *CohereClient) EmbedQuery(query string) ([]float32, error) {
-	embeddings, err := c.embed([]string{query}, "search_query")
+	return c.EmbedQueryContext(context.Background(), query)
+}
+
+// EmbedQueryContext is EmbedQuery with a caller context (for tracing and cancellation)
+func (c *CohereClient) EmbedQueryContext(ctx context.Context, query string) ([]float32, error) {
+	embeddings, err := c.embed(ctx, []string{query}, "search_query")
 	if err != nil {
 		return nil, err
 	}
@@ -72,14 +81,14 @@ func (c *CohereClient) EmbedQuery(query string) ([]float32, error) {
 }
 
 // embed is the internal method that calls Cohere API
-func (c *CohereClient) embed(texts []string, inputType string) ([][]float32, error) {
+func (c *CohereClient) embed(ctx context.Context, texts []string, inputType string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return nil, fmt.Errorf("no texts provided")
 	}
 
 	// Cohere has a limit of 96 texts per request
 	if len(texts) > 96 {
-		return c.embedBatch(texts, inputType)
+		return c.embedBatch(ctx, texts, inputType)
 	}
 
 	reqBody := CohereEmbedRequest{
@@ -93,7 +102,7 @@ func (c *CohereClient) embed(texts []string, inputType string) ([][]float32, err
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.BaseURL+"/v1/embed", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/v1/embed", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -127,7 +136,7 @@ func (c *CohereClient) embed(texts []string, inputType string) ([][]float32, err
 }
 
 // embedBatch handles embedding more than 96 texts by splitting into batches
-func (c *CohereClient) embedBatch(texts []string, inputType string) ([][]float32, error) {
+func (c *CohereClient) embedBatch(ctx context.Context, texts []string, inputType string) ([][]float32, error) {
 	var allEmbeddings [][]float32
 	batchSize := 96
 
@@ -138,7 +147,7 @@ func (c *CohereClient) embedBatch(texts []string, inputType string) ([][]float32
 		}
 
 		batch := texts[i:end]
-		embeddings, err := c.embed(batch, inputType)
+		embeddings, err := c.embed(ctx, batch, inputType)
 		if err != nil {
 			return nil, fmt.Errorf("failed to embed batch %d: %w", i/batchSize, err)
 		}
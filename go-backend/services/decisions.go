@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidDecision  = errors.New("decision must be accepted, waitlisted or rejected")
+	ErrInvalidAward     = errors.New("award amount can't be negative")
+	ErrBudgetExceeded   = errors.New("award exceeds the pool's remaining budget")
+	ErrPoolNotOnForm    = errors.New("award pool does not belong to this form")
+	ErrPoolInUse        = errors.New("award pool has decisions drawing on it")
+	ErrWaitlistEmpty    = errors.New("no waitlisted submissions to promote")
+	ErrDecisionReleased = errors.New("decision has already been released")
+)
+
+// decisionReleaseInterval is how often the release worker looks for
+// scheduled decisions that are due
+const decisionReleaseInterval = time.Minute
+
+// DecisionInput is what staff record for a submission
+type DecisionInput struct {
+	Decision         string     `json:"decision"`
+	AwardAmount      *float64   `json:"award_amount"`
+	AwardPoolID      *uuid.UUID `json:"award_pool_id"`
+	WaitlistRank     *int       `json:"waitlist_rank"` // Defaults to the end of the waitlist
+	Notes            *string    `json:"notes"`
+	ApplicantMessage *string    `json:"applicant_message"`
+}
+
+// IsValidDecision reports whether decision is a known outcome
+func IsValidDecision(decision string) bool {
+	switch decision {
+	case models.DecisionAccepted, models.DecisionWaitlisted, models.DecisionRejected:
+		return true
+	}
+	return false
+}
+
+// PoolAllocated sums the awards of accepted decisions drawing on a pool,
+// leaving out one submission's (its own award is being replaced)
+func PoolAllocated(tx *gorm.DB, poolID, exceptSubmissionID uuid.UUID) (float64, error) {
+	var allocated float64
+	err := tx.Model(&models.SubmissionDecision{}).
+		Where("award_pool_id = ? AND decision = ? AND submission_id <> ?", poolID, models.DecisionAccepted, exceptSubmissionID).
+		Select("COALESCE(SUM(award_amount), 0)").
+		Scan(&allocated).Error
+	return allocated, err
+}
+
+// FillPoolBalances sets each pool's Allocated and Remaining
+func FillPoolBalances(tx *gorm.DB, pools []models.AwardPool) error {
+	for i := range pools {
+		allocated, err := PoolAllocated(tx, pools[i].ID, uuid.Nil)
+		if err != nil {
+			return err
+		}
+		pools[i].Allocated = allocated
+		pools[i].Remaining = pools[i].Budget - allocated
+	}
+	return nil
+}
+
+// lockAwardPool loads a form's pool FOR UPDATE. Every allocation takes this
+// lock first, so concurrent decisions can't overspend the pool between
+// summing and saving.
+func lockAwardPool(tx *gorm.DB, formID, poolID uuid.UUID) (models.AwardPool, error) {
+	var pool models.AwardPool
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&pool, "id = ?", poolID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && pool.FormID != formID) {
+		return pool, ErrPoolNotOnForm
+	}
+	return pool, err
+}
+
+// checkPoolBudget locks the pool and fails with ErrBudgetExceeded when
+// awarding amount to the submission would take it over budget
+func checkPoolBudget(tx *gorm.DB, formID, poolID, submissionID uuid.UUID, amount float64) error {
+	pool, err := lockAwardPool(tx, formID, poolID)
+	if err != nil {
+		return err
+	}
+	allocated, err := PoolAllocated(tx, poolID, submissionID)
+	if err != nil {
+		return err
+	}
+	if allocated+amount > pool.Budget {
+		return fmt.Errorf("%w: %.2f of %.2f %s left", ErrBudgetExceeded, pool.Budget-allocated, pool.Budget, pool.Currency)
+	}
+	return nil
+}
+
+// SetAwardPoolBudget changes a pool's budget, refusing to set it below what
+// accepted decisions already draw on it
+func SetAwardPoolBudget(tx *gorm.DB, pool *models.AwardPool, budget float64) error {
+	if budget < 0 {
+		return ErrInvalidAward
+	}
+	if _, err := lockAwardPool(tx, pool.FormID, pool.ID); err != nil {
+		return err
+	}
+	allocated, err := PoolAllocated(tx, pool.ID, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	if budget < allocated {
+		return fmt.Errorf("%w: %.2f is already awarded", ErrBudgetExceeded, allocated)
+	}
+	pool.Budget = budget
+	return nil
+}
+
+// DeleteAwardPool removes a pool no decision draws on
+func DeleteAwardPool(tx *gorm.DB, pool models.AwardPool) error {
+	if _, err := lockAwardPool(tx, pool.FormID, pool.ID); err != nil {
+		return err
+	}
+	var count int64
+	if err := tx.Model(&models.SubmissionDecision{}).Where("award_pool_id = ?", pool.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPoolInUse
+	}
+	return tx.Delete(&pool).Error
+}
+
+// SaveDecision records staff's decision on a submission. Accepting with an
+// award from a pool is refused when the pool can't cover it. Changing the
+// outcome of a released decision withdraws the release and puts the
+// submission back under review until it is re-released.
+func SaveDecision(tx *gorm.DB, submission models.FormSubmission, input DecisionInput, actorID *string) (models.SubmissionDecision, error) {
+	var decision models.SubmissionDecision
+	if !IsValidDecision(input.Decision) {
+		return decision, ErrInvalidDecision
+	}
+	if input.AwardAmount != nil && *input.AwardAmount < 0 {
+		return decision, ErrInvalidAward
+	}
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("submission_id = ?", submission.ID).First(&decision).Error
+	exists := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return decision, err
+	}
+
+	if input.AwardPoolID != nil {
+		if input.Decision == models.DecisionAccepted && input.AwardAmount != nil {
+			if err := checkPoolBudget(tx, submission.FormID, *input.AwardPoolID, submission.ID, *input.AwardAmount); err != nil {
+				return decision, err
+			}
+		} else if _, err := lockAwardPool(tx, submission.FormID, *input.AwardPoolID); err != nil {
+			return decision, err
+		}
+	}
+
+	rank := input.WaitlistRank
+	if input.Decision == models.DecisionWaitlisted && rank == nil {
+		if exists && decision.Decision == models.DecisionWaitlisted {
+			rank = decision.WaitlistRank
+		} else {
+			rank = new(int)
+			if err := tx.Model(&models.SubmissionDecision{}).
+				Where("form_id = ? AND decision = ?", submission.FormID, models.DecisionWaitlisted).
+				Select("COALESCE(MAX(waitlist_rank), 0) + 1").
+				Scan(rank).Error; err != nil {
+				return decision, err
+			}
+		}
+	}
+	if input.Decision != models.DecisionWaitlisted {
+		rank = nil
+	}
+
+	if exists && decision.ReleasedAt != nil &&
+		(decision.Decision != input.Decision || !sameAmount(decision.AwardAmount, input.AwardAmount) || !sameText(decision.ApplicantMessage, input.ApplicantMessage)) {
+		decision.ReleasedAt = nil
+		decision.ReleaseAt = nil
+		if err := tx.Model(&models.FormSubmission{}).Where("id = ?", submission.ID).
+			Updates(map[string]interface{}{"status": "under_review", "updated_at": time.Now()}).Error; err != nil {
+			return decision, err
+		}
+	}
+
+	decision.SubmissionID = submission.ID
+	decision.FormID = submission.FormID
+	decision.Decision = input.Decision
+	decision.AwardAmount = input.AwardAmount
+	decision.AwardPoolID = input.AwardPoolID
+	decision.WaitlistRank = rank
+	decision.Notes = input.Notes
+	decision.ApplicantMessage = input.ApplicantMessage
+	decision.DecidedBy = actorID
+	decision.DecidedAt = time.Now()
+	if !exists {
+		return decision, tx.Create(&decision).Error
+	}
+	return decision, tx.Save(&decision).Error
+}
+
+// PromoteFromWaitlist accepts up to count waitlisted submissions in rank
+// order, each with the award it was waitlisted for. Promotion stops at the
+// first submission its pool can't cover rather than skipping ahead of it.
+// Promoted decisions are released at releaseAt, or held when nil.
+func PromoteFromWaitlist(tx *gorm.DB, formID uuid.UUID, count int, releaseAt *time.Time, actorID *string) ([]models.SubmissionDecision, error) {
+	if count <= 0 {
+		count = 1
+	}
+	var waitlist []models.SubmissionDecision
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("form_id = ? AND decision = ?", formID, models.DecisionWaitlisted).
+		Order("waitlist_rank ASC NULLS LAST, decided_at ASC").
+		Limit(count).
+		Find(&waitlist).Error; err != nil {
+		return nil, err
+	}
+	if len(waitlist) == 0 {
+		return nil, ErrWaitlistEmpty
+	}
+
+	promoted := make([]models.SubmissionDecision, 0, len(waitlist))
+	now := time.Now()
+	for _, decision := range waitlist {
+		if decision.AwardPoolID != nil && decision.AwardAmount != nil {
+			if err := checkPoolBudget(tx, formID, *decision.AwardPoolID, decision.SubmissionID, *decision.AwardAmount); err != nil {
+				if errors.Is(err, ErrBudgetExceeded) && len(promoted) > 0 {
+					break
+				}
+				return nil, err
+			}
+		}
+		decision.Decision = models.DecisionAccepted
+		decision.WaitlistRank = nil
+		decision.PromotedAt = &now
+		decision.DecidedBy = actorID
+		decision.DecidedAt = now
+		decision.ReleasedAt = nil
+		decision.ReleaseAt = releaseAt
+		if err := tx.Save(&decision).Error; err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, decision)
+	}
+	return promoted, nil
+}
+
+// ScheduleDecisionRelease sets when a form's unreleased decisions are shown
+// to applicants; with no submission IDs every unreleased decision is
+// scheduled. Returns how many were scheduled.
+func ScheduleDecisionRelease(tx *gorm.DB, formID uuid.UUID, submissionIDs []uuid.UUID, releaseAt time.Time) (int64, error) {
+	query := tx.Model(&models.SubmissionDecision{}).Where("form_id = ? AND released_at IS NULL", formID)
+	if len(submissionIDs) > 0 {
+		query = query.Where("submission_id IN ?", submissionIDs)
+	}
+	result := query.Updates(map[string]interface{}{"release_at": releaseAt, "updated_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// ReleaseDueDecisions releases every decision whose release time has come,
+// optionally only one form's. A decision that fails to release is logged and
+// skipped. Returns how many were released.
+func ReleaseDueDecisions(ctx context.Context, formID *uuid.UUID) (int, error) {
+	query := database.DB.Model(&models.SubmissionDecision{}).Where("released_at IS NULL AND release_at <= ?", time.Now())
+	if formID != nil {
+		query = query.Where("form_id = ?", *formID)
+	}
+	var ids []uuid.UUID
+	if err := query.Order("release_at ASC").Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, id := range ids {
+		decision, err := releaseDecision(id)
+		if errors.Is(err, ErrDecisionReleased) {
+			continue
+		}
+		if err != nil {
+			// One bad decision mustn't hold back the rest of the batch
+			slog.ErrorContext(ctx, "failed to release decision", "component", "decisions",
+				"decision_id", id, "error", err)
+			continue
+		}
+		released++
+		if err := sendDecisionEmail(ctx, decision); err != nil {
+			slog.WarnContext(ctx, "failed to queue decision email", "component", "decisions",
+				"submission_id", decision.SubmissionID, "error", err)
+		}
+	}
+	return released, nil
+}
+
+// releaseDecision marks a decision released and makes its outcome the
+// submission's status, which is what the portal shows the applicant
+func releaseDecision(id uuid.UUID) (models.SubmissionDecision, error) {
+	var decision models.SubmissionDecision
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&decision, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if decision.ReleasedAt != nil || decision.ReleaseAt == nil || decision.ReleaseAt.After(time.Now()) {
+			return ErrDecisionReleased
+		}
+		now := time.Now()
+		decision.ReleasedAt = &now
+		if err := tx.Model(&decision).Updates(map[string]interface{}{"released_at": now, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.FormSubmission{}).Where("id = ?", decision.SubmissionID).
+			Updates(map[string]interface{}{"status": decision.Decision, "updated_at": now}).Error; err != nil {
+			return err
+		}
+
+		metadata := map[string]interface{}{"decision": decision.Decision}
+		if decision.Decision == models.DecisionAccepted && decision.AwardAmount != nil {
+			metadata["award_amount"] = *decision.AwardAmount
+		}
+		event := models.SubmissionEvent{
+			SubmissionID: decision.SubmissionID,
+			FormID:       decision.FormID,
+			EventType:    models.SubmissionEventDecisionReleased,
+			Title:        decisionEventTitles[decision.Decision],
+			Metadata:     EventMetadata(metadata),
+			DedupeKey:    EventDedupeKey(models.SubmissionEventDecisionReleased, decision.ID, now.Unix()),
+			OccurredAt:   now,
+		}
+		if decision.ApplicantMessage != nil {
+			event.Content = *decision.ApplicantMessage
+		}
+		return RecordSubmissionEvent(tx, event)
+	})
+	return decision, err
+}
+
+// decisionEventTitles title the timeline event of a released decision
+var decisionEventTitles = map[string]string{
+	models.DecisionAccepted:   "Application accepted",
+	models.DecisionWaitlisted: "Application waitlisted",
+	models.DecisionRejected:   "Decision released",
+}
+
+// sendDecisionEmail queues the form's automated templates for a released
+// decision. Templates can merge {{decision}}, {{award_amount}},
+// {{award_currency}} and {{decision_message}} along with the answers.
+func sendDecisionEmail(ctx context.Context, decision models.SubmissionDecision) error {
+	var submission models.FormSubmission
+	if err := database.DB.Preload("Form").Preload("User").First(&submission, "id = ?", decision.SubmissionID).Error; err != nil {
+		return err
+	}
+	if submission.Form == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	trigger := EmailTriggerForStatus(decision.Decision)
+	if trigger == "" {
+		return nil
+	}
+	data := SubmissionAnswers(database.DB, submission)
+	data["decision"] = decision.Decision
+	if decision.AwardAmount != nil {
+		data["award_amount"] = fmt.Sprintf("%.2f", *decision.AwardAmount)
+	}
+	if decision.AwardPoolID != nil {
+		var pool models.AwardPool
+		if err := database.DB.Select("currency").First(&pool, "id = ?", *decision.AwardPoolID).Error; err == nil {
+			data["award_currency"] = pool.Currency
+		}
+	}
+	if decision.ApplicantMessage != nil {
+		data["decision_message"] = *decision.ApplicantMessage
+	}
+
+	recipient := ""
+	if submission.User != nil {
+		recipient = submission.User.Email
+	}
+	// Templates are attached to the form's legacy table where it has one
+	formID := submission.Form.ID
+	if submission.Form.LegacyTableID != nil {
+		formID = *submission.Form.LegacyTableID
+	}
+	_, err := FireEmailTrigger(ctx, EmailTriggerEvent{
+		Trigger:      trigger,
+		FormID:       formID,
+		SubmissionID: submission.ID,
+		Data:         data,
+		Recipient:    recipient,
+		EventID:      fmt.Sprintf("decision:%d", decision.ReleasedAt.Unix()),
+	})
+	return err
+}
+
+// PortalDecisionFor returns a submission's released decision, or nil while
+// there is none to show the applicant
+func PortalDecisionFor(db *gorm.DB, submissionID uuid.UUID) *models.PortalDecision {
+	var decision models.SubmissionDecision
+	if err := db.Preload("AwardPool").
+		Where("submission_id = ? AND released_at IS NOT NULL", submissionID).
+		First(&decision).Error; err != nil {
+		return nil
+	}
+	portal := &models.PortalDecision{
+		Decision:   decision.Decision,
+		Message:    decision.ApplicantMessage,
+		ReleasedAt: *decision.ReleasedAt,
+	}
+	if decision.Decision == models.DecisionAccepted {
+		portal.AwardAmount = decision.AwardAmount
+		if decision.AwardPool != nil {
+			portal.Currency = decision.AwardPool.Currency
+		}
+	}
+	return portal
+}
+
+func sameAmount(a, b *float64) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func sameText(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// ==================== Release Worker ====================
+
+// DecisionReleaser periodically releases scheduled decisions that are due
+type DecisionReleaser struct {
+	stop chan bool
+}
+
+// NewDecisionReleaser creates a new decision release worker
+func NewDecisionReleaser() *DecisionReleaser {
+	return &DecisionReleaser{stop: make(chan bool)}
+}
+
+// Start starts the worker in a goroutine
+func (r *DecisionReleaser) Start(ctx context.Context) {
+	go r.run(ctx)
+}
+
+// Stop stops the worker
+func (r *DecisionReleaser) Stop() {
+	r.stop <- true
+}
+
+func (r *DecisionReleaser) run(ctx context.Context) {
+	ticker := time.NewTicker(decisionReleaseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := ReleaseDueDecisions(ctx, nil)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to release scheduled decisions", "component", "decisions", "error", err)
+			} else if released > 0 {
+				slog.InfoContext(ctx, "released scheduled decisions", "component", "decisions", "count", released)
+			}
+			RecordHeartbeat(HeartbeatDecisionReleaser)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Metrics an A/B test can pick its winner on
+const (
+	WinnerMetricOpenRate  = "open_rate"
+	WinnerMetricClickRate = "click_rate"
+)
+
+// Holdout recipients get one queue item per variant in this status until the
+// winner is picked; the winner's items are released and the rest deleted
+const heldQueueStatus = "held"
+
+// CampaignVariantStats is the performance of one A/B test variant
+type CampaignVariantStats struct {
+	VariantID    uuid.UUID `json:"variant_id"`
+	Label        string    `json:"label"`
+	Subject      string    `json:"subject"`
+	SplitPercent int       `json:"split_percent"`
+	Sent         int64     `json:"sent"`
+	Opened       int64     `json:"opened"`
+	Clicked      int64     `json:"clicked"`
+	OpenRate     float64   `json:"open_rate"`
+	ClickRate    float64   `json:"click_rate"`
+	Winner       bool      `json:"winner"`
+}
+
+// IsValidWinnerMetric reports whether metric can pick an A/B test winner
+func IsValidWinnerMetric(metric string) bool {
+	return metric == WinnerMetricOpenRate || metric == WinnerMetricClickRate
+}
+
+// AssignCampaignVariant places a recipient in a variant's test group, or
+// returns nil for the holdout that waits for the winner. The same campaign
+// and address always get the same answer.
+func AssignCampaignVariant(campaignID uuid.UUID, email string, variants []models.EmailCampaignVariant) *models.EmailCampaignVariant {
+	sum := sha256.Sum256([]byte(campaignID.String() + ":" + NormalizeEmail(email)))
+	bucket := int(binary.BigEndian.Uint64(sum[:8]) % 10000) // basis points
+
+	threshold := 0
+	for i := range variants {
+		threshold += variants[i].SplitPercent * 100
+		if bucket < threshold {
+			return &variants[i]
+		}
+	}
+	return nil
+}
+
+// CampaignVariants returns a campaign's variants in label order
+func CampaignVariants(campaignID uuid.UUID) []models.EmailCampaignVariant {
+	variants := []models.EmailCampaignVariant{}
+	database.DB.Where("campaign_id = ?", campaignID).Order("label").Find(&variants)
+	return variants
+}
+
+// CampaignVariantResults returns per-variant sends, opens and clicks for an
+// A/B tested campaign. Failed sends don't count towards rates.
+func CampaignVariantResults(campaign models.EmailCampaign) []CampaignVariantStats {
+	variants := CampaignVariants(campaign.ID)
+	if len(variants) == 0 {
+		return nil
+	}
+
+	var counts []struct {
+		VariantID uuid.UUID
+		Sent      int64
+		Opened    int64
+		Clicked   int64
+	}
+	database.DB.Model(&models.SentEmail{}).
+		Select(`variant_id, COUNT(*) AS sent,
+			COUNT(*) FILTER (WHERE opened_at IS NOT NULL OR status IN ('opened', 'clicked')) AS opened,
+			COUNT(*) FILTER (WHERE clicked_at IS NOT NULL OR status = 'clicked') AS clicked`).
+		Where("campaign_id = ? AND variant_id IS NOT NULL AND status <> ?", campaign.ID, "failed").
+		Group("variant_id").
+		Scan(&counts)
+
+	results := make([]CampaignVariantStats, 0, len(variants))
+	for _, variant := range variants {
+		stats := CampaignVariantStats{
+			VariantID:    variant.ID,
+			Label:        variant.Label,
+			Subject:      variant.Subject,
+			SplitPercent: variant.SplitPercent,
+			Winner:       campaign.WinnerVariantID != nil && *campaign.WinnerVariantID == variant.ID,
+		}
+		for _, count := range counts {
+			if count.VariantID == variant.ID {
+				stats.Sent, stats.Opened, stats.Clicked = count.Sent, count.Opened, count.Clicked
+			}
+		}
+		if stats.Sent > 0 {
+			stats.OpenRate = float64(stats.Opened) / float64(stats.Sent) * 100
+			stats.ClickRate = float64(stats.Clicked) / float64(stats.Sent) * 100
+		}
+		results = append(results, stats)
+	}
+	return results
+}
+
+// SelectCampaignWinners picks the winner of every sending A/B test whose test
+// window has ended and releases the winning variant to the holdout. Paused
+// campaigns wait until they're resumed.
+func SelectCampaignWinners(ctx context.Context, router *EmailRouter) {
+	var campaigns []models.EmailCampaign
+	if err := database.DB.Where("status = ? AND winner_variant_id IS NULL AND test_ends_at <= ?", "sending", time.Now()).
+		Find(&campaigns).Error; err != nil {
+		slog.ErrorContext(ctx, "failed to fetch A/B tested campaigns", "component", "email_queue_worker", "error", err)
+		return
+	}
+
+	for _, campaign := range campaigns {
+		winner, err := selectCampaignWinner(campaign.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to pick A/B test winner", "component", "email_queue_worker",
+				"campaign_id", campaign.ID, "error", err)
+			continue
+		}
+		if winner == nil {
+			continue
+		}
+		slog.InfoContext(ctx, "picked A/B test winner", "component", "email_queue_worker",
+			"campaign_id", campaign.ID, "variant_id", winner.VariantID, "label", winner.Label)
+
+		// Spread the holdout by the campaign's stagger delay, like any release
+		if err := ProcessCampaignQueue(ctx, router, campaign.ID, campaign.StaggerDelaySeconds); err != nil {
+			slog.ErrorContext(ctx, "failed to release A/B test winner", "component", "email_queue_worker",
+				"campaign_id", campaign.ID, "error", err)
+		}
+		completeCampaignIfDone(campaign.ID)
+	}
+}
+
+// selectCampaignWinner records the best variant by the campaign's metric and
+// swaps the holdout's held emails for the winner's. Returns nil when another
+// worker already picked one or the campaign is no longer sending.
+func selectCampaignWinner(campaignID uuid.UUID) (*CampaignVariantStats, error) {
+	var winner *CampaignVariantStats
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var campaign models.EmailCampaign
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&campaign, "id = ?", campaignID).Error; err != nil {
+			return err
+		}
+		if campaign.Status != "sending" || campaign.WinnerVariantID != nil {
+			return nil
+		}
+
+		results := CampaignVariantResults(campaign)
+		if len(results) == 0 {
+			return errors.New("campaign has no variants")
+		}
+		winner = bestVariant(results, campaign.WinnerMetric)
+
+		now := time.Now()
+		if err := tx.Model(&campaign).Updates(map[string]interface{}{
+			"winner_variant_id":  winner.VariantID,
+			"winner_selected_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.EmailQueueItem{}).
+			Where("campaign_id = ? AND status = ? AND variant_id = ?", campaign.ID, heldQueueStatus, winner.VariantID).
+			Updates(map[string]interface{}{"status": "pending", "scheduled_for": now}).Error; err != nil {
+			return err
+		}
+		return tx.Where("campaign_id = ? AND status = ?", campaign.ID, heldQueueStatus).
+			Delete(&models.EmailQueueItem{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("select winner: %w", err)
+	}
+	return winner, nil
+}
+
+// bestVariant returns the variant with the highest rate for metric. Ties go
+// to the variant with more sends, then to the earlier label.
+func bestVariant(results []CampaignVariantStats, metric string) *CampaignVariantStats {
+	rate := func(stats CampaignVariantStats) float64 {
+		if metric == WinnerMetricClickRate {
+			return stats.ClickRate
+		}
+		return stats.OpenRate
+	}
+	ranked := append([]CampaignVariantStats{}, results...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if rate(ranked[i]) != rate(ranked[j]) {
+			return rate(ranked[i]) > rate(ranked[j])
+		}
+		return ranked[i].Sent > ranked[j].Sent
+	})
+	ranked[0].Winner = true
+	return &ranked[0]
+}
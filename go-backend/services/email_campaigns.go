@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCampaignTransition is returned when a campaign can't move to
+// the requested state, e.g. resuming a campaign that isn't paused
+var ErrInvalidCampaignTransition = errors.New("invalid campaign state change")
+
+// Queue item statuses that haven't been handed to a provider yet
+var unsentQueueStatuses = []string{"pending", "scheduled", "paused", "retrying", heldQueueStatus}
+
+// ReleaseScheduledCampaigns starts campaigns whose scheduled time has come,
+// spreading their queued emails by the campaign's stagger delay
+func ReleaseScheduledCampaigns(ctx context.Context, router *EmailRouter) {
+	var campaigns []models.EmailCampaign
+	if err := database.DB.Where("status = ? AND scheduled_for <= ?", "scheduled", time.Now()).
+		Find(&campaigns).Error; err != nil {
+		slog.ErrorContext(ctx, "failed to fetch scheduled campaigns", "component", "email_queue_worker", "error", err)
+		return
+	}
+
+	for _, campaign := range campaigns {
+		// Claim the campaign so concurrent workers don't release it twice
+		result := database.DB.Model(&models.EmailCampaign{}).
+			Where("id = ? AND status = ?", campaign.ID, "scheduled").
+			Update("status", "sending")
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		if err := ProcessCampaignQueue(ctx, router, campaign.ID, campaign.StaggerDelaySeconds); err != nil {
+			slog.ErrorContext(ctx, "failed to release scheduled campaign", "component", "email_queue_worker",
+				"campaign_id", campaign.ID, "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "released scheduled campaign", "component", "email_queue_worker", "campaign_id", campaign.ID)
+	}
+}
+
+// PauseCampaign holds a scheduled or sending campaign. Emails already handed
+// to a provider still go out.
+func PauseCampaign(campaignID uuid.UUID) (*models.EmailCampaign, error) {
+	return transitionCampaign(campaignID, []string{"scheduled", "sending"}, func(tx *gorm.DB, campaign *models.EmailCampaign) error {
+		campaign.Status = "paused"
+		return tx.Model(&models.EmailQueueItem{}).
+			Where("campaign_id = ? AND status IN ?", campaign.ID, []string{"pending", "scheduled", "retrying"}).
+			Update("status", "paused").Error
+	})
+}
+
+// ResumeCampaign continues a paused campaign. It goes back to waiting when
+// its scheduled time is still ahead; otherwise the remaining emails are
+// re-staggered from now.
+func ResumeCampaign(ctx context.Context, router *EmailRouter, campaignID uuid.UUID) (*models.EmailCampaign, error) {
+	campaign, err := transitionCampaign(campaignID, []string{"paused"}, func(tx *gorm.DB, campaign *models.EmailCampaign) error {
+		if campaign.ScheduledFor != nil && campaign.ScheduledFor.After(time.Now()) {
+			campaign.Status = "scheduled"
+			return tx.Model(&models.EmailQueueItem{}).
+				Where("campaign_id = ? AND status = ?", campaign.ID, "paused").
+				Update("status", "scheduled").Error
+		}
+		campaign.Status = "sending"
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status == "sending" {
+		if err := ProcessCampaignQueue(ctx, router, campaign.ID, campaign.StaggerDelaySeconds); err != nil {
+			return nil, err
+		}
+	}
+	return campaign, nil
+}
+
+// CancelCampaign stops a campaign for good; emails not yet sent are cancelled
+func CancelCampaign(campaignID uuid.UUID) (*models.EmailCampaign, error) {
+	return transitionCampaign(campaignID, []string{"draft", "scheduled", "sending", "paused"}, func(tx *gorm.DB, campaign *models.EmailCampaign) error {
+		campaign.Status = "cancelled"
+		return tx.Model(&models.EmailQueueItem{}).
+			Where("campaign_id = ? AND status IN ?", campaign.ID, unsentQueueStatuses).
+			Updates(map[string]interface{}{"status": "cancelled", "error_message": "Campaign cancelled"}).Error
+	})
+}
+
+// transitionCampaign locks the campaign, checks its current status is one of
+// from, and applies change along with the campaign's new status
+func transitionCampaign(campaignID uuid.UUID, from []string, change func(tx *gorm.DB, campaign *models.EmailCampaign) error) (*models.EmailCampaign, error) {
+	var campaign models.EmailCampaign
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&campaign, "id = ?", campaignID).Error; err != nil {
+			return err
+		}
+		allowed := false
+		for _, status := range from {
+			if campaign.Status == status {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: campaign is %s", ErrInvalidCampaignTransition, campaign.Status)
+		}
+		if err := change(tx, &campaign); err != nil {
+			return err
+		}
+		return tx.Model(&campaign).Update("status", campaign.Status).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// completeCampaignIfDone marks a sending campaign sent once none of its
+// emails are waiting
+func completeCampaignIfDone(campaignID uuid.UUID) {
+	var remainingCount int64
+	database.DB.Model(&models.EmailQueueItem{}).
+		Where("campaign_id = ? AND status IN ?", campaignID, append([]string{"processing"}, unsentQueueStatuses...)).
+		Count(&remainingCount)
+	if remainingCount > 0 {
+		return
+	}
+
+	now := time.Now()
+	database.DB.Model(&models.EmailCampaign{}).
+		Where("id = ? AND status = ?", campaignID, "sending").
+		Updates(map[string]interface{}{"status": "sent", "sent_at": now})
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+)
+
+// Email categories recipients can opt out of individually. Emails without a
+// category (invitations, password resets, direct replies) are transactional
+// and only blocked by the suppression list.
+const (
+	EmailCategoryReminders     = "reminders"
+	EmailCategoryAnnouncements = "announcements"
+)
+
+// EmailCategoryInfo describes a category on the preference page
+type EmailCategoryInfo struct {
+	Key         string `json:"key"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// EmailCategories lists the categories shown on the preference page
+var EmailCategories = []EmailCategoryInfo{
+	{Key: EmailCategoryReminders, Label: "Reminders", Description: "Deadline and incomplete application reminders"},
+	{Key: EmailCategoryAnnouncements, Label: "Announcements", Description: "Program news and general updates"},
+}
+
+// IsValidEmailCategory reports whether category is a known opt-out category
+func IsValidEmailCategory(category string) bool {
+	for _, c := range EmailCategories {
+		if c.Key == category {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrRecipientOptedOut is returned when the recipient opted out of the email's category
+var ErrRecipientOptedOut = errors.New("recipient opted out of this email category")
+
+// ErrInvalidPreferenceToken is returned for malformed or tampered tokens
+var ErrInvalidPreferenceToken = errors.New("invalid preference token")
+
+// ==================== Tokens ====================
+
+// emailLinkSecret signs unsubscribe, preference and click-tracking links
+func emailLinkSecret() string {
+	for _, key := range []string{"EMAIL_PREFERENCES_SECRET", "BETTER_AUTH_SECRET", "JWT_SECRET"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// PreferencesEnabled reports whether preference links can be signed
+func PreferencesEnabled() bool {
+	return emailLinkSecret() != ""
+}
+
+// PreferenceToken builds the signed token identifying a recipient in a
+// workspace. Tokens don't expire so links in old emails keep working.
+func PreferenceToken(workspaceID uuid.UUID, email string) (string, error) {
+	secret := emailLinkSecret()
+	if secret == "" {
+		return "", errors.New("EMAIL_PREFERENCES_SECRET is not configured")
+	}
+
+	payload := workspaceID.String() + ":" + NormalizeEmail(email)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// ParsePreferenceToken verifies a token and returns the workspace and email it identifies
+func ParsePreferenceToken(token string) (uuid.UUID, string, error) {
+	secret := emailLinkSecret()
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if secret == "" || !ok {
+		return uuid.Nil, "", ErrInvalidPreferenceToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidPreferenceToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidPreferenceToken
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return uuid.Nil, "", ErrInvalidPreferenceToken
+	}
+
+	workspacePart, email, ok := strings.Cut(string(payload), ":")
+	workspaceID, err := uuid.Parse(workspacePart)
+	if !ok || err != nil || email == "" {
+		return uuid.Nil, "", ErrInvalidPreferenceToken
+	}
+	return workspaceID, email, nil
+}
+
+// ==================== Links & Headers ====================
+
+// UnsubscribeURL is the RFC 8058 one-click endpoint for a token (empty when
+// GO_BACKEND_URL is not set, since mail clients need an absolute URL)
+func UnsubscribeURL(token string) string {
+	backendURL := os.Getenv("GO_BACKEND_URL")
+	if backendURL == "" || token == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/api/v1/email/unsubscribe/%s", strings.TrimRight(backendURL, "/"), token)
+}
+
+// PreferencesURL is the recipient-facing preference page for a token
+func PreferencesURL(token string) string {
+	baseURL := os.Getenv("APP_URL")
+	if baseURL == "" {
+		baseURL = os.Getenv("NEXT_PUBLIC_APP_URL")
+	}
+	if baseURL == "" {
+		baseURL = "https://www.maticsapp.com" // Production default
+	}
+	return fmt.Sprintf("%s/email-preferences/%s", strings.TrimRight(baseURL, "/"), token)
+}
+
+// ListUnsubscribeHeaders returns the RFC 2369 / RFC 8058 headers for a
+// recipient, or nil when no token or unsubscribe URL can be built
+func ListUnsubscribeHeaders(workspaceID uuid.UUID, email string) map[string]string {
+	token, err := PreferenceToken(workspaceID, email)
+	if err != nil {
+		return nil
+	}
+	unsubscribeURL := UnsubscribeURL(token)
+	if unsubscribeURL == "" {
+		return nil
+	}
+	return map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
+// ==================== Preferences ====================
+
+// OptedOutCategories returns the categories email opted out of in the workspace
+func OptedOutCategories(workspaceID uuid.UUID, email string) []string {
+	var pref models.EmailPreference
+	if err := database.DB.Where("workspace_id = ? AND email = ?", workspaceID, NormalizeEmail(email)).
+		First(&pref).Error; err != nil {
+		return []string{}
+	}
+	var categories []string
+	if err := json.Unmarshal(pref.OptedOutCategories, &categories); err != nil || categories == nil {
+		return []string{}
+	}
+	return categories
+}
+
+// SetOptedOutCategories replaces the recipient's category opt-outs
+func SetOptedOutCategories(workspaceID uuid.UUID, email string, categories []string) error {
+	if categories == nil {
+		categories = []string{}
+	}
+	categoriesJSON, err := json.Marshal(categories)
+	if err != nil {
+		return err
+	}
+
+	pref := models.EmailPreference{
+		WorkspaceID:        workspaceID,
+		Email:              NormalizeEmail(email),
+		OptedOutCategories: datatypes.JSON(categoriesJSON),
+	}
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"opted_out_categories", "updated_at"}),
+	}).Create(&pref).Error
+}
+
+// IsOptedOut reports whether email opted out of category in the workspace
+func IsOptedOut(workspaceID uuid.UUID, email, category string) bool {
+	if category == "" {
+		return false
+	}
+	return len(OptedOutEmails(workspaceID, []string{email}, category)) > 0
+}
+
+// OptedOutEmails returns the subset of emails (normalized) that opted out of
+// category, using a single query
+func OptedOutEmails(workspaceID uuid.UUID, emails []string, category string) map[string]bool {
+	optedOut := make(map[string]bool)
+	if category == "" || len(emails) == 0 {
+		return optedOut
+	}
+
+	normalized := make([]string, 0, len(emails))
+	for _, email := range emails {
+		normalized = append(normalized, NormalizeEmail(email))
+	}
+
+	categoryJSON, _ := json.Marshal([]string{category})
+	var matches []string
+	database.DB.Model(&models.EmailPreference{}).
+		Where("workspace_id = ? AND email IN ? AND opted_out_categories @> ?", workspaceID, normalized, string(categoryJSON)).
+		Pluck("email", &matches)
+	for _, email := range matches {
+		optedOut[email] = true
+	}
+	return optedOut
+}
+
+// Unsubscribe stops all workspace email to the recipient by adding them to
+// the suppression list
+func Unsubscribe(workspaceID uuid.UUID, email, source string) error {
+	_, err := AddSuppression(SuppressionInput{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Reason:      SuppressionUnsubscribe,
+		Source:      source,
+	})
+	return err
+}
+
+// Resubscribe lifts an unsubscribe. Bounce and complaint suppressions stay
+// in place; only an admin can remove those.
+func Resubscribe(workspaceID uuid.UUID, email string) error {
+	return database.DB.Where("workspace_id = ? AND email = ? AND reason = ?", workspaceID, NormalizeEmail(email), SuppressionUnsubscribe).
+		Delete(&models.EmailSuppression{}).Error
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceTypeMemory keeps email in memory instead of sending it. It is only
+// registered when EMAIL_PROVIDER_OVERRIDE=memory.
+const ServiceTypeMemory EmailServiceType = "memory"
+
+// MemoryEmail is a message captured by MemoryEmailProvider
+type MemoryEmail struct {
+	EmailSendRequest
+	MessageID string    `json:"message_id"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// MemoryEmailProvider records email instead of delivering it, for local
+// development and tests
+type MemoryEmailProvider struct {
+	mu       sync.Mutex
+	messages []MemoryEmail
+}
+
+// NewMemoryEmailProvider creates an empty in-memory provider
+func NewMemoryEmailProvider() *MemoryEmailProvider {
+	return &MemoryEmailProvider{}
+}
+
+func (p *MemoryEmailProvider) Type() EmailServiceType { return ServiceTypeMemory }
+
+func (p *MemoryEmailProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{Attachments: true, Threading: true, CustomHeaders: true, ListUnsubscribe: true}
+}
+
+func (p *MemoryEmailProvider) Send(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
+	message := MemoryEmail{
+		EmailSendRequest: req,
+		MessageID:        "memory-" + uuid.New().String(),
+		SentAt:           time.Now(),
+	}
+
+	p.mu.Lock()
+	p.messages = append(p.messages, message)
+	p.mu.Unlock()
+
+	slog.InfoContext(ctx, "email captured by memory provider",
+		"component", "email_router", "message_id", message.MessageID, "to", req.To, "subject", req.Subject)
+
+	return &EmailSendResult{
+		Success:     true,
+		MessageID:   message.MessageID,
+		ServiceType: ServiceTypeMemory,
+	}, nil
+}
+
+func (p *MemoryEmailProvider) HealthCheck(ctx context.Context, workspaceID uuid.UUID) error {
+	return nil
+}
+
+// Messages returns a copy of the captured email, oldest first
+func (p *MemoryEmailProvider) Messages() []MemoryEmail {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]MemoryEmail(nil), p.messages...)
+}
+
+// Reset discards the captured email
+func (p *MemoryEmailProvider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = nil
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/metrics"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/tracing"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrProviderNotConfigured is returned when a workspace hasn't set up a
+// provider. It doesn't count as an outage in email service health.
+var ErrProviderNotConfigured = errors.New("email provider not configured for workspace")
+
+// ProviderCapabilities describes what a provider can deliver
+type ProviderCapabilities struct {
+	Attachments   bool `json:"attachments"`
+	Threading     bool `json:"threading"`      // Replies land in the original thread
+	CustomHeaders bool `json:"custom_headers"` // e.g. List-Unsubscribe
+	// List-Unsubscribe and List-Unsubscribe-Post reach the recipient, so
+	// mail clients can offer one-click unsubscribe (RFC 8058)
+	ListUnsubscribe bool `json:"list_unsubscribe"`
+}
+
+// EmailProvider sends email for a workspace through one service
+type EmailProvider interface {
+	Type() EmailServiceType
+	Capabilities() ProviderCapabilities
+	// Send delivers req. The returned result is non-nil even on error.
+	Send(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error)
+	// HealthCheck verifies the workspace's configuration (and connectivity
+	// where that is cheap) without sending email
+	HealthCheck(ctx context.Context, workspaceID uuid.UUID) error
+}
+
+// ==================== Registry ====================
+
+// ProviderRegistry holds the providers the router may choose from
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[EmailServiceType]EmailProvider
+}
+
+// NewProviderRegistry creates a registry with the given providers
+func NewProviderRegistry(providers ...EmailProvider) *ProviderRegistry {
+	registry := &ProviderRegistry{providers: make(map[EmailServiceType]EmailProvider)}
+	for _, provider := range providers {
+		registry.Register(provider)
+	}
+	return registry
+}
+
+// Register adds or replaces the provider for its service type
+func (r *ProviderRegistry) Register(provider EmailProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[provider.Type()] = provider
+}
+
+// Get returns the provider for serviceType
+func (r *ProviderRegistry) Get(serviceType EmailServiceType) (EmailProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[serviceType]
+	return provider, ok
+}
+
+// Types returns the registered service types in a stable order
+func (r *ProviderRegistry) Types() []EmailServiceType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]EmailServiceType, 0, len(r.providers))
+	for serviceType := range r.providers {
+		types = append(types, serviceType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+var (
+	defaultProviders     *ProviderRegistry
+	defaultProvidersOnce sync.Once
+)
+
+// DefaultEmailProviders returns the process-wide registry: Gmail, Resend and
+// SMTP, plus the in-memory provider when EMAIL_PROVIDER_OVERRIDE=memory
+func DefaultEmailProviders() *ProviderRegistry {
+	defaultProvidersOnce.Do(func() {
+		defaultProviders = NewProviderRegistry(GmailProvider{}, ResendProvider{}, SMTPProvider{})
+		if EmailProviderOverride() == ServiceTypeMemory {
+			defaultProviders.Register(NewMemoryEmailProvider())
+		}
+	})
+	return defaultProviders
+}
+
+// EmailProviderOverride forces every workspace onto one provider, e.g.
+// "memory" to run the email pipeline locally without credentials
+func EmailProviderOverride() EmailServiceType {
+	return EmailServiceType(os.Getenv("EMAIL_PROVIDER_OVERRIDE"))
+}
+
+// ==================== Gmail ====================
+
+// GmailProvider sends through the workspace's connected Gmail account
+type GmailProvider struct{}
+
+func (GmailProvider) Type() EmailServiceType { return ServiceTypeGmail }
+
+func (GmailProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{Attachments: true, Threading: true, CustomHeaders: true, ListUnsubscribe: true}
+}
+
+func (GmailProvider) Send(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
+	// Build from address
+	fromName := req.FromName
+	if fromName == "" {
+		fromName = req.From
+	}
+
+	// One-click unsubscribe (RFC 8058) for categorized, non-transactional email
+	var headers map[string]string
+	if req.Category != "" {
+		headers = ListUnsubscribeHeaders(req.WorkspaceID, req.To)
+	}
+
+	messageID, threadID, err := SendGmailEmail(
+		ctx,
+		req.WorkspaceID,
+		req.To,
+		req.ToName,
+		req.From,
+		fromName,
+		req.Subject,
+		req.Body,
+		req.BodyHTML,
+		req.ThreadID,
+		GmailMessageOptions{
+			ReplyTo:     req.ReplyTo,
+			InReplyTo:   req.InReplyTo,
+			References:  req.References,
+			Headers:     headers,
+			Attachments: req.Attachments,
+		},
+	)
+	if err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeGmail,
+			ErrorMessage: err.Error(),
+		}, err
+	}
+
+	return &EmailSendResult{
+		Success:     true,
+		MessageID:   messageID,
+		ThreadID:    threadID,
+		ServiceType: ServiceTypeGmail,
+	}, nil
+}
+
+func (GmailProvider) HealthCheck(ctx context.Context, workspaceID uuid.UUID) error {
+	var connection models.GmailConnection
+	if err := database.DB.WithContext(ctx).Where("workspace_id = ?", workspaceID).First(&connection).Error; err != nil {
+		return ErrProviderNotConfigured
+	}
+	if connection.NeedsReconnect {
+		return fmt.Errorf("Gmail needs to be reconnected: %s", connection.ReconnectReason)
+	}
+	return nil
+}
+
+// ==================== Resend ====================
+
+// ResendProvider sends through the workspace's Resend API key
+type ResendProvider struct{}
+
+func (ResendProvider) Type() EmailServiceType { return ServiceTypeResend }
+
+func (ResendProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{CustomHeaders: true, ListUnsubscribe: true}
+}
+
+func (ResendProvider) Send(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
+	// Get Resend integration
+	var resendIntegration models.ResendIntegration
+	if err := database.DB.Where("workspace_id = ? AND is_active = ?", req.WorkspaceID, true).First(&resendIntegration).Error; err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeResend,
+			ErrorMessage: "Resend integration not configured",
+		}, ErrProviderNotConfigured
+	}
+
+	// Prepare Resend API request
+	resendURL := "https://api.resend.com/emails"
+	if apiURL := os.Getenv("RESEND_API_URL"); apiURL != "" {
+		resendURL = apiURL + "/emails"
+	}
+
+	// Without a sender from the caller, send as the integration's address
+	from := req.From
+	if from == "" {
+		from = resendIntegration.FromEmail
+		if resendIntegration.FromName != "" {
+			from = fmt.Sprintf("%s <%s>", resendIntegration.FromName, resendIntegration.FromEmail)
+		}
+	}
+
+	payload := map[string]interface{}{
+		"from":    from,
+		"to":      []string{req.To},
+		"subject": req.Subject,
+		"text":    req.Body,
+	}
+
+	if req.BodyHTML != "" {
+		payload["html"] = req.BodyHTML
+	}
+
+	if req.ReplyTo != "" {
+		payload["reply_to"] = req.ReplyTo
+	}
+
+	// One-click unsubscribe (RFC 8058) for categorized, non-transactional email
+	if req.Category != "" {
+		if headers := ListUnsubscribeHeaders(req.WorkspaceID, req.To); headers != nil {
+			payload["headers"] = headers
+		}
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeResend,
+			ErrorMessage: fmt.Sprintf("Failed to marshal payload: %v", err),
+		}, err
+	}
+
+	// Make HTTP request to Resend
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", resendURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeResend,
+			ErrorMessage: fmt.Sprintf("Failed to create request: %v", err),
+		}, err
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+resendIntegration.APIKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Execute request
+	client := tracing.HTTPClient("resend", 30*time.Second)
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeResend,
+			ErrorMessage: fmt.Sprintf("Failed to send email: %v", err),
+		}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeResend,
+			ErrorMessage: fmt.Sprintf("Failed to read response: %v", err),
+		}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(body, &errorResp)
+
+		errorMsg := errorResp.Message
+		if errorMsg == "" {
+			errorMsg = fmt.Sprintf("HTTP %d: Failed to send email", resp.StatusCode)
+		}
+
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeResend,
+			ErrorMessage: errorMsg,
+		}, errors.New(errorMsg)
+	}
+
+	// Parse successful response
+	var resendResp struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &resendResp); err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeResend,
+			ErrorMessage: fmt.Sprintf("Failed to parse response: %v", err),
+		}, err
+	}
+
+	return &EmailSendResult{
+		Success:     true,
+		MessageID:   resendResp.ID,
+		ServiceType: ServiceTypeResend,
+	}, nil
+}
+
+func (ResendProvider) HealthCheck(ctx context.Context, workspaceID uuid.UUID) error {
+	var integration models.ResendIntegration
+	if err := database.DB.WithContext(ctx).Where("workspace_id = ? AND is_active = ?", workspaceID, true).First(&integration).Error; err != nil {
+		return ErrProviderNotConfigured
+	}
+	return nil
+}
+
+// ==================== SMTP ====================
+
+// SMTPProvider relays through the workspace's own SMTP server
+type SMTPProvider struct{}
+
+func (SMTPProvider) Type() EmailServiceType { return ServiceTypeSMTP }
+
+func (SMTPProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{Threading: true, CustomHeaders: true, ListUnsubscribe: true}
+}
+
+func (SMTPProvider) Send(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
+	var integration models.SMTPIntegration
+	if err := database.DB.Where("workspace_id = ? AND is_active = ?", req.WorkspaceID, true).First(&integration).Error; err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeSMTP,
+			ErrorMessage: "SMTP integration not configured",
+		}, ErrProviderNotConfigured
+	}
+
+	msg := SMTPMessage{
+		From:       req.From,
+		FromName:   req.FromName,
+		To:         req.To,
+		ToName:     req.ToName,
+		ReplyTo:    req.ReplyTo,
+		Subject:    req.Subject,
+		Body:       req.Body,
+		BodyHTML:   req.BodyHTML,
+		InReplyTo:  req.InReplyTo,
+		References: req.References,
+	}
+	// One-click unsubscribe (RFC 8058) for categorized, non-transactional email
+	if req.Category != "" {
+		msg.Headers = ListUnsubscribeHeaders(req.WorkspaceID, req.To)
+	}
+
+	// SMTP doesn't go through the instrumented HTTP transport, so record the
+	// span and provider metrics here
+	ctx, span := tracing.Tracer().Start(ctx, "smtp send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("peer.service", "smtp"), attribute.String("server.address", integration.Host)))
+	start := time.Now()
+	messageID, err := SendSMTPEmail(ctx, integration, msg)
+	metrics.ProviderRequestDuration.WithLabelValues("smtp").Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.ProviderRequestErrors.WithLabelValues("smtp", "send").Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	if err != nil {
+		return &EmailSendResult{
+			Success:      false,
+			ServiceType:  ServiceTypeSMTP,
+			ErrorMessage: err.Error(),
+		}, err
+	}
+
+	return &EmailSendResult{
+		Success:     true,
+		MessageID:   messageID,
+		ServiceType: ServiceTypeSMTP,
+	}, nil
+}
+
+func (SMTPProvider) HealthCheck(ctx context.Context, workspaceID uuid.UUID) error {
+	var integration models.SMTPIntegration
+	if err := database.DB.WithContext(ctx).Where("workspace_id = ? AND is_active = ?", workspaceID, true).First(&integration).Error; err != nil {
+		return ErrProviderNotConfigured
+	}
+	return CheckSMTPConnection(ctx, integration)
+}
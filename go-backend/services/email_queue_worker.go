@@ -2,13 +2,21 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/logging"
+	"github.com/Jsanchez767/matic-platform/metrics"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // EmailQueueWorker processes queued emails
@@ -50,7 +58,10 @@ func (w *EmailQueueWorker) run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			ReleaseScheduledCampaigns(ctx, w.router)
+			SelectCampaignWinners(ctx, w.router)
 			w.processQueue(ctx)
+			RecordHeartbeat(HeartbeatEmailQueueWorker)
 		}
 	}
 }
@@ -63,7 +74,7 @@ func (w *EmailQueueWorker) processQueue(ctx context.Context) {
 		w.tableExists = database.DB.Migrator().HasTable(&models.EmailQueueItem{})
 		w.tableChecked = true
 		if !w.tableExists {
-			fmt.Printf("[EmailQueueWorker] email_queue table does not exist. Please run the migration or restart the server.\n")
+			slog.WarnContext(ctx, "email_queue table does not exist; run the migration or restart the server", "component", "email_queue_worker")
 		}
 	}
 	tableExists := w.tableExists
@@ -74,11 +85,11 @@ func (w *EmailQueueWorker) processQueue(ctx context.Context) {
 		return
 	}
 
-	// Get pending emails that are scheduled for now or earlier
+	// Get pending emails and due retries that are scheduled for now or earlier
 	now := time.Now()
 	var queueItems []models.EmailQueueItem
 
-	err := database.DB.Where("status = ? AND scheduled_for <= ?", "pending", now).
+	err := database.DB.Where("status IN ? AND scheduled_for <= ?", []string{"pending", "retrying"}, now).
 		Order("priority DESC, scheduled_for ASC").
 		Limit(10). // Process up to 10 emails per batch
 		Find(&queueItems).Error
@@ -91,7 +102,7 @@ func (w *EmailQueueWorker) processQueue(ctx context.Context) {
 			w.tableCheckMutex.Unlock()
 			return
 		}
-		fmt.Printf("[EmailQueueWorker] Error fetching queue items: %v\n", err)
+		slog.ErrorContext(ctx, "failed to fetch email queue items", "component", "email_queue_worker", "error", err)
 		return
 	}
 
@@ -99,20 +110,67 @@ func (w *EmailQueueWorker) processQueue(ctx context.Context) {
 		return
 	}
 
-	fmt.Printf("[EmailQueueWorker] Processing %d queue items\n", len(queueItems))
+	slog.InfoContext(ctx, "processing email queue items", "component", "email_queue_worker", "count", len(queueItems))
 
+	campaigns := map[uuid.UUID]*models.EmailCampaign{}
 	for _, item := range queueItems {
-		// Mark as processing
+		// Hold the email until the recipient's quiet hours end
+		if campaign := w.loadCampaign(campaigns, item.CampaignID); campaign != nil && campaign.QuietHoursStart != "" {
+			timezone := item.RecipientTZ
+			if timezone == "" {
+				timezone = campaign.Timezone
+			}
+			if resumeAt, quiet := QuietHoursEnd(time.Now(), timezone, campaign.QuietHoursStart, campaign.QuietHoursEnd); quiet {
+				database.DB.Model(&models.EmailQueueItem{}).
+					Where("id = ? AND status = ?", item.ID, item.Status).
+					Update("scheduled_for", resumeAt)
+				continue
+			}
+		}
+
+		// Claim the item; it may have been paused or cancelled since it was fetched
+		claim := database.DB.Model(&models.EmailQueueItem{}).
+			Where("id = ? AND status = ?", item.ID, item.Status).
+			Update("status", "processing")
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
 		item.Status = "processing"
-		database.DB.Save(&item)
 
-		// Process the email
-		err := w.processQueueItem(ctx, item)
+		// Continue the trace of the request that queued the email
+		itemCtx := logging.WithWorkspaceID(tracing.ContextWithTraceParent(ctx, item.TraceParent), item.WorkspaceID.String())
+		if item.RequestID != "" {
+			itemCtx = logging.WithRequestID(itemCtx, item.RequestID)
+		}
+		itemCtx, span := tracing.Tracer().Start(itemCtx, "email_queue.send",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				attribute.String("email_queue.item_id", item.ID.String()),
+				attribute.String("email.service", item.ServiceType),
+				attribute.Int("email_queue.attempt", item.AttemptCount+1),
+			))
 
-		if err != nil {
+		// Process the email
+		err := w.processQueueItem(itemCtx, item)
+
+		var capErr *DailyCapError
+		if errors.As(err, &capErr) {
+			// Every provider is at its daily cap; wait for a slot without using an attempt
+			item.Status = "pending"
+			item.ScheduledFor = capErr.RetryAt
+			item.ErrorMessage = err.Error()
+			database.DB.Save(&item)
+			metrics.EmailQueueItemsTotal.WithLabelValues("deferred", item.ServiceType).Inc()
+			slog.InfoContext(itemCtx, "email queue item deferred by daily cap", "component", "email_queue_worker",
+				"queue_item_id", item.ID, "retry_at", capErr.RetryAt)
+		} else if err != nil {
 			// Handle failure
 			item.AttemptCount++
-			if item.AttemptCount >= item.MaxAttempts {
+			if errors.Is(err, ErrRecipientSuppressed) || errors.Is(err, ErrRecipientOptedOut) {
+				// Retrying won't help; the address stays suppressed until an admin removes it
+				item.Status = "suppressed"
+				item.ErrorMessage = err.Error()
+			} else if item.AttemptCount >= item.MaxAttempts {
 				item.Status = "failed"
 				item.ErrorMessage = err.Error()
 			} else {
@@ -122,20 +180,53 @@ func (w *EmailQueueWorker) processQueue(ctx context.Context) {
 				item.ScheduledFor = time.Now().Add(retryDelay)
 			}
 			database.DB.Save(&item)
-			fmt.Printf("[EmailQueueWorker] Failed to process queue item %s: %v\n", item.ID, err)
+			metrics.EmailQueueItemsTotal.WithLabelValues(item.Status, item.ServiceType).Inc()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			slog.WarnContext(itemCtx, "failed to process email queue item", "component", "email_queue_worker",
+				"queue_item_id", item.ID, "status", item.Status, "attempt", item.AttemptCount, "error", err)
 		} else {
 			// Success
 			now := time.Now()
 			item.Status = "sent"
 			item.SentAt = &now
 			database.DB.Save(&item)
-			fmt.Printf("[EmailQueueWorker] Successfully processed queue item %s\n", item.ID)
+			metrics.EmailQueueItemsTotal.WithLabelValues(item.Status, item.ServiceType).Inc()
+			slog.InfoContext(itemCtx, "email queue item sent", "component", "email_queue_worker", "queue_item_id", item.ID)
 		}
+		span.End()
+
+		if item.CampaignID != nil {
+			completeCampaignIfDone(*item.CampaignID)
+		}
+	}
+}
+
+// loadCampaign returns the item's campaign, caching lookups for the batch
+func (w *EmailQueueWorker) loadCampaign(cache map[uuid.UUID]*models.EmailCampaign, campaignID *uuid.UUID) *models.EmailCampaign {
+	if campaignID == nil {
+		return nil
+	}
+	if campaign, ok := cache[*campaignID]; ok {
+		return campaign
 	}
+	var campaign models.EmailCampaign
+	if err := database.DB.First(&campaign, "id = ?", *campaignID).Error; err != nil {
+		cache[*campaignID] = nil
+		return nil
+	}
+	cache[*campaignID] = &campaign
+	return &campaign
 }
 
 // processQueueItem processes a single queue item
 func (w *EmailQueueWorker) processQueueItem(ctx context.Context, item models.EmailQueueItem) error {
+	trackingID := item.TrackingID
+	if trackingID == "" {
+		trackingID = uuid.New().String()
+	}
+	bodyHTML, trackedLinks := DecorateTrackedHTML(item.BodyHTML, trackingID, item.TrackOpens, item.TrackClicks)
+
 	// Convert queue item to EmailSendRequest
 	req := EmailSendRequest{
 		WorkspaceID:  item.WorkspaceID,
@@ -144,10 +235,13 @@ func (w *EmailQueueWorker) processQueueItem(ctx context.Context, item models.Ema
 		From:         item.SenderEmail,
 		Subject:      item.Subject,
 		Body:         item.Body,
-		BodyHTML:     item.BodyHTML,
+		BodyHTML:     bodyHTML,
 		ServiceType:  EmailServiceType(item.ServiceType),
+		EmailType:    item.EmailType,
+		Category:     item.Category,
 		FormID:       item.FormID,
 		SubmissionID: item.SubmissionID,
+		TrackingID:   trackingID,
 	}
 
 	// Determine service type if not set
@@ -172,15 +266,15 @@ func (w *EmailQueueWorker) processQueueItem(ctx context.Context, item models.Ema
 		RecipientName:  item.RecipientName,
 		Subject:        item.Subject,
 		Body:           item.Body,
-		BodyHTML:       item.BodyHTML,
+		BodyHTML:       bodyHTML,
 		SenderEmail:    item.SenderEmail,
-		ServiceType:    string(result.ServiceType),
 		Status:         "sent",
 		SentAt:         time.Now(),
 	}
 
 	if item.CampaignID != nil {
 		sentEmail.CampaignID = item.CampaignID
+		sentEmail.VariantID = item.VariantID
 	}
 	if item.FormID != nil {
 		sentEmail.FormID = item.FormID
@@ -190,37 +284,15 @@ func (w *EmailQueueWorker) processQueueItem(ctx context.Context, item models.Ema
 	}
 
 	// Set message ID based on service type
-	if result.ServiceType == ServiceTypeGmail {
-		sentEmail.GmailMessageID = result.MessageID
-	} else if result.ServiceType == ServiceTypeResend {
-		sentEmail.ResendMessageID = result.MessageID
-	}
+	result.ApplyTo(&sentEmail)
 
-	// Generate tracking ID
-	trackingID := uuid.New().String()
 	sentEmail.TrackingID = trackingID
 
 	if err := database.DB.Create(&sentEmail).Error; err != nil {
 		return fmt.Errorf("failed to create sent email record: %w", err)
 	}
-
-	// Update campaign status if applicable
-	if item.CampaignID != nil {
-		var campaign models.EmailCampaign
-		if err := database.DB.First(&campaign, "id = ?", item.CampaignID).Error; err == nil {
-			// Check if all emails in campaign are sent
-			var remainingCount int64
-			database.DB.Model(&models.EmailQueueItem{}).
-				Where("campaign_id = ? AND status IN ('pending', 'processing', 'retrying')", item.CampaignID).
-				Count(&remainingCount)
-
-			if remainingCount == 0 {
-				campaign.Status = "sent"
-				now := time.Now()
-				campaign.SentAt = &now
-				database.DB.Save(&campaign)
-			}
-		}
+	if err := SaveTrackedLinks(sentEmail, trackedLinks); err != nil {
+		slog.WarnContext(ctx, "failed to save tracked links", "component", "email_queue_worker", "sent_email_id", sentEmail.ID, "error", err)
 	}
 
 	return nil
@@ -229,7 +301,7 @@ func (w *EmailQueueWorker) processQueueItem(ctx context.Context, item models.Ema
 // ProcessCampaignQueue processes all queue items for a campaign
 func ProcessCampaignQueue(ctx context.Context, router *EmailRouter, campaignID uuid.UUID, staggerDelaySeconds int) error {
 	var queueItems []models.EmailQueueItem
-	if err := database.DB.Where("campaign_id = ? AND status = ?", campaignID, "pending").
+	if err := database.DB.Where("campaign_id = ? AND status IN ?", campaignID, []string{"pending", "scheduled", "paused"}).
 		Order("created_at ASC").
 		Find(&queueItems).Error; err != nil {
 		return fmt.Errorf("failed to fetch campaign queue items: %w", err)
@@ -243,8 +315,9 @@ func ProcessCampaignQueue(ctx context.Context, router *EmailRouter, campaignID u
 	baseTime := time.Now()
 	for i, item := range queueItems {
 		delay := time.Duration(i*staggerDelaySeconds) * time.Second
-		item.ScheduledFor = baseTime.Add(delay)
-		database.DB.Save(&item)
+		database.DB.Model(&models.EmailQueueItem{}).
+			Where("id = ? AND status = ?", item.ID, item.Status).
+			Updates(map[string]interface{}{"status": "pending", "scheduled_for": baseTime.Add(delay)})
 	}
 
 	return nil
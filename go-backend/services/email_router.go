@@ -1,16 +1,15 @@
 package services
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/logging"
 	"github.com/Jsanchez767/matic-platform/models"
 	"github.com/google/uuid"
 )
@@ -21,6 +20,7 @@ type EmailServiceType string
 const (
 	ServiceTypeGmail  EmailServiceType = "gmail"
 	ServiceTypeResend EmailServiceType = "resend"
+	ServiceTypeSMTP   EmailServiceType = "smtp"
 )
 
 // EmailSendRequest represents a request to send an email
@@ -37,266 +37,308 @@ type EmailSendRequest struct {
 	SubmissionID *uuid.UUID
 	FormID       *uuid.UUID
 	ServiceType  EmailServiceType // Preferred service, will fallback if needed
+	EmailType    string           // Selects the routing rule: reminder, system, notification, communication (default), applicant
+	Category     string           // reminders, announcements; empty for transactional email
 	TrackOpens   bool
+	TrackingID   string // Sent email's tracking ID, used for the plus-addressed reply-to
 	ThreadID     string
 	InReplyTo    string
 	References   string
+	Attachments  []EmailAttachment // Only sent through providers that support attachments
+}
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        string // Base64 encoded content
 }
 
 // EmailSendResult represents the result of sending an email
 type EmailSendResult struct {
 	Success      bool
 	MessageID    string
+	ThreadID     string // Gmail thread, used to match replies
 	ServiceType  EmailServiceType
 	ErrorMessage string
 }
 
-// EmailRouter handles intelligent routing between Gmail and Resend
-type EmailRouter struct{}
+// ApplyTo records the provider and its message IDs on the sent email
+func (r *EmailSendResult) ApplyTo(sentEmail *models.SentEmail) {
+	sentEmail.ServiceType = string(r.ServiceType)
+	switch r.ServiceType {
+	case ServiceTypeGmail:
+		sentEmail.GmailMessageID = r.MessageID
+		sentEmail.GmailThreadID = r.ThreadID
+	case ServiceTypeResend:
+		sentEmail.ResendMessageID = r.MessageID
+	case ServiceTypeSMTP:
+		sentEmail.SMTPMessageID = r.MessageID
+	}
+}
 
-// NewEmailRouter creates a new email router instance
+// healthStaleAfter is how long a recorded provider health stays current
+const healthStaleAfter = 5 * time.Minute
+
+// EmailRouter picks a provider for each email from the workspace routing
+// policy and provider health, then sends through it
+type EmailRouter struct {
+	providers *ProviderRegistry
+}
+
+// NewEmailRouter creates a new email router instance using the default providers
 func NewEmailRouter() *EmailRouter {
-	return &EmailRouter{}
+	return NewEmailRouterWithProviders(DefaultEmailProviders())
+}
+
+// NewEmailRouterWithProviders creates a router over a custom registry, e.g.
+// one holding only a MemoryEmailProvider in tests
+func NewEmailRouterWithProviders(providers *ProviderRegistry) *EmailRouter {
+	return &EmailRouter{providers: providers}
+}
+
+// Providers returns the registry the router chooses from
+func (r *EmailRouter) Providers() *ProviderRegistry {
+	return r.providers
 }
 
 // DetermineServiceType determines which service to use based on email type and health
 func (r *EmailRouter) DetermineServiceType(ctx context.Context, workspaceID uuid.UUID, preferredType EmailServiceType, emailType string) (EmailServiceType, error) {
-	// For reminders and system notifications, prefer Resend
-	if emailType == "reminder" || emailType == "system" || emailType == "notification" {
-		health, err := r.checkServiceHealth(ctx, workspaceID, ServiceTypeResend)
-		if err == nil && health.Status == "healthy" {
-			return ServiceTypeResend, nil
-		}
-		// Fallback to Gmail if Resend is down
-		health, err = r.checkServiceHealth(ctx, workspaceID, ServiceTypeGmail)
-		if err == nil && health.Status == "healthy" {
-			return ServiceTypeGmail, nil
-		}
-		return preferredType, nil
-	}
+	return r.selectService(ctx, workspaceID, preferredType, emailType, ProviderCapabilities{}, nil)
+}
 
-	// For communications, prefer Gmail (personal touch)
-	if emailType == "communication" || emailType == "applicant" {
-		health, err := r.checkServiceHealth(ctx, workspaceID, ServiceTypeGmail)
-		if err == nil && health.Status == "healthy" {
-			return ServiceTypeGmail, nil
+// selectService returns the first healthy provider in the routing order,
+// preferring providers with the needed capabilities and leaving out skip.
+// Providers without recent health are treated as healthy. When none is
+// healthy it returns the preferred (or first) provider so the send fails visibly.
+func (r *EmailRouter) selectService(ctx context.Context, workspaceID uuid.UUID, preferredType EmailServiceType, emailType string, needs ProviderCapabilities, skip map[EmailServiceType]bool) (EmailServiceType, error) {
+	if override := EmailProviderOverride(); override != "" {
+		if _, ok := r.providers.Get(override); !ok {
+			return "", fmt.Errorf("EMAIL_PROVIDER_OVERRIDE names unknown provider %q", override)
 		}
-		// Fallback to Resend if Gmail is down
-		health, err = r.checkServiceHealth(ctx, workspaceID, ServiceTypeResend)
-		if err == nil && health.Status == "healthy" {
-			return ServiceTypeResend, nil
+		if remaining, limited := DailyCapRemaining(workspaceID, override); limited && remaining == 0 {
+			return "", &DailyCapError{RetryAt: nextDailyCapSlot(workspaceID, override)}
 		}
-		return preferredType, nil
+		return override, nil
 	}
 
-	// Default: use preferred type, but check health
-	health, err := r.checkServiceHealth(ctx, workspaceID, preferredType)
-	if err == nil && health.Status == "healthy" {
-		return preferredType, nil
+	ordered := r.routingOrder(workspaceID, preferredType, emailType, needs)
+	if len(ordered) == 0 {
+		return "", fmt.Errorf("no registered email provider can send this email")
 	}
 
-	// Fallback to the other service
-	fallbackType := ServiceTypeGmail
-	if preferredType == ServiceTypeGmail {
-		fallbackType = ServiceTypeResend
+	// Skip providers that reached their daily cap (e.g. Gmail sending limits)
+	var candidates []EmailServiceType
+	var retryAt time.Time
+	for _, serviceType := range ordered {
+		if skip[serviceType] {
+			continue
+		}
+		if remaining, limited := DailyCapRemaining(workspaceID, serviceType); limited && remaining == 0 {
+			if slot := nextDailyCapSlot(workspaceID, serviceType); retryAt.IsZero() || slot.Before(retryAt) {
+				retryAt = slot
+			}
+			continue
+		}
+		candidates = append(candidates, serviceType)
 	}
-	health, err = r.checkServiceHealth(ctx, workspaceID, fallbackType)
-	if err == nil && health.Status == "healthy" {
-		return fallbackType, nil
+	if len(candidates) == 0 {
+		if retryAt.IsZero() {
+			return "", ErrProviderNotConfigured
+		}
+		return "", &DailyCapError{RetryAt: retryAt}
 	}
 
-	// If both are unhealthy, return preferred type anyway (will fail gracefully)
-	return preferredType, nil
-}
-
-// SendEmail routes and sends an email using the appropriate service
-func (r *EmailRouter) SendEmail(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
-	// Determine which service to use
-	serviceType, err := r.DetermineServiceType(ctx, req.WorkspaceID, req.ServiceType, "communication")
-	if err != nil {
-		return &EmailSendResult{
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("Failed to determine service type: %v", err),
-		}, err
+	for _, serviceType := range candidates {
+		health, err := r.checkServiceHealth(ctx, workspaceID, serviceType)
+		if err != nil {
+			continue
+		}
+		if health.Status == "unknown" {
+			// No recent sends to judge by. A health check can mean an SMTP
+			// dial, so don't hold up the send: assume healthy and check in
+			// the background.
+			r.refreshHealthInBackground(ctx, workspaceID, serviceType)
+			return serviceType, nil
+		}
+		if health.Status == "healthy" {
+			return serviceType, nil
+		}
 	}
 
-	// Send via the determined service
-	switch serviceType {
-	case ServiceTypeResend:
-		return r.sendViaResend(ctx, req)
-	case ServiceTypeGmail:
-		return r.sendViaGmail(ctx, req)
-	default:
-		return &EmailSendResult{
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("Unknown service type: %s", serviceType),
-		}, fmt.Errorf("unknown service type: %s", serviceType)
+	for _, serviceType := range candidates {
+		if serviceType == preferredType {
+			return preferredType, nil
+		}
 	}
+	return candidates[0], nil
 }
 
-// sendViaResend sends an email via Resend API
-func (r *EmailRouter) sendViaResend(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
-	// Get Resend integration
-	var resendIntegration models.ResendIntegration
-	if err := database.DB.Where("workspace_id = ? AND is_active = ?", req.WorkspaceID, true).First(&resendIntegration).Error; err != nil {
-		return &EmailSendResult{
-			Success:      false,
-			ServiceType:  ServiceTypeResend,
-			ErrorMessage: "Resend integration not configured",
-		}, err
-	}
-
-	// Prepare Resend API request
-	resendURL := "https://api.resend.com/emails"
-	if apiURL := os.Getenv("RESEND_API_URL"); apiURL != "" {
-		resendURL = apiURL + "/emails"
-	}
-
-	payload := map[string]interface{}{
-		"from":    req.From,
-		"to":      []string{req.To},
-		"subject": req.Subject,
-		"text":    req.Body,
-	}
-
-	if req.BodyHTML != "" {
-		payload["html"] = req.BodyHTML
+// routingOrder lists registered providers in the order the workspace policy
+// tries them for emailType. Providers that can't thread come after those
+// that can when threading is needed; providers without attachment support
+// are left out when there are attachments.
+func (r *EmailRouter) routingOrder(workspaceID uuid.UUID, preferredType EmailServiceType, emailType string, needs ProviderCapabilities) []EmailServiceType {
+	rules := WorkspaceRoutingRules(workspaceID)
+	order, ok := rules[emailType]
+	if !ok {
+		order = rules[RoutingRuleDefault]
+		if preferredType != "" {
+			order = append([]EmailServiceType{preferredType}, order...)
+		}
 	}
 
-	if req.ReplyTo != "" {
-		payload["reply_to"] = req.ReplyTo
+	var capable, others []EmailServiceType
+	seen := map[EmailServiceType]bool{}
+	for _, serviceType := range order {
+		provider, registered := r.providers.Get(serviceType)
+		if !registered || seen[serviceType] {
+			continue
+		}
+		seen[serviceType] = true
+		capabilities := provider.Capabilities()
+		if needs.Attachments && !capabilities.Attachments {
+			continue // Would drop the files
+		}
+		if needs.Threading && !capabilities.Threading {
+			others = append(others, serviceType)
+			continue
+		}
+		capable = append(capable, serviceType)
 	}
+	return append(capable, others...)
+}
 
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
+// SendEmail routes and sends an email using the appropriate service
+func (r *EmailRouter) SendEmail(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
+	// Never send to bounced, complained or unsubscribed addresses
+	if suppressed, entry := IsSuppressed(req.WorkspaceID, req.To); suppressed {
 		return &EmailSendResult{
 			Success:      false,
-			ServiceType:  ServiceTypeResend,
-			ErrorMessage: fmt.Sprintf("Failed to marshal payload: %v", err),
-		}, err
+			ErrorMessage: fmt.Sprintf("Recipient is suppressed (%s)", entry.Reason),
+		}, ErrRecipientSuppressed
 	}
-
-	// Make HTTP request to Resend
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", resendURL, bytes.NewBuffer(payloadBytes))
-	if err != nil {
+	if IsOptedOut(req.WorkspaceID, req.To, req.Category) {
 		return &EmailSendResult{
 			Success:      false,
-			ServiceType:  ServiceTypeResend,
-			ErrorMessage: fmt.Sprintf("Failed to create request: %v", err),
-		}, err
+			ErrorMessage: fmt.Sprintf("Recipient opted out of %s emails", req.Category),
+		}, ErrRecipientOptedOut
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+resendIntegration.APIKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		// Update health status
-		r.UpdateServiceHealth(ctx, req.WorkspaceID, ServiceTypeResend, "down", err.Error())
-		return &EmailSendResult{
-			Success:      false,
-			ServiceType:  ServiceTypeResend,
-			ErrorMessage: fmt.Sprintf("Failed to send email: %v", err),
-		}, err
+	emailType := req.EmailType
+	if emailType == "" {
+		emailType = "communication"
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return &EmailSendResult{
-			Success:      false,
-			ServiceType:  ServiceTypeResend,
-			ErrorMessage: fmt.Sprintf("Failed to read response: %v", err),
-		}, err
+	// Replies should go through a provider that keeps them in the thread, and
+	// attachments through one that can carry them
+	needs := ProviderCapabilities{
+		Threading:   req.ThreadID != "" || req.InReplyTo != "",
+		Attachments: len(req.Attachments) > 0,
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		var errorResp struct {
-			Message string `json:"message"`
+	// A provider picked without a health check may turn out not to be set
+	// up for the workspace; move on to the next one in that case
+	skip := map[EmailServiceType]bool{}
+	var serviceType EmailServiceType
+	var result *EmailSendResult
+	var err error
+	for {
+		serviceType, err = r.selectService(ctx, req.WorkspaceID, req.ServiceType, emailType, needs, skip)
+		if err != nil {
+			return &EmailSendResult{
+				Success:      false,
+				ErrorMessage: fmt.Sprintf("Failed to determine service type: %v", err),
+			}, err
 		}
-		json.Unmarshal(body, &errorResp)
+		provider, _ := r.providers.Get(serviceType)
 
-		errorMsg := errorResp.Message
-		if errorMsg == "" {
-			errorMsg = fmt.Sprintf("HTTP %d: Failed to send email", resp.StatusCode)
+		// Gmail replies are matched by thread; other providers route replies to
+		// the inbound address with a token identifying this email
+		sendReq := req
+		if sendReq.ReplyTo == "" && sendReq.TrackingID != "" && serviceType != ServiceTypeGmail {
+			sendReq.ReplyTo = ReplyToAddress(sendReq.TrackingID)
 		}
 
-		// Update health status
-		r.UpdateServiceHealth(ctx, req.WorkspaceID, ServiceTypeResend, "down", errorMsg)
-
-		return &EmailSendResult{
-			Success:      false,
-			ServiceType:  ServiceTypeResend,
-			ErrorMessage: errorMsg,
-		}, fmt.Errorf(errorMsg)
+		result, err = provider.Send(ctx, sendReq)
+		if result == nil {
+			result = &EmailSendResult{ServiceType: serviceType}
+			if err != nil {
+				result.ErrorMessage = err.Error()
+			}
+		}
+		if !errors.Is(err, ErrProviderNotConfigured) || EmailProviderOverride() != "" {
+			break
+		}
+		skip[serviceType] = true
 	}
 
-	// Parse successful response
-	var resendResp struct {
-		ID string `json:"id"`
-	}
-	if err := json.Unmarshal(body, &resendResp); err != nil {
-		return &EmailSendResult{
-			Success:      false,
-			ServiceType:  ServiceTypeResend,
-			ErrorMessage: fmt.Sprintf("Failed to parse response: %v", err),
-		}, err
+	// A missing integration is a configuration problem, not an outage
+	switch {
+	case errors.Is(err, ErrProviderNotConfigured):
+	case err != nil:
+		r.UpdateServiceHealth(ctx, req.WorkspaceID, serviceType, "down", err.Error())
+	default:
+		r.UpdateServiceHealth(ctx, req.WorkspaceID, serviceType, "healthy", "")
 	}
-
-	// Update health status to healthy
-	r.UpdateServiceHealth(ctx, req.WorkspaceID, ServiceTypeResend, "healthy", "")
-
-	return &EmailSendResult{
-		Success:     true,
-		MessageID:   resendResp.ID,
-		ServiceType: ServiceTypeResend,
-	}, nil
+	return result, err
 }
 
-// sendViaGmail sends an email via Gmail API
-func (r *EmailRouter) sendViaGmail(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
-	// Build from address
-	fromName := req.FromName
-	if fromName == "" {
-		fromName = req.From
+// RefreshServiceHealth runs every provider's HealthCheck for the workspace
+// and records the results. Providers the workspace hasn't configured are skipped.
+func (r *EmailRouter) RefreshServiceHealth(ctx context.Context, workspaceID uuid.UUID) map[EmailServiceType]string {
+	statuses := make(map[EmailServiceType]string)
+	for _, serviceType := range r.providers.Types() {
+		provider, _ := r.providers.Get(serviceType)
+		err := provider.HealthCheck(ctx, workspaceID)
+		switch {
+		case errors.Is(err, ErrProviderNotConfigured):
+			statuses[serviceType] = "not_configured"
+		case err != nil:
+			r.UpdateServiceHealth(ctx, workspaceID, serviceType, "down", err.Error())
+			statuses[serviceType] = "down"
+		default:
+			r.UpdateServiceHealth(ctx, workspaceID, serviceType, "healthy", "")
+			statuses[serviceType] = "healthy"
+		}
 	}
+	return statuses
+}
 
-	// Send via Gmail
-	messageID, _, err := SendGmailEmail(
-		ctx,
-		req.WorkspaceID,
-		req.To,
-		req.ToName,
-		req.From,
-		fromName,
-		req.Subject,
-		req.Body,
-		req.BodyHTML,
-		req.ThreadID,
-		req.InReplyTo,
-		req.References,
-	)
+// healthRefreshes remembers when each workspace provider was last checked in
+// the background, keyed by "<workspace>:<service>"
+var healthRefreshes sync.Map
 
-	if err != nil {
-		// Update health status
-		r.UpdateServiceHealth(ctx, req.WorkspaceID, ServiceTypeGmail, "down", err.Error())
-		return &EmailSendResult{
-			Success:      false,
-			ServiceType:  ServiceTypeGmail,
-			ErrorMessage: err.Error(),
-		}, err
+// refreshHealthInBackground runs the provider's HealthCheck without blocking
+// the caller, at most once per staleness window for each workspace provider
+func (r *EmailRouter) refreshHealthInBackground(ctx context.Context, workspaceID uuid.UUID, serviceType EmailServiceType) {
+	key := workspaceID.String() + ":" + string(serviceType)
+	now := time.Now()
+	if last, loaded := healthRefreshes.LoadOrStore(key, now); loaded {
+		if now.Sub(last.(time.Time)) < healthStaleAfter {
+			return
+		}
+		healthRefreshes.Store(key, now)
 	}
 
-	// Update health status to healthy
-	r.UpdateServiceHealth(ctx, req.WorkspaceID, ServiceTypeGmail, "healthy", "")
-
-	return &EmailSendResult{
-		Success:     true,
-		MessageID:   messageID,
-		ServiceType: ServiceTypeGmail,
-	}, nil
+	provider, ok := r.providers.Get(serviceType)
+	if !ok {
+		return
+	}
+	ctx = logging.Detach(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+		defer cancel()
+		err := provider.HealthCheck(ctx, workspaceID)
+		switch {
+		case errors.Is(err, ErrProviderNotConfigured):
+		case err != nil:
+			slog.WarnContext(ctx, "email provider health check failed",
+				"component", "email_router", "workspace_id", workspaceID, "service_type", serviceType, "error", err)
+			r.UpdateServiceHealth(ctx, workspaceID, serviceType, "down", err.Error())
+		default:
+			r.UpdateServiceHealth(ctx, workspaceID, serviceType, "healthy", "")
+		}
+	}()
 }
 
 // checkServiceHealth checks the health status of an email service
@@ -315,8 +357,8 @@ func (r *EmailRouter) checkServiceHealth(ctx context.Context, workspaceID uuid.U
 		return &health, nil
 	}
 
-	// If health check is older than 5 minutes, mark as stale
-	if time.Since(health.LastCheckedAt) > 5*time.Minute {
+	// If health check is older than the staleness window, mark as stale
+	if time.Since(health.LastCheckedAt) > healthStaleAfter {
 		health.Status = "unknown"
 	}
 
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// emptyDB answers every query with no rows, except counts, which return
+// emptyDBCount. That leaves the router with no suppressions, opt-outs,
+// stored routing rules or health records.
+type emptyDB struct{}
+
+var emptyDBCount atomic.Int64
+
+func (emptyDB) Open(name string) (driver.Conn, error) { return emptyConn{}, nil }
+
+type emptyConn struct{}
+
+func (emptyConn) Prepare(query string) (driver.Stmt, error) { return emptyStmt{query}, nil }
+func (emptyConn) Close() error                              { return nil }
+func (emptyConn) Begin() (driver.Tx, error)                 { return emptyTx{}, nil }
+
+func (emptyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return emptyStmt{query}.Query(nil)
+}
+
+func (emptyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+type emptyStmt struct{ query string }
+
+func (emptyStmt) Close() error                                    { return nil }
+func (emptyStmt) NumInput() int                                   { return -1 }
+func (emptyStmt) Exec(args []driver.Value) (driver.Result, error) { return driver.RowsAffected(0), nil }
+
+func (s emptyStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if strings.Contains(strings.ToLower(s.query), "count(") {
+		return &emptyRows{count: []driver.Value{emptyDBCount.Load()}}, nil
+	}
+	return &emptyRows{}, nil
+}
+
+type emptyTx struct{}
+
+func (emptyTx) Commit() error   { return nil }
+func (emptyTx) Rollback() error { return nil }
+
+type emptyRows struct{ count []driver.Value }
+
+func (r *emptyRows) Columns() []string {
+	if r.count != nil {
+		return []string{"count"}
+	}
+	return nil
+}
+
+func (r *emptyRows) Close() error { return nil }
+
+func (r *emptyRows) Next(dest []driver.Value) error {
+	if r.count == nil {
+		return io.EOF
+	}
+	copy(dest, r.count)
+	r.count = nil
+	return nil
+}
+
+var useEmptyDBOnce sync.Once
+
+// useEmptyDB points database.DB at emptyDB. It is never reset because
+// background health checks keep using it after a test returns.
+func useEmptyDB(t *testing.T) {
+	t.Helper()
+	useEmptyDBOnce.Do(func() {
+		sql.Register("emptydb", emptyDB{})
+		sqlDB, err := sql.Open("emptydb", "")
+		if err != nil {
+			t.Fatalf("open empty database: %v", err)
+		}
+		database.DB, err = gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if err != nil {
+			t.Fatalf("open gorm: %v", err)
+		}
+	})
+	emptyDBCount.Store(0)
+}
+
+// stubProvider records what it is asked to send
+type stubProvider struct {
+	serviceType  EmailServiceType
+	capabilities ProviderCapabilities
+	err          error
+	sent         []EmailSendRequest
+}
+
+func (p *stubProvider) Type() EmailServiceType             { return p.serviceType }
+func (p *stubProvider) Capabilities() ProviderCapabilities { return p.capabilities }
+
+func (p *stubProvider) Send(ctx context.Context, req EmailSendRequest) (*EmailSendResult, error) {
+	p.sent = append(p.sent, req)
+	if p.err != nil {
+		return &EmailSendResult{ServiceType: p.serviceType, ErrorMessage: p.err.Error()}, p.err
+	}
+	return &EmailSendResult{Success: true, MessageID: "stub-" + string(p.serviceType), ServiceType: p.serviceType}, nil
+}
+
+func (p *stubProvider) HealthCheck(ctx context.Context, workspaceID uuid.UUID) error { return p.err }
+
+func TestEmailRouterOverrideSendsThroughMemory(t *testing.T) {
+	useEmptyDB(t)
+	t.Setenv("EMAIL_PROVIDER_OVERRIDE", string(ServiceTypeMemory))
+
+	memory := NewMemoryEmailProvider()
+	gmail := &stubProvider{serviceType: ServiceTypeGmail, capabilities: ProviderCapabilities{Threading: true}}
+	router := NewEmailRouterWithProviders(NewProviderRegistry(gmail, memory))
+
+	result, err := router.SendEmail(context.Background(), EmailSendRequest{
+		WorkspaceID: uuid.New(),
+		To:          "ada@example.com",
+		Subject:     "Welcome",
+		Body:        "Hello",
+		ServiceType: ServiceTypeGmail,
+	})
+	if err != nil {
+		t.Fatalf("SendEmail error: %v", err)
+	}
+	if !result.Success || result.ServiceType != ServiceTypeMemory {
+		t.Fatalf("result = %+v, want a successful memory send", result)
+	}
+	messages := memory.Messages()
+	if len(messages) != 1 || messages[0].To != "ada@example.com" || messages[0].MessageID != result.MessageID {
+		t.Fatalf("captured %+v, want the one message", messages)
+	}
+	if len(gmail.sent) != 0 {
+		t.Errorf("gmail sent %d emails, want none under the override", len(gmail.sent))
+	}
+}
+
+func TestEmailRouterUnknownOverride(t *testing.T) {
+	useEmptyDB(t)
+	t.Setenv("EMAIL_PROVIDER_OVERRIDE", string(ServiceTypeMemory))
+
+	router := NewEmailRouterWithProviders(NewProviderRegistry(&stubProvider{serviceType: ServiceTypeGmail}))
+	result, err := router.SendEmail(context.Background(), EmailSendRequest{WorkspaceID: uuid.New(), To: "ada@example.com"})
+	if err == nil || result.Success {
+		t.Fatalf("SendEmail = %+v, %v; want an error for the unregistered override", result, err)
+	}
+}
+
+func TestEmailRouterRouting(t *testing.T) {
+	attachment := []EmailAttachment{{Filename: "offer.pdf", ContentType: "application/pdf", Data: "JVBERi0="}}
+
+	tests := []struct {
+		name      string
+		gmail     *stubProvider
+		resend    *stubProvider
+		req       EmailSendRequest
+		sentCount int64
+		gmailCap  string
+		want      EmailServiceType
+		wantErr   bool
+	}{
+		{
+			name:   "communication prefers gmail",
+			gmail:  &stubProvider{serviceType: ServiceTypeGmail},
+			resend: &stubProvider{serviceType: ServiceTypeResend},
+			req:    EmailSendRequest{EmailType: "communication"},
+			want:   ServiceTypeGmail,
+		},
+		{
+			name:   "falls through when gmail is not connected",
+			gmail:  &stubProvider{serviceType: ServiceTypeGmail, err: ErrProviderNotConfigured},
+			resend: &stubProvider{serviceType: ServiceTypeResend},
+			req:    EmailSendRequest{EmailType: "communication"},
+			want:   ServiceTypeResend,
+		},
+		{
+			name:   "replies prefer a threading provider",
+			gmail:  &stubProvider{serviceType: ServiceTypeGmail, capabilities: ProviderCapabilities{Threading: true}},
+			resend: &stubProvider{serviceType: ServiceTypeResend},
+			req:    EmailSendRequest{EmailType: "reminder", ThreadID: "thread-1"},
+			want:   ServiceTypeGmail,
+		},
+		{
+			name:   "attachments skip providers that would drop them",
+			gmail:  &stubProvider{serviceType: ServiceTypeGmail},
+			resend: &stubProvider{serviceType: ServiceTypeResend, capabilities: ProviderCapabilities{Attachments: true}},
+			req:    EmailSendRequest{EmailType: "communication", Attachments: attachment},
+			want:   ServiceTypeResend,
+		},
+		{
+			name:    "attachments without a capable provider fail",
+			gmail:   &stubProvider{serviceType: ServiceTypeGmail},
+			resend:  &stubProvider{serviceType: ServiceTypeResend},
+			req:     EmailSendRequest{EmailType: "communication", Attachments: attachment},
+			wantErr: true,
+		},
+		{
+			name:      "providers at their daily cap are skipped",
+			gmail:     &stubProvider{serviceType: ServiceTypeGmail},
+			resend:    &stubProvider{serviceType: ServiceTypeResend},
+			req:       EmailSendRequest{EmailType: "communication"},
+			sentCount: 5,
+			gmailCap:  "5",
+			want:      ServiceTypeResend,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useEmptyDB(t)
+			emptyDBCount.Store(tt.sentCount)
+			t.Setenv("EMAIL_PROVIDER_OVERRIDE", "")
+			t.Setenv("EMAIL_DAILY_CAP_GMAIL", tt.gmailCap)
+
+			router := NewEmailRouterWithProviders(NewProviderRegistry(tt.gmail, tt.resend))
+			req := tt.req
+			req.WorkspaceID = uuid.New() // Fresh health state for each case
+			req.To = "ada@example.com"
+
+			result, err := router.SendEmail(context.Background(), req)
+			if tt.wantErr {
+				if err == nil || result.Success {
+					t.Fatalf("SendEmail = %+v, %v; want an error", result, err)
+				}
+				if len(tt.gmail.sent)+len(tt.resend.sent) != 0 {
+					t.Errorf("providers sent %d emails, want none", len(tt.gmail.sent)+len(tt.resend.sent))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendEmail error: %v", err)
+			}
+			if result.ServiceType != tt.want {
+				t.Errorf("sent through %s, want %s", result.ServiceType, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailRouterDailyCapError(t *testing.T) {
+	useEmptyDB(t)
+	emptyDBCount.Store(2)
+	t.Setenv("EMAIL_PROVIDER_OVERRIDE", "")
+	t.Setenv("EMAIL_DAILY_CAP_GMAIL", "2")
+
+	router := NewEmailRouterWithProviders(NewProviderRegistry(&stubProvider{serviceType: ServiceTypeGmail}))
+	_, err := router.DetermineServiceType(context.Background(), uuid.New(), ServiceTypeGmail, "communication")
+	if !errors.Is(err, ErrDailyCapReached) {
+		t.Fatalf("DetermineServiceType error = %v, want ErrDailyCapReached", err)
+	}
+}
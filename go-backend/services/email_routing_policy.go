@@ -0,0 +1,99 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+)
+
+// RoutingRuleDefault applies to email types without their own rule. The
+// request's preferred service is tried first under this rule.
+const RoutingRuleDefault = "default"
+
+// RoutingRules maps an email type to providers in the order they are tried
+type RoutingRules map[string][]EmailServiceType
+
+// DefaultRoutingRules is the built-in policy: reminders and system mail
+// prefer Resend, communications prefer Gmail (personal touch), and every
+// type falls back to the remaining services
+func DefaultRoutingRules() RoutingRules {
+	bulkFirst := []EmailServiceType{ServiceTypeResend, ServiceTypeGmail, ServiceTypeSMTP}
+	personalFirst := []EmailServiceType{ServiceTypeGmail, ServiceTypeResend, ServiceTypeSMTP}
+	return RoutingRules{
+		"reminder":         bulkFirst,
+		"system":           bulkFirst,
+		"notification":     bulkFirst,
+		"communication":    personalFirst,
+		"applicant":        personalFirst,
+		RoutingRuleDefault: personalFirst,
+	}
+}
+
+// WorkspaceRoutingRules returns the workspace's stored rules layered over
+// the defaults
+func WorkspaceRoutingRules(workspaceID uuid.UUID) RoutingRules {
+	rules := DefaultRoutingRules()
+
+	var policy models.EmailRoutingPolicy
+	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&policy).Error; err != nil {
+		return rules
+	}
+	var stored RoutingRules
+	if err := json.Unmarshal(policy.Rules, &stored); err != nil {
+		return rules
+	}
+	for emailType, order := range stored {
+		if len(order) > 0 {
+			rules[emailType] = order
+		}
+	}
+	return rules
+}
+
+// ValidateRoutingRules checks that every rule names registered providers
+// without repeats
+func ValidateRoutingRules(registry *ProviderRegistry, rules RoutingRules) error {
+	for emailType, order := range rules {
+		if emailType == "" {
+			return fmt.Errorf("email type must not be empty")
+		}
+		seen := map[EmailServiceType]bool{}
+		for _, serviceType := range order {
+			if _, ok := registry.Get(serviceType); !ok {
+				return fmt.Errorf("unknown email provider %q in rule %q", serviceType, emailType)
+			}
+			if seen[serviceType] {
+				return fmt.Errorf("provider %q listed twice in rule %q", serviceType, emailType)
+			}
+			seen[serviceType] = true
+		}
+	}
+	return nil
+}
+
+// SaveRoutingRules replaces the workspace's stored rules. Empty rules reset
+// the workspace to the defaults.
+func SaveRoutingRules(workspaceID uuid.UUID, rules RoutingRules, updatedBy string) error {
+	if rules == nil {
+		rules = RoutingRules{}
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	policy := models.EmailRoutingPolicy{
+		WorkspaceID: workspaceID,
+		Rules:       datatypes.JSON(rulesJSON),
+		UpdatedBy:   updatedBy,
+	}
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rules", "updated_by", "updated_at"}),
+	}).Create(&policy).Error
+}
@@ -16,8 +16,14 @@ type EmailTemplateBuilder struct {
 	CompanyName   string
 	CompanyLogo   string
 	FooterText    string
+	// PreferencesURL adds "Manage email preferences" to the footer. Campaigns
+	// set PreferencesURLPlaceholder and substitute the per-recipient link.
+	PreferencesURL string
 }
 
+// PreferencesURLPlaceholder is replaced with each recipient's preference link
+const PreferencesURLPlaceholder = "{{__preferences_url__}}"
+
 // AuthEmailTemplate creates clean, Vercel/Notion-style authentication emails
 type AuthEmailTemplate struct {
 	Type          string // "magic-link", "password-reset", "verification"
@@ -224,6 +230,9 @@ func (b *EmailTemplateBuilder) buildFooter() string {
 	if footerText == "" {
 		footerText = fmt.Sprintf("&copy; %s. All rights reserved.", b.CompanyName)
 	}
+	if b.PreferencesURL != "" {
+		footerText += fmt.Sprintf(`<br /><a href="%s" style="color: #6B7280; text-decoration: underline;">Manage email preferences or unsubscribe</a>`, b.PreferencesURL)
+	}
 
 	return footerText
 }
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+)
+
+// ==================== Daily Provider Caps ====================
+
+// dailyCapWindow is the rolling window daily caps are counted over
+const dailyCapWindow = 24 * time.Hour
+
+// defaultDailyCaps are used when EMAIL_DAILY_CAP_<PROVIDER> isn't set. Gmail
+// limits consumer accounts to 500 recipients a day (Workspace accounts get
+// 2000); the other providers are limited by their own plans.
+var defaultDailyCaps = map[EmailServiceType]int{
+	ServiceTypeGmail: 500,
+}
+
+// ErrDailyCapReached is returned when every eligible provider has reached
+// its daily cap for the workspace
+var ErrDailyCapReached = errors.New("daily email cap reached")
+
+// DailyCapError reports when sending can resume after a daily cap was reached
+type DailyCapError struct {
+	RetryAt time.Time
+}
+
+func (e *DailyCapError) Error() string {
+	return fmt.Sprintf("%s; next slot at %s", ErrDailyCapReached, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *DailyCapError) Is(target error) bool {
+	return target == ErrDailyCapReached
+}
+
+// ProviderDailyCap returns the per-workspace daily cap for a provider, or 0
+// for no cap. EMAIL_DAILY_CAP_GMAIL=2000 raises the Gmail cap, 0 disables it.
+func ProviderDailyCap(serviceType EmailServiceType) int {
+	if value := os.Getenv("EMAIL_DAILY_CAP_" + strings.ToUpper(string(serviceType))); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil && limit >= 0 {
+			return limit
+		}
+	}
+	return defaultDailyCaps[serviceType]
+}
+
+// DailyCapRemaining returns how many more emails the workspace may send
+// through a provider in the current window. limited is false when the
+// provider has no cap.
+func DailyCapRemaining(workspaceID uuid.UUID, serviceType EmailServiceType) (remaining int, limited bool) {
+	limit := ProviderDailyCap(serviceType)
+	if limit == 0 {
+		return 0, false
+	}
+
+	var sent int64
+	database.DB.Model(&models.SentEmail{}).
+		Where("workspace_id = ? AND service_type = ? AND status <> ? AND sent_at > ?",
+			workspaceID, string(serviceType), "failed", time.Now().Add(-dailyCapWindow)).
+		Count(&sent)
+
+	if remaining = limit - int(sent); remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// nextDailyCapSlot returns when the oldest send in the window ages out,
+// freeing a slot for the provider
+func nextDailyCapSlot(workspaceID uuid.UUID, serviceType EmailServiceType) time.Time {
+	var oldest models.SentEmail
+	err := database.DB.Select("sent_at").
+		Where("workspace_id = ? AND service_type = ? AND status <> ? AND sent_at > ?",
+			workspaceID, string(serviceType), "failed", time.Now().Add(-dailyCapWindow)).
+		Order("sent_at ASC").
+		First(&oldest).Error
+	if err != nil {
+		return time.Now().Add(time.Minute)
+	}
+	return oldest.SentAt.Add(dailyCapWindow)
+}
+
+// ==================== Quiet Hours ====================
+
+// ParseClock parses "HH:MM" into minutes after midnight
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ValidateQuietHours checks a quiet hours window. Both ends must be set
+// together; the window may span midnight.
+func ValidateQuietHours(start, end string) error {
+	if start == "" && end == "" {
+		return nil
+	}
+	if start == "" || end == "" {
+		return errors.New("quiet_hours_start and quiet_hours_end must be set together")
+	}
+	startMinutes, err := ParseClock(start)
+	if err != nil {
+		return err
+	}
+	endMinutes, err := ParseClock(end)
+	if err != nil {
+		return err
+	}
+	if startMinutes == endMinutes {
+		return errors.New("quiet hours must not start and end at the same time")
+	}
+	return nil
+}
+
+// LoadTimezone returns the location for an IANA name, falling back to UTC
+func LoadTimezone(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// QuietHoursEnd reports whether now falls inside the quiet window in the
+// given timezone and, if so, when the window ends
+func QuietHoursEnd(now time.Time, timezone, start, end string) (time.Time, bool) {
+	startMinutes, err := ParseClock(start)
+	if err != nil {
+		return now, false
+	}
+	endMinutes, err := ParseClock(end)
+	if err != nil || startMinutes == endMinutes {
+		return now, false
+	}
+
+	local := now.In(LoadTimezone(timezone))
+	current := local.Hour()*60 + local.Minute()
+
+	var inside bool
+	if startMinutes < endMinutes {
+		inside = current >= startMinutes && current < endMinutes
+	} else {
+		// Window spans midnight, e.g. 21:00-08:00
+		inside = current >= startMinutes || current < endMinutes
+	}
+	if !inside {
+		return now, false
+	}
+
+	resume := time.Date(local.Year(), local.Month(), local.Day(), endMinutes/60, endMinutes%60, 0, 0, local.Location())
+	if !resume.After(local) {
+		resume = resume.AddDate(0, 0, 1)
+	}
+	return resume, true
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/logging"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/tracing"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Events that fire automated email templates (EmailTemplate.TriggerOn)
+const (
+	EmailTriggerSubmission = "submission"
+	EmailTriggerApproval   = "approval"
+	EmailTriggerRejection  = "rejection"
+	EmailTriggerWaitlist   = "waitlist"
+)
+
+// automatedEmailType is the routing rule automated template emails use
+const automatedEmailType = "applicant"
+
+// EmailTriggerEvent is something that happened to a submission which may
+// fire automated templates
+type EmailTriggerEvent struct {
+	Trigger      string
+	FormID       uuid.UUID // The form's table (or v2 form); templates for this form and workspace-wide ones fire
+	SubmissionID uuid.UUID
+	Data         map[string]interface{} // Submission data used for merge tags
+	Recipient    string                 // Found in Data when empty
+	// EventID separates occurrences that may each email once, e.g. two
+	// reviewed change requests. Empty means once per submission.
+	EventID string
+}
+
+// IsValidEmailTrigger reports whether trigger is a known TriggerOn value
+func IsValidEmailTrigger(trigger string) bool {
+	switch trigger {
+	case EmailTriggerSubmission, EmailTriggerApproval, EmailTriggerRejection, EmailTriggerWaitlist:
+		return true
+	}
+	return false
+}
+
+// EmailTriggerForStatus returns the trigger fired when a submission moves to
+// status, or "" when the status doesn't fire one
+func EmailTriggerForStatus(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "submitted":
+		return EmailTriggerSubmission
+	case "approved", "accepted":
+		return EmailTriggerApproval
+	case "rejected", "declined", "denied":
+		return EmailTriggerRejection
+	case "waitlisted":
+		return EmailTriggerWaitlist
+	}
+	return ""
+}
+
+// FireEmailTrigger queues the form's active automated templates for the
+// event. Each template is queued at most once per submission and event, so
+// repeated saves or retried requests never email twice. Returns the number
+// of emails queued.
+func FireEmailTrigger(ctx context.Context, event EmailTriggerEvent) (int, error) {
+	var form models.Table
+	if err := database.DB.Select("id", "workspace_id", "name").First(&form, "id = ?", event.FormID).Error; err != nil {
+		// Forms created on the v2 schema have no legacy table
+		var formV2 models.Form
+		if errV2 := database.DB.Select("id", "workspace_id", "name").First(&formV2, "id = ?", event.FormID).Error; errV2 != nil {
+			return 0, fmt.Errorf("load form: %w", err)
+		}
+		form.ID, form.WorkspaceID, form.Name = formV2.ID, formV2.WorkspaceID, formV2.Name
+	}
+
+	var templates []models.EmailTemplate
+	if err := database.DB.
+		Where("workspace_id = ? AND type = ? AND trigger_on = ? AND is_active = ?", form.WorkspaceID, "automated", event.Trigger, true).
+		Where("form_id = ? OR form_id IS NULL", form.ID).
+		Find(&templates).Error; err != nil {
+		return 0, fmt.Errorf("load templates: %w", err)
+	}
+	if len(templates) == 0 {
+		return 0, nil
+	}
+
+	recipient := event.Recipient
+	if recipient == "" {
+		recipient = triggerRecipient(event.Data)
+	}
+	if recipient == "" {
+		recipient = submissionUserEmail(event.SubmissionID)
+	}
+	if recipient == "" {
+		slog.WarnContext(ctx, "automated email has no recipient", "component", "email_triggers",
+			"trigger", event.Trigger, "submission_id", event.SubmissionID)
+		return 0, nil
+	}
+
+	sender := automatedSenderEmail(form.WorkspaceID)
+	resolve := ChainMergeResolvers(MapMergeResolver(event.Data), MapMergeResolver(map[string]interface{}{
+		"form_name":     form.Name,
+		"submission_id": event.SubmissionID.String(),
+		"email":         recipient,
+	}))
+
+	queued := 0
+	for _, template := range templates {
+		dedupeKey := fmt.Sprintf("trigger:%s:%s:%s", template.ID, event.SubmissionID, event.Trigger)
+		if event.EventID != "" {
+			dedupeKey += ":" + event.EventID
+		}
+
+		subject, body, bodyHTML, err := renderAutomatedTemplate(template, resolve)
+		if err != nil {
+			slog.WarnContext(ctx, "skipping automated template with invalid merge tags", "component", "email_triggers",
+				"template_id", template.ID, "error", err)
+			continue
+		}
+		if subject == "" {
+			subject = form.Name
+		}
+
+		submissionID := event.SubmissionID
+		formID := form.ID
+		item := models.EmailQueueItem{
+			WorkspaceID:    form.WorkspaceID,
+			RecipientEmail: recipient,
+			Subject:        subject,
+			Body:           body,
+			BodyHTML:       bodyHTML,
+			SenderEmail:    sender,
+			SubmissionID:   &submissionID,
+			FormID:         &formID,
+			Category:       template.Category,
+			EmailType:      automatedEmailType,
+			Status:         "pending",
+			ScheduledFor:   time.Now(),
+			TrackingID:     uuid.New().String(),
+			DedupeKey:      &dedupeKey,
+			TraceParent:    tracing.TraceParent(ctx),
+			RequestID:      logging.RequestID(ctx),
+		}
+
+		err = database.DB.Transaction(func(tx *gorm.DB) error {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&item)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			queued++
+			return tx.Model(&models.EmailTemplate{}).Where("id = ?", template.ID).Updates(map[string]interface{}{
+				"usage_count":  gorm.Expr("usage_count + 1"),
+				"last_used_at": time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return queued, fmt.Errorf("queue template %s: %w", template.ID, err)
+		}
+	}
+
+	if queued > 0 {
+		slog.InfoContext(ctx, "queued automated emails", "component", "email_triggers",
+			"trigger", event.Trigger, "submission_id", event.SubmissionID, "count", queued)
+	}
+	return queued, nil
+}
+
+// renderAutomatedTemplate applies merge tags to a template's subject and bodies
+func renderAutomatedTemplate(template models.EmailTemplate, resolve MergeResolver) (string, string, string, error) {
+	subject, err := RenderMergeTags(template.Subject, resolve, MergeRenderOptions{})
+	if err != nil {
+		return "", "", "", err
+	}
+	body, err := RenderMergeTags(template.Body, resolve, MergeRenderOptions{})
+	if err != nil {
+		return "", "", "", err
+	}
+	bodyHTML, err := RenderMergeTags(template.BodyHTML, resolve, MergeRenderOptions{HTML: true})
+	if err != nil {
+		return "", "", "", err
+	}
+	return subject, body, bodyHTML, nil
+}
+
+// triggerRecipient finds the applicant's email in submission data
+func triggerRecipient(data map[string]interface{}) string {
+	for _, key := range []string{"_applicant_email", "email", "applicant_email", "email_address"} {
+		if email, ok := data[key].(string); ok && strings.Contains(email, "@") {
+			return strings.TrimSpace(email)
+		}
+	}
+	if personal, ok := data["personal"].(map[string]interface{}); ok {
+		if email, ok := personal["personalEmail"].(string); ok && strings.Contains(email, "@") {
+			return strings.TrimSpace(email)
+		}
+	}
+	return ""
+}
+
+// submissionUserEmail returns the email of the portal account that owns a
+// form submission, or "" for legacy rows and anonymous submissions
+func submissionUserEmail(submissionID uuid.UUID) string {
+	var submission models.FormSubmission
+	if err := database.DB.Select("user_id").First(&submission, "id = ?", submissionID).Error; err != nil || submission.UserID == "" {
+		return ""
+	}
+	var user models.BetterAuthUser
+	if err := database.DB.Select("email").First(&user, "id = ?", submission.UserID).Error; err != nil {
+		return ""
+	}
+	return strings.TrimSpace(user.Email)
+}
+
+// automatedSenderEmail picks the from address for emails nobody sent by
+// hand: the connected Gmail account, else the Resend or SMTP sender
+func automatedSenderEmail(workspaceID uuid.UUID) string {
+	var gmail models.GmailConnection
+	if err := database.DB.Select("email").Where("workspace_id = ?", workspaceID).First(&gmail).Error; err == nil {
+		return gmail.Email
+	}
+	var resend models.ResendIntegration
+	if err := database.DB.Select("from_email").Where("workspace_id = ? AND is_active = ?", workspaceID, true).First(&resend).Error; err == nil {
+		return resend.FromEmail
+	}
+	var smtp models.SMTPIntegration
+	if err := database.DB.Select("from_email").Where("workspace_id = ? AND is_active = ?", workspaceID, true).First(&smtp).Error; err == nil {
+		return smtp.FromEmail
+	}
+	return ""
+}
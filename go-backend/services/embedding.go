@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -33,6 +34,8 @@ func NewEmbeddingService(cohereAPIKey string) *EmbeddingService {
 // ProcessPendingEmbeddings processes items in the embedding queue
 // Enhanced to populate field_embeddings and indexed_fields
 func (s *EmbeddingService) ProcessPendingEmbeddings(batchSize int) (int, error) {
+	defer RecordHeartbeat(HeartbeatEmbeddingService)
+
 	// Get pending items from queue
 	var queue []models.EmbeddingQueue
 	result := database.DB.
@@ -194,6 +197,11 @@ func (s *EmbeddingService) GenerateQueryEmbedding(query string) ([]float32, erro
 	return s.Cohere.EmbedQuery(query)
 }
 
+// GenerateQueryEmbeddingContext is GenerateQueryEmbedding with a caller context
+func (s *EmbeddingService) GenerateQueryEmbeddingContext(ctx context.Context, query string) ([]float32, error) {
+	return s.Cohere.EmbedQueryContext(ctx, query)
+}
+
 // IndexNewItem adds a new item to the embedding queue
 func (s *EmbeddingService) IndexNewItem(entityID uuid.UUID, entityType string, priority int) error {
 	queue := models.EmbeddingQueue{
@@ -0,0 +1,826 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Calculation formulas are parsed by a small evaluator that only knows the
+// operators and functions below; it can read answers but has no access to
+// anything else, and runs in time bounded by the formula's length.
+//
+//	{total_cost} * 0.1
+//	round(sum(tuition, fees) - scholarship, 2)
+//	if(household_size > 4 && income < 50000, 'eligible', 'review')
+//
+// Answers are referenced by field key, bare or in braces ({field key} allows
+// spaces). Supported: numbers, 'strings', true/false/null, + - * / %,
+// == != < <= > >=, && || ! (or and/or/not) and the functions in exprFunctions.
+const (
+	maxExpressionLength = 2000
+	maxExpressionDepth  = 64
+)
+
+// ExpressionError is a formula that can't be parsed or evaluated
+type ExpressionError struct {
+	Expression string
+	Message    string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("formula %q: %s", e.Expression, e.Message)
+}
+
+// Expression is a parsed calculation formula
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// ParseExpression parses a calculation formula
+func ParseExpression(source string) (*Expression, error) {
+	if len(source) > maxExpressionLength {
+		return nil, &ExpressionError{Expression: source[:40] + "…", Message: "formula is too long"}
+	}
+	tokens, err := tokenizeExpression(source)
+	if err != nil {
+		return nil, &ExpressionError{Expression: source, Message: err.Error()}
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseBinary(0)
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, &ExpressionError{Expression: source, Message: err.Error()}
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// Fields returns the field keys the formula reads
+func (e *Expression) Fields() []string {
+	seen := map[string]bool{}
+	var keys []string
+	var walk func(node exprNode)
+	walk = func(node exprNode) {
+		switch n := node.(type) {
+		case fieldNode:
+			if !seen[n.key] {
+				seen[n.key] = true
+				keys = append(keys, n.key)
+			}
+		case unaryNode:
+			walk(n.operand)
+		case binaryNode:
+			walk(n.left)
+			walk(n.right)
+		case callNode:
+			for _, arg := range n.args {
+				walk(arg)
+			}
+		}
+	}
+	walk(e.root)
+	return keys
+}
+
+// Evaluate runs the formula against form answers keyed by field key
+func (e *Expression) Evaluate(data map[string]interface{}) (interface{}, error) {
+	value, err := e.root.eval(data)
+	if err != nil {
+		return nil, &ExpressionError{Expression: e.source, Message: err.Error()}
+	}
+	if f, ok := value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return nil, &ExpressionError{Expression: e.source, Message: "result is not a finite number"}
+	}
+	return value, nil
+}
+
+// EvaluateExpression parses and evaluates a formula in one step
+func EvaluateExpression(source string, data map[string]interface{}) (interface{}, error) {
+	expression, err := ParseExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	return expression.Evaluate(data)
+}
+
+// ==================== TOKENIZER ====================
+
+type exprTokenKind int
+
+const (
+	tokEOF exprTokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokField
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+}
+
+// exprOperators lists multi-character operators before their prefixes
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "+", "-", "*", "/", "%", "<", ">", "!", "="}
+
+func tokenizeExpression(source string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokNumber, text: string(runes[start:i])})
+		case r == '\'' || r == '"':
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			tokens = append(tokens, exprToken{kind: tokString, text: sb.String()})
+		case r == '{':
+			end := i + 1
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated field reference")
+			}
+			key := strings.TrimSpace(string(runes[i+1 : end]))
+			if key == "" {
+				return nil, fmt.Errorf("empty field reference")
+			}
+			tokens = append(tokens, exprToken{kind: tokField, text: key})
+			i = end + 1
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokIdent, text: string(runes[start:i])})
+		case r == '(':
+			tokens = append(tokens, exprToken{kind: tokLParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, exprToken{kind: tokRParen, text: ")"})
+			i++
+		case r == ',':
+			tokens = append(tokens, exprToken{kind: tokComma, text: ","})
+			i++
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					i += len(op)
+					if op == "=" {
+						op = "==" // Spreadsheet-style equality
+					}
+					tokens = append(tokens, exprToken{kind: tokOperator, text: op})
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+		}
+	}
+	return append(tokens, exprToken{kind: tokEOF}), nil
+}
+
+// ==================== PARSER ====================
+
+// exprPrecedence ranks binary operators; higher binds tighter
+var exprPrecedence = map[string]int{
+	"||": 1, "&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	depth  int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokEOF {
+		p.pos++
+	}
+	return token
+}
+
+// binaryOperator returns the operator at the cursor, mapping the and/or
+// keywords to && and ||
+func (p *exprParser) binaryOperator() (string, bool) {
+	token := p.peek()
+	switch {
+	case token.kind == tokOperator:
+		_, ok := exprPrecedence[token.text]
+		return token.text, ok
+	case token.kind == tokIdent && strings.EqualFold(token.text, "and"):
+		return "&&", true
+	case token.kind == tokIdent && strings.EqualFold(token.text, "or"):
+		return "||", true
+	}
+	return "", false
+}
+
+func (p *exprParser) parseBinary(minPrecedence int) (exprNode, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExpressionDepth {
+		return nil, fmt.Errorf("formula is nested too deeply")
+	}
+
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.binaryOperator()
+		if !ok || exprPrecedence[op] <= minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(exprPrecedence[op])
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	token := p.peek()
+	if (token.kind == tokOperator && (token.text == "-" || token.text == "!" || token.text == "+")) ||
+		(token.kind == tokIdent && strings.EqualFold(token.text, "not")) {
+		p.next()
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > maxExpressionDepth {
+			return nil, fmt.Errorf("formula is nested too deeply")
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		op := token.text
+		if op != "-" && op != "+" {
+			op = "!"
+		}
+		return unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	token := p.next()
+	switch token.kind {
+	case tokNumber:
+		value, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token.text)
+		}
+		return literalNode{value: value}, nil
+	case tokString:
+		return literalNode{value: token.text}, nil
+	case tokField:
+		return fieldNode{key: token.text}, nil
+	case tokLParen:
+		inner, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	case tokIdent:
+		switch strings.ToLower(token.text) {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		if p.peek().kind != tokLParen {
+			return fieldNode{key: token.text}, nil
+		}
+		return p.parseCall(token.text)
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of formula")
+	}
+	return nil, fmt.Errorf("unexpected %q", token.text)
+}
+
+func (p *exprParser) parseCall(name string) (exprNode, error) {
+	name = strings.ToLower(name)
+	if _, ok := exprFunctions[name]; !ok && name != "if" {
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+	p.next() // (
+	call := callNode{name: name}
+	if p.peek().kind == tokRParen {
+		p.next()
+		return call, checkArity(call)
+	}
+	for {
+		arg, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		switch p.next().kind {
+		case tokComma:
+			continue
+		case tokRParen:
+			return call, checkArity(call)
+		default:
+			return nil, fmt.Errorf("missing ) after %s() arguments", name)
+		}
+	}
+}
+
+// checkArity rejects calls evaluation can't handle, such as if() without
+// its three arguments
+func checkArity(call callNode) error {
+	if call.name == "if" && len(call.args) != 3 {
+		return fmt.Errorf("if() takes a condition and two values")
+	}
+	return nil
+}
+
+// ==================== EVALUATION ====================
+
+type exprNode interface {
+	eval(data map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+type fieldNode struct{ key string }
+
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+type callNode struct {
+	name string
+	args []exprNode
+}
+
+func (n literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n fieldNode) eval(data map[string]interface{}) (interface{}, error) {
+	if value, ok := data[n.key]; ok {
+		return value, nil
+	}
+	// Dotted keys read into object answers, e.g. address.city
+	value, ok := lookupMergePath(data, n.key)
+	if !ok {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (n unaryNode) eval(data map[string]interface{}) (interface{}, error) {
+	value, err := n.operand.eval(data)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		return !exprTruthy(value), nil
+	case "-":
+		number, err := exprNumber(value)
+		return -number, err
+	}
+	return exprNumber(value)
+}
+
+func (n binaryNode) eval(data map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(data)
+	if err != nil {
+		return nil, err
+	}
+	// Short-circuit so if-style guards like `count > 0 && total / count > 5` work
+	switch n.op {
+	case "&&":
+		if !exprTruthy(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(data)
+		return exprTruthy(right), err
+	case "||":
+		if exprTruthy(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(data)
+		return exprTruthy(right), err
+	}
+
+	right, err := n.right.eval(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==", "!=":
+		equal := exprEqual(left, right)
+		return equal == (n.op == "=="), nil
+	case "<", "<=", ">", ">=":
+		cmp, err := exprCompare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		}
+		return cmp >= 0, nil
+	case "+":
+		// Joins text unless both sides are numbers (or empty answers)
+		a, aErr := exprNumber(left)
+		b, bErr := exprNumber(right)
+		if aErr != nil || bErr != nil {
+			return exprString(left) + exprString(right), nil
+		}
+		return a + b, nil
+	}
+
+	a, err := exprNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	b, err := exprNumber(right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(a, b), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+func (n callNode) eval(data map[string]interface{}) (interface{}, error) {
+	// if() only evaluates the branch it returns
+	if n.name == "if" {
+		condition, err := n.args[0].eval(data)
+		if err != nil {
+			return nil, err
+		}
+		if exprTruthy(condition) {
+			return n.args[1].eval(data)
+		}
+		return n.args[2].eval(data)
+	}
+
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(data)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	return exprFunctions[n.name](args)
+}
+
+// exprFunctions are the functions formulas may call. List answers (e.g. a
+// repeater's amounts) are flattened by the aggregate functions.
+var exprFunctions = map[string]func(args []interface{}) (interface{}, error){
+	"sum": func(args []interface{}) (interface{}, error) {
+		total := 0.0
+		for _, value := range exprFlatten(args) {
+			number, err := exprNumber(value)
+			if err != nil {
+				return nil, err
+			}
+			total += number
+		}
+		return total, nil
+	},
+	"avg": func(args []interface{}) (interface{}, error) {
+		values := exprNonEmpty(exprFlatten(args))
+		if len(values) == 0 {
+			return nil, nil
+		}
+		total := 0.0
+		for _, value := range values {
+			number, err := exprNumber(value)
+			if err != nil {
+				return nil, err
+			}
+			total += number
+		}
+		return total / float64(len(values)), nil
+	},
+	"min": func(args []interface{}) (interface{}, error) {
+		return exprExtreme(args, -1)
+	},
+	"max": func(args []interface{}) (interface{}, error) {
+		return exprExtreme(args, 1)
+	},
+	"count": func(args []interface{}) (interface{}, error) {
+		return float64(len(exprNonEmpty(exprFlatten(args)))), nil
+	},
+	"round": func(args []interface{}) (interface{}, error) {
+		if len(args) == 0 || len(args) > 2 {
+			return nil, fmt.Errorf("round() takes a number and optional decimal places")
+		}
+		number, err := exprNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+		places := 0.0
+		if len(args) == 2 {
+			if places, err = exprNumber(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		scale := math.Pow(10, math.Max(0, math.Min(places, 10)))
+		return math.Round(number*scale) / scale, nil
+	},
+	"floor":  exprMath(math.Floor),
+	"ceil":   exprMath(math.Ceil),
+	"abs":    exprMath(math.Abs),
+	"number": exprMath(func(x float64) float64 { return x }),
+	"coalesce": func(args []interface{}) (interface{}, error) {
+		for _, value := range args {
+			if !isEmptyFieldValue(value) {
+				return value, nil
+			}
+		}
+		return nil, nil
+	},
+	"concat": func(args []interface{}) (interface{}, error) {
+		var sb strings.Builder
+		for _, value := range args {
+			sb.WriteString(exprString(value))
+		}
+		return sb.String(), nil
+	},
+	"upper": exprText(strings.ToUpper),
+	"lower": exprText(strings.ToLower),
+	"trim":  exprText(strings.TrimSpace),
+	"len": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("len() takes one value")
+		}
+		if items, ok := args[0].([]interface{}); ok {
+			return float64(len(items)), nil
+		}
+		return float64(len([]rune(exprString(args[0])))), nil
+	},
+	"days_between": func(args []interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("days_between() takes two dates")
+		}
+		from, fromOk := exprDate(args[0])
+		to, toOk := exprDate(args[1])
+		if !fromOk || !toOk {
+			return nil, nil
+		}
+		return math.Round(to.Sub(from).Hours() / 24), nil
+	},
+	"years_since": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("years_since() takes one date")
+		}
+		date, ok := exprDate(args[0])
+		if !ok {
+			return nil, nil
+		}
+		now := time.Now()
+		years := now.Year() - date.Year()
+		if now.YearDay() < date.YearDay() {
+			years--
+		}
+		return float64(years), nil
+	},
+	"today": func(args []interface{}) (interface{}, error) {
+		return time.Now().Format("2006-01-02"), nil
+	},
+}
+
+func exprMath(fn func(float64) float64) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("function takes one number")
+		}
+		number, err := exprNumber(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(number), nil
+	}
+}
+
+func exprText(fn func(string) string) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("function takes one value")
+		}
+		return fn(exprString(args[0])), nil
+	}
+}
+
+func exprExtreme(args []interface{}, sign float64) (interface{}, error) {
+	var best *float64
+	for _, value := range exprNonEmpty(exprFlatten(args)) {
+		number, err := exprNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		if best == nil || (number-*best)*sign > 0 {
+			best = &number
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return *best, nil
+}
+
+// exprFlatten expands list arguments one level
+func exprFlatten(args []interface{}) []interface{} {
+	var values []interface{}
+	for _, arg := range args {
+		if items, ok := arg.([]interface{}); ok {
+			values = append(values, items...)
+			continue
+		}
+		values = append(values, arg)
+	}
+	return values
+}
+
+func exprNonEmpty(values []interface{}) []interface{} {
+	var kept []interface{}
+	for _, value := range values {
+		if !isEmptyFieldValue(value) {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+// exprNumber reads a value as a number. Empty answers count as 0 so a
+// formula over an unanswered field still has a result.
+func exprNumber(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		trimmed := strings.TrimSpace(strings.NewReplacer(",", "", "$", "").Replace(v))
+		if trimmed == "" {
+			return 0, nil
+		}
+		if number, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return number, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a number", exprString(value))
+}
+
+func exprString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return MergeValueString(value)
+}
+
+func exprTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != "" && !strings.EqualFold(v, "false")
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+func exprEqual(a, b interface{}) bool {
+	if isEmptyFieldValue(a) || isEmptyFieldValue(b) {
+		return isEmptyFieldValue(a) == isEmptyFieldValue(b)
+	}
+	if an, err := exprNumber(a); err == nil {
+		if bn, err := exprNumber(b); err == nil {
+			return an == bn
+		}
+	}
+	return strings.EqualFold(exprString(a), exprString(b))
+}
+
+// exprCompare orders two values as numbers, then dates, then text
+func exprCompare(a, b interface{}) (int, error) {
+	if an, err := exprNumber(a); err == nil {
+		if bn, err := exprNumber(b); err == nil {
+			switch {
+			case an < bn:
+				return -1, nil
+			case an > bn:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if ad, ok := exprDate(a); ok {
+		if bd, ok := exprDate(b); ok {
+			return ad.Compare(bd), nil
+		}
+	}
+	return strings.Compare(exprString(a), exprString(b)), nil
+}
+
+func exprDate(value interface{}) (time.Time, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	return (&FormLogicService{}).toTime(text)
+}
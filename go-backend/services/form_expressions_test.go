@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	data := map[string]interface{}{
+		"tuition":        1200.0,
+		"fees":           "300",
+		"scholarship":    250.5,
+		"household_size": 5.0,
+		"income":         42000.0,
+		"first name":     "Ada",
+		"amounts":        []interface{}{10.0, 20.0, "", 30.0},
+		"address":        map[string]interface{}{"city": "Chicago"},
+		"empty":          "",
+	}
+
+	tests := []struct {
+		name    string
+		formula string
+		want    interface{}
+	}{
+		{"arithmetic precedence", "1 + 2 * 3", 7.0},
+		{"parentheses", "(1 + 2) * 3", 9.0},
+		{"modulo", "10 % 4", 2.0},
+		{"unary minus", "-tuition", -1200.0},
+		{"numeric text answer", "tuition + fees", 1500.0},
+		{"round with places", "round(sum(tuition, fees) - scholarship, 2)", 1249.5},
+		{"braced key with spaces", "upper({first name})", "ADA"},
+		{"text join", "'Hi ' + {first name}", "Hi Ada"},
+		{"missing answer is empty", "coalesce(missing, 'none')", "none"},
+		{"dotted key", "address.city", "Chicago"},
+		{"aggregates flatten lists", "sum(amounts)", 60.0},
+		{"avg skips empty", "avg(amounts)", 20.0},
+		{"count skips empty", "count(amounts)", 3.0},
+		{"min", "min(amounts)", 10.0},
+		{"max", "max(3, tuition, 7)", 1200.0},
+		{"len of text", "len({first name})", 3.0},
+		{"comparison", "income < 50000", true},
+		{"and/or keywords", "household_size > 4 and not (income > 50000)", true},
+		{"if picks then branch", "if(household_size > 4 && income < 50000, 'eligible', 'review')", "eligible"},
+		{"if picks else branch", "if(income > 50000, 'eligible', 'review')", "review"},
+		{"if skips the other branch", "if(true, 1, 1 / 0)", 1.0},
+		{"short circuit guards division", "empty != '' && 10 / 0 > 1", false},
+		{"equality of number and text", "fees == 300", true},
+		{"days between dates", "days_between('2026-01-01', '2026-01-31')", 30.0},
+		{"null literal", "null", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvaluateExpression(tt.formula, data)
+			if err != nil {
+				t.Fatalf("EvaluateExpression(%q) error: %v", tt.formula, err)
+			}
+			if got != tt.want {
+				t.Errorf("EvaluateExpression(%q) = %#v, want %#v", tt.formula, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+	}{
+		{"if without arguments", "if()"},
+		{"if with two arguments", "if(true, 1)"},
+		{"if with four arguments", "if(true, 1, 2, 3)"},
+		{"unknown function", "eval('x')"},
+		{"unclosed call", "sum(1, 2"},
+		{"trailing operator", "1 +"},
+		{"unbalanced parenthesis", "(1 + 2"},
+		{"empty formula", ""},
+		{"unterminated string", "'abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseExpression(tt.formula)
+			var exprErr *ExpressionError
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("ParseExpression(%q) error = %v, want *ExpressionError", tt.formula, err)
+			}
+		})
+	}
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+	}{
+		{"division by zero", "tuition / 0"},
+		{"modulo by zero", "tuition % 0"},
+		{"arithmetic on text", "'abc' * 2"},
+		{"round without arguments", "round()"},
+		{"math function arity", "abs(1, 2)"},
+	}
+
+	data := map[string]interface{}{"tuition": 1200.0}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EvaluateExpression(tt.formula, data); err == nil {
+				t.Fatalf("EvaluateExpression(%q) succeeded, want an error", tt.formula)
+			}
+		})
+	}
+}
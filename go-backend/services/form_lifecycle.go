@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reasons a form refuses to start, save or submit a submission
+var (
+	ErrFormNotPublished = errors.New("form is not accepting submissions")
+	ErrFormNotYetOpen   = errors.New("form is not open yet")
+	ErrFormClosed       = errors.New("form is closed")
+	ErrFormFull         = errors.New("form has reached its submission limit")
+	ErrAlreadySubmitted = errors.New("you have already submitted this form")
+)
+
+// scheduleLayouts are the wall-clock formats accepted for form schedule
+// times entered without an offset
+var scheduleLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// FormLocation returns a form's timezone, falling back to UTC
+func FormLocation(timezone string) *time.Location {
+	if timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// ParseFormTime reads a schedule time for a form. RFC 3339 values are used
+// as is; values without an offset ("2026-03-01T17:00") are wall-clock times
+// in the form's timezone. An empty value clears the time.
+func ParseFormTime(value, timezone string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	location := FormLocation(timezone)
+	for _, layout := range scheduleLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time %q", value)
+}
+
+// FormDeadline returns when a user's work on a form must stop: the form's
+// ClosesAt, or a later extension granted to the user. nil means no deadline.
+func FormDeadline(db *gorm.DB, form models.Form, userID string) *time.Time {
+	if form.ClosesAt == nil {
+		return nil
+	}
+	deadline := *form.ClosesAt
+	if userID != "" {
+		var extension models.FormDeadlineExtension
+		if err := db.Where("form_id = ? AND user_id = ?", form.ID, userID).First(&extension).Error; err == nil &&
+			extension.ExtendedUntil.After(deadline) {
+			deadline = extension.ExtendedUntil
+		}
+	}
+	return &deadline
+}
+
+// CheckFormOpen reports why a user can't work on a form right now: it isn't
+// published, its window hasn't opened, or it has closed without an extension
+// for them. Returns nil when the form is open.
+func CheckFormOpen(db *gorm.DB, form models.Form, userID string) error {
+	switch form.Status {
+	case "published":
+	case "closed", "archived":
+		return ErrFormClosed
+	default:
+		return ErrFormNotPublished
+	}
+
+	now := time.Now()
+	if form.OpensAt != nil && now.Before(*form.OpensAt) {
+		return ErrFormNotYetOpen
+	}
+	if deadline := FormDeadline(db, form, userID); deadline != nil && now.After(*deadline) {
+		return ErrFormClosed
+	}
+	return nil
+}
+
+// CheckFormCapacity returns ErrFormFull when every submission slot is taken
+func CheckFormCapacity(form models.Form) error {
+	if form.MaxSubmissions != nil && form.SubmissionCount >= *form.MaxSubmissions {
+		return ErrFormFull
+	}
+	return nil
+}
+
+// LockFormForSubmission locks a form's row for the rest of tx so that
+// concurrent starts and submits for it run one at a time
+func LockFormForSubmission(tx *gorm.DB, formID uuid.UUID) (models.Form, error) {
+	var form models.Form
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&form, "id = ?", formID).Error
+	return form, err
+}
+
+// ClaimSubmissionSlot counts a submit against the form's MaxSubmissions,
+// returning ErrFormFull when none are left. The check and increment are a
+// single statement, so two submits can never take the last slot.
+func ClaimSubmissionSlot(tx *gorm.DB, formID uuid.UUID) error {
+	result := tx.Model(&models.Form{}).
+		Where("id = ? AND (max_submissions IS NULL OR submission_count < max_submissions)", formID).
+		UpdateColumn("submission_count", gorm.Expr("submission_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFormFull
+	}
+	return nil
+}
+
+// CheckSingleSubmission returns ErrAlreadySubmitted when the form takes one
+// submission per user and the user already submitted one other than
+// submissionID
+func CheckSingleSubmission(tx *gorm.DB, form models.Form, userID string, submissionID uuid.UUID) error {
+	if form.AllowMultipleSubmissions {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&models.FormSubmission{}).
+		Where("form_id = ? AND user_id = ? AND id <> ?", form.ID, userID, submissionID).
+		Where("submitted_at IS NOT NULL AND status <> ?", "withdrawn").
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAlreadySubmitted
+	}
+	return nil
+}
+
+// EnforceFormLifecycle is the check every path that starts, saves or submits
+// a submission goes through. submission is nil when starting a new one;
+// submit is true when the write submits it. Run it in the transaction that
+// writes: starts and submits lock the form row first, so concurrent ones
+// for a form run one at a time and see each other's submissions.
+//
+// The form must be open for the user (schedule and extensions). A start
+// also needs a free slot; a first submit enforces one submission per user
+// and claims a slot. Re-submitting an edited submission takes no new slot.
+func EnforceFormLifecycle(tx *gorm.DB, formID uuid.UUID, userID string, submission *models.FormSubmission, submit bool) (models.Form, error) {
+	var form models.Form
+	var err error
+	if submission == nil || submit {
+		form, err = LockFormForSubmission(tx, formID)
+	} else {
+		err = tx.First(&form, "id = ?", formID).Error
+	}
+	if err != nil {
+		return form, err
+	}
+
+	if err := CheckFormOpen(tx, form, userID); err != nil {
+		return form, err
+	}
+	if submission == nil {
+		if err := CheckFormCapacity(form); err != nil {
+			return form, err
+		}
+	}
+	if !submit || (submission != nil && submission.SubmittedAt != nil) {
+		return form, nil
+	}
+
+	var submissionID uuid.UUID
+	if submission != nil {
+		submissionID = submission.ID
+	}
+	if err := CheckSingleSubmission(tx, form, userID, submissionID); err != nil {
+		return form, err
+	}
+	return form, ClaimSubmissionSlot(tx, form.ID)
+}
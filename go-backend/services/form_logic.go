@@ -1,8 +1,10 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -320,15 +322,8 @@ func (s *FormLogicService) GetVisibleFields(fields []models.FormField, data map[
 	visibleKeys := []string{}
 
 	for _, field := range fields {
-		// Parse conditions from JSONB
-		var actions []models.ConditionalAction
-		if field.Conditions != nil {
-			// Unmarshal JSON to actions
-			// In practice, you'd use json.Unmarshal here
-		}
-
 		visible := true
-		for _, action := range actions {
+		for _, action := range fieldActions(field) {
 			if action.Type == "show" || action.Type == "hide" {
 				conditionMet := s.EvaluateConditions(action.Conditions, action.Logic, data)
 				if action.Type == "hide" && conditionMet {
@@ -350,12 +345,249 @@ func (s *FormLogicService) GetVisibleFields(fields []models.FormField, data map[
 	return visibleKeys
 }
 
+// IsFieldRequired reports whether a field must be answered: it's required
+// itself or a "require" rule's conditions are met
+func (s *FormLogicService) IsFieldRequired(field models.FormField, data map[string]interface{}) bool {
+	if field.Required {
+		return true
+	}
+	for _, action := range fieldActions(field) {
+		if action.Type == "require" && s.EvaluateConditions(action.Conditions, action.Logic, data) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldActions parses a field's conditional actions, ignoring malformed JSON
+func fieldActions(field models.FormField) []models.ConditionalAction {
+	var actions []models.ConditionalAction
+	if len(field.Conditions) > 0 {
+		json.Unmarshal(field.Conditions, &actions)
+	}
+	return actions
+}
+
+// Where a server-side answer came from (FormResponse.ValueSource)
+const (
+	ValueSourceUser       = "user"
+	ValueSourcePrefill    = "prefill"
+	ValueSourceCalculated = "calculated"
+)
+
+// ComputedValue is an answer the server worked out for a field
+type ComputedValue struct {
+	Value  interface{}
+	Source string // ValueSourcePrefill or ValueSourceCalculated
+}
+
+// computeRule is one prefill or calculate rule targeting a field
+type computeRule struct {
+	target     string
+	source     string
+	value      interface{} // Literal, or a formula when formula is set
+	formula    *Expression
+	conditions []models.FieldCondition
+	logic      string
+}
+
+// IsCalculatedField reports whether a field's answer is always computed by
+// the server (a CalculationRule or "calculate" action), so clients can't set it
+func IsCalculatedField(field models.FormField) bool {
+	if field.CalculationRule != nil && strings.TrimSpace(*field.CalculationRule) != "" {
+		return true
+	}
+	for _, action := range fieldActions(field) {
+		if action.Type == "calculate" && (action.Target == "" || action.Target == field.FieldKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFieldLogic checks that a field's calculation rule and any formula
+// prefill or calculate actions parse
+func ValidateFieldLogic(field models.FormField) error {
+	_, err := fieldComputeRules(field)
+	return err
+}
+
+// ApplyComputedValues works out the answers the server owns. Calculated
+// fields always take their formula's result (or nothing when no calculate
+// rule's conditions are met), whatever the client sent. Prefills only fill
+// fields that have never been answered (absent from data), so clearing a
+// prefilled answer sticks. data is updated in place so later formulas see
+// earlier results. Returns the computed values and, per field key, why a
+// formula failed.
+func (s *FormLogicService) ApplyComputedValues(fields []models.FormField, data map[string]interface{}) (map[string]ComputedValue, map[string]string) {
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.FieldKey] = true
+	}
+
+	var calculations, prefills []computeRule
+	calculated := map[string]bool{}
+	failures := map[string]string{}
+	for _, field := range fields {
+		rules, err := fieldComputeRules(field)
+		if err != nil {
+			failures[field.FieldKey] = err.Error()
+			continue
+		}
+		for _, rule := range rules {
+			if !known[rule.target] {
+				continue
+			}
+			if rule.source == ValueSourceCalculated {
+				calculations = append(calculations, rule)
+				calculated[rule.target] = true
+			} else {
+				prefills = append(prefills, rule)
+			}
+		}
+	}
+
+	computed := map[string]ComputedValue{}
+
+	// Prefills go first so formulas can read them
+	for _, rule := range prefills {
+		if calculated[rule.target] {
+			continue
+		}
+		if _, answered := data[rule.target]; answered {
+			continue
+		}
+		if !s.EvaluateConditions(rule.conditions, rule.logic, data) {
+			continue
+		}
+		value, err := rule.evaluate(data)
+		if err != nil {
+			failures[rule.target] = err.Error()
+			continue
+		}
+		if isEmptyFieldValue(value) {
+			continue
+		}
+		computed[rule.target] = ComputedValue{Value: value, Source: ValueSourcePrefill}
+		data[rule.target] = value
+	}
+
+	// Formulas may read other calculated fields; repeat until nothing changes,
+	// at most once per calculated field, so chains resolve in any order
+	for pass := 0; pass <= len(calculated); pass++ {
+		changed := false
+		next := map[string]interface{}{}
+		for _, rule := range calculations {
+			if _, done := next[rule.target]; done {
+				continue // First calculate rule whose conditions are met wins
+			}
+			if !s.EvaluateConditions(rule.conditions, rule.logic, data) {
+				continue
+			}
+			value, err := rule.evaluate(data)
+			if err != nil {
+				failures[rule.target] = err.Error()
+				next[rule.target] = nil
+				continue
+			}
+			delete(failures, rule.target)
+			next[rule.target] = value
+		}
+		for target := range calculated {
+			value := next[target]
+			if current, ok := computed[target]; !ok || !sameComputedValue(current.Value, value) {
+				changed = true
+			}
+			computed[target] = ComputedValue{Value: value, Source: ValueSourceCalculated}
+			data[target] = value
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return computed, failures
+}
+
+// fieldComputeRules collects a field's CalculationRule, PrefillValue and
+// prefill/calculate actions. Prefill values starting with "=" are formulas.
+func fieldComputeRules(field models.FormField) ([]computeRule, error) {
+	var rules []computeRule
+	if field.CalculationRule != nil && strings.TrimSpace(*field.CalculationRule) != "" {
+		formula, err := ParseExpression(strings.TrimPrefix(strings.TrimSpace(*field.CalculationRule), "="))
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, computeRule{target: field.FieldKey, source: ValueSourceCalculated, formula: formula})
+	}
+
+	for _, action := range fieldActions(field) {
+		if action.Type != "calculate" && action.Type != "prefill" {
+			continue
+		}
+		rule := computeRule{target: action.Target, conditions: action.Conditions, logic: action.Logic, value: action.Value}
+		if rule.target == "" {
+			rule.target = field.FieldKey
+		}
+		text, isText := action.Value.(string)
+		if action.Type == "calculate" {
+			rule.source = ValueSourceCalculated
+			if !isText {
+				return nil, fmt.Errorf("calculate action on %s needs a formula", field.FieldKey)
+			}
+			text = strings.TrimPrefix(strings.TrimSpace(text), "=")
+		} else {
+			rule.source = ValueSourcePrefill
+			if !isText || !strings.HasPrefix(strings.TrimSpace(text), "=") {
+				rules = append(rules, rule)
+				continue
+			}
+			text = strings.TrimPrefix(strings.TrimSpace(text), "=")
+		}
+		formula, err := ParseExpression(text)
+		if err != nil {
+			return nil, err
+		}
+		rule.formula = formula
+		rules = append(rules, rule)
+	}
+
+	if field.PrefillValue != nil && *field.PrefillValue != "" {
+		rule := computeRule{target: field.FieldKey, source: ValueSourcePrefill, value: *field.PrefillValue}
+		if text := strings.TrimSpace(*field.PrefillValue); strings.HasPrefix(text, "=") {
+			formula, err := ParseExpression(strings.TrimPrefix(text, "="))
+			if err != nil {
+				return nil, err
+			}
+			rule.formula = formula
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r computeRule) evaluate(data map[string]interface{}) (interface{}, error) {
+	if r.formula == nil {
+		return r.value, nil
+	}
+	return r.formula.Evaluate(data)
+}
+
+// sameComputedValue compares two answers by their JSON form
+func sameComputedValue(a, b interface{}) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aJSON) == string(bJSON)
+}
+
 // GetNextSection returns the next section ID based on branching rules
 func (s *FormLogicService) GetNextSection(currentSectionID string, branchingRules []models.BranchingRule, data map[string]interface{}) *string {
 	// Sort rules by priority (lower number = higher priority)
 	// Evaluate in order until a condition matches
+	rules := append([]models.BranchingRule{}, branchingRules...)
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority < rules[j].Priority })
 
-	for _, rule := range branchingRules {
+	for _, rule := range rules {
 		if rule.FromSectionID == currentSectionID {
 			if s.EvaluateConditions(rule.Conditions, "and", data) {
 				return &rule.ToSectionID
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// FormPath is the route an applicant takes through a form given their
+// answers so far: the sections branching sends them through, and the
+// visible required fields inside them
+type FormPath struct {
+	SectionIDs           []uuid.UUID `json:"section_ids"`               // In the order the applicant visits them
+	NextSectionID        *uuid.UUID  `json:"next_section_id,omitempty"` // First section on the path with a required field left; nil when done
+	RequiredFields       []string    `json:"required_fields"`
+	MissingFields        []string    `json:"missing_fields"`
+	CompletionPercentage int         `json:"completion_percentage"`
+}
+
+// Fields returns the fields on the path: those in one of its sections and
+// those outside any section
+func (p FormPath) Fields(fields []models.FormField) []models.FormField {
+	onPath := make(map[uuid.UUID]bool, len(p.SectionIDs))
+	for _, id := range p.SectionIDs {
+		onPath[id] = true
+	}
+	result := make([]models.FormField, 0, len(fields))
+	for _, field := range fields {
+		if field.SectionID == nil || onPath[*field.SectionID] {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// sectionRule is one entry of FormSection.Conditions, which holds either
+// show/hide actions or bare conditions that must all hold for the section
+// to show
+type sectionRule struct {
+	Type       string                  `json:"type"`
+	Conditions []models.FieldCondition `json:"conditions"`
+	Logic      string                  `json:"logic"`
+	FieldKey   string                  `json:"field_key"`
+	Operator   string                  `json:"operator"`
+	Value      any                     `json:"value"`
+}
+
+// ResolvePath walks the form from its start section, following branching
+// rules (when enabled) and otherwise section order, skipping hidden
+// sections. Fields outside any section are always on the path. Completion
+// counts the visible required fields on the path; when there are none it
+// counts every visible data field on the path.
+func (s *FormLogicService) ResolvePath(sections []models.FormSection, fields []models.FormField, settingsJSON datatypes.JSON, data map[string]interface{}) FormPath {
+	var settings models.FormSettings
+	if len(settingsJSON) > 0 {
+		json.Unmarshal(settingsJSON, &settings)
+	}
+
+	ordered := append([]models.FormSection{}, sections...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].SortOrder < ordered[j].SortOrder })
+	position := make(map[string]int, len(ordered))
+	for i, section := range ordered {
+		position[section.ID.String()] = i
+	}
+
+	path := FormPath{SectionIDs: []uuid.UUID{}, RequiredFields: []string{}, MissingFields: []string{}}
+	onPath := map[uuid.UUID]bool{}
+
+	current := 0
+	if settings.StartSectionID != nil {
+		if i, ok := position[*settings.StartSectionID]; ok {
+			current = i
+		}
+	}
+	visited := map[int]bool{}
+	for current >= 0 && current < len(ordered) && !visited[current] {
+		visited[current] = true
+		section := ordered[current]
+		if s.sectionVisible(section, data) {
+			path.SectionIDs = append(path.SectionIDs, section.ID)
+			onPath[section.ID] = true
+		}
+
+		current++
+		if settings.EnableBranching {
+			if next := s.GetNextSection(section.ID.String(), settings.BranchingRules, data); next != nil {
+				if i, ok := position[*next]; ok {
+					current = i
+				}
+			}
+		}
+	}
+
+	visible := map[string]bool{}
+	for _, key := range s.GetVisibleFields(fields, data) {
+		visible[key] = true
+	}
+
+	var candidates []models.FormField
+	for _, field := range fields {
+		if field.Category == "layout" || layoutFieldTypes[field.FieldType] || !visible[field.FieldKey] {
+			continue
+		}
+		if field.SectionID != nil && !onPath[*field.SectionID] {
+			continue
+		}
+		candidates = append(candidates, field)
+	}
+
+	counted := candidates
+	var required []models.FormField
+	for _, field := range candidates {
+		if s.IsFieldRequired(field, data) {
+			required = append(required, field)
+		}
+	}
+	if len(required) > 0 {
+		counted = required
+	}
+
+	missingSection := map[uuid.UUID]bool{}
+	answered := 0
+	for _, field := range counted {
+		if len(required) > 0 {
+			path.RequiredFields = append(path.RequiredFields, field.FieldKey)
+		}
+		if !isEmptyFieldValue(data[field.FieldKey]) {
+			answered++
+			continue
+		}
+		if len(required) > 0 {
+			path.MissingFields = append(path.MissingFields, field.FieldKey)
+			if field.SectionID != nil {
+				missingSection[*field.SectionID] = true
+			}
+		}
+	}
+
+	for _, id := range path.SectionIDs {
+		if missingSection[id] {
+			next := id
+			path.NextSectionID = &next
+			break
+		}
+	}
+
+	if len(counted) == 0 {
+		path.CompletionPercentage = 100
+	} else {
+		path.CompletionPercentage = answered * 100 / len(counted)
+	}
+	return path
+}
+
+// sectionVisible evaluates a section's show/hide rules
+func (s *FormLogicService) sectionVisible(section models.FormSection, data map[string]interface{}) bool {
+	var rules []sectionRule
+	if len(section.Conditions) == 0 || json.Unmarshal(section.Conditions, &rules) != nil {
+		return true
+	}
+
+	var bare []models.FieldCondition
+	for _, rule := range rules {
+		switch {
+		case rule.FieldKey != "":
+			bare = append(bare, models.FieldCondition{FieldKey: rule.FieldKey, Operator: rule.Operator, Value: rule.Value})
+		case rule.Type == "hide" && s.EvaluateConditions(rule.Conditions, rule.Logic, data):
+			return false
+		case rule.Type == "show" && !s.EvaluateConditions(rule.Conditions, rule.Logic, data):
+			return false
+		}
+	}
+	return s.EvaluateConditions(bare, "and", data)
+}
+
+// ResolveSubmissionPath resolves a submission's path against the form
+// version it is pinned to. data is keyed by field key.
+func ResolveSubmissionPath(db *gorm.DB, form models.Form, version int, data map[string]interface{}) FormPath {
+	sections := form.Sections
+	settings := form.Settings
+	if snapshot, schema, err := LoadFormVersion(db, form.ID, version); err == nil {
+		sections = schema.Sections
+		settings = snapshot.Settings
+	} else if sections == nil {
+		db.Where("form_id = ?", form.ID).Order("sort_order ASC").Find(&sections)
+	}
+	fields := PinnedFormFields(db, form, version)
+	return NewFormLogicService().ResolvePath(sections, fields, settings, data)
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+func TestValidateSubmissionOnPath(t *testing.T) {
+	basics := models.FormSection{ID: uuid.New(), Name: "Basics", SortOrder: 0}
+	// Only shown to applicants who say they are employed
+	employment := models.FormSection{
+		ID:         uuid.New(),
+		Name:       "Employment",
+		SortOrder:  1,
+		Conditions: datatypes.JSON(`[{"field_key": "employed", "operator": "equals", "value": "yes"}]`),
+	}
+
+	fields := []models.FormField{
+		{ID: uuid.New(), SectionID: &basics.ID, FieldKey: "employed", FieldType: "text", Label: "Employed", Required: true},
+		{ID: uuid.New(), SectionID: &employment.ID, FieldKey: "employer", FieldType: "text", Label: "Employer", Required: true},
+		{ID: uuid.New(), FieldKey: "consent", FieldType: "checkbox", Label: "Consent", Required: true},
+	}
+	sections := []models.FormSection{basics, employment}
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		missing []string
+	}{
+		{"skipped section is not validated", map[string]interface{}{"employed": "no", "consent": true}, nil},
+		{"section on path is validated", map[string]interface{}{"employed": "yes", "consent": true}, []string{"employer"}},
+		{"unsectioned fields are always validated", map[string]interface{}{"employed": "no"}, []string{"consent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := NewFormLogicService().ResolvePath(sections, fields, nil, tt.data)
+			validation := NewFormValidationService().ValidateSubmission(path.Fields(fields), tt.data)
+			if len(validation.Errors) != len(tt.missing) {
+				t.Fatalf("errors = %v, want %v", validation.Errors, tt.missing)
+			}
+			for _, key := range tt.missing {
+				if _, ok := validation.Errors[key]; !ok {
+					t.Errorf("errors = %v, want an error for %s", validation.Errors, key)
+				}
+			}
+		})
+	}
+}
@@ -1,14 +1,19 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"html"
 	"net/mail"
 	"net/url"
+	"path"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
 // FormValidationService handles field validation
@@ -355,24 +360,207 @@ func (s *FormValidationService) matchesPattern(value interface{}, pattern string
 	return err == nil && matched
 }
 
-// File validation
+// File validation: the value is a file object ({url, name, size, mime_type})
+// or a list of them
 func (s *FormValidationService) validateFile(value interface{}, validation *models.FieldValidation) []string {
 	errors := []string{}
 
-	// This would need to be adapted based on how file uploads are handled
-	// Placeholder implementation
+	var files []map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		files = append(files, v)
+	case []interface{}:
+		for _, item := range v {
+			if file, ok := item.(map[string]interface{}); ok {
+				files = append(files, file)
+			}
+		}
+	}
 
-	if validation.MaxFileSize != nil {
-		// Check file size
+	if validation.MinFiles != nil && len(files) < *validation.MinFiles {
+		errors = append(errors, fmt.Sprintf("Upload at least %d file(s)", *validation.MinFiles))
+	}
+	if validation.MaxFiles != nil && len(files) > *validation.MaxFiles {
+		errors = append(errors, fmt.Sprintf("Upload at most %d file(s)", *validation.MaxFiles))
 	}
 
-	if len(validation.AllowedFileTypes) > 0 {
-		// Check file type/extension
+	for _, file := range files {
+		name, _ := file["name"].(string)
+		if validation.MaxFileSize != nil {
+			if size, ok := file["size"].(float64); ok && int64(size) > *validation.MaxFileSize {
+				errors = append(errors, fmt.Sprintf("%s is larger than %s", name, formatFileSize(*validation.MaxFileSize)))
+			}
+		}
+		if len(validation.AllowedFileTypes) > 0 {
+			mimeType, _ := file["mime_type"].(string)
+			if !fileTypeAllowed(name, mimeType, validation.AllowedFileTypes) {
+				errors = append(errors, fmt.Sprintf("%s is not an allowed file type (%s)", name, strings.Join(validation.AllowedFileTypes, ", ")))
+			}
+		}
 	}
 
 	return errors
 }
 
+// fileTypeAllowed matches a file against extensions (".pdf", "pdf") and MIME
+// types ("application/pdf", "image/*")
+func fileTypeAllowed(name, mimeType string, allowed []string) bool {
+	extension := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	mimeType = strings.ToLower(mimeType)
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case strings.HasSuffix(entry, "/*"):
+			if strings.HasPrefix(mimeType, strings.TrimSuffix(entry, "*")) {
+				return true
+			}
+		case strings.Contains(entry, "/"):
+			if mimeType == entry {
+				return true
+			}
+		case extension != "" && strings.TrimPrefix(entry, ".") == extension:
+			return true
+		}
+	}
+	return false
+}
+
+// formatFileSize renders a byte count for error messages
+func formatFileSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(bytes)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", bytes)
+}
+
+// SubmissionValidation is the result of validating a whole submission
+type SubmissionValidation struct {
+	Errors        map[string][]string `json:"errors"`         // field_key -> messages, only for fields with problems
+	MissingFields []string            `json:"missing_fields"` // Labels of required fields without an answer
+}
+
+// Valid reports whether the submission has no validation errors
+func (v *SubmissionValidation) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// Field types that display content but never hold an answer
+var layoutFieldTypes = map[string]bool{
+	"divider": true, "heading": true, "paragraph": true, "section": true, "callout": true,
+}
+
+// Field types whose type checks apply without an explicit validation_type
+var implicitValidationTypes = map[string]string{
+	"email": "email",
+	"phone": "phone",
+	"url":   "url",
+	"date":  "date",
+}
+
+// ValidateSubmission is the validation pipeline shared by autosave and final
+// submit. Visibility and "require" rules are evaluated against the data
+// first; only visible fields are then checked against their type and
+// validation rules, so hidden fields never block a submission.
+func (s *FormValidationService) ValidateSubmission(fields []models.FormField, data map[string]interface{}) *SubmissionValidation {
+	result := &SubmissionValidation{Errors: map[string][]string{}, MissingFields: []string{}}
+
+	logic := NewFormLogicService()
+	visible := map[string]bool{}
+	for _, key := range logic.GetVisibleFields(fields, data) {
+		visible[key] = true
+	}
+
+	for _, field := range fields {
+		if field.Category == "layout" || layoutFieldTypes[field.FieldType] || !visible[field.FieldKey] {
+			continue
+		}
+
+		value := data[field.FieldKey]
+		if isEmptyFieldValue(value) {
+			if logic.IsFieldRequired(field, data) {
+				label := fieldDisplayLabel(field)
+				result.Errors[field.FieldKey] = []string{fmt.Sprintf("%s is required", label)}
+				result.MissingFields = append(result.MissingFields, label)
+			}
+			continue
+		}
+
+		if messages := s.ValidateField(field, value, fieldValidationRules(field)); len(messages) > 0 {
+			result.Errors[field.FieldKey] = messages
+		}
+	}
+	return result
+}
+
+// SubmissionValues returns a submission's answers keyed by field key, with
+// JSON answers decoded, for conditions and validation
+func SubmissionValues(fields []models.FormField, responses []models.FormResponse) map[string]interface{} {
+	keys := make(map[uuid.UUID]string, len(fields))
+	for _, field := range fields {
+		keys[field.ID] = field.FieldKey
+	}
+
+	data := make(map[string]interface{}, len(responses))
+	for i := range responses {
+		key, ok := keys[responses[i].FieldID]
+		if !ok {
+			continue
+		}
+		value := responses[i].GetValue()
+		if raw, ok := value.(datatypes.JSON); ok {
+			value = nil
+			if len(raw) > 0 {
+				json.Unmarshal(raw, &value)
+			}
+		}
+		data[key] = value
+	}
+	return data
+}
+
+// fieldValidationRules parses a field's validation rules, defaulting the
+// validation type from the field type (an email field validates as email)
+func fieldValidationRules(field models.FormField) *models.FieldValidation {
+	validation := &models.FieldValidation{}
+	if len(field.Validation) > 0 {
+		json.Unmarshal(field.Validation, validation)
+	}
+	if validation.ValidationType == "" {
+		validation.ValidationType = implicitValidationTypes[field.FieldType]
+	}
+	return validation
+}
+
+// isEmptyFieldValue reports whether an answer counts as not given
+func isEmptyFieldValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// fieldDisplayLabel is a field's label as plain text
+func fieldDisplayLabel(field models.FormField) string {
+	label := field.Label
+	if field.IsRichText {
+		label = html.UnescapeString(htmlTagPattern.ReplaceAllString(label, ""))
+	}
+	if label = strings.TrimSpace(label); label == "" {
+		return field.FieldKey
+	}
+	return label
+}
+
 // Built-in validation patterns
 var ValidationPatterns = map[string]string{
 	"email":        `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`,
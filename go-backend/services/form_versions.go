@@ -0,0 +1,305 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// FieldVersionDiff is a field that was added, removed or changed between two
+// form versions
+type FieldVersionDiff struct {
+	FieldID  uuid.UUID `json:"field_id"`
+	FieldKey string    `json:"field_key"`
+	Label    string    `json:"label"`
+	Changes  []string  `json:"changes,omitempty"` // Properties that differ, e.g. "required", "options"
+}
+
+// SectionVersionDiff is a section that was added, removed or changed
+// between two form versions
+type SectionVersionDiff struct {
+	SectionID uuid.UUID `json:"section_id"`
+	Name      string    `json:"name"`
+	Changes   []string  `json:"changes,omitempty"`
+}
+
+// FormVersionDiff lists what changed from one published version to another
+type FormVersionDiff struct {
+	From            int                  `json:"from"`
+	To              int                  `json:"to"`
+	AddedFields     []FieldVersionDiff   `json:"added_fields"`
+	RemovedFields   []FieldVersionDiff   `json:"removed_fields"`
+	ChangedFields   []FieldVersionDiff   `json:"changed_fields"`
+	AddedSections   []SectionVersionDiff `json:"added_sections"`
+	RemovedSections []SectionVersionDiff `json:"removed_sections"`
+	ChangedSections []SectionVersionDiff `json:"changed_sections"`
+	SettingsChanged bool                 `json:"settings_changed"`
+	LayoutChanged   bool                 `json:"layout_changed"`
+}
+
+// PublishFormVersion snapshots the form's current sections and fields as its
+// next version and marks it published. The first publish keeps the form's
+// current version number so submissions already pinned to it match.
+func PublishFormVersion(tx *gorm.DB, formID uuid.UUID, publishedBy *string) (models.Form, models.FormVersion, error) {
+	form, err := LockFormForSubmission(tx, formID)
+	if err != nil {
+		return form, models.FormVersion{}, err
+	}
+
+	var sections []models.FormSection
+	if err := tx.Where("form_id = ?", formID).Order("sort_order ASC").Find(&sections).Error; err != nil {
+		return form, models.FormVersion{}, fmt.Errorf("load sections: %w", err)
+	}
+	var fields []models.FormField
+	if err := tx.Where("form_id = ?", formID).Order("sort_order ASC").Find(&fields).Error; err != nil {
+		return form, models.FormVersion{}, fmt.Errorf("load fields: %w", err)
+	}
+	schema, err := json.Marshal(models.FormVersionSchema{Sections: sections, Fields: fields})
+	if err != nil {
+		return form, models.FormVersion{}, err
+	}
+
+	var latest int
+	if err := tx.Model(&models.FormVersion{}).Where("form_id = ?", formID).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+		return form, models.FormVersion{}, err
+	}
+	next := form.Version
+	if latest > 0 {
+		next = max(form.Version, latest) + 1
+	}
+
+	now := time.Now()
+	version := models.FormVersion{
+		FormID:      form.ID,
+		Version:     next,
+		Settings:    form.Settings,
+		Layout:      form.Layout,
+		Schema:      datatypes.JSON(schema),
+		PublishedAt: now,
+		PublishedBy: publishedBy,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return form, models.FormVersion{}, fmt.Errorf("create version: %w", err)
+	}
+
+	form.Version = next
+	form.Status = "published"
+	form.PublishedAt = &now
+	if err := tx.Model(&form).Updates(map[string]interface{}{
+		"version":      form.Version,
+		"status":       form.Status,
+		"published_at": now,
+	}).Error; err != nil {
+		return form, models.FormVersion{}, err
+	}
+	return form, version, nil
+}
+
+// MigrateDraftsToVersion re-pins the form's unsubmitted submissions to
+// version. Answers to fields dropped from that version are kept but no longer
+// shown or validated. Returns the number of submissions moved.
+func MigrateDraftsToVersion(tx *gorm.DB, formID uuid.UUID, version int) (int64, error) {
+	result := tx.Model(&models.FormSubmission{}).
+		Where("form_id = ? AND submitted_at IS NULL AND status IN ?", formID, []string{"draft", "in_progress"}).
+		Where("form_version <> ?", version).
+		Update("form_version", version)
+	return result.RowsAffected, result.Error
+}
+
+// LoadFormVersion returns a form's published snapshot, or
+// gorm.ErrRecordNotFound when that version was never published
+func LoadFormVersion(db *gorm.DB, formID uuid.UUID, version int) (models.FormVersion, models.FormVersionSchema, error) {
+	var snapshot models.FormVersion
+	var schema models.FormVersionSchema
+	if err := db.Where("form_id = ? AND version = ?", formID, version).First(&snapshot).Error; err != nil {
+		return snapshot, schema, err
+	}
+	if err := json.Unmarshal(snapshot.Schema, &schema); err != nil {
+		return snapshot, schema, fmt.Errorf("decode version %d: %w", version, err)
+	}
+	return snapshot, schema, nil
+}
+
+// ApplyFormVersion swaps a form's sections, fields, settings and layout for
+// a published snapshot. Returns false, leaving the form untouched, when the
+// version has no snapshot (forms and submissions from before versioning).
+func ApplyFormVersion(db *gorm.DB, form *models.Form, version int) bool {
+	snapshot, schema, err := LoadFormVersion(db, form.ID, version)
+	if err != nil {
+		return false
+	}
+	form.Sections = schema.Sections
+	form.Fields = schema.Fields
+	form.Settings = snapshot.Settings
+	form.Layout = snapshot.Layout
+	form.Version = version
+	return true
+}
+
+// PinnedFormFields returns the fields a submission renders and validates
+// against: its pinned version's snapshot, or the form's live fields when
+// that version has no snapshot. Snapshot fields since deleted from the live
+// form are dropped, as their answers can no longer be stored.
+func PinnedFormFields(db *gorm.DB, form models.Form, version int) []models.FormField {
+	if _, schema, err := LoadFormVersion(db, form.ID, version); err == nil {
+		var liveIDs []uuid.UUID
+		db.Model(&models.FormField{}).Where("form_id = ?", form.ID).Pluck("id", &liveIDs)
+		live := make(map[uuid.UUID]bool, len(liveIDs))
+		for _, id := range liveIDs {
+			live[id] = true
+		}
+		fields := make([]models.FormField, 0, len(schema.Fields))
+		for _, field := range schema.Fields {
+			if live[field.ID] {
+				fields = append(fields, field)
+			}
+		}
+		return fields
+	}
+	if form.Fields != nil {
+		return form.Fields
+	}
+	var fields []models.FormField
+	db.Where("form_id = ?", form.ID).Order("sort_order ASC").Find(&fields)
+	return fields
+}
+
+// DiffFormVersions compares two published snapshots. Fields and sections are
+// matched by ID, so a renamed field key shows up as a change, not a removal.
+func DiffFormVersions(from, to models.FormVersion) (FormVersionDiff, error) {
+	diff := FormVersionDiff{
+		From:            from.Version,
+		To:              to.Version,
+		AddedFields:     []FieldVersionDiff{},
+		RemovedFields:   []FieldVersionDiff{},
+		ChangedFields:   []FieldVersionDiff{},
+		AddedSections:   []SectionVersionDiff{},
+		RemovedSections: []SectionVersionDiff{},
+		ChangedSections: []SectionVersionDiff{},
+		SettingsChanged: !jsonEqual(from.Settings, to.Settings),
+		LayoutChanged:   !jsonEqual(from.Layout, to.Layout),
+	}
+
+	var before, after models.FormVersionSchema
+	if err := json.Unmarshal(from.Schema, &before); err != nil {
+		return diff, fmt.Errorf("decode version %d: %w", from.Version, err)
+	}
+	if err := json.Unmarshal(to.Schema, &after); err != nil {
+		return diff, fmt.Errorf("decode version %d: %w", to.Version, err)
+	}
+
+	oldFields := make(map[uuid.UUID]models.FormField, len(before.Fields))
+	for _, field := range before.Fields {
+		oldFields[field.ID] = field
+	}
+	for _, field := range after.Fields {
+		old, existed := oldFields[field.ID]
+		delete(oldFields, field.ID)
+		entry := FieldVersionDiff{FieldID: field.ID, FieldKey: field.FieldKey, Label: field.Label}
+		if !existed {
+			diff.AddedFields = append(diff.AddedFields, entry)
+			continue
+		}
+		if entry.Changes = fieldChanges(old, field); len(entry.Changes) > 0 {
+			diff.ChangedFields = append(diff.ChangedFields, entry)
+		}
+	}
+	for _, field := range before.Fields {
+		if _, removed := oldFields[field.ID]; removed {
+			diff.RemovedFields = append(diff.RemovedFields, FieldVersionDiff{FieldID: field.ID, FieldKey: field.FieldKey, Label: field.Label})
+		}
+	}
+
+	oldSections := make(map[uuid.UUID]models.FormSection, len(before.Sections))
+	for _, section := range before.Sections {
+		oldSections[section.ID] = section
+	}
+	for _, section := range after.Sections {
+		old, existed := oldSections[section.ID]
+		delete(oldSections, section.ID)
+		entry := SectionVersionDiff{SectionID: section.ID, Name: section.Name}
+		if !existed {
+			diff.AddedSections = append(diff.AddedSections, entry)
+			continue
+		}
+		if entry.Changes = sectionChanges(old, section); len(entry.Changes) > 0 {
+			diff.ChangedSections = append(diff.ChangedSections, entry)
+		}
+	}
+	for _, section := range before.Sections {
+		if _, removed := oldSections[section.ID]; removed {
+			diff.RemovedSections = append(diff.RemovedSections, SectionVersionDiff{SectionID: section.ID, Name: section.Name})
+		}
+	}
+
+	return diff, nil
+}
+
+// fieldChanges names the properties that differ between two snapshots of a field
+func fieldChanges(before, after models.FormField) []string {
+	var changes []string
+	check := func(name string, changed bool) {
+		if changed {
+			changes = append(changes, name)
+		}
+	}
+	check("field_key", before.FieldKey != after.FieldKey)
+	check("field_type", before.FieldType != after.FieldType)
+	check("label", before.Label != after.Label)
+	check("description", !reflect.DeepEqual(before.Description, after.Description))
+	check("placeholder", !reflect.DeepEqual(before.Placeholder, after.Placeholder))
+	check("help_text", !reflect.DeepEqual(before.HelpText, after.HelpText))
+	check("required", before.Required != after.Required)
+	check("validation", !jsonEqual(before.Validation, after.Validation))
+	check("options", !jsonEqual(before.Options, after.Options))
+	check("conditions", !jsonEqual(before.Conditions, after.Conditions))
+	check("prefill_value", !reflect.DeepEqual(before.PrefillValue, after.PrefillValue))
+	check("calculation_rule", !reflect.DeepEqual(before.CalculationRule, after.CalculationRule))
+	check("section_id", !reflect.DeepEqual(before.SectionID, after.SectionID))
+	check("sort_order", before.SortOrder != after.SortOrder)
+	check("width", before.Width != after.Width)
+	check("category", before.Category != after.Category)
+	return changes
+}
+
+// sectionChanges names the properties that differ between two snapshots of a section
+func sectionChanges(before, after models.FormSection) []string {
+	var changes []string
+	if before.Name != after.Name {
+		changes = append(changes, "name")
+	}
+	if !reflect.DeepEqual(before.Description, after.Description) {
+		changes = append(changes, "description")
+	}
+	if before.SortOrder != after.SortOrder {
+		changes = append(changes, "sort_order")
+	}
+	if !jsonEqual(before.Conditions, after.Conditions) {
+		changes = append(changes, "conditions")
+	}
+	return changes
+}
+
+// jsonEqual compares two JSON documents by value, so key order and
+// whitespace don't count as changes
+func jsonEqual(a, b datatypes.JSON) bool {
+	var av, bv interface{}
+	if len(a) > 0 {
+		if err := json.Unmarshal(a, &av); err != nil {
+			return string(a) == string(b)
+		}
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &bv); err != nil {
+			return string(a) == string(b)
+		}
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -11,6 +11,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/Jsanchez767/matic-platform/tracing"
 )
 
 // GeminiClient handles communication with Google Gemini API
@@ -117,7 +119,8 @@ func NewGeminiClient() *GeminiClient {
 		APIKey:  apiKey,
 		BaseURL: "https://generativelanguage.googleapis.com/v1beta",
 		HTTPClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: tracing.Transport("gemini", nil),
 		},
 	}
 }
@@ -3,13 +3,16 @@ package services
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
 	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/Jsanchez767/matic-platform/tracing"
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -19,57 +22,26 @@ import (
 
 // SendGmailEmail sends an email via Gmail API
 // This is a helper function that can be used by EmailRouter
-func SendGmailEmail(ctx context.Context, workspaceID uuid.UUID, to string, toName string, from string, fromName string, subject string, body string, bodyHTML string, threadID string, inReplyTo string, references string) (messageID string, threadIDResult string, err error) {
-	// Get Gmail connection
-	var connection models.GmailConnection
-	if err := database.DB.Where("workspace_id = ?", workspaceID).First(&connection).Error; err != nil {
-		return "", "", fmt.Errorf("Gmail not connected: %w", err)
-	}
-
-	// Create OAuth token
-	token := &oauth2.Token{
-		AccessToken:  connection.AccessToken,
-		RefreshToken: connection.RefreshToken,
-		Expiry:       connection.TokenExpiry,
-	}
-
-	config := getGmailOAuthConfig()
-
-	// Refresh token if expired
-	if token.Expiry.Before(time.Now()) {
-		tokenSource := config.TokenSource(ctx, token)
-		newToken, err := tokenSource.Token()
-		if err != nil {
-			// Mark connection as needing reconnect
-			connection.NeedsReconnect = true
-			connection.ReconnectReason = "Token refresh failed. Please reconnect your Gmail account."
-			database.DB.Save(&connection)
-			return "", "", fmt.Errorf("token refresh failed: %w", err)
-		}
-		if newToken.AccessToken != token.AccessToken {
-			connection.AccessToken = newToken.AccessToken
-			connection.RefreshToken = newToken.RefreshToken
-			connection.TokenExpiry = newToken.Expiry
-			database.DB.Save(&connection)
-		}
-		token = newToken
-	}
-
-	// Create Gmail service
-	client := config.Client(ctx, token)
-	gmailService, err := gmail.NewService(ctx, option.WithHTTPClient(client))
+func SendGmailEmail(ctx context.Context, workspaceID uuid.UUID, to string, toName string, from string, fromName string, subject string, body string, bodyHTML string, threadID string, opts GmailMessageOptions) (messageID string, threadIDResult string, err error) {
+	gmailService, connection, err := gmailServiceForWorkspace(ctx, workspaceID, from)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create Gmail service: %w", err)
+		return "", "", err
 	}
 
 	// Build From address
 	fromAddress := from
+	if fromAddress == "" {
+		fromAddress = connection.Email
+	}
 	if fromName != "" {
-		fromAddress = fmt.Sprintf("%s <%s>", fromName, from)
+		fromAddress = fmt.Sprintf("%s <%s>", fromName, fromAddress)
 	}
 
 	// Create MIME message
-	mimeMessage := createSimpleMIMMessage(fromAddress, to, toName, subject, body, bodyHTML)
+	mimeMessage, err := createSimpleMIMMessage(fromAddress, to, toName, subject, body, bodyHTML, opts)
+	if err != nil {
+		return "", "", err
+	}
 
 	// Create Gmail message
 	message := &gmail.Message{
@@ -93,7 +65,7 @@ func SendGmailEmail(ctx context.Context, workspaceID uuid.UUID, to string, toNam
 			strings.Contains(errStr, "unauthorized") {
 			connection.NeedsReconnect = true
 			connection.ReconnectReason = "Your Gmail authorization has expired or been revoked. Please reconnect your account."
-			database.DB.Save(&connection)
+			database.DB.Save(connection)
 		}
 		return "", "", fmt.Errorf("failed to send email: %w", err)
 	}
@@ -101,6 +73,56 @@ func SendGmailEmail(ctx context.Context, workspaceID uuid.UUID, to string, toNam
 	return sentMessage.Id, sentMessage.ThreadId, nil
 }
 
+// gmailServiceForWorkspace returns a Gmail API client for the workspace's
+// connected account for from, or its first account when from is empty or
+// not connected, refreshing the OAuth token if it has expired
+func gmailServiceForWorkspace(ctx context.Context, workspaceID uuid.UUID, from string) (*gmail.Service, *models.GmailConnection, error) {
+	// Get Gmail connection
+	var connection models.GmailConnection
+	if from == "" || database.DB.Where("workspace_id = ? AND email = ?", workspaceID, from).First(&connection).Error != nil {
+		if err := database.DB.Where("workspace_id = ?", workspaceID).First(&connection).Error; err != nil {
+			return nil, nil, fmt.Errorf("Gmail not connected: %w", err)
+		}
+	}
+
+	// Create OAuth token
+	token := &oauth2.Token{
+		AccessToken:  connection.AccessToken,
+		RefreshToken: connection.RefreshToken,
+		Expiry:       connection.TokenExpiry,
+	}
+
+	config := getGmailOAuthConfig()
+
+	// Refresh token if expired
+	if token.Expiry.Before(time.Now()) {
+		tokenSource := config.TokenSource(ctx, token)
+		newToken, err := tokenSource.Token()
+		if err != nil {
+			// Mark connection as needing reconnect
+			connection.NeedsReconnect = true
+			connection.ReconnectReason = "Token refresh failed. Please reconnect your Gmail account."
+			database.DB.Save(&connection)
+			return nil, nil, fmt.Errorf("token refresh failed: %w", err)
+		}
+		if newToken.AccessToken != token.AccessToken {
+			connection.AccessToken = newToken.AccessToken
+			connection.RefreshToken = newToken.RefreshToken
+			connection.TokenExpiry = newToken.Expiry
+			database.DB.Save(&connection)
+		}
+		token = newToken
+	}
+
+	// Create Gmail service
+	client := config.Client(tracing.OAuthContext(ctx, "gmail"), token)
+	gmailService, err := gmail.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Gmail service: %w", err)
+	}
+	return gmailService, &connection, nil
+}
+
 // getGmailOAuthConfig creates Gmail OAuth config from environment variables
 func getGmailOAuthConfig() *oauth2.Config {
 	baseURL := os.Getenv("GO_BACKEND_URL")
@@ -121,8 +143,23 @@ func getGmailOAuthConfig() *oauth2.Config {
 	}
 }
 
+// GmailMessageOptions are the optional headers and attachments of a Gmail message
+type GmailMessageOptions struct {
+	ReplyTo     string
+	InReplyTo   string
+	References  string
+	Headers     map[string]string // Extra headers, e.g. List-Unsubscribe
+	Attachments []EmailAttachment
+}
+
 // createSimpleMIMMessage creates a simple MIME message for email sending
-func createSimpleMIMMessage(from, to, toName, subject, textBody, htmlBody string) string {
+func createSimpleMIMMessage(from, to, toName, subject, textBody, htmlBody string, opts GmailMessageOptions) (string, error) {
+	for _, value := range []string{from, to, toName, subject, opts.ReplyTo, opts.InReplyTo, opts.References} {
+		if strings.ContainsAny(value, "\r\n") {
+			return "", errors.New("email headers must not contain line breaks")
+		}
+	}
+
 	var sb strings.Builder
 
 	// Headers
@@ -133,8 +170,40 @@ func createSimpleMIMMessage(from, to, toName, subject, textBody, htmlBody string
 	}
 	sb.WriteString(fmt.Sprintf("From: %s\r\n", from))
 	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	if opts.ReplyTo != "" {
+		sb.WriteString(fmt.Sprintf("Reply-To: %s\r\n", opts.ReplyTo))
+	}
+	if opts.InReplyTo != "" {
+		sb.WriteString(fmt.Sprintf("In-Reply-To: %s\r\n", opts.InReplyTo))
+	}
+	if opts.References != "" {
+		sb.WriteString(fmt.Sprintf("References: %s\r\n", opts.References))
+	}
 	sb.WriteString("MIME-Version: 1.0\r\n")
 
+	// Extra headers, e.g. List-Unsubscribe / List-Unsubscribe-Post (RFC 8058)
+	headerNames := make([]string, 0, len(opts.Headers))
+	for name := range opts.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		value := opts.Headers[name]
+		if strings.ContainsAny(name+value, "\r\n") {
+			return "", errors.New("email headers must not contain line breaks")
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s\r\n", name, value))
+	}
+
+	// Attachments wrap the body in multipart/mixed
+	var mixedBoundary string
+	if len(opts.Attachments) > 0 {
+		mixedBoundary = "mixed_" + uuid.New().String()
+		sb.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\r\n", mixedBoundary))
+		sb.WriteString("\r\n")
+		sb.WriteString(fmt.Sprintf("--%s\r\n", mixedBoundary))
+	}
+
 	// Body
 	if htmlBody != "" {
 		boundary := "boundary_" + uuid.New().String()
@@ -163,6 +232,27 @@ func createSimpleMIMMessage(from, to, toName, subject, textBody, htmlBody string
 		sb.WriteString(textBody)
 	}
 
-	return sb.String()
+	if mixedBoundary != "" {
+		sb.WriteString("\r\n")
+		for _, attachment := range opts.Attachments {
+			if strings.ContainsAny(attachment.Filename+attachment.ContentType, "\r\n\"") {
+				return "", errors.New("attachment names must not contain line breaks or quotes")
+			}
+			contentType := attachment.ContentType
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			sb.WriteString(fmt.Sprintf("--%s\r\n", mixedBoundary))
+			sb.WriteString(fmt.Sprintf("Content-Type: %s; name=\"%s\"\r\n", contentType, attachment.Filename))
+			sb.WriteString("Content-Transfer-Encoding: base64\r\n")
+			sb.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=\"%s\"\r\n", attachment.Filename))
+			sb.WriteString("\r\n")
+			sb.WriteString(attachment.Data)
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(fmt.Sprintf("--%s--\r\n", mixedBoundary))
+	}
+
+	return sb.String(), nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Jsanchez767/matic-platform/tracing"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
@@ -134,7 +135,7 @@ func (s *GoogleDriveService) GetDriveClient(ctx context.Context, accessToken, re
 		Expiry:       expiresAt,
 	}
 
-	client := s.config.Client(ctx, token)
+	client := s.config.Client(tracing.OAuthContext(ctx, "google_drive"), token)
 	srv, err := drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create drive service: %w", err)
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// Heartbeats record when each background loop last ran so /readyz can tell a
+// stalled worker from an idle one
+
+const (
+	HeartbeatEmailQueueWorker = "email_queue_worker"
+	HeartbeatJobProcessor     = "job_processor"
+	HeartbeatEmbeddingService = "embedding_service"
+	HeartbeatReplyPoller      = "reply_poller"
+	HeartbeatDecisionReleaser = "decision_releaser"
+)
+
+var heartbeats sync.Map // component -> time.Time
+
+// RecordHeartbeat marks component as having just completed a tick
+func RecordHeartbeat(component string) {
+	heartbeats.Store(component, time.Now())
+}
+
+// LastHeartbeat returns when component last ticked (false if it never has)
+func LastHeartbeat(component string) (time.Time, bool) {
+	value, ok := heartbeats.Load(component)
+	if !ok {
+		return time.Time{}, false
+	}
+	return value.(time.Time), true
+}
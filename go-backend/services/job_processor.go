@@ -5,10 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
 	"time"
 
 	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/logging"
+	"github.com/Jsanchez767/matic-platform/metrics"
+	"github.com/Jsanchez767/matic-platform/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // AsyncJobProcessor handles background job processing for embeddings and search indexing
@@ -57,6 +64,14 @@ type Job struct {
 	CreatedAt   time.Time       `json:"created_at"`
 	StartedAt   *time.Time      `json:"started_at,omitempty"`
 	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+
+	// TraceContext carries the W3C trace headers of the enqueuing request so
+	// the job span joins the same trace
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+
+	// Correlation IDs of the enqueuing request, restored into the job context for logging
+	RequestID   string `json:"request_id,omitempty"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
 }
 
 // JobProcessor manages a pool of workers to process background jobs
@@ -101,6 +116,12 @@ func InitJobProcessor(workers int) {
 	log.Printf("Job processor started with %d workers", workers)
 }
 
+// JobProcessorRunning reports whether InitJobProcessor has started the
+// global job processor
+func JobProcessorRunning() bool {
+	return defaultProcessor != nil
+}
+
 // RegisterHandler registers a handler for a specific job type
 func (jp *JobProcessor) RegisterHandler(jobType JobType, handler JobHandler) {
 	jp.handlers[jobType] = handler
@@ -108,30 +129,40 @@ func (jp *JobProcessor) RegisterHandler(jobType JobType, handler JobHandler) {
 
 // EnqueueJob adds a new job to the queue
 func EnqueueJob(jobType JobType, payload interface{}, priority JobPriority) (uuid.UUID, error) {
+	return EnqueueJobContext(context.Background(), jobType, payload, priority)
+}
+
+// EnqueueJobContext adds a new job to the queue, propagating the trace context in ctx
+func EnqueueJobContext(ctx context.Context, jobType JobType, payload interface{}, priority JobPriority) (uuid.UUID, error) {
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
 	job := Job{
-		ID:          uuid.New(),
-		Type:        jobType,
-		Priority:    priority,
-		Status:      JobStatusPending,
-		Payload:     payloadJSON,
-		Attempts:    0,
-		MaxAttempts: 3,
-		CreatedAt:   time.Now(),
+		ID:           uuid.New(),
+		Type:         jobType,
+		Priority:     priority,
+		Status:       JobStatusPending,
+		Payload:      payloadJSON,
+		Attempts:     0,
+		MaxAttempts:  3,
+		CreatedAt:    time.Now(),
+		TraceContext: tracing.Inject(ctx),
+		RequestID:    logging.RequestID(ctx),
+		WorkspaceID:  logging.WorkspaceID(ctx),
 	}
 
 	// Send to in-memory queue
 	select {
 	case defaultProcessor.jobQueue <- job:
-		log.Printf("Job %s enqueued: type=%s priority=%d", job.ID, job.Type, job.Priority)
+		metrics.JobQueueDepth.Set(float64(len(defaultProcessor.jobQueue)))
+		slog.DebugContext(ctx, "job enqueued", "job_id", job.ID, "job_type", job.Type, "priority", job.Priority)
 		return job.ID, nil
 	default:
 		// Queue full, optionally persist to database for later processing
-		log.Printf("Job queue full, job %s will be processed later", job.ID)
+		metrics.JobsTotal.WithLabelValues(string(job.Type), "dropped").Inc()
+		slog.WarnContext(ctx, "job queue full, job dropped", "job_id", job.ID, "job_type", job.Type)
 		return job.ID, nil
 	}
 }
@@ -142,6 +173,7 @@ func (jp *JobProcessor) worker(id int) {
 	for {
 		select {
 		case job := <-jp.jobQueue:
+			metrics.JobQueueDepth.Set(float64(len(jp.jobQueue)))
 			jp.processJob(id, job)
 		case <-jp.stopChannel:
 			log.Printf("Worker %d stopped", id)
@@ -154,8 +186,24 @@ func (jp *JobProcessor) worker(id int) {
 func (jp *JobProcessor) processJob(workerID int, job Job) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
+	if job.RequestID != "" {
+		ctx = logging.WithRequestID(ctx, job.RequestID)
+	}
+	if job.WorkspaceID != "" {
+		ctx = logging.WithWorkspaceID(ctx, job.WorkspaceID)
+	}
+
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, job.TraceContext), "job "+string(job.Type),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("job.id", job.ID.String()),
+			attribute.String("job.type", string(job.Type)),
+			attribute.Int("job.attempt", job.Attempts+1),
+		))
+	defer span.End()
 
-	log.Printf("Worker %d processing job %s (type=%s)", workerID, job.ID, job.Type)
+	logger := slog.With("worker", workerID, "job_id", job.ID, "job_type", job.Type)
+	logger.DebugContext(ctx, "processing job")
 
 	job.Status = JobStatusProcessing
 	now := time.Now()
@@ -164,33 +212,45 @@ func (jp *JobProcessor) processJob(workerID int, job Job) {
 
 	handler, exists := jp.handlers[job.Type]
 	if !exists {
-		log.Printf("No handler for job type %s", job.Type)
+		logger.ErrorContext(ctx, "no handler for job type")
+		metrics.JobsTotal.WithLabelValues(string(job.Type), "failed").Inc()
 		job.Status = JobStatusFailed
 		job.Error = fmt.Sprintf("no handler for job type %s", job.Type)
+		span.SetStatus(codes.Error, job.Error)
 		return
 	}
 
 	err := handler(ctx, job)
+	duration := time.Since(now).Seconds()
+	RecordHeartbeat(HeartbeatJobProcessor)
 	if err != nil {
-		log.Printf("Job %s failed (attempt %d/%d): %v", job.ID, job.Attempts, job.MaxAttempts, err)
+		logger.WarnContext(ctx, "job failed", "attempt", job.Attempts, "max_attempts", job.MaxAttempts, "error", err)
 		job.Error = err.Error()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 
 		if job.Attempts < job.MaxAttempts {
 			// Retry with exponential backoff
 			retryDelay := time.Duration(job.Attempts*job.Attempts) * time.Second
-			log.Printf("Retrying job %s in %v", job.ID, retryDelay)
+			metrics.JobDuration.WithLabelValues(string(job.Type), "retrying").Observe(duration)
+			metrics.JobsTotal.WithLabelValues(string(job.Type), "retrying").Inc()
+			logger.InfoContext(ctx, "retrying job", "retry_in", retryDelay.String())
 			time.AfterFunc(retryDelay, func() {
 				jp.jobQueue <- job
 			})
 		} else {
 			job.Status = JobStatusFailed
-			log.Printf("Job %s failed permanently after %d attempts", job.ID, job.Attempts)
+			metrics.JobDuration.WithLabelValues(string(job.Type), "failed").Observe(duration)
+			metrics.JobsTotal.WithLabelValues(string(job.Type), "failed").Inc()
+			logger.ErrorContext(ctx, "job failed permanently", "attempts", job.Attempts)
 		}
 	} else {
 		job.Status = JobStatusCompleted
 		completedAt := time.Now()
 		job.CompletedAt = &completedAt
-		log.Printf("Job %s completed successfully (took %v)", job.ID, completedAt.Sub(*job.StartedAt))
+		metrics.JobDuration.WithLabelValues(string(job.Type), "completed").Observe(duration)
+		metrics.JobsTotal.WithLabelValues(string(job.Type), "completed").Inc()
+		logger.InfoContext(ctx, "job completed", "duration_ms", completedAt.Sub(*job.StartedAt).Milliseconds())
 	}
 }
 
@@ -204,6 +264,11 @@ func (jp *JobProcessor) jobLoader() {
 		case <-ticker.C:
 			// Optional: Load pending jobs from database if queue is not full
 			// This ensures jobs survive server restarts
+
+			// An idle processor is healthy; with a backlog, only finished jobs count
+			if len(jp.jobQueue) == 0 {
+				RecordHeartbeat(HeartbeatJobProcessor)
+			}
 		case <-jp.stopChannel:
 			return
 		}
@@ -0,0 +1,814 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+)
+
+// Merge templates are shared by email templates, recommendation emails and
+// ending pages. The syntax extends the plain {{Field Name}} tags:
+//
+//	{{ name }}                      value, HTML-escaped in HTML output
+//	{{{ name }}} or {{ name | raw }}  value without escaping
+//	{{ name | default:"there" }}     filters: default, upper, lower, date, join, raw
+//	{{#if name}}...{{else}}...{{/if}} also {{#if status == "approved"}} and !=
+//	{{#unless name}}...{{/unless}}
+//	{{#each repeater}}{{ child }}{{/each}}  plus {{this}}, {{@index}}, {{@number}}
+//	{{! comment }}
+//
+// Names match data keys exactly first, then ignoring case, spaces, dashes and
+// underscores, so {{First Name}} finds first_name.
+
+// MergeResolver looks up the value of a merge tag name
+type MergeResolver func(name string) (interface{}, bool)
+
+// MergeRenderOptions controls how a merge template is rendered
+type MergeRenderOptions struct {
+	HTML           bool // Escape values for an HTML body
+	KeepUnresolved bool // Leave tags whose name doesn't resolve as written, e.g. for later substitution
+}
+
+// MergeTemplateError is a syntax error in a merge template
+type MergeTemplateError struct {
+	Line    int
+	Tag     string
+	Message string
+}
+
+func (e *MergeTemplateError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Kinds of merge tag issue
+const (
+	MergeIssueSyntax     = "syntax"
+	MergeIssueUnknownTag = "unknown_tag"
+)
+
+// MergeTagIssue is a problem found when validating a template
+type MergeTagIssue struct {
+	Kind    string `json:"kind"` // syntax, unknown_tag
+	Tag     string `json:"tag"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// MergeTemplate is a parsed merge template
+type MergeTemplate struct {
+	nodes []mergeNode
+}
+
+type mergeNodeKind int
+
+const (
+	mergeText mergeNodeKind = iota
+	mergeVar
+	mergeIf
+	mergeEach
+)
+
+type mergeNode struct {
+	kind     mergeNodeKind
+	text     string // Literal text, or the tag as written for a variable
+	expr     mergeExpr
+	raw      bool // Written with triple braces
+	negate   bool // {{#unless}}
+	body     []mergeNode
+	elseBody []mergeNode
+	line     int
+}
+
+type mergeExpr struct {
+	name     string
+	op       string // "", "==" or "!="
+	operand  string
+	filters  []mergeFilter
+	filtered bool
+}
+
+type mergeFilter struct {
+	name string
+	arg  string
+}
+
+var mergeFilters = map[string]bool{
+	"default": true,
+	"upper":   true,
+	"lower":   true,
+	"date":    true,
+	"join":    true,
+	"raw":     true,
+}
+
+// Named layouts for the date filter; any other argument is a Go layout
+var mergeDateLayouts = map[string]string{
+	"":         "January 2, 2006",
+	"long":     "January 2, 2006",
+	"short":    "Jan 2, 2006",
+	"iso":      "2006-01-02",
+	"datetime": "Jan 2, 2006 3:04 PM",
+	"us":       "01/02/2006",
+}
+
+// Formats accepted when parsing date values
+var mergeDateInputs = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+// ==================== Parsing ====================
+
+// ParseMergeTemplate parses a merge template
+func ParseMergeTemplate(source string) (*MergeTemplate, error) {
+	p := &mergeParser{source: source, line: 1}
+	nodes, closing, err := p.parseUntil()
+	if err != nil {
+		return nil, err
+	}
+	if closing != "" {
+		return nil, &MergeTemplateError{Line: p.line, Tag: closing, Message: fmt.Sprintf("unexpected {{%s}}", closing)}
+	}
+	return &MergeTemplate{nodes: nodes}, nil
+}
+
+type mergeParser struct {
+	source string
+	pos    int
+	line   int
+}
+
+// parseUntil parses nodes until the end of input or a closing/else tag,
+// which it returns without consuming anything after it
+func (p *mergeParser) parseUntil() ([]mergeNode, string, error) {
+	var nodes []mergeNode
+	for p.pos < len(p.source) {
+		start := strings.Index(p.source[p.pos:], "{{")
+		if start < 0 {
+			nodes = append(nodes, p.textNode(p.source[p.pos:]))
+			p.pos = len(p.source)
+			break
+		}
+		if start > 0 {
+			nodes = append(nodes, p.textNode(p.source[p.pos:p.pos+start]))
+			p.pos += start
+		}
+
+		tagLine := p.line
+		raw := strings.HasPrefix(p.source[p.pos:], "{{{")
+		open, close := "{{", "}}"
+		if raw {
+			open, close = "{{{", "}}}"
+		}
+		end := strings.Index(p.source[p.pos+len(open):], close)
+		if end < 0 {
+			return nil, "", &MergeTemplateError{Line: tagLine, Tag: open, Message: "unclosed merge tag"}
+		}
+		written := p.source[p.pos : p.pos+len(open)+end+len(close)]
+		// Editors may have HTML-escaped quotes and operators inside the tag
+		content := strings.TrimSpace(html.UnescapeString(p.source[p.pos+len(open) : p.pos+len(open)+end]))
+		p.pos += len(written)
+		p.line += strings.Count(written, "\n")
+
+		switch {
+		case content == "":
+			return nil, "", &MergeTemplateError{Line: tagLine, Tag: written, Message: "empty merge tag"}
+		case strings.HasPrefix(content, "!"):
+			continue
+		case raw:
+			expr, err := parseMergeExpr(content, false)
+			if err != nil {
+				return nil, "", &MergeTemplateError{Line: tagLine, Tag: written, Message: err.Error()}
+			}
+			nodes = append(nodes, mergeNode{kind: mergeVar, text: written, expr: expr, raw: true, line: tagLine})
+		case content == "else" || strings.HasPrefix(content, "/"):
+			return nodes, content, nil
+		case strings.HasPrefix(content, "#"):
+			node, err := p.parseBlock(content, written, tagLine)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, node)
+		default:
+			expr, err := parseMergeExpr(content, false)
+			if err != nil {
+				return nil, "", &MergeTemplateError{Line: tagLine, Tag: written, Message: err.Error()}
+			}
+			nodes = append(nodes, mergeNode{kind: mergeVar, text: written, expr: expr, line: tagLine})
+		}
+	}
+	return nodes, "", nil
+}
+
+func (p *mergeParser) textNode(text string) mergeNode {
+	node := mergeNode{kind: mergeText, text: text, line: p.line}
+	p.line += strings.Count(text, "\n")
+	return node
+}
+
+// parseBlock parses an {{#if}}, {{#unless}} or {{#each}} block after its opening tag
+func (p *mergeParser) parseBlock(content, written string, line int) (mergeNode, error) {
+	keyword, rest, _ := strings.Cut(content[1:], " ")
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return mergeNode{}, &MergeTemplateError{Line: line, Tag: written, Message: fmt.Sprintf("{{#%s}} needs a field name", keyword)}
+	}
+
+	node := mergeNode{line: line, text: written}
+	switch keyword {
+	case "if", "unless":
+		expr, err := parseMergeExpr(rest, keyword == "if")
+		if err != nil {
+			return mergeNode{}, &MergeTemplateError{Line: line, Tag: written, Message: err.Error()}
+		}
+		node.kind = mergeIf
+		node.expr = expr
+		node.negate = keyword == "unless"
+	case "each":
+		expr, err := parseMergeExpr(rest, false)
+		if err != nil {
+			return mergeNode{}, &MergeTemplateError{Line: line, Tag: written, Message: err.Error()}
+		}
+		node.kind = mergeEach
+		node.expr = expr
+	default:
+		return mergeNode{}, &MergeTemplateError{Line: line, Tag: written, Message: fmt.Sprintf("unknown block {{#%s}}", keyword)}
+	}
+
+	body, closing, err := p.parseUntil()
+	if err != nil {
+		return mergeNode{}, err
+	}
+	node.body = body
+	if closing == "else" {
+		elseBody, elseClosing, err := p.parseUntil()
+		if err != nil {
+			return mergeNode{}, err
+		}
+		node.elseBody = elseBody
+		closing = elseClosing
+	}
+	if closing != "/"+keyword {
+		if closing == "" {
+			return mergeNode{}, &MergeTemplateError{Line: line, Tag: written, Message: fmt.Sprintf("{{#%s}} is never closed with {{/%s}}", keyword, keyword)}
+		}
+		return mergeNode{}, &MergeTemplateError{Line: p.line, Tag: closing, Message: fmt.Sprintf("expected {{/%s}} but found {{%s}}", keyword, closing)}
+	}
+	return node, nil
+}
+
+// parseMergeExpr parses "name | filter:arg | filter", or with comparisons
+// allowed, `name == "value"`
+func parseMergeExpr(content string, allowComparison bool) (mergeExpr, error) {
+	segments := splitOutsideQuotes(content, '|')
+	expr := mergeExpr{name: strings.TrimSpace(segments[0])}
+
+	if allowComparison {
+		for _, op := range []string{"==", "!="} {
+			if name, operand, ok := strings.Cut(expr.name, op); ok {
+				expr.name = strings.TrimSpace(name)
+				expr.op = op
+				expr.operand = unquoteMergeArg(strings.TrimSpace(operand))
+				break
+			}
+		}
+	}
+	if expr.name == "" {
+		return expr, fmt.Errorf("missing field name")
+	}
+
+	for _, segment := range segments[1:] {
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(segment), ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !mergeFilters[name] {
+			return expr, fmt.Errorf("unknown filter %q", name)
+		}
+		filter := mergeFilter{name: name}
+		if hasArg {
+			filter.arg = unquoteMergeArg(strings.TrimSpace(arg))
+		}
+		expr.filters = append(expr.filters, filter)
+		expr.filtered = true
+	}
+	return expr, nil
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside quotes
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	var current strings.Builder
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == sep:
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	return append(parts, current.String())
+}
+
+func unquoteMergeArg(arg string) string {
+	if len(arg) >= 2 && (arg[0] == '"' || arg[0] == '\'') && arg[len(arg)-1] == arg[0] {
+		return arg[1 : len(arg)-1]
+	}
+	return arg
+}
+
+// ==================== Rendering ====================
+
+// RenderMergeTags parses and renders a template in one step
+func RenderMergeTags(source string, resolve MergeResolver, opts MergeRenderOptions) (string, error) {
+	if !strings.Contains(source, "{{") {
+		return source, nil
+	}
+	tmpl, err := ParseMergeTemplate(source)
+	if err != nil {
+		return source, err
+	}
+	return tmpl.Render(resolve, opts), nil
+}
+
+// Render renders the template with values from resolve
+func (t *MergeTemplate) Render(resolve MergeResolver, opts MergeRenderOptions) string {
+	var sb strings.Builder
+	scope := &mergeScope{resolve: resolve}
+	renderMergeNodes(&sb, t.nodes, scope, opts)
+	return sb.String()
+}
+
+// mergeScope is one level of name lookup; loops push a scope for each item
+type mergeScope struct {
+	resolve MergeResolver
+	item    interface{}
+	index   int
+	count   int
+	parent  *mergeScope
+}
+
+func (s *mergeScope) lookup(name string) (interface{}, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if scope.parent != nil {
+			switch name {
+			case "this", ".":
+				return scope.item, true
+			case "@index":
+				return float64(scope.index), true
+			case "@number":
+				return float64(scope.index + 1), true
+			case "@first":
+				return scope.index == 0, true
+			case "@last":
+				return scope.index == scope.count-1, true
+			}
+			if value, ok := lookupMergePath(scope.item, name); ok {
+				return value, true
+			}
+			continue
+		}
+		if scope.resolve == nil {
+			return nil, false
+		}
+		if value, ok := scope.resolve(name); ok {
+			return value, true
+		}
+		// Dotted names walk into nested values, e.g. {{address.city}}
+		if head, rest, ok := strings.Cut(name, "."); ok {
+			if value, ok := scope.resolve(head); ok {
+				return lookupMergePath(value, rest)
+			}
+		}
+	}
+	return nil, false
+}
+
+func renderMergeNodes(sb *strings.Builder, nodes []mergeNode, scope *mergeScope, opts MergeRenderOptions) {
+	for _, node := range nodes {
+		switch node.kind {
+		case mergeText:
+			sb.WriteString(node.text)
+		case mergeVar:
+			value, found := scope.lookup(node.expr.name)
+			if !found && opts.KeepUnresolved && !node.expr.filtered {
+				sb.WriteString(node.text)
+				continue
+			}
+			rendered, raw := applyMergeFilters(value, node.expr.filters)
+			if opts.HTML && !raw && !node.raw {
+				rendered = html.EscapeString(rendered)
+			}
+			sb.WriteString(rendered)
+		case mergeIf:
+			value, _ := scope.lookup(node.expr.name)
+			var truthy bool
+			switch node.expr.op {
+			case "==":
+				truthy = strings.EqualFold(MergeValueString(value), node.expr.operand)
+			case "!=":
+				truthy = !strings.EqualFold(MergeValueString(value), node.expr.operand)
+			default:
+				truthy = mergeTruthy(value)
+			}
+			if truthy != node.negate {
+				renderMergeNodes(sb, node.body, scope, opts)
+			} else {
+				renderMergeNodes(sb, node.elseBody, scope, opts)
+			}
+		case mergeEach:
+			value, _ := scope.lookup(node.expr.name)
+			items := mergeItems(value)
+			if len(items) == 0 {
+				renderMergeNodes(sb, node.elseBody, scope, opts)
+				continue
+			}
+			for i, item := range items {
+				itemScope := &mergeScope{item: item, index: i, count: len(items), parent: scope}
+				renderMergeNodes(sb, node.body, itemScope, opts)
+			}
+		}
+	}
+}
+
+// applyMergeFilters runs a value through its filters and reports whether
+// the result should skip escaping
+func applyMergeFilters(value interface{}, filters []mergeFilter) (string, bool) {
+	raw := false
+	var rendered string
+	stringified := false
+	current := func() string {
+		if !stringified {
+			rendered = MergeValueString(value)
+			stringified = true
+		}
+		return rendered
+	}
+
+	for _, filter := range filters {
+		switch filter.name {
+		case "raw":
+			raw = true
+		case "default":
+			if strings.TrimSpace(current()) == "" {
+				rendered = filter.arg
+			}
+		case "upper":
+			rendered = strings.ToUpper(current())
+		case "lower":
+			rendered = strings.ToLower(current())
+		case "join":
+			separator := filter.arg
+			if separator == "" {
+				separator = ", "
+			}
+			if items, ok := value.([]interface{}); ok && !stringified {
+				parts := make([]string, 0, len(items))
+				for _, item := range items {
+					if part := MergeValueString(item); part != "" {
+						parts = append(parts, part)
+					}
+				}
+				rendered = strings.Join(parts, separator)
+				stringified = true
+			}
+		case "date":
+			if formatted, ok := formatMergeDate(value, current(), filter.arg); ok {
+				rendered = formatted
+			}
+		}
+	}
+	return current(), raw
+}
+
+func formatMergeDate(value interface{}, text, format string) (string, bool) {
+	layout, named := mergeDateLayouts[strings.ToLower(format)]
+	if !named {
+		layout = format
+	}
+	if t, ok := value.(time.Time); ok {
+		return t.Format(layout), true
+	}
+	text = strings.TrimSpace(text)
+	for _, input := range mergeDateInputs {
+		if t, err := time.Parse(input, text); err == nil {
+			return t.Format(layout), true
+		}
+	}
+	return "", false
+}
+
+// MergeValueString renders a submission value as merge tag text: lists are
+// joined, choice objects show their label and booleans read Yes/No
+func MergeValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case time.Time:
+		return v.Format("January 2, 2006")
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if part := MergeValueString(item); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		for _, key := range []string{"label", "name", "title", "text", "display", "display_value", "value"} {
+			if raw, ok := v[key]; ok {
+				if s := MergeValueString(raw); s != "" {
+					return s
+				}
+			}
+		}
+		if encoded, err := json.Marshal(v); err == nil {
+			return string(encoded)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// mergeTruthy decides {{#if}}: empty values, false, 0, "false" and "no" are false
+func mergeTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case int:
+		return v != 0
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "false", "no", "0":
+			return false
+		}
+		return true
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// mergeItems returns the items an {{#each}} iterates: list elements, or map
+// values in key order
+func mergeItems(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			items = append(items, v[key])
+		}
+		return items
+	}
+	return nil
+}
+
+// lookupMergePath walks a dotted path through nested maps
+func lookupMergePath(value interface{}, path string) (interface{}, bool) {
+	for _, segment := range strings.Split(path, ".") {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = lookupMergeKey(data, segment); !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// lookupMergeKey finds a key exactly, then by normalized name
+func lookupMergeKey(data map[string]interface{}, name string) (interface{}, bool) {
+	if value, ok := data[name]; ok {
+		return value, true
+	}
+	wanted := NormalizeMergeTagName(name)
+	for key, value := range data {
+		if NormalizeMergeTagName(key) == wanted {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// NormalizeMergeTagName compares tag names ignoring case, spaces, dashes and underscores
+func NormalizeMergeTagName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name)
+}
+
+// MapMergeResolver resolves names against a data map
+func MapMergeResolver(data map[string]interface{}) MergeResolver {
+	return func(name string) (interface{}, bool) {
+		return lookupMergeKey(data, name)
+	}
+}
+
+// ChainMergeResolvers tries each resolver in turn
+func ChainMergeResolvers(resolvers ...MergeResolver) MergeResolver {
+	return func(name string) (interface{}, bool) {
+		for _, resolve := range resolvers {
+			if resolve == nil {
+				continue
+			}
+			if value, ok := resolve(name); ok {
+				return value, true
+			}
+		}
+		return nil, false
+	}
+}
+
+// ==================== Validation ====================
+
+// MergeFieldSet is the set of merge tag names a template may use
+type MergeFieldSet struct {
+	names    map[string]bool
+	children map[string]map[string]bool // Repeater name -> child field names
+}
+
+// NewMergeFieldSet creates a field set with the given names
+func NewMergeFieldSet(names ...string) *MergeFieldSet {
+	set := &MergeFieldSet{names: map[string]bool{}, children: map[string]map[string]bool{}}
+	set.Add(names...)
+	return set
+}
+
+// Add marks names as known
+func (s *MergeFieldSet) Add(names ...string) {
+	for _, name := range names {
+		if normalized := NormalizeMergeTagName(name); normalized != "" {
+			s.names[normalized] = true
+		}
+	}
+}
+
+// AddChildren marks names as known inside {{#each parent}}
+func (s *MergeFieldSet) AddChildren(parent string, names ...string) {
+	key := NormalizeMergeTagName(parent)
+	if s.children[key] == nil {
+		s.children[key] = map[string]bool{}
+	}
+	for _, name := range names {
+		if normalized := NormalizeMergeTagName(name); normalized != "" {
+			s.children[key][normalized] = true
+		}
+	}
+}
+
+// Has reports whether a top-level name is known
+func (s *MergeFieldSet) Has(name string) bool {
+	if s.names[NormalizeMergeTagName(name)] {
+		return true
+	}
+	// Dotted names are checked by their first segment
+	if head, _, ok := strings.Cut(name, "."); ok {
+		return s.names[NormalizeMergeTagName(head)]
+	}
+	return false
+}
+
+// ValidateMergeTemplate reports syntax errors and, when known is set, merge
+// tags that don't match a known field
+func ValidateMergeTemplate(source string, known *MergeFieldSet) []MergeTagIssue {
+	tmpl, err := ParseMergeTemplate(source)
+	if err != nil {
+		if syntaxErr, ok := err.(*MergeTemplateError); ok {
+			return []MergeTagIssue{{Kind: MergeIssueSyntax, Tag: syntaxErr.Tag, Line: syntaxErr.Line, Message: syntaxErr.Message}}
+		}
+		return []MergeTagIssue{{Kind: MergeIssueSyntax, Message: err.Error()}}
+	}
+	if known == nil {
+		return nil
+	}
+
+	issues := []MergeTagIssue{}
+	seen := map[string]bool{}
+	var walk func(nodes []mergeNode, loops []string)
+	check := func(node mergeNode, loops []string) {
+		name := node.expr.name
+		if known.Has(name) || strings.HasPrefix(name, "@") || name == "this" || name == "." {
+			return
+		}
+		// Inside a loop, accept the repeater's children, or anything when its children aren't known
+		for _, loop := range loops {
+			children, ok := known.children[NormalizeMergeTagName(loop)]
+			if !ok || children[NormalizeMergeTagName(name)] {
+				return
+			}
+		}
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		issues = append(issues, MergeTagIssue{
+			Kind:    MergeIssueUnknownTag,
+			Tag:     name,
+			Line:    node.line,
+			Message: fmt.Sprintf("unknown merge tag %q", name),
+		})
+	}
+	walk = func(nodes []mergeNode, loops []string) {
+		for _, node := range nodes {
+			switch node.kind {
+			case mergeVar:
+				check(node, loops)
+			case mergeIf:
+				check(node, loops)
+				walk(node.body, loops)
+				walk(node.elseBody, loops)
+			case mergeEach:
+				check(node, loops)
+				walk(node.body, append(append([]string{}, loops...), node.expr.name))
+				walk(node.elseBody, loops)
+			}
+		}
+	}
+	walk(tmpl.nodes, nil)
+	return issues
+}
+
+// FormMergeFields returns the merge tag names a form's fields provide: names,
+// labels and IDs of the legacy table fields and the v2 form fields, with
+// repeater children registered for {{#each}}
+func FormMergeFields(formID uuid.UUID) *MergeFieldSet {
+	set := NewMergeFieldSet()
+
+	var fields []models.Field
+	database.DB.Where("table_id = ?", formID).Find(&fields)
+	names := make(map[uuid.UUID]string, len(fields))
+	for _, field := range fields {
+		names[field.ID] = field.Name
+	}
+	for _, field := range fields {
+		if field.ParentFieldID != nil {
+			if parent, ok := names[*field.ParentFieldID]; ok {
+				set.AddChildren(parent, field.Name, field.Label, field.ID.String())
+				continue
+			}
+		}
+		set.Add(field.Name, field.Label, field.ID.String())
+	}
+
+	var formFields []models.FormField
+	database.DB.Where("form_id = ? OR form_id IN (?)", formID,
+		database.DB.Model(&models.Form{}).Select("id").Where("legacy_table_id = ?", formID)).
+		Find(&formFields)
+	for _, field := range formFields {
+		set.Add(field.FieldKey, field.ID.String())
+		if !field.IsRichText {
+			set.Add(field.Label)
+		}
+		if field.LegacyFieldID != nil {
+			set.Add(field.LegacyFieldID.String())
+		}
+	}
+	return set
+}
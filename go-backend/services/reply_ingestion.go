@@ -0,0 +1,630 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"google.golang.org/api/gmail/v1"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// replyPollInterval is how often Gmail threads are checked for replies
+	replyPollInterval = 2 * time.Minute
+	// replyPollLookback limits polling to threads of recently sent emails
+	replyPollLookback = 14 * 24 * time.Hour
+	// maxInboundAttachmentSize matches the portal document upload limit
+	maxInboundAttachmentSize = 10 * 1024 * 1024
+	// replyTokenLength is a 32 hex character tracking ID plus a 12 hex character signature
+	replyTokenLength = 44
+)
+
+var (
+	// ErrReplyNotMatched is returned when an inbound email can't be tied to
+	// an email sent to a submission
+	ErrReplyNotMatched = errors.New("reply doesn't match an email sent to a submission")
+	// ErrReplyAlreadyIngested is returned when the message was stored before
+	ErrReplyAlreadyIngested = errors.New("reply already ingested")
+)
+
+// Matches the attribution line mail clients put above a quoted reply
+var quotedReplyHeaderPattern = regexp.MustCompile(`(?m)^\s*On .+ wrote:\s*$`)
+
+// Match HTML line breaks and tags when only an HTML body is available
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// InboundAttachment is a file attached to a reply
+type InboundAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// InboundReply is a reply to one of our emails, parsed from Gmail or an
+// inbound webhook
+type InboundReply struct {
+	Source      string // gmail, webhook
+	MessageID   string // Dedupe key: the Gmail message ID or the RFC 5322 Message-ID
+	From        string
+	Subject     string
+	Text        string
+	HTML        string
+	ReceivedAt  time.Time
+	Attachments []InboundAttachment
+}
+
+// ==================== Reply-To Tokens ====================
+
+// InboundReplyAddress is the mailbox inbound replies are delivered to, e.g.
+// replies@inbound.example.com, set by EMAIL_INBOUND_ADDRESS
+func InboundReplyAddress() string {
+	return strings.TrimSpace(os.Getenv("EMAIL_INBOUND_ADDRESS"))
+}
+
+// ReplyToAddress returns the plus-addressed inbound address for a sent
+// email, e.g. replies+<token>@inbound.example.com, or "" when inbound
+// replies aren't configured
+func ReplyToAddress(trackingID string) string {
+	address := InboundReplyAddress()
+	at := strings.LastIndex(address, "@")
+	if at <= 0 || emailLinkSecret() == "" {
+		return ""
+	}
+	token := replyToken(trackingID)
+	if token == "" {
+		return ""
+	}
+	return address[:at] + "+" + token + address[at:]
+}
+
+// replyToken encodes a tracking ID with a signature. It's lowercase hex so
+// it survives mail systems that fold the case of the local part.
+func replyToken(trackingID string) string {
+	id, err := uuid.Parse(trackingID)
+	if err != nil {
+		return ""
+	}
+	compact := hex.EncodeToString(id[:])
+	mac := hmac.New(sha256.New, []byte(emailLinkSecret()))
+	mac.Write([]byte("reply:" + compact))
+	return compact + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// TrackingIDFromReplyAddress extracts the tracking ID from a plus-addressed
+// reply address, verifying its signature
+func TrackingIDFromReplyAddress(address string) (string, bool) {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return "", false
+	}
+	local := address[:at]
+	plus := strings.Index(local, "+")
+	if plus < 0 || emailLinkSecret() == "" {
+		return "", false
+	}
+
+	token := strings.ToLower(local[plus+1:])
+	if len(token) != replyTokenLength {
+		return "", false
+	}
+	raw, err := hex.DecodeString(token[:32])
+	if err != nil {
+		return "", false
+	}
+	id, err := uuid.FromBytes(raw)
+	if err != nil {
+		return "", false
+	}
+	if !hmac.Equal([]byte(token), []byte(replyToken(id.String()))) {
+		return "", false
+	}
+	return id.String(), true
+}
+
+// ==================== Matching ====================
+
+// FindSentEmailByReplyAddress returns the email that a reply delivered to
+// one of addresses answers
+func FindSentEmailByReplyAddress(addresses []string) (*models.SentEmail, error) {
+	for _, address := range addresses {
+		trackingID, ok := TrackingIDFromReplyAddress(address)
+		if !ok {
+			continue
+		}
+		var sentEmail models.SentEmail
+		if err := database.DB.Where("tracking_id = ?", trackingID).First(&sentEmail).Error; err != nil {
+			continue
+		}
+		if sentEmail.SubmissionID == nil {
+			return nil, ErrReplyNotMatched
+		}
+		return &sentEmail, nil
+	}
+	return nil, ErrReplyNotMatched
+}
+
+// ==================== Ingestion ====================
+
+// IngestReply stores a reply as a message on the submission the sent email
+// went to. Attachments are saved as portal documents for the submission.
+func IngestReply(ctx context.Context, sentEmail *models.SentEmail, reply InboundReply) (*models.PortalActivity, error) {
+	if sentEmail.SubmissionID == nil {
+		return nil, ErrReplyNotMatched
+	}
+	messageID := strings.TrimSpace(reply.MessageID)
+	if messageID == "" {
+		sum := sha256.Sum256([]byte(reply.From + "\n" + reply.Subject + "\n" + reply.Text + reply.HTML))
+		messageID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	var existing int64
+	database.DB.Model(&models.InboundEmail{}).
+		Where("workspace_id = ? AND message_id = ?", sentEmail.WorkspaceID, messageID).
+		Count(&existing)
+	if existing > 0 {
+		return nil, ErrReplyAlreadyIngested
+	}
+
+	// Emails about v2 submissions record the form_submissions ID; replies are
+	// kept on the linked legacy row, where portal messages live
+	var row models.Row
+	if err := database.DB.First(&row, "id IN ?", LinkedSubmissionIDs(database.DB, *sentEmail.SubmissionID)).Error; err != nil {
+		return nil, ErrReplyNotMatched
+	}
+
+	fromAddress := reply.From
+	if parsed, err := mail.ParseAddress(reply.From); err == nil {
+		fromAddress = parsed.Address
+	}
+	receivedAt := reply.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+
+	// Replies from the applicant's account show as applicant messages;
+	// anyone else's reply is kept for staff only
+	activity := models.PortalActivity{
+		FormID:          row.TableID,
+		RowID:           row.ID,
+		ActivityType:    "message",
+		Content:         replyContent(reply),
+		Visibility:      "internal",
+		ReadByApplicant: true,
+	}
+	var applicant models.PortalApplicant
+	if err := database.DB.Where("form_id = ? AND LOWER(email) = ?", row.TableID, NormalizeEmail(fromAddress)).
+		First(&applicant).Error; err == nil {
+		activity.ApplicantID = &applicant.ID
+		activity.Visibility = "both"
+	}
+
+	documents := saveReplyAttachments(ctx, row, activity.ApplicantID, reply.Attachments)
+	attachmentInfo := make([]map[string]interface{}, 0, len(documents))
+	for _, document := range documents {
+		attachmentInfo = append(attachmentInfo, map[string]interface{}{
+			"name":      document.Name,
+			"url":       document.URL,
+			"size":      document.Size,
+			"mime_type": document.MimeType,
+		})
+	}
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"source":        "email_reply",
+		"channel":       reply.Source,
+		"from":          fromAddress,
+		"subject":       reply.Subject,
+		"sent_email_id": sentEmail.ID,
+		"received_at":   receivedAt,
+		"attachments":   attachmentInfo,
+	})
+	activity.Metadata = datatypes.JSON(metadata)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		inbound := models.InboundEmail{
+			WorkspaceID:  sentEmail.WorkspaceID,
+			MessageID:    messageID,
+			Source:       reply.Source,
+			SentEmailID:  sentEmail.ID,
+			SubmissionID: row.ID,
+			FromEmail:    fromAddress,
+			Subject:      reply.Subject,
+			ReceivedAt:   receivedAt,
+		}
+		// A concurrent ingest of the same message loses here
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&inbound)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrReplyAlreadyIngested
+		}
+
+		if err := tx.Create(&activity).Error; err != nil {
+			return err
+		}
+		if event, ok := ActivityEvent(activity); ok {
+			if err := RecordSubmissionEvent(tx, event); err != nil {
+				return err
+			}
+		}
+		for i := range documents {
+			if err := tx.Create(&documents[i]).Error; err != nil {
+				return err
+			}
+			event := models.SubmissionEvent{
+				SubmissionID: row.ID,
+				FormID:       row.TableID,
+				EventType:    models.SubmissionEventDocumentUploaded,
+				Title:        "Document received",
+				Content:      documents[i].Name,
+				Metadata:     EventMetadata(map[string]interface{}{"document_id": documents[i].ID, "source": "email_reply"}),
+				DedupeKey:    EventDedupeKey(models.SubmissionEventDocumentUploaded, documents[i].ID),
+				OccurredAt:   receivedAt,
+			}
+			// Attachments on a reply only staff can see stay off the applicant's timeline
+			if activity.Visibility == "internal" {
+				event.Visibility = models.EventVisibilityInternal
+			}
+			if err := RecordSubmissionEvent(tx, event); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&inbound).Update("activity_id", activity.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "ingested email reply", "component", "reply_ingestion",
+		"sent_email_id", sentEmail.ID, "submission_id", row.ID, "source", reply.Source, "attachments", len(documents))
+	return &activity, nil
+}
+
+// replyContent returns the new text of a reply without the quoted original
+func replyContent(reply InboundReply) string {
+	text := reply.Text
+	if strings.TrimSpace(text) == "" && reply.HTML != "" {
+		withBreaks := htmlBreakPattern.ReplaceAllString(reply.HTML, "\n")
+		text = html.UnescapeString(htmlTagPattern.ReplaceAllString(withBreaks, ""))
+	}
+	content := StripQuotedReply(text)
+	if content == "" && len(reply.Attachments) > 0 {
+		content = "(attachments only)"
+	}
+	return content
+}
+
+// StripQuotedReply removes the quoted original message that mail clients
+// append below a reply
+func StripQuotedReply(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if loc := quotedReplyHeaderPattern.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]]
+	}
+
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "-----Original Message-----" || strings.HasPrefix(trimmed, "________________________________") {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// saveReplyAttachments writes attachments to the submission's portal upload
+// directory and returns unsaved document records for them. Oversized or
+// unwritable attachments are skipped.
+func saveReplyAttachments(ctx context.Context, row models.Row, applicantID *uuid.UUID, attachments []InboundAttachment) []models.PortalDocument {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	uploadDir := filepath.Join("uploads", "portal", row.ID.String())
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		slog.ErrorContext(ctx, "failed to create upload directory", "component", "reply_ingestion", "error", err)
+		return nil
+	}
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = BackendURL()
+	}
+
+	var documents []models.PortalDocument
+	for _, attachment := range attachments {
+		if len(attachment.Content) == 0 {
+			continue
+		}
+		if len(attachment.Content) > maxInboundAttachmentSize {
+			slog.WarnContext(ctx, "skipping oversized reply attachment", "component", "reply_ingestion",
+				"submission_id", row.ID, "size", len(attachment.Content))
+			continue
+		}
+
+		name := filepath.Base(strings.ReplaceAll(attachment.Filename, "\\", "/"))
+		if name == "." || name == "/" || name == "" {
+			name = "attachment"
+		}
+		ext := filepath.Ext(name)
+		filename := fmt.Sprintf("%s_%s%s", uuid.New().String()[:8], strings.TrimSuffix(name, ext), ext)
+		if err := os.WriteFile(filepath.Join(uploadDir, filename), attachment.Content, 0644); err != nil {
+			slog.ErrorContext(ctx, "failed to save reply attachment", "component", "reply_ingestion",
+				"submission_id", row.ID, "error", err)
+			continue
+		}
+
+		documents = append(documents, models.PortalDocument{
+			FormID:      row.TableID,
+			RowID:       row.ID,
+			ApplicantID: applicantID,
+			Name:        name,
+			URL:         fmt.Sprintf("%s/uploads/portal/%s/%s", strings.TrimRight(baseURL, "/"), row.ID, filename),
+			Size:        int64(len(attachment.Content)),
+			MimeType:    attachment.ContentType,
+			UploadedAt:  time.Now(),
+		})
+	}
+	return documents
+}
+
+// ==================== Gmail Polling ====================
+
+// ReplyPollingEnabled reports whether Gmail threads are polled for replies;
+// EMAIL_REPLY_POLLING=false turns polling off
+func ReplyPollingEnabled() bool {
+	return os.Getenv("EMAIL_REPLY_POLLING") != "false"
+}
+
+// ReplyPoller periodically ingests replies in the Gmail threads of emails
+// sent to submissions
+type ReplyPoller struct {
+	stop       chan bool
+	mu         sync.Mutex
+	lastPolled map[uuid.UUID]time.Time
+}
+
+// NewReplyPoller creates a new Gmail reply poller
+func NewReplyPoller() *ReplyPoller {
+	return &ReplyPoller{
+		stop:       make(chan bool),
+		lastPolled: map[uuid.UUID]time.Time{},
+	}
+}
+
+// Start starts the poller in a goroutine
+func (p *ReplyPoller) Start(ctx context.Context) {
+	go p.run(ctx)
+}
+
+// Stop stops the poller
+func (p *ReplyPoller) Stop() {
+	p.stop <- true
+}
+
+func (p *ReplyPoller) run(ctx context.Context) {
+	ticker := time.NewTicker(replyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.pollAll(ctx)
+			RecordHeartbeat(HeartbeatReplyPoller)
+		}
+	}
+}
+
+// pollAll polls every workspace with recent Gmail emails to submissions
+func (p *ReplyPoller) pollAll(ctx context.Context) {
+	var workspaceIDs []uuid.UUID
+	if err := database.DB.Model(&models.SentEmail{}).
+		Where("gmail_thread_id <> '' AND submission_id IS NOT NULL AND sent_at > ?", time.Now().Add(-replyPollLookback)).
+		Distinct("workspace_id").
+		Pluck("workspace_id", &workspaceIDs).Error; err != nil {
+		slog.ErrorContext(ctx, "failed to list workspaces for reply polling", "component", "reply_ingestion", "error", err)
+		return
+	}
+
+	for _, workspaceID := range workspaceIDs {
+		startedAt := time.Now()
+		p.mu.Lock()
+		since, ok := p.lastPolled[workspaceID]
+		p.mu.Unlock()
+		if ok {
+			// Overlap polls a little; already ingested messages are skipped
+			since = since.Add(-10 * time.Minute)
+		}
+
+		if _, err := PollGmailReplies(ctx, workspaceID, since); err != nil {
+			slog.WarnContext(ctx, "gmail reply poll failed", "component", "reply_ingestion", "workspace_id", workspaceID, "error", err)
+			continue
+		}
+		p.mu.Lock()
+		p.lastPolled[workspaceID] = startedAt
+		p.mu.Unlock()
+	}
+}
+
+// PollGmailReplies ingests replies received since the given time (zero for
+// the whole lookback window) in the Gmail threads of emails the workspace
+// sent to submissions. Returns the number of replies stored.
+func PollGmailReplies(ctx context.Context, workspaceID uuid.UUID, since time.Time) (int, error) {
+	if since.IsZero() {
+		since = time.Now().Add(-replyPollLookback)
+	}
+	var sentEmails []models.SentEmail
+	if err := database.DB.
+		Where("workspace_id = ? AND gmail_thread_id <> '' AND submission_id IS NOT NULL AND sent_at > ?",
+			workspaceID, time.Now().Add(-replyPollLookback)).
+		Order("sent_at ASC").
+		Find(&sentEmails).Error; err != nil {
+		return 0, err
+	}
+	if len(sentEmails) == 0 {
+		return 0, nil
+	}
+	// Later emails in a thread win, so replies attach to the latest submission emailed
+	threads := make(map[string]models.SentEmail, len(sentEmails))
+	for _, sentEmail := range sentEmails {
+		threads[sentEmail.GmailThreadID] = sentEmail
+	}
+
+	gmailService, connection, err := gmailServiceForWorkspace(ctx, workspaceID, "")
+	if err != nil {
+		return 0, err
+	}
+
+	ingested := 0
+	query := fmt.Sprintf("-from:me after:%d", since.Unix())
+	err = gmailService.Users.Messages.List("me").Q(query).MaxResults(100).Pages(ctx, func(page *gmail.ListMessagesResponse) error {
+		for _, listed := range page.Messages {
+			sentEmail, ok := threads[listed.ThreadId]
+			if !ok {
+				continue
+			}
+			var existing int64
+			database.DB.Model(&models.InboundEmail{}).
+				Where("workspace_id = ? AND message_id = ?", workspaceID, listed.Id).
+				Count(&existing)
+			if existing > 0 {
+				continue
+			}
+
+			message, err := gmailService.Users.Messages.Get("me", listed.Id).Format("full").Context(ctx).Do()
+			if err != nil {
+				slog.WarnContext(ctx, "failed to fetch gmail reply", "component", "reply_ingestion", "message_id", listed.Id, "error", err)
+				continue
+			}
+			reply := gmailReply(ctx, gmailService, message)
+			if parsed, err := mail.ParseAddress(reply.From); err == nil && NormalizeEmail(parsed.Address) == NormalizeEmail(connection.Email) {
+				continue
+			}
+
+			if _, err := IngestReply(ctx, &sentEmail, reply); err != nil {
+				if !errors.Is(err, ErrReplyAlreadyIngested) {
+					slog.WarnContext(ctx, "failed to ingest gmail reply", "component", "reply_ingestion", "message_id", listed.Id, "error", err)
+				}
+				continue
+			}
+			ingested++
+		}
+		return nil
+	})
+	return ingested, err
+}
+
+// gmailReply converts a full Gmail message into an inbound reply
+func gmailReply(ctx context.Context, gmailService *gmail.Service, message *gmail.Message) InboundReply {
+	reply := InboundReply{
+		Source:     "gmail",
+		MessageID:  message.Id,
+		ReceivedAt: time.UnixMilli(message.InternalDate),
+	}
+	if message.Payload == nil {
+		reply.Text = message.Snippet
+		return reply
+	}
+	for _, header := range message.Payload.Headers {
+		switch strings.ToLower(header.Name) {
+		case "from":
+			reply.From = header.Value
+		case "subject":
+			reply.Subject = header.Value
+		}
+	}
+
+	var walk func(part *gmail.MessagePart)
+	walk = func(part *gmail.MessagePart) {
+		if part.Filename != "" && part.Body != nil {
+			if part.Body.Size > maxInboundAttachmentSize {
+				return
+			}
+			data := part.Body.Data
+			if data == "" && part.Body.AttachmentId != "" {
+				body, err := gmailService.Users.Messages.Attachments.Get("me", message.Id, part.Body.AttachmentId).Context(ctx).Do()
+				if err != nil {
+					slog.WarnContext(ctx, "failed to fetch gmail attachment", "component", "reply_ingestion", "message_id", message.Id, "error", err)
+					return
+				}
+				data = body.Data
+			}
+			if content, ok := decodeGmailData(data); ok {
+				reply.Attachments = append(reply.Attachments, InboundAttachment{
+					Filename:    part.Filename,
+					ContentType: part.MimeType,
+					Content:     content,
+				})
+			}
+			return
+		}
+
+		if part.Body != nil && part.Body.Data != "" {
+			if content, ok := decodeGmailData(part.Body.Data); ok {
+				switch part.MimeType {
+				case "text/plain":
+					if reply.Text == "" {
+						reply.Text = string(content)
+					}
+				case "text/html":
+					if reply.HTML == "" {
+						reply.HTML = string(content)
+					}
+				}
+			}
+		}
+		for _, child := range part.Parts {
+			walk(child)
+		}
+	}
+	walk(message.Payload)
+
+	if reply.Text == "" && reply.HTML == "" {
+		reply.Text = message.Snippet
+	}
+	return reply
+}
+
+// decodeGmailData decodes Gmail's base64url body data, padded or not
+func decodeGmailData(data string) ([]byte, bool) {
+	if decoded, err := base64.URLEncoding.DecodeString(data); err == nil {
+		return decoded, true
+	}
+	if decoded, err := base64.RawURLEncoding.DecodeString(data); err == nil {
+		return decoded, true
+	}
+	return nil, false
+}
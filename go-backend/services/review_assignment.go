@@ -0,0 +1,468 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reasons the assignment engine refuses to run or a manual assignment is refused
+var (
+	ErrNoReviewerPool   = errors.New("stage has no reviewer pool in its assignment_rules")
+	ErrReviewerConflict = errors.New("reviewer has a conflict of interest with this submission")
+)
+
+// AssignmentResult summarizes one run of the assignment engine
+type AssignmentResult struct {
+	Assigned int            `json:"assigned"`
+	Declined int            `json:"declined,omitempty"`
+	Unfilled []uuid.UUID    `json:"unfilled"` // Submissions still short of reviewers
+	Loads    map[string]int `json:"loads"`    // Open assignments per pool reviewer afterwards
+}
+
+// reviewerDeclaration is a parsed ReviewerConflict
+type reviewerDeclaration struct {
+	attributes map[string]interface{}
+	excluded   map[string]bool
+}
+
+// ParseAssignmentRules decodes a stage's assignment rules, defaulting to one
+// reviewer per submission
+func ParseAssignmentRules(stage models.ReviewStage) (models.ReviewAssignmentRules, error) {
+	var rules models.ReviewAssignmentRules
+	if len(stage.AssignmentRules) > 0 {
+		if err := json.Unmarshal(stage.AssignmentRules, &rules); err != nil {
+			return rules, fmt.Errorf("invalid assignment_rules: %w", err)
+		}
+	}
+	if rules.ReviewersPerSubmission < 1 {
+		rules.ReviewersPerSubmission = 1
+	}
+	if rules.MaxLoad < 0 {
+		return rules, errors.New("max_load can't be negative")
+	}
+	return rules, nil
+}
+
+// RecordAssignmentAudit stores one assignment decision
+func RecordAssignmentAudit(tx *gorm.DB, audit models.ReviewAssignmentAudit) error {
+	if len(audit.Details) == 0 {
+		audit.Details = datatypes.JSON("{}")
+	}
+	return tx.Create(&audit).Error
+}
+
+// auditDetails encodes an audit's details, dropping them if they can't be
+func auditDetails(details map[string]interface{}) datatypes.JSON {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return nil
+	}
+	return datatypes.JSON(encoded)
+}
+
+// ReviewerLoads counts each reviewer's open assignments across every form
+func ReviewerLoads(db *gorm.DB, reviewerIDs []string) (map[string]int, error) {
+	type loadRow struct {
+		ReviewerID string
+		Open       int
+	}
+	var rows []loadRow
+	if err := db.Model(&models.ReviewAssignment{}).
+		Select("reviewer_id, COUNT(*) AS open").
+		Where("reviewer_id IN ? AND status = ?", reviewerIDs, models.ReviewAssignmentAssigned).
+		Group("reviewer_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	loads := make(map[string]int, len(reviewerIDs))
+	for _, id := range reviewerIDs {
+		loads[id] = 0
+	}
+	for _, row := range rows {
+		loads[row.ReviewerID] = row.Open
+	}
+	return loads, nil
+}
+
+// loadDeclarations returns the form's conflict declarations by reviewer
+func loadDeclarations(db *gorm.DB, formID uuid.UUID, reviewerIDs []string) (map[string]reviewerDeclaration, error) {
+	var conflicts []models.ReviewerConflict
+	query := db.Where("form_id = ?", formID)
+	if reviewerIDs != nil {
+		query = query.Where("reviewer_id IN ?", reviewerIDs)
+	}
+	if err := query.Find(&conflicts).Error; err != nil {
+		return nil, err
+	}
+	declarations := make(map[string]reviewerDeclaration, len(conflicts))
+	for _, conflict := range conflicts {
+		declaration := reviewerDeclaration{attributes: map[string]interface{}{}, excluded: map[string]bool{}}
+		if len(conflict.Attributes) > 0 {
+			json.Unmarshal(conflict.Attributes, &declaration.attributes)
+		}
+		var excluded []string
+		if len(conflict.ExcludedUserIDs) > 0 {
+			json.Unmarshal(conflict.ExcludedUserIDs, &excluded)
+		}
+		for _, id := range excluded {
+			declaration.excluded[id] = true
+		}
+		declarations[conflict.ReviewerID] = declaration
+	}
+	return declarations, nil
+}
+
+// conflictReason explains why a reviewer can't review an applicant's
+// submission, or returns "" when they can
+func conflictReason(reviewerID string, declaration reviewerDeclaration, applicantID string, answers map[string]interface{}, conflictFields []string) string {
+	if reviewerID == applicantID {
+		return "reviewer is the applicant"
+	}
+	if declaration.excluded[applicantID] {
+		return "applicant is on the reviewer's exclusion list"
+	}
+	for _, key := range conflictFields {
+		if sameConflictValue(declaration.attributes[key], answers[key]) {
+			return fmt.Sprintf("same %s", key)
+		}
+	}
+	return ""
+}
+
+// sameConflictValue compares a reviewer's declared value with an applicant's
+// answer, ignoring case and surrounding space. Multi-select answers and
+// lists of declared values conflict on any overlap.
+func sameConflictValue(declared, answer interface{}) bool {
+	left, right := conflictValues(declared), conflictValues(answer)
+	for value := range left {
+		if right[value] {
+			return true
+		}
+	}
+	return false
+}
+
+func conflictValues(value interface{}) map[string]bool {
+	values := map[string]bool{}
+	var add func(v interface{})
+	add = func(v interface{}) {
+		switch typed := v.(type) {
+		case nil:
+		case []interface{}:
+			for _, item := range typed {
+				add(item)
+			}
+		case []string:
+			for _, item := range typed {
+				add(item)
+			}
+		default:
+			if normalized := strings.ToLower(strings.TrimSpace(fmt.Sprint(typed))); normalized != "" {
+				values[normalized] = true
+			}
+		}
+	}
+	add(value)
+	return values
+}
+
+// AutoAssignReviewers fills each submission's reviewer slots at a stage from
+// the stage's pool. Reviewers with the fewest open assignments go first;
+// those with a conflict of interest or at max_load are passed over. Every
+// assignment, conflict and shortfall is audited. actorID is nil when the
+// engine runs on its own.
+func AutoAssignReviewers(tx *gorm.DB, stage models.ReviewStage, submissionIDs []uuid.UUID, actorID *string) (AssignmentResult, error) {
+	result := AssignmentResult{Unfilled: []uuid.UUID{}}
+	rules, err := ParseAssignmentRules(stage)
+	if err != nil {
+		return result, err
+	}
+	if len(rules.ReviewerIDs) == 0 {
+		return result, ErrNoReviewerPool
+	}
+
+	loads, err := ReviewerLoads(tx, rules.ReviewerIDs)
+	if err != nil {
+		return result, err
+	}
+	result.Loads = loads
+	declarations, err := loadDeclarations(tx, stage.FormID, rules.ReviewerIDs)
+	if err != nil {
+		return result, err
+	}
+
+	var submissions []models.FormSubmission
+	if err := tx.Preload("Form").Where("id IN ? AND current_stage_id = ?", submissionIDs, stage.ID).
+		Order("submitted_at ASC").Find(&submissions).Error; err != nil {
+		return result, err
+	}
+
+	for _, submission := range submissions {
+		var existing []models.ReviewAssignment
+		if err := tx.Where("submission_id = ? AND stage_id = ?", submission.ID, stage.ID).Find(&existing).Error; err != nil {
+			return result, err
+		}
+		taken := map[string]bool{}
+		active := 0
+		for _, assignment := range existing {
+			taken[assignment.ReviewerID] = true
+			if assignment.Status != models.ReviewAssignmentDeclined {
+				active++
+			}
+		}
+		needed := rules.ReviewersPerSubmission - active
+		if needed <= 0 {
+			continue
+		}
+
+		var answers map[string]interface{}
+		if len(rules.ConflictFields) > 0 {
+			answers = SubmissionAnswers(tx, submission)
+		}
+
+		// Least loaded first; ties go to the reviewer ID so runs are repeatable
+		pool := append([]string{}, rules.ReviewerIDs...)
+		sort.SliceStable(pool, func(i, j int) bool {
+			if loads[pool[i]] != loads[pool[j]] {
+				return loads[pool[i]] < loads[pool[j]]
+			}
+			return pool[i] < pool[j]
+		})
+
+		assigned, conflicted, atCapacity := 0, 0, 0
+		for _, reviewerID := range pool {
+			if assigned == needed {
+				break
+			}
+			if taken[reviewerID] {
+				continue
+			}
+			if reason := conflictReason(reviewerID, declarations[reviewerID], submission.UserID, answers, rules.ConflictFields); reason != "" {
+				conflicted++
+				if err := RecordAssignmentAudit(tx, models.ReviewAssignmentAudit{
+					FormID: stage.FormID, SubmissionID: submission.ID, StageID: stage.ID,
+					ReviewerID: stringRef(reviewerID), Action: models.AssignmentAuditConflict,
+					Reason: reason, ActorID: actorID,
+				}); err != nil {
+					return result, err
+				}
+				continue
+			}
+			if rules.MaxLoad > 0 && loads[reviewerID] >= rules.MaxLoad {
+				atCapacity++
+				continue
+			}
+
+			created, err := createAssignment(tx, submission.ID, stage.ID, reviewerID, actorID)
+			if err != nil {
+				return result, err
+			}
+			if !created {
+				continue // Assigned concurrently
+			}
+			if err := RecordAssignmentAudit(tx, models.ReviewAssignmentAudit{
+				FormID: stage.FormID, SubmissionID: submission.ID, StageID: stage.ID,
+				ReviewerID: stringRef(reviewerID), Action: models.AssignmentAuditAssigned,
+				Reason:  fmt.Sprintf("least loaded eligible reviewer (%d open assignments)", loads[reviewerID]),
+				Details: auditDetails(map[string]interface{}{"load_before": loads[reviewerID], "slot": active + assigned + 1, "slots": rules.ReviewersPerSubmission}),
+				ActorID: actorID,
+			}); err != nil {
+				return result, err
+			}
+			loads[reviewerID]++
+			assigned++
+			result.Assigned++
+		}
+
+		if assigned < needed {
+			result.Unfilled = append(result.Unfilled, submission.ID)
+			if err := RecordAssignmentAudit(tx, models.ReviewAssignmentAudit{
+				FormID: stage.FormID, SubmissionID: submission.ID, StageID: stage.ID,
+				Action: models.AssignmentAuditUnfilled,
+				Reason: fmt.Sprintf("%d of %d reviewer slots left open", needed-assigned, rules.ReviewersPerSubmission),
+				Details: auditDetails(map[string]interface{}{
+					"needed": needed, "assigned": assigned, "conflicts": conflicted, "at_capacity": atCapacity,
+				}),
+				ActorID: actorID,
+			}); err != nil {
+				return result, err
+			}
+		}
+		if err := SyncPrimaryReviewer(tx, submission.ID, stage.ID); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// AssignReviewer assigns one reviewer by hand. A conflict of interest
+// refuses the assignment unless override is set; either way it is audited.
+// Returns false when the reviewer was already assigned.
+func AssignReviewer(tx *gorm.DB, submission models.FormSubmission, stage models.ReviewStage, reviewerID string, actorID *string, override bool) (bool, error) {
+	rules, err := ParseAssignmentRules(stage)
+	if err != nil {
+		return false, err
+	}
+	declarations, err := loadDeclarations(tx, stage.FormID, []string{reviewerID})
+	if err != nil {
+		return false, err
+	}
+	var answers map[string]interface{}
+	if len(rules.ConflictFields) > 0 {
+		answers = SubmissionAnswers(tx, submission)
+	}
+	conflict := conflictReason(reviewerID, declarations[reviewerID], submission.UserID, answers, rules.ConflictFields)
+	if conflict != "" && (!override || reviewerID == submission.UserID) {
+		return false, fmt.Errorf("%w: %s", ErrReviewerConflict, conflict)
+	}
+
+	created, err := createAssignment(tx, submission.ID, stage.ID, reviewerID, actorID)
+	if err != nil || !created {
+		return false, err
+	}
+	reason := "assigned by staff"
+	if conflict != "" {
+		reason = "assigned by staff, overriding conflict: " + conflict
+	}
+	if err := RecordAssignmentAudit(tx, models.ReviewAssignmentAudit{
+		FormID: stage.FormID, SubmissionID: submission.ID, StageID: stage.ID,
+		ReviewerID: stringRef(reviewerID), Action: models.AssignmentAuditAssigned,
+		Reason: reason, ActorID: actorID,
+	}); err != nil {
+		return false, err
+	}
+	return true, SyncPrimaryReviewer(tx, submission.ID, stage.ID)
+}
+
+// UnassignReviewer removes a reviewer's assignment by hand and audits it.
+// Returns false when there was none.
+func UnassignReviewer(tx *gorm.DB, submission models.FormSubmission, stageID uuid.UUID, reviewerID string, actorID *string) (bool, error) {
+	result := tx.Where("submission_id = ? AND stage_id = ? AND reviewer_id = ?", submission.ID, stageID, reviewerID).
+		Delete(&models.ReviewAssignment{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	if err := RecordAssignmentAudit(tx, models.ReviewAssignmentAudit{
+		FormID: submission.FormID, SubmissionID: submission.ID, StageID: stageID,
+		ReviewerID: stringRef(reviewerID), Action: models.AssignmentAuditUnassigned,
+		Reason: "unassigned by staff", ActorID: actorID,
+	}); err != nil {
+		return false, err
+	}
+	return true, SyncPrimaryReviewer(tx, submission.ID, stageID)
+}
+
+// DeclineAssignments takes a reviewer off their open assignments on a form,
+// or only on one submission, and refills each slot from the stage's pool.
+// Used when a reviewer drops out or declines a submission.
+func DeclineAssignments(tx *gorm.DB, formID uuid.UUID, reviewerID string, submissionID *uuid.UUID, actorID *string, reason string) (AssignmentResult, error) {
+	result := AssignmentResult{Unfilled: []uuid.UUID{}, Loads: map[string]int{}}
+
+	query := tx.Model(&models.ReviewAssignment{}).
+		Joins("JOIN form_submissions fs ON fs.id = review_assignments.submission_id AND fs.current_stage_id = review_assignments.stage_id").
+		Where("fs.form_id = ? AND review_assignments.reviewer_id = ? AND review_assignments.status = ?", formID, reviewerID, models.ReviewAssignmentAssigned)
+	if submissionID != nil {
+		query = query.Where("review_assignments.submission_id = ?", *submissionID)
+	}
+	var open []models.ReviewAssignment
+	if err := query.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "review_assignments"}}).
+		Find(&open).Error; err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	byStage := map[uuid.UUID][]uuid.UUID{}
+	for _, assignment := range open {
+		if err := tx.Model(&assignment).Updates(map[string]interface{}{
+			"status":       models.ReviewAssignmentDeclined,
+			"completed_at": now,
+		}).Error; err != nil {
+			return result, err
+		}
+		if err := RecordAssignmentAudit(tx, models.ReviewAssignmentAudit{
+			FormID: formID, SubmissionID: assignment.SubmissionID, StageID: assignment.StageID,
+			ReviewerID: stringRef(reviewerID), Action: models.AssignmentAuditDeclined,
+			Reason: reason, ActorID: actorID,
+		}); err != nil {
+			return result, err
+		}
+		result.Declined++
+		byStage[assignment.StageID] = append(byStage[assignment.StageID], assignment.SubmissionID)
+	}
+
+	for stageID, submissionIDs := range byStage {
+		var stage models.ReviewStage
+		if err := tx.First(&stage, "id = ?", stageID).Error; err != nil {
+			return result, err
+		}
+		refilled, err := AutoAssignReviewers(tx, stage, submissionIDs, actorID)
+		if errors.Is(err, ErrNoReviewerPool) {
+			// Nobody to hand the work to; staff assign by hand
+			for _, id := range submissionIDs {
+				if err := RecordAssignmentAudit(tx, models.ReviewAssignmentAudit{
+					FormID: formID, SubmissionID: id, StageID: stageID,
+					Action: models.AssignmentAuditUnfilled, Reason: ErrNoReviewerPool.Error(), ActorID: actorID,
+				}); err != nil {
+					return result, err
+				}
+				if err := SyncPrimaryReviewer(tx, id, stageID); err != nil {
+					return result, err
+				}
+			}
+			result.Unfilled = append(result.Unfilled, submissionIDs...)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Assigned += refilled.Assigned
+		result.Unfilled = append(result.Unfilled, refilled.Unfilled...)
+		for id, load := range refilled.Loads {
+			result.Loads[id] = load
+		}
+	}
+	return result, nil
+}
+
+// SyncPrimaryReviewer points FormSubmission.AssignedReviewerID at the
+// earliest active reviewer at the stage, or clears it
+func SyncPrimaryReviewer(tx *gorm.DB, submissionID, stageID uuid.UUID) error {
+	var reviewerIDs []string
+	if err := tx.Model(&models.ReviewAssignment{}).
+		Where("submission_id = ? AND stage_id = ? AND status <> ?", submissionID, stageID, models.ReviewAssignmentDeclined).
+		Order("assigned_at ASC").Limit(1).Pluck("reviewer_id", &reviewerIDs).Error; err != nil {
+		return err
+	}
+	var primary *string
+	if len(reviewerIDs) > 0 {
+		primary = &reviewerIDs[0]
+	}
+	return tx.Model(&models.FormSubmission{}).Where("id = ?", submissionID).Update("assigned_reviewer_id", primary).Error
+}
+
+// createAssignment inserts an assignment, returning false when the reviewer
+// already has one for the submission at the stage
+func createAssignment(tx *gorm.DB, submissionID, stageID uuid.UUID, reviewerID string, actorID *string) (bool, error) {
+	assignment := models.ReviewAssignment{
+		SubmissionID: submissionID,
+		StageID:      stageID,
+		ReviewerID:   reviewerID,
+		Status:       models.ReviewAssignmentAssigned,
+		AssignedBy:   actorID,
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignment)
+	return result.RowsAffected > 0, result.Error
+}
+
+func stringRef(s string) *string {
+	return &s
+}
@@ -0,0 +1,510 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reasons a review action is refused
+var (
+	ErrNotInReview        = errors.New("submission is not in a review stage")
+	ErrNotAssigned        = errors.New("you are not assigned to review this submission at its current stage")
+	ErrSheetIncomplete    = errors.New("every rubric criterion needs a score before submitting")
+	ErrInvalidScore       = errors.New("invalid score")
+	ErrExitRulesNotMet    = errors.New("the stage's exit rules are not met yet")
+	ErrNoNextStage        = errors.New("no later stage accepts this submission")
+	ErrStageNotOnForm     = errors.New("stage does not belong to this submission's form")
+	ErrUnknownAggregation = errors.New("aggregation must be mean, trimmed_mean or normalized")
+)
+
+// ScoreAggregate summarizes the submitted score sheets for one submission at
+// one stage. Score is the value under the stage's aggregation method.
+type ScoreAggregate struct {
+	Reviews     int      `json:"reviews"`
+	Method      string   `json:"method"`
+	Score       *float64 `json:"score,omitempty"`
+	Mean        *float64 `json:"mean,omitempty"`
+	TrimmedMean *float64 `json:"trimmed_mean,omitempty"`
+	Normalized  *float64 `json:"normalized,omitempty"`
+}
+
+// ParseRubricCriteria decodes and checks a rubric's criteria: IDs must be
+// present and unique, weights positive and each score range non-empty
+func ParseRubricCriteria(raw datatypes.JSON) ([]models.RubricCriterion, error) {
+	var criteria []models.RubricCriterion
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &criteria); err != nil {
+			return nil, fmt.Errorf("criteria must be an array: %w", err)
+		}
+	}
+	seen := map[string]bool{}
+	for _, criterion := range criteria {
+		switch {
+		case criterion.ID == "":
+			return nil, fmt.Errorf("criterion %q has no id", criterion.Label)
+		case seen[criterion.ID]:
+			return nil, fmt.Errorf("duplicate criterion id %q", criterion.ID)
+		case criterion.Weight <= 0:
+			return nil, fmt.Errorf("criterion %q needs a positive weight", criterion.ID)
+		case criterion.MaxScore <= criterion.MinScore:
+			return nil, fmt.Errorf("criterion %q needs max_score above min_score", criterion.ID)
+		}
+		seen[criterion.ID] = true
+	}
+	return criteria, nil
+}
+
+// ParseStageRules decodes a stage's entry and exit rules, filling in the
+// defaults (one review, mean aggregation)
+func ParseStageRules(stage models.ReviewStage) (models.ReviewEntryRules, models.ReviewExitRules, error) {
+	var entry models.ReviewEntryRules
+	var exit models.ReviewExitRules
+	if len(stage.EntryRules) > 0 {
+		if err := json.Unmarshal(stage.EntryRules, &entry); err != nil {
+			return entry, exit, fmt.Errorf("invalid entry_rules: %w", err)
+		}
+	}
+	if len(stage.ExitRules) > 0 {
+		if err := json.Unmarshal(stage.ExitRules, &exit); err != nil {
+			return entry, exit, fmt.Errorf("invalid exit_rules: %w", err)
+		}
+	}
+	if exit.MinReviews < 1 {
+		exit.MinReviews = 1
+	}
+	switch exit.Aggregation {
+	case "":
+		exit.Aggregation = models.AggregationMean
+	case models.AggregationMean, models.AggregationTrimmedMean, models.AggregationNormalized:
+	default:
+		return entry, exit, ErrUnknownAggregation
+	}
+	return entry, exit, nil
+}
+
+// ScoreSheetTotal checks each score against its criterion's range and
+// returns the weighted total on a 0-100 scale. Unknown criteria are an
+// error; when complete is set, so are missing ones. Returns nil when
+// nothing is scored yet.
+func ScoreSheetTotal(criteria []models.RubricCriterion, scores map[string]float64, complete bool) (*float64, error) {
+	known := make(map[string]bool, len(criteria))
+	var weighted, weights float64
+	for _, criterion := range criteria {
+		known[criterion.ID] = true
+		score, ok := scores[criterion.ID]
+		if !ok {
+			if complete {
+				return nil, ErrSheetIncomplete
+			}
+			continue
+		}
+		if score < criterion.MinScore || score > criterion.MaxScore {
+			return nil, fmt.Errorf("%w: %q must be between %g and %g", ErrInvalidScore, criterion.ID, criterion.MinScore, criterion.MaxScore)
+		}
+		weighted += criterion.Weight * (score - criterion.MinScore) / (criterion.MaxScore - criterion.MinScore)
+		weights += criterion.Weight
+	}
+	for id := range scores {
+		if !known[id] {
+			return nil, fmt.Errorf("%w: unknown criterion %q", ErrInvalidScore, id)
+		}
+	}
+	if weights == 0 {
+		return nil, nil
+	}
+	total := roundScore(weighted / weights * 100)
+	return &total, nil
+}
+
+// AggregateStageScores aggregates the submitted score sheets at a stage for
+// the given submissions. Normalization needs every sheet in the stage, since
+// each reviewer's scores are rescaled against their own mean and spread
+// before averaging.
+func AggregateStageScores(db *gorm.DB, stage models.ReviewStage, submissionIDs []uuid.UUID) (map[uuid.UUID]ScoreAggregate, error) {
+	_, exit, err := ParseStageRules(stage)
+	if err != nil {
+		return nil, err
+	}
+
+	var sheets []models.ReviewScoreSheet
+	if err := db.Where("stage_id = ? AND status = ?", stage.ID, models.ScoreSheetSubmitted).
+		Find(&sheets).Error; err != nil {
+		return nil, err
+	}
+
+	// Every submitted sheet is a review, but only sheets with a total (the
+	// stage has a rubric) are scored
+	reviews := map[uuid.UUID]int{}
+	var scored []models.ReviewScoreSheet
+	for _, sheet := range sheets {
+		reviews[sheet.SubmissionID]++
+		if sheet.TotalScore != nil {
+			scored = append(scored, sheet)
+		}
+	}
+
+	normalized := normalizeSheets(scored)
+	raw := map[uuid.UUID][]float64{}
+	adjusted := map[uuid.UUID][]float64{}
+	for _, sheet := range scored {
+		raw[sheet.SubmissionID] = append(raw[sheet.SubmissionID], *sheet.TotalScore)
+		adjusted[sheet.SubmissionID] = append(adjusted[sheet.SubmissionID], normalized[sheet.ID])
+	}
+
+	results := make(map[uuid.UUID]ScoreAggregate, len(submissionIDs))
+	for _, id := range submissionIDs {
+		aggregate := ScoreAggregate{Reviews: reviews[id], Method: exit.Aggregation}
+		if len(raw[id]) > 0 {
+			aggregate.Mean = mean(raw[id])
+			aggregate.TrimmedMean = trimmedMean(raw[id])
+			aggregate.Normalized = mean(adjusted[id])
+			switch exit.Aggregation {
+			case models.AggregationTrimmedMean:
+				aggregate.Score = aggregate.TrimmedMean
+			case models.AggregationNormalized:
+				aggregate.Score = aggregate.Normalized
+			default:
+				aggregate.Score = aggregate.Mean
+			}
+		}
+		results[id] = aggregate
+	}
+	return results, nil
+}
+
+// normalizeSheets maps each sheet's total onto the stage-wide distribution:
+// a reviewer's z-score is taken against their own sheets, then scaled by the
+// stage's mean and spread. Reviewers with fewer than two sheets, or who give
+// every submission the same score, keep their raw totals.
+func normalizeSheets(sheets []models.ReviewScoreSheet) map[uuid.UUID]float64 {
+	var all []float64
+	byReviewer := map[string][]float64{}
+	for _, sheet := range sheets {
+		all = append(all, *sheet.TotalScore)
+		byReviewer[sheet.ReviewerID] = append(byReviewer[sheet.ReviewerID], *sheet.TotalScore)
+	}
+	stageMean, stageSpread := meanAndSpread(all)
+
+	normalized := make(map[uuid.UUID]float64, len(sheets))
+	for _, sheet := range sheets {
+		score := *sheet.TotalScore
+		own := byReviewer[sheet.ReviewerID]
+		if ownMean, ownSpread := meanAndSpread(own); len(own) > 1 && ownSpread > 0 {
+			score = stageMean + (score-ownMean)/ownSpread*stageSpread
+		}
+		normalized[sheet.ID] = score
+	}
+	return normalized
+}
+
+// meanAndSpread returns the mean and population standard deviation
+func meanAndSpread(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	avg := sum / float64(len(values))
+	var squares float64
+	for _, v := range values {
+		squares += (v - avg) * (v - avg)
+	}
+	return avg, math.Sqrt(squares / float64(len(values)))
+}
+
+func mean(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	avg, _ := meanAndSpread(values)
+	avg = roundScore(avg)
+	return &avg
+}
+
+// trimmedMean drops the single highest and lowest score when there are at
+// least three, so one outlier reviewer can't swing the result
+func trimmedMean(values []float64) *float64 {
+	if len(values) < 3 {
+		return mean(values)
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	return mean(sorted[1 : len(sorted)-1])
+}
+
+func roundScore(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// ReviewStages returns a form's stages in pipeline order
+func ReviewStages(db *gorm.DB, formID uuid.UUID) ([]models.ReviewStage, error) {
+	var stages []models.ReviewStage
+	err := db.Where("form_id = ?", formID).Order("sort_order ASC, created_at ASC").Find(&stages).Error
+	return stages, err
+}
+
+// SubmissionAnswers returns a submission's answers keyed by field key, read
+// against the form version it was submitted on
+func SubmissionAnswers(db *gorm.DB, submission models.FormSubmission) map[string]interface{} {
+	form := models.Form{ID: submission.FormID}
+	if submission.Form != nil {
+		form = *submission.Form
+	}
+	var responses []models.FormResponse
+	db.Where("submission_id = ?", submission.ID).Find(&responses)
+	return SubmissionValues(PinnedFormFields(db, form, submission.FormVersion), responses)
+}
+
+// stageAccepts reports whether a submission meets a stage's entry rules.
+// previousScore is the aggregate from the stage it is leaving, nil on entry.
+func stageAccepts(stage models.ReviewStage, data map[string]interface{}, previousScore *float64) bool {
+	entry, _, err := ParseStageRules(stage)
+	if err != nil {
+		return false
+	}
+	if entry.MinPreviousScore != nil && (previousScore == nil || *previousScore < *entry.MinPreviousScore) {
+		return false
+	}
+	return NewFormLogicService().EvaluateConditions(entry.Conditions, entry.Logic, data)
+}
+
+// EnterReviewPipeline places a newly submitted submission in the first stage
+// whose entry rules it meets. Returns nil, changing nothing, when the form
+// has no stages or none accept it.
+func EnterReviewPipeline(tx *gorm.DB, submission *models.FormSubmission) (*models.ReviewStage, error) {
+	stages, err := ReviewStages(tx, submission.FormID)
+	if err != nil || len(stages) == 0 {
+		return nil, err
+	}
+	data := SubmissionAnswers(tx, *submission)
+	for _, stage := range stages {
+		if stageAccepts(stage, data, nil) {
+			if err := moveToStage(tx, submission, stage); err != nil {
+				return nil, err
+			}
+			return &stage, nil
+		}
+	}
+	return nil, nil
+}
+
+// CheckExitRules returns the submission's aggregate at its current stage,
+// and ErrExitRulesNotMet when it doesn't have enough submitted reviews or
+// scores below the stage's minimum
+func CheckExitRules(db *gorm.DB, stage models.ReviewStage, submissionID uuid.UUID) (ScoreAggregate, error) {
+	_, exit, err := ParseStageRules(stage)
+	if err != nil {
+		return ScoreAggregate{}, err
+	}
+	aggregates, err := AggregateStageScores(db, stage, []uuid.UUID{submissionID})
+	if err != nil {
+		return ScoreAggregate{}, err
+	}
+	aggregate := aggregates[submissionID]
+	if aggregate.Reviews < exit.MinReviews {
+		return aggregate, ErrExitRulesNotMet
+	}
+	if exit.MinScore != nil && (aggregate.Score == nil || *aggregate.Score < *exit.MinScore) {
+		return aggregate, ErrExitRulesNotMet
+	}
+	return aggregate, nil
+}
+
+// AdvanceSubmission moves a submission from its current stage to the next
+// stage whose entry rules it meets. Unless force is set the current stage's
+// exit rules must hold. The submission row is locked for the move.
+func AdvanceSubmission(tx *gorm.DB, submissionID uuid.UUID, force bool) (*models.ReviewStage, error) {
+	var submission models.FormSubmission
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&submission, "id = ?", submissionID).Error; err != nil {
+		return nil, err
+	}
+	if submission.CurrentStageID == nil {
+		return nil, ErrNotInReview
+	}
+
+	var current models.ReviewStage
+	if err := tx.First(&current, "id = ?", *submission.CurrentStageID).Error; err != nil {
+		return nil, err
+	}
+	aggregate, err := CheckExitRules(tx, current, submission.ID)
+	if err != nil && !(force && errors.Is(err, ErrExitRulesNotMet)) {
+		return nil, err
+	}
+
+	stages, err := ReviewStages(tx, submission.FormID)
+	if err != nil {
+		return nil, err
+	}
+	data := SubmissionAnswers(tx, submission)
+	passed := false
+	for _, stage := range stages {
+		if stage.ID == current.ID {
+			passed = true
+			continue
+		}
+		if passed && stageAccepts(stage, data, aggregate.Score) {
+			if err := moveToStage(tx, &submission, stage); err != nil {
+				return nil, err
+			}
+			return &stage, nil
+		}
+	}
+	return nil, ErrNoNextStage
+}
+
+// MoveSubmissionToStage puts a submission in any stage of its form,
+// ignoring entry and exit rules
+func MoveSubmissionToStage(tx *gorm.DB, submission *models.FormSubmission, stageID uuid.UUID) (models.ReviewStage, error) {
+	var stage models.ReviewStage
+	if err := tx.First(&stage, "id = ?", stageID).Error; err != nil {
+		return stage, err
+	}
+	if stage.FormID != submission.FormID {
+		return stage, ErrStageNotOnForm
+	}
+	return stage, moveToStage(tx, submission, stage)
+}
+
+func moveToStage(tx *gorm.DB, submission *models.FormSubmission, stage models.ReviewStage) error {
+	updates := map[string]interface{}{"current_stage_id": stage.ID, "updated_at": time.Now()}
+	if submission.Status == "submitted" {
+		updates["status"] = "under_review"
+	}
+	if err := tx.Model(&models.FormSubmission{}).Where("id = ?", submission.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	submission.CurrentStageID = &stage.ID
+	if status, ok := updates["status"].(string); ok {
+		submission.Status = status
+	}
+	if err := RecordSubmissionEvent(tx, models.SubmissionEvent{
+		SubmissionID: submission.ID,
+		FormID:       submission.FormID,
+		EventType:    models.SubmissionEventStageChanged,
+		Title:        "Application moved to " + stage.Name,
+		Metadata:     EventMetadata(map[string]interface{}{"stage_id": stage.ID, "stage_type": stage.StageType}),
+	}); err != nil {
+		return err
+	}
+
+	// Stages that auto-assign staff the submission as it arrives
+	rules, err := ParseAssignmentRules(stage)
+	if err != nil || !rules.AutoAssign {
+		return err
+	}
+	if _, err := AutoAssignReviewers(tx, stage, []uuid.UUID{submission.ID}, nil); err != nil && !errors.Is(err, ErrNoReviewerPool) {
+		return err
+	}
+	return nil
+}
+
+// SaveScoreSheet stores a reviewer's scores for a submission at its current
+// stage. The reviewer must hold an active assignment there. Saving over a
+// submitted sheet needs a complete set of scores. Submitting completes the
+// assignment and, when the stage auto-advances and its exit rules now hold,
+// moves the submission on; the new stage is returned.
+func SaveScoreSheet(tx *gorm.DB, submissionID uuid.UUID, reviewerID string, scores map[string]float64, notes, recommendation *string, submit bool) (models.ReviewScoreSheet, *models.ReviewStage, error) {
+	var sheet models.ReviewScoreSheet
+	var submission models.FormSubmission
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&submission, "id = ?", submissionID).Error; err != nil {
+		return sheet, nil, err
+	}
+	if submission.CurrentStageID == nil {
+		return sheet, nil, ErrNotInReview
+	}
+
+	var assignment models.ReviewAssignment
+	if err := tx.Where("submission_id = ? AND stage_id = ? AND reviewer_id = ? AND status <> ?",
+		submission.ID, *submission.CurrentStageID, reviewerID, models.ReviewAssignmentDeclined).
+		First(&assignment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return sheet, nil, ErrNotAssigned
+		}
+		return sheet, nil, err
+	}
+
+	var stage models.ReviewStage
+	if err := tx.Preload("Rubric").First(&stage, "id = ?", *submission.CurrentStageID).Error; err != nil {
+		return sheet, nil, err
+	}
+	var criteria []models.RubricCriterion
+	if stage.Rubric != nil {
+		parsed, err := ParseRubricCriteria(stage.Rubric.Criteria)
+		if err != nil {
+			return sheet, nil, err
+		}
+		criteria = parsed
+	}
+	err := tx.Where("submission_id = ? AND stage_id = ? AND reviewer_id = ?", submission.ID, stage.ID, reviewerID).First(&sheet).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return sheet, nil, err
+	}
+
+	// A submitted sheet stays complete: edits to it must score every
+	// criterion so a partial total never replaces the submitted one
+	total, err := ScoreSheetTotal(criteria, scores, submit || sheet.Status == models.ScoreSheetSubmitted)
+	if err != nil {
+		return sheet, nil, err
+	}
+	scoresJSON, err := json.Marshal(scores)
+	if err != nil {
+		return sheet, nil, err
+	}
+	sheet.SubmissionID = submission.ID
+	sheet.StageID = stage.ID
+	sheet.RubricID = stage.RubricID
+	sheet.ReviewerID = reviewerID
+	sheet.Scores = datatypes.JSON(scoresJSON)
+	sheet.TotalScore = total
+	sheet.Notes = notes
+	sheet.Recommendation = recommendation
+	if sheet.Status == "" {
+		sheet.Status = models.ScoreSheetDraft
+	}
+
+	now := time.Now()
+	if submit {
+		sheet.Status = models.ScoreSheetSubmitted
+		sheet.SubmittedAt = &now
+	}
+	if err := tx.Save(&sheet).Error; err != nil {
+		return sheet, nil, err
+	}
+	if !submit {
+		return sheet, nil, nil
+	}
+
+	if err := tx.Model(&assignment).Updates(map[string]interface{}{
+		"status":       models.ReviewAssignmentCompleted,
+		"completed_at": now,
+	}).Error; err != nil {
+		return sheet, nil, err
+	}
+
+	if _, exit, err := ParseStageRules(stage); err != nil || !exit.AutoAdvance {
+		return sheet, nil, err
+	}
+	if _, err := CheckExitRules(tx, stage, submission.ID); err != nil {
+		if errors.Is(err, ErrExitRulesNotMet) {
+			return sheet, nil, nil
+		}
+		return sheet, nil, err
+	}
+	next, err := AdvanceSubmission(tx, submission.ID, false)
+	if errors.Is(err, ErrNoNextStage) {
+		return sheet, nil, nil
+	}
+	return sheet, next, err
+}
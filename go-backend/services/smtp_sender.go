@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+)
+
+// SMTP connection security modes
+const (
+	SMTPSecuritySTARTTLS = "starttls" // Plain connection upgraded with STARTTLS (usually port 587)
+	SMTPSecurityTLS      = "tls"      // Implicit TLS from the first byte (usually port 465)
+	SMTPSecurityNone     = "none"     // No encryption; only for local sinks such as MailHog, see SMTPLocalRelaysAllowed
+)
+
+var (
+	// ErrSMTPHostNotAllowed is returned when a relay resolves to a private,
+	// loopback or link-local address and local relays aren't allowed
+	ErrSMTPHostNotAllowed = errors.New("SMTP host must not resolve to a private or loopback address")
+	// ErrSMTPSecurityNotAllowed is returned for security "none" when local
+	// relays aren't allowed
+	ErrSMTPSecurityNotAllowed = errors.New(`security "none" is only available when SMTP_ALLOW_LOCAL_RELAYS is set`)
+)
+
+// smtpTimeout bounds a whole SMTP conversation when the context has no deadline
+const smtpTimeout = 30 * time.Second
+
+// SMTPMessage is a single email to send over SMTP
+type SMTPMessage struct {
+	From       string
+	FromName   string
+	To         string
+	ToName     string
+	ReplyTo    string
+	Subject    string
+	Body       string
+	BodyHTML   string
+	InReplyTo  string
+	References string
+	Headers    map[string]string // Extra headers, e.g. List-Unsubscribe
+}
+
+// IsValidSMTPSecurity reports whether security is a supported mode
+func IsValidSMTPSecurity(security string) bool {
+	switch security {
+	case SMTPSecuritySTARTTLS, SMTPSecurityTLS, SMTPSecurityNone:
+		return true
+	}
+	return false
+}
+
+// SMTPLocalRelaysAllowed reports whether SMTP_ALLOW_LOCAL_RELAYS opts in to
+// unencrypted relays on private and loopback addresses, for local sinks
+// such as MailHog. Off by default: workspace members choose the relay host,
+// so it must not reach internal services.
+func SMTPLocalRelaysAllowed() bool {
+	return os.Getenv("SMTP_ALLOW_LOCAL_RELAYS") == "true"
+}
+
+// SendSMTPEmail delivers msg through the workspace's SMTP relay and returns
+// the Message-ID it was sent with
+func SendSMTPEmail(ctx context.Context, integration models.SMTPIntegration, msg SMTPMessage) (string, error) {
+	// Always send as the integration's verified address. A different From
+	// from the caller only survives as the Reply-To.
+	if msg.From != "" && !strings.EqualFold(msg.From, integration.FromEmail) && msg.ReplyTo == "" {
+		msg.ReplyTo = msg.From
+	}
+	msg.From = integration.FromEmail
+	if msg.FromName == "" {
+		msg.FromName = integration.FromName
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", uuid.New().String(), smtpMessageIDDomain(msg.From, integration.Host))
+	raw, err := buildSMTPMessage(msg, messageID)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := dialSMTP(ctx, integration)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	if err := client.Mail(msg.From); err != nil {
+		return "", fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return "", fmt.Errorf("RCPT TO rejected: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return "", fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return "", fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("message rejected: %w", err)
+	}
+	client.Quit()
+
+	return messageID, nil
+}
+
+// CheckSMTPConnection connects, negotiates TLS and authenticates without
+// sending anything
+func CheckSMTPConnection(ctx context.Context, integration models.SMTPIntegration) error {
+	client, err := dialSMTP(ctx, integration)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Quit()
+}
+
+// dialSMTP opens an authenticated SMTP session using the integration's
+// security mode. STARTTLS is required, not opportunistic, when configured.
+func dialSMTP(ctx context.Context, integration models.SMTPIntegration) (*smtp.Client, error) {
+	if integration.Host == "" || integration.Port == 0 {
+		return nil, errors.New("SMTP host and port are required")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, smtpTimeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	addr := net.JoinHostPort(integration.Host, strconv.Itoa(integration.Port))
+	tlsConfig := &tls.Config{ServerName: integration.Host, MinVersion: tls.VersionTLS12}
+
+	localAllowed := SMTPLocalRelaysAllowed()
+	if integration.Security == SMTPSecurityNone && !localAllowed {
+		return nil, ErrSMTPSecurityNotAllowed
+	}
+
+	dialer := &net.Dialer{}
+	if !localAllowed {
+		// Checked on the resolved address so DNS can't point a public name at
+		// an internal service
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+				return ErrSMTPHostNotAllowed
+			}
+			return nil
+		}
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	// The whole conversation shares the context deadline
+	conn.SetDeadline(deadline)
+
+	if integration.Security == SMTPSecurityTLS {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", addr, err)
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, integration.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SMTP greeting from %s failed: %w", addr, err)
+	}
+
+	if integration.Security == SMTPSecuritySTARTTLS || integration.Security == "" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("%s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS with %s failed: %w", addr, err)
+		}
+	}
+
+	if integration.Username != "" {
+		// net/smtp refuses PLAIN auth over unencrypted connections to
+		// non-local hosts, so credentials never leave in clear text
+		auth := smtp.PlainAuth("", integration.Username, integration.Password, integration.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// CheckSMTPRelayHost rejects a relay host that resolves to a private,
+// loopback or link-local address unless local relays are allowed. Sends
+// check the address they actually connect to as well.
+func CheckSMTPRelayHost(ctx context.Context, host string) error {
+	if SMTPLocalRelaysAllowed() {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if isInternalIP(addr.IP) {
+			return ErrSMTPHostNotAllowed
+		}
+	}
+	return nil
+}
+
+// isInternalIP reports whether ip is not publicly routable
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// buildSMTPMessage renders msg as an RFC 5322 message with a plain text part
+// and, when present, an HTML alternative
+func buildSMTPMessage(msg SMTPMessage, messageID string) ([]byte, error) {
+	for _, value := range []string{msg.From, msg.To, msg.Subject, msg.ReplyTo, msg.InReplyTo, msg.References} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, errors.New("email headers must not contain line breaks")
+		}
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+
+	writeHeader("From", (&mail.Address{Name: msg.FromName, Address: msg.From}).String())
+	writeHeader("To", (&mail.Address{Name: msg.ToName, Address: msg.To}).String())
+	if msg.ReplyTo != "" {
+		writeHeader("Reply-To", msg.ReplyTo)
+	}
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Message-ID", messageID)
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	if msg.InReplyTo != "" {
+		writeHeader("In-Reply-To", msg.InReplyTo)
+	}
+	if msg.References != "" {
+		writeHeader("References", msg.References)
+	}
+	writeHeader("MIME-Version", "1.0")
+
+	headerNames := make([]string, 0, len(msg.Headers))
+	for name := range msg.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		value := msg.Headers[name]
+		if strings.ContainsAny(name+value, "\r\n") {
+			return nil, errors.New("email headers must not contain line breaks")
+		}
+		writeHeader(name, value)
+	}
+
+	if msg.BodyHTML == "" {
+		writeHeader("Content-Type", "text/plain; charset=UTF-8")
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		writeQuotedPrintable(&buf, msg.Body)
+		return buf.Bytes(), nil
+	}
+
+	boundary := "boundary_" + uuid.New().String()
+	writeHeader("Content-Type", fmt.Sprintf("multipart/alternative; boundary=\"%s\"", boundary))
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", msg.Body},
+		{"text/html; charset=UTF-8", msg.BodyHTML},
+	} {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader("Content-Type", part.contentType)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		writeQuotedPrintable(&buf, part.body)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) {
+	w := quotedprintable.NewWriter(buf)
+	w.Write([]byte(body))
+	w.Close()
+}
+
+// smtpMessageIDDomain picks the domain for generated Message-IDs
+func smtpMessageIDDomain(from, host string) string {
+	if _, domain, ok := strings.Cut(from, "@"); ok && domain != "" {
+		return domain
+	}
+	return host
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EventVisibilityFor returns who sees events of a type on a form: the
+// form's settings.event_visibility override, else the type's default.
+// formID may be a v2 form or its legacy table.
+func EventVisibilityFor(db *gorm.DB, formID uuid.UUID, eventType string) string {
+	var form models.Form
+	if err := db.Select("id", "settings").Where("id = ? OR legacy_table_id = ?", formID, formID).First(&form).Error; err == nil && len(form.Settings) > 0 {
+		var settings models.FormSettings
+		if json.Unmarshal(form.Settings, &settings) == nil {
+			switch visibility := settings.EventVisibility[eventType]; visibility {
+			case models.EventVisibilityApplicant, models.EventVisibilityInternal:
+				return visibility
+			}
+		}
+	}
+	if visibility, ok := models.DefaultEventVisibility[eventType]; ok {
+		return visibility
+	}
+	return models.EventVisibilityInternal
+}
+
+// RecordSubmissionEvent appends an event to a submission's log. Visibility
+// defaults to the form's setting for the type and OccurredAt to now. Events
+// with a DedupeKey are only recorded once.
+func RecordSubmissionEvent(tx *gorm.DB, event models.SubmissionEvent) error {
+	if event.Visibility == "" {
+		event.Visibility = EventVisibilityFor(tx, event.FormID, event.EventType)
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if len(event.Metadata) == 0 {
+		event.Metadata = datatypes.JSON("{}")
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event).Error
+}
+
+// EventMetadata encodes metadata for a submission event
+func EventMetadata(metadata map[string]interface{}) datatypes.JSON {
+	encoded, _ := json.Marshal(metadata)
+	return datatypes.JSON(encoded)
+}
+
+// EventDedupeKey builds the dedupe key of an event that happens once per
+// subject, e.g. EventDedupeKey("started", submissionID)
+func EventDedupeKey(eventType string, parts ...interface{}) *string {
+	key := eventType
+	for _, part := range parts {
+		key += fmt.Sprintf(":%v", part)
+	}
+	return &key
+}
+
+// ActivityEvent returns the event for a portal activity that belongs on the
+// timeline: a document request, a message or a status update. Activities
+// staff marked internal stay internal.
+func ActivityEvent(activity models.PortalActivity) (models.SubmissionEvent, bool) {
+	event := models.SubmissionEvent{
+		SubmissionID: activity.RowID,
+		FormID:       activity.FormID,
+		EventType:    activity.ActivityType,
+		Content:      activity.Content,
+		Metadata:     EventMetadata(map[string]interface{}{"activity_id": activity.ID}),
+		DedupeKey:    EventDedupeKey(activity.ActivityType, activity.ID),
+		OccurredAt:   activity.CreatedAt,
+	}
+	switch activity.ActivityType {
+	case "file_request":
+		event.EventType = models.SubmissionEventDocumentRequested
+		event.DedupeKey = EventDedupeKey(models.SubmissionEventDocumentRequested, activity.ID)
+		event.Title = "Document requested"
+	case models.SubmissionEventMessage:
+		event.Title = "New message from staff"
+		if activity.ApplicantID != nil {
+			event.Title = "You sent a message"
+		}
+	case models.SubmissionEventStatusUpdate:
+		event.Title = "Status updated"
+	default:
+		return event, false
+	}
+	if activity.Visibility == "internal" {
+		event.Visibility = models.EventVisibilityInternal
+	}
+	return event, true
+}
+
+// SubmissionTimeline returns the events logged against any of an
+// application's IDs (its v2 submission and legacy row) oldest first,
+// optionally only those the applicant may see
+func SubmissionTimeline(db *gorm.DB, submissionIDs []uuid.UUID, applicantOnly bool) ([]models.SubmissionEvent, error) {
+	query := db.Where("submission_id IN ?", submissionIDs)
+	if applicantOnly {
+		query = query.Where("visibility = ?", models.EventVisibilityApplicant)
+	}
+	var events []models.SubmissionEvent
+	err := query.Order("occurred_at ASC, created_at ASC").Find(&events).Error
+	return events, err
+}
+
+// LinkedSubmissionIDs returns id together with the legacy row or v2
+// submission linked to it, since an application's events may be logged
+// against either
+func LinkedSubmissionIDs(db *gorm.DB, id uuid.UUID) []uuid.UUID {
+	ids := []uuid.UUID{id}
+	var submission models.FormSubmission
+	if err := db.Select("id", "legacy_row_id").Where("id = ? OR legacy_row_id = ?", id, id).First(&submission).Error; err == nil {
+		if submission.ID != id {
+			ids = append(ids, submission.ID)
+		}
+		if submission.LegacyRowID != nil && *submission.LegacyRowID != id {
+			ids = append(ids, *submission.LegacyRowID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/database"
+	"github.com/Jsanchez767/matic-platform/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// Suppression reasons
+const (
+	SuppressionHardBounce     = "hard_bounce"
+	SuppressionRepeatedBounce = "repeated_bounce"
+	SuppressionComplaint      = "complaint"
+	SuppressionUnsubscribe    = "unsubscribe"
+	SuppressionManual         = "manual"
+)
+
+// Soft bounces to the same address within this window before it is suppressed
+const (
+	repeatedBounceThreshold = 3
+	repeatedBounceWindow    = 30 * 24 * time.Hour
+)
+
+// ErrRecipientSuppressed is returned when sending to an address on the
+// workspace suppression list
+var ErrRecipientSuppressed = errors.New("recipient is on the suppression list")
+
+// NormalizeEmail lowercases and strips the display name from an address so
+// suppression lookups match regardless of how the address was written
+func NormalizeEmail(email string) string {
+	email = strings.TrimSpace(email)
+	if addr, err := mail.ParseAddress(email); err == nil {
+		email = addr.Address
+	}
+	return strings.ToLower(email)
+}
+
+// SuppressionInput describes a new suppression entry
+type SuppressionInput struct {
+	WorkspaceID uuid.UUID
+	Email       string
+	Reason      string
+	Source      string
+	SentEmailID *uuid.UUID
+	Details     string
+	CreatedBy   string
+}
+
+// AddSuppression adds an address to the workspace suppression list. An
+// existing entry is kept as-is (the first reason wins).
+func AddSuppression(input SuppressionInput) (*models.EmailSuppression, error) {
+	email := NormalizeEmail(input.Email)
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, errors.New("a valid email address is required")
+	}
+
+	suppression := models.EmailSuppression{
+		WorkspaceID: input.WorkspaceID,
+		Email:       email,
+		Reason:      input.Reason,
+		Source:      input.Source,
+		SentEmailID: input.SentEmailID,
+		Details:     input.Details,
+		CreatedBy:   input.CreatedBy,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "email"}},
+		DoNothing: true,
+	}).Create(&suppression).Error; err != nil {
+		return nil, err
+	}
+
+	// On conflict nothing was inserted; return the existing row
+	if err := database.DB.Where("workspace_id = ? AND email = ?", input.WorkspaceID, email).
+		First(&suppression).Error; err != nil {
+		return nil, err
+	}
+	return &suppression, nil
+}
+
+// IsSuppressed reports whether email is on the workspace suppression list
+func IsSuppressed(workspaceID uuid.UUID, email string) (bool, *models.EmailSuppression) {
+	var suppression models.EmailSuppression
+	err := database.DB.Where("workspace_id = ? AND email = ?", workspaceID, NormalizeEmail(email)).
+		First(&suppression).Error
+	if err != nil {
+		return false, nil
+	}
+	return true, &suppression
+}
+
+// SuppressedEmails returns the subset of emails (normalized) that are
+// suppressed in the workspace, using a single query
+func SuppressedEmails(workspaceID uuid.UUID, emails []string) map[string]bool {
+	suppressed := make(map[string]bool)
+	if len(emails) == 0 {
+		return suppressed
+	}
+
+	normalized := make([]string, 0, len(emails))
+	for _, email := range emails {
+		normalized = append(normalized, NormalizeEmail(email))
+	}
+
+	var matches []string
+	database.DB.Model(&models.EmailSuppression{}).
+		Where("workspace_id = ? AND email IN ?", workspaceID, normalized).
+		Pluck("email", &matches)
+	for _, email := range matches {
+		suppressed[email] = true
+	}
+	return suppressed
+}
+
+// RecordBounce suppresses the recipient of a bounced email. Hard bounces are
+// suppressed immediately; soft bounces only once they repeat.
+func RecordBounce(sentEmail models.SentEmail, bounceType, reason string) error {
+	if isSoftBounce(bounceType) {
+		var recentBounces int64
+		database.DB.Model(&models.SentEmail{}).
+			Where("workspace_id = ? AND LOWER(recipient_email) = ? AND status = ? AND bounced_at > ?",
+				sentEmail.WorkspaceID, NormalizeEmail(sentEmail.RecipientEmail), "bounced", time.Now().Add(-repeatedBounceWindow)).
+			Count(&recentBounces)
+		if recentBounces < repeatedBounceThreshold {
+			return nil
+		}
+		_, err := AddSuppression(SuppressionInput{
+			WorkspaceID: sentEmail.WorkspaceID,
+			Email:       sentEmail.RecipientEmail,
+			Reason:      SuppressionRepeatedBounce,
+			Source:      "resend_webhook",
+			SentEmailID: &sentEmail.ID,
+			Details:     reason,
+		})
+		return err
+	}
+
+	_, err := AddSuppression(SuppressionInput{
+		WorkspaceID: sentEmail.WorkspaceID,
+		Email:       sentEmail.RecipientEmail,
+		Reason:      SuppressionHardBounce,
+		Source:      "resend_webhook",
+		SentEmailID: &sentEmail.ID,
+		Details:     reason,
+	})
+	return err
+}
+
+// isSoftBounce treats only explicitly transient bounce types as soft; an
+// unspecified type is handled as a hard bounce
+func isSoftBounce(bounceType string) bool {
+	switch strings.ToLower(strings.TrimSpace(bounceType)) {
+	case "soft", "transient", "temporary", "undetermined":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// GORM tracing callbacks.
+//
+// A span is only started when the statement context already carries a span
+// (i.e. the caller used DB.WithContext(c.Request.Context()) or a job context),
+// so unscoped background queries don't produce thousands of root traces.
+
+const gormSpanKey = "otel:span"
+
+// maxStatementLength caps db.statement so huge IN lists don't bloat spans
+const maxStatementLength = 2000
+
+// RegisterGORMCallbacks adds before/after callbacks around every GORM operation
+func RegisterGORMCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("otel:before_create", beforeCallback("create")),
+		cb.Create().After("gorm:create").Register("otel:after_create", afterCallback),
+		cb.Query().Before("gorm:query").Register("otel:before_query", beforeCallback("query")),
+		cb.Query().After("gorm:query").Register("otel:after_query", afterCallback),
+		cb.Update().Before("gorm:update").Register("otel:before_update", beforeCallback("update")),
+		cb.Update().After("gorm:update").Register("otel:after_update", afterCallback),
+		cb.Delete().Before("gorm:delete").Register("otel:before_delete", beforeCallback("delete")),
+		cb.Delete().After("gorm:delete").Register("otel:after_delete", afterCallback),
+		cb.Row().Before("gorm:row").Register("otel:before_row", beforeCallback("row")),
+		cb.Row().After("gorm:row").Register("otel:after_row", afterCallback),
+		cb.Raw().Before("gorm:raw").Register("otel:before_raw", beforeCallback("raw")),
+		cb.Raw().After("gorm:raw").Register("otel:after_raw", afterCallback),
+	)
+}
+
+func beforeCallback(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			return
+		}
+
+		spanName := "gorm." + operation
+		if db.Statement.Table != "" {
+			spanName += " " + db.Statement.Table
+		}
+		ctx, span := Tracer().Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient))
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func afterCallback(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	statement := db.Statement.SQL.String()
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength] + "..."
+	}
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.String("db.operation", strings.ToUpper(firstWord(statement))),
+		attribute.String("db.statement", statement), // bound values are not included
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+func firstWord(s string) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexAny(s, " \n\t"); idx > 0 {
+		return s[:idx]
+	}
+	return s
+}
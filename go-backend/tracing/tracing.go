@@ -0,0 +1,157 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Jsanchez767/matic-platform/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+)
+
+// OpenTelemetry tracing setup.
+//
+// Exporters (TRACING_EXPORTER):
+//   - "otlp":   OTLP over HTTP. Endpoint/headers come from the standard
+//     OTEL_EXPORTER_OTLP_ENDPOINT / OTEL_EXPORTER_OTLP_HEADERS env vars.
+//   - "stdout": pretty-printed spans on stdout, for local runs.
+//   - "" / "none": tracing disabled (spans are no-ops).
+
+const instrumentationName = "github.com/Jsanchez767/matic-platform"
+
+// Options configures the tracer provider
+type Options struct {
+	Exporter    string  // otlp, stdout, none
+	ServiceName string  // service.name resource attribute
+	SampleRatio float64 // 0..1, parent-based ratio sampling
+}
+
+// Init installs the global tracer provider and W3C propagators. The returned
+// shutdown func flushes pending spans and should be called on exit.
+func Init(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	// Always propagate context, even when spans are not exported, so upstream
+	// trace IDs survive into queued jobs and outbound calls
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch opts.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint(), stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q (expected otlp, stdout or none)", opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", opts.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	ratio := opts.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	log.Printf("🔭 Tracing enabled (exporter=%s, service=%s, sample_ratio=%.2f)", opts.Exporter, opts.ServiceName, ratio)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the application tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// ==================== Outbound HTTP ====================
+
+// Transport wraps base (or http.DefaultTransport) with a client span and the
+// provider_* Prometheus metrics for calls to the named provider
+func Transport(provider string, base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(
+		metrics.InstrumentTransport(provider, base),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return provider + " " + r.Method + " " + r.URL.Path
+		}),
+		otelhttp.WithSpanOptions(trace.WithAttributes(attribute.String("peer.service", provider))),
+	)
+}
+
+// HTTPClient returns an instrumented *http.Client for the named provider
+func HTTPClient(provider string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: Transport(provider, nil),
+	}
+}
+
+// OAuthContext makes oauth2.Config.Client (used for Gmail and Drive) build its
+// client on an instrumented transport
+func OAuthContext(ctx context.Context, provider string) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: Transport(provider, nil)})
+}
+
+// ==================== Context Propagation ====================
+
+// Inject serializes the trace context in ctx into a string map, suitable for
+// storing on queued jobs
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract restores a trace context captured by Inject
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// TraceParent returns the W3C traceparent header for ctx ("" when there is no span)
+func TraceParent(ctx context.Context) string {
+	return Inject(ctx)["traceparent"]
+}
+
+// ContextWithTraceParent restores a context from a stored traceparent header
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return Extract(ctx, map[string]string{"traceparent": traceParent})
+}